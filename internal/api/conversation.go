@@ -17,6 +17,7 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/conversationext"
 	"github.com/openimsdk/protocol/conversation"
 	"github.com/openimsdk/tools/a2r"
 )
@@ -70,3 +71,15 @@ func (o *ConversationApi) GetNotNotifyConversationIDs(c *gin.Context) {
 func (o *ConversationApi) GetPinnedConversationIDs(c *gin.Context) {
 	a2r.Call(conversation.ConversationClient.GetPinnedConversationIDs, o.Client, c)
 }
+
+func (o *ConversationApi) PinMsg(c *gin.Context) {
+	a2r.Call(conversationext.ConversationExtClient.PinMsg, o.ExtClient, c)
+}
+
+func (o *ConversationApi) UnpinMsg(c *gin.Context) {
+	a2r.Call(conversationext.ConversationExtClient.UnpinMsg, o.ExtClient, c)
+}
+
+func (o *ConversationApi) GetPinnedMsgs(c *gin.Context) {
+	a2r.Call(conversationext.ConversationExtClient.GetPinnedMsgs, o.ExtClient, c)
+}
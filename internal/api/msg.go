@@ -15,6 +15,10 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/mitchellh/mapstructure"
@@ -22,6 +26,7 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/protocol/sdkws"
@@ -119,6 +124,74 @@ func (m *MessageApi) RevokeMsg(c *gin.Context) {
 	a2r.Call(msg.MsgClient.RevokeMsg, m.Client, c)
 }
 
+func (m *MessageApi) EditMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.EditMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) AddReaction(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.AddReaction, m.ExtClient, c)
+}
+
+func (m *MessageApi) RemoveReaction(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.RemoveReaction, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetGroupMsgReadMembers(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetGroupMsgReadMembers, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetScheduledMsgs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetScheduledMsgs, m.ExtClient, c)
+}
+
+func (m *MessageApi) UpdateScheduledMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.UpdateScheduledMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) CancelScheduledMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.CancelScheduledMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) CreateMsgExportJob(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.CreateMsgExportJob, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetMsgExportJob(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetMsgExportJob, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetMsgExportJobs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetMsgExportJobs, m.ExtClient, c)
+}
+
+func (m *MessageApi) SetRetentionPolicy(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.SetRetentionPolicy, m.ExtClient, c)
+}
+
+func (m *MessageApi) DeleteRetentionPolicy(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.DeleteRetentionPolicy, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetRetentionPolicies(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetRetentionPolicies, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetRetentionSkips(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetRetentionSkips, m.ExtClient, c)
+}
+
+func (m *MessageApi) GetModerationReviews(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.GetModerationReviews, m.ExtClient, c)
+}
+
+func (m *MessageApi) ReviewModerationMsg(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.ReviewModerationMsg, m.ExtClient, c)
+}
+
+func (m *MessageApi) ForwardMsgs(c *gin.Context) {
+	a2r.Call(msgext.MsgExtClient.ForwardMsgs, m.ExtClient, c)
+}
+
 func (m *MessageApi) MarkMsgsAsRead(c *gin.Context) {
 	a2r.Call(msg.MsgClient.MarkMsgsAsRead, m.Client, c)
 }
@@ -248,6 +321,30 @@ func (m *MessageApi) SendMessage(c *gin.Context) {
 	apiresp.GinSuccess(c, respPb)
 }
 
+// ScheduleMsg stores a message which is sent at req.ScheduleTime, users can schedule their own messages.
+func (m *MessageApi) ScheduleMsg(c *gin.Context) {
+	req := apistruct.ScheduleMsgReq{}
+	if err := c.BindJSON(&req); err != nil {
+		apiresp.GinError(c, errs.ErrArgs.WithDetail(err.Error()).Wrap())
+		return
+	}
+	sendMsgReq, err := m.getSendMsgReq(c, req.SendMsg)
+	if err != nil {
+		apiresp.GinError(c, err)
+		return
+	}
+	sendMsgReq.MsgData.RecvID = req.RecvID
+	if !authverify.IsAppManagerUid(c, m.imAdminUserID) {
+		sendMsgReq.MsgData.MsgFrom = constant.UserMsgType
+	}
+	resp, err := m.ExtClient.ScheduleMsg(c, &msgext.ScheduleMsgReq{SendTime: req.ScheduleTime, MsgReq: sendMsgReq})
+	if err != nil {
+		apiresp.GinError(c, err)
+		return
+	}
+	apiresp.GinSuccess(c, resp)
+}
+
 func (m *MessageApi) SendBusinessNotification(c *gin.Context) {
 	req := struct {
 		Key        string `json:"key"`
@@ -366,8 +463,30 @@ func (m *MessageApi) GetActiveGroup(c *gin.Context) {
 	a2r.Call(msg.MsgClient.GetActiveGroup, m.Client, c)
 }
 
+// SearchMsg filters the messages by msg.SearchMessageReq. A request with a keyword searches the full text index
+// instead, it returns the ranked hits across the conversations of req.UserID, which defaults to the operator.
 func (m *MessageApi) SearchMsg(c *gin.Context) {
-	a2r.Call(msg.MsgClient.SearchMessage, m.Client, c)
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apiresp.GinError(c, errs.ErrArgs.WrapMsg("read request body failed", "err", err.Error()))
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var fullText struct {
+		Keyword string `json:"keyword"`
+	}
+	if err := json.Unmarshal(body, &fullText); err != nil || fullText.Keyword == "" {
+		a2r.Call(msg.MsgClient.SearchMessage, m.Client, c)
+		return
+	}
+	a2r.Call(msgext.MsgExtClient.FullTextSearchMsg, m.ExtClient, c, &a2r.Option[msgext.FullTextSearchMsgReq, msgext.FullTextSearchMsgResp]{
+		BindAfter: func(req *msgext.FullTextSearchMsgReq) error {
+			if req.UserID == "" {
+				req.UserID = mcontext.GetOpUserID(c)
+			}
+			return nil
+		},
+	})
 }
 
 func (m *MessageApi) GetServerTime(c *gin.Context) {
@@ -15,9 +15,12 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/translate"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/tools/apiresp"
 	"github.com/openimsdk/tools/discovery"
@@ -64,6 +67,7 @@ func newGinRouter(disCov discovery.SvcDiscoveryRegistry, config *Config) *gin.En
 	conversationRpc := rpcclient.NewConversation(disCov, config.Share.RpcRegisterName.Conversation)
 	authRpc := rpcclient.NewAuth(disCov, config.Share.RpcRegisterName.Auth)
 	thirdRpc := rpcclient.NewThird(disCov, config.Share.RpcRegisterName.Third, config.API.Prometheus.GrafanaURL)
+	pushRpc := rpcclient.NewPush(disCov, config.Share.RpcRegisterName.Push)
 	switch config.API.Api.CompressionLevel {
 	case NoCompression:
 	case DefaultCompression:
@@ -102,6 +106,10 @@ func newGinRouter(disCov discovery.SvcDiscoveryRegistry, config *Config) *gin.En
 		userRouterGroup.POST("/add_notification_account", u.AddNotificationAccount)
 		userRouterGroup.POST("/update_notification_account", u.UpdateNotificationAccountInfo)
 		userRouterGroup.POST("/search_notification_account", u.SearchNotificationAccount)
+
+		userRouterGroup.POST("/set_notification_setting", u.SetNotificationSetting)
+		userRouterGroup.POST("/delete_notification_setting", u.DeleteNotificationSetting)
+		userRouterGroup.POST("/get_notification_settings", u.GetNotificationSettings)
 	}
 	// friend routing group
 	friendRouterGroup := r.Group("/friend")
@@ -178,7 +186,11 @@ func newGinRouter(disCov discovery.SvcDiscoveryRegistry, config *Config) *gin.En
 	{
 		t := NewThirdApi(*thirdRpc)
 		thirdGroup.GET("/prometheus", t.GetPrometheus)
+		// Deprecated: use /register_device, kept for the clients registering their token with it.
 		thirdGroup.POST("/fcm_update_token", t.FcmUpdateToken)
+		thirdGroup.POST("/register_device", t.RegisterDevice)
+		thirdGroup.POST("/unregister_device", t.UnregisterDevice)
+		thirdGroup.POST("/get_devices", t.GetDevices)
 		thirdGroup.POST("/set_app_badge", t.SetAppBadge)
 
 		logs := thirdGroup.Group("/logs")
@@ -207,10 +219,19 @@ func newGinRouter(disCov discovery.SvcDiscoveryRegistry, config *Config) *gin.En
 		msgGroup.POST("/send_business_notification", m.SendBusinessNotification)
 		msgGroup.POST("/pull_msg_by_seq", m.PullMsgBySeqs)
 		msgGroup.POST("/revoke_msg", m.RevokeMsg)
+		msgGroup.POST("/edit_msg", m.EditMsg)
+		msgGroup.POST("/forward_msgs", m.ForwardMsgs)
+		msgGroup.POST("/add_reaction", m.AddReaction)
+		msgGroup.POST("/remove_reaction", m.RemoveReaction)
+		msgGroup.POST("/schedule_msg", m.ScheduleMsg)
+		msgGroup.POST("/get_scheduled_msgs", m.GetScheduledMsgs)
+		msgGroup.POST("/update_scheduled_msg", m.UpdateScheduledMsg)
+		msgGroup.POST("/cancel_scheduled_msg", m.CancelScheduledMsg)
 		msgGroup.POST("/mark_msgs_as_read", m.MarkMsgsAsRead)
 		msgGroup.POST("/mark_conversation_as_read", m.MarkConversationAsRead)
 		msgGroup.POST("/get_conversations_has_read_and_max_seq", m.GetConversationsHasReadAndMaxSeq)
 		msgGroup.POST("/set_conversation_has_read_seq", m.SetConversationHasReadSeq)
+		msgGroup.POST("/get_group_msg_read_members", m.GetGroupMsgReadMembers)
 
 		msgGroup.POST("/clear_conversation_msg", m.ClearConversationsMsg)
 		msgGroup.POST("/user_clear_all_msg", m.UserClearAllMsg)
@@ -218,6 +239,16 @@ func newGinRouter(disCov discovery.SvcDiscoveryRegistry, config *Config) *gin.En
 		msgGroup.POST("/delete_msg_phsical_by_seq", m.DeleteMsgPhysicalBySeq)
 		msgGroup.POST("/delete_msg_physical", m.DeleteMsgPhysical)
 
+		msgGroup.POST("/create_msg_export_job", m.CreateMsgExportJob)
+		msgGroup.POST("/get_msg_export_job", m.GetMsgExportJob)
+		msgGroup.POST("/get_msg_export_jobs", m.GetMsgExportJobs)
+		msgGroup.POST("/set_retention_policy", m.SetRetentionPolicy)
+		msgGroup.POST("/delete_retention_policy", m.DeleteRetentionPolicy)
+		msgGroup.POST("/get_retention_policies", m.GetRetentionPolicies)
+		msgGroup.POST("/get_retention_skips", m.GetRetentionSkips)
+		msgGroup.POST("/get_moderation_reviews", m.GetModerationReviews)
+		msgGroup.POST("/review_moderation_msg", m.ReviewModerationMsg)
+
 		msgGroup.POST("/batch_send_msg", m.BatchSendMsg)
 		msgGroup.POST("/check_msg_is_send_success", m.CheckMsgIsSendSuccess)
 		msgGroup.POST("/get_server_time", m.GetServerTime)
@@ -237,6 +268,17 @@ func newGinRouter(disCov discovery.SvcDiscoveryRegistry, config *Config) *gin.En
 		conversationGroup.POST("/get_owner_conversation", c.GetOwnerConversation)
 		conversationGroup.POST("/get_not_notify_conversation_ids", c.GetNotNotifyConversationIDs)
 		conversationGroup.POST("/get_pinned_conversation_ids", c.GetPinnedConversationIDs)
+		conversationGroup.POST("/pin_msg", c.PinMsg)
+		conversationGroup.POST("/unpin_msg", c.UnpinMsg)
+		conversationGroup.POST("/get_pinned_msgs", c.GetPinnedMsgs)
+	}
+
+	// Offline push dead letters
+	pushGroup := r.Group("/push")
+	{
+		p := NewPushApi(*pushRpc)
+		pushGroup.POST("/get_dead_letters", p.GetDeadLetters)
+		pushGroup.POST("/replay_dead_letters", p.ReplayDeadLetters)
 	}
 
 	statisticsGroup := r.Group("/statistics")
@@ -272,6 +314,16 @@ func GinParseToken(authRPC *rpcclient.Auth) gin.HandlerFunc {
 				c.Abort()
 				return
 			}
+			if tenantID := authverify.GetTenantIDFromToken(token); tenantID != "" {
+				keys, _ := c.Value(constant.RpcCustomHeader).([]string)
+				c.Set(constant.RpcCustomHeader, append(keys[:len(keys):len(keys)], tenant.Key))
+				c.Set(tenant.Key, []string{tenantID})
+			}
+			if language, ok := translate.NormalizeLanguage(c.Request.Header.Get(translate.LanguageKey)); ok {
+				keys, _ := c.Value(constant.RpcCustomHeader).([]string)
+				c.Set(constant.RpcCustomHeader, append(keys[:len(keys):len(keys)], translate.LanguageKey))
+				c.Set(translate.LanguageKey, []string{language})
+			}
 			resp, err := authRPC.ParseToken(c, token)
 			if err != nil {
 				apiresp.GinError(c, err)
@@ -25,6 +25,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
 	"github.com/openimsdk/protocol/third"
 	"github.com/openimsdk/tools/a2r"
 	"github.com/openimsdk/tools/errs"
@@ -37,10 +38,24 @@ func NewThirdApi(client rpcclient.Third) ThirdApi {
 	return ThirdApi(client)
 }
 
+// FcmUpdateToken registers the token as the device of the platform for the default provider.
+// Deprecated: use RegisterDevice.
 func (o *ThirdApi) FcmUpdateToken(c *gin.Context) {
 	a2r.Call(third.ThirdClient.FcmUpdateToken, o.Client, c)
 }
 
+func (o *ThirdApi) RegisterDevice(c *gin.Context) {
+	a2r.Call(thirdext.ThirdExtClient.RegisterDevice, o.ExtClient, c)
+}
+
+func (o *ThirdApi) UnregisterDevice(c *gin.Context) {
+	a2r.Call(thirdext.ThirdExtClient.UnregisterDevice, o.ExtClient, c)
+}
+
+func (o *ThirdApi) GetDevices(c *gin.Context) {
+	a2r.Call(thirdext.ThirdExtClient.GetDevices, o.ExtClient, c)
+}
+
 func (o *ThirdApi) SetAppBadge(c *gin.Context) {
 	a2r.Call(third.ThirdClient.SetAppBadge, o.Client, c)
 }
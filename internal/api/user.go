@@ -17,6 +17,7 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/userext"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/msggateway"
 	"github.com/openimsdk/protocol/user"
@@ -237,3 +238,15 @@ func (u *UserApi) UpdateNotificationAccountInfo(c *gin.Context) {
 func (u *UserApi) SearchNotificationAccount(c *gin.Context) {
 	a2r.Call(user.UserClient.SearchNotificationAccount, u.Client, c)
 }
+
+func (u *UserApi) SetNotificationSetting(c *gin.Context) {
+	a2r.Call(userext.UserExtClient.SetNotificationSetting, u.ExtClient, c)
+}
+
+func (u *UserApi) DeleteNotificationSetting(c *gin.Context) {
+	a2r.Call(userext.UserExtClient.DeleteNotificationSetting, u.ExtClient, c)
+}
+
+func (u *UserApi) GetNotificationSettings(c *gin.Context) {
+	a2r.Call(userext.UserExtClient.GetNotificationSettings, u.ExtClient, c)
+}
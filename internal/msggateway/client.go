@@ -16,15 +16,20 @@ package msggateway
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/translate"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/tools/apiresp"
@@ -68,7 +73,11 @@ type Client struct {
 	PlatformID     int    `json:"platformID"`
 	IsCompress     bool   `json:"isCompress"`
 	UserID         string `json:"userID"`
+	TenantID       string `json:"tenantID,omitempty"`
+	Language       string `json:"language,omitempty"`
 	IsBackground   bool   `json:"isBackground"`
+	encoder        Encoder
+	compressor     Compressor
 	ctx            *UserConnContext
 	longConnServer LongConnServer
 	closed         atomic.Bool
@@ -77,7 +86,13 @@ type Client struct {
 	hbCtx          context.Context
 	hbCancel       context.CancelFunc
 	subLock        *sync.Mutex
-	subUserIDs     map[string]struct{} // client conn subscription list
+	subUserIDs     map[string]struct{}     // client conn subscription list
+	session        *resumeSession          // resumable session, nil if the client did not ask for one
+	resumed        bool                    // the connection continues an earlier session
+	registered     chan struct{}           // closed once the client is visible to pushes, nil without a session
+	replayDone     chan struct{}           // closed once the session replay was written, nil without a session
+	replayedIndex  uint64                  // replay index the session replay ran up to
+	limiters       map[int32]*rate.Limiter // per connection token buckets by ReqIdentifier
 }
 
 // ResetClient updates the client's state with new connection and context information.
@@ -85,11 +100,15 @@ func (c *Client) ResetClient(ctx *UserConnContext, conn LongConn, longConnServer
 	c.w = new(sync.Mutex)
 	c.conn = conn
 	c.PlatformID = stringutil.StringToInt(ctx.GetPlatformID())
-	c.IsCompress = ctx.GetCompression()
+	c.compressor, c.IsCompress = GetCompressor(ctx.GetCompression())
 	c.IsBackground = ctx.GetBackground()
 	c.UserID = ctx.GetUserID()
+	c.TenantID = ctx.GetTenantID()
+	c.Language = ctx.GetLanguage()
 	c.ctx = ctx
 	c.longConnServer = longConnServer
+	// the encoding was validated by ParseEssentialArgs before the upgrade
+	c.encoder, _ = GetEncoder(ctx.GetEncoding())
 	c.IsBackground = false
 	c.closed.Store(false)
 	c.closedErr = nil
@@ -100,6 +119,12 @@ func (c *Client) ResetClient(ctx *UserConnContext, conn LongConn, longConnServer
 		clear(c.subUserIDs)
 	}
 	c.subUserIDs = make(map[string]struct{})
+	c.session = nil
+	c.resumed = false
+	c.registered = nil
+	c.replayDone = nil
+	c.replayedIndex = 0
+	c.limiters = nil
 }
 
 func (c *Client) pingHandler(appData string) error {
@@ -159,8 +184,17 @@ func (c *Client) readMessage() {
 				return
 			}
 		case MessageText:
-			c.closedErr = ErrNotSupportMessageProtocol
-			return
+			// Text frames are only meaningful for clients that negotiated JSON encoding.
+			if c.frameType() != MessageText {
+				c.closedErr = ErrNotSupportMessageProtocol
+				return
+			}
+			_ = c.conn.SetReadDeadline(pongWait)
+			parseDataErr := c.handleMessage(message)
+			if parseDataErr != nil {
+				c.closedErr = parseDataErr
+				return
+			}
 
 		case PingMessage:
 			err := c.writePongMsg("")
@@ -179,7 +213,7 @@ func (c *Client) readMessage() {
 func (c *Client) handleMessage(message []byte) error {
 	if c.IsCompress {
 		var err error
-		message, err = c.longConnServer.DecompressWithPool(message)
+		message, err = c.compressor.DecompressWithPool(message)
 		if err != nil {
 			return errs.Wrap(err)
 		}
@@ -188,7 +222,7 @@ func (c *Client) handleMessage(message []byte) error {
 	var binaryReq = getReq()
 	defer freeReq(binaryReq)
 
-	err := c.longConnServer.Decode(message, binaryReq)
+	err := c.encoder.Decode(message, binaryReq)
 	if err != nil {
 		return err
 	}
@@ -204,9 +238,16 @@ func (c *Client) handleMessage(message []byte) error {
 	ctx := mcontext.WithMustInfoCtx(
 		[]string{binaryReq.OperationID, binaryReq.SendID, constant.PlatformIDToName(c.PlatformID), c.ctx.GetConnID()},
 	)
+	ctx = tenant.WithTenantID(ctx, c.TenantID)
+	ctx = translate.WithLanguage(ctx, c.Language)
 
 	log.ZDebug(ctx, "gateway req message", "req", binaryReq.String())
 
+	if err := c.longConnServer.AllowRequest(c, binaryReq.ReqIdentifier); err != nil {
+		log.ZWarn(ctx, "gateway req rate limited", err, "req", binaryReq.String())
+		return c.replyMessage(ctx, binaryReq, err, nil)
+	}
+
 	var (
 		resp       []byte
 		messageErr error
@@ -231,6 +272,8 @@ func (c *Client) handleMessage(message []byte) error {
 		resp, messageErr = c.setAppBackgroundStatus(ctx, binaryReq)
 	case WsSubUserOnlineStatus:
 		resp, messageErr = c.longConnServer.SubUserOnlineStatus(ctx, c, binaryReq)
+	case WsSendEphemeralEvent:
+		resp, messageErr = c.longConnServer.SendEphemeralEvent(ctx, c, binaryReq)
 	default:
 		return fmt.Errorf(
 			"ReqIdentifier failed,sendID:%s,msgIncr:%s,reqIdentifier:%d",
@@ -285,12 +328,21 @@ func (c *Client) replyMessage(ctx context.Context, binaryReq *Req, err error, re
 	log.ZDebug(ctx, "wireBinaryMsg end", "time cost", time.Since(t))
 
 	if binaryReq.ReqIdentifier == WsLogoutMsg {
+		c.discardSession()
 		return errs.New("user logout", "operationID", binaryReq.OperationID).Wrap()
 	}
 	return nil
 }
 
 func (c *Client) PushMessage(ctx context.Context, msgData *sdkws.MsgData) error {
+	resp, err := newPushMessageResp(ctx, msgData)
+	if err != nil {
+		return err
+	}
+	return c.writeBinaryMsg(resp)
+}
+
+func newPushMessageResp(ctx context.Context, msgData *sdkws.MsgData) (Resp, error) {
 	var msg sdkws.PushMessages
 	conversationID := msgprocessor.GetConversationIDByMsg(msgData)
 	m := map[string]*sdkws.PullMsgs{conversationID: {Msgs: []*sdkws.MsgData{msgData}}}
@@ -302,14 +354,67 @@ func (c *Client) PushMessage(ctx context.Context, msgData *sdkws.MsgData) error
 	log.ZDebug(ctx, "PushMessage", "msg", &msg)
 	data, err := proto.Marshal(&msg)
 	if err != nil {
-		return err
+		return Resp{}, err
 	}
-	resp := Resp{
+	return Resp{
 		ReqIdentifier: WSPushMsg,
 		OperationID:   mcontext.GetOperationID(ctx),
 		Data:          data,
+	}, nil
+}
+
+// ackPush records that the push at replayIndex reached this connection, so it is not replayed on resume.
+func (c *Client) ackPush(replayIndex uint64) {
+	if c.session == nil || replayIndex == 0 || c.closed.Load() {
+		return
 	}
-	return c.writeBinaryMsg(resp)
+	c.session.store.ack(c, c.session, replayIndex)
+}
+
+// discardSession ends the resumable session, a kicked or logged out connection must not be resumed.
+func (c *Client) discardSession() {
+	if c.session == nil {
+		return
+	}
+	c.session.store.drop(c, c.session)
+}
+
+// waitReplay blocks a live push until the session replay was written, so it is never sent ahead
+// of the WsResumeSession frame, and reports whether the push at replayIndex was part of the replay.
+func (c *Client) waitReplay(replayIndex uint64) (replayed bool) {
+	if c.replayDone == nil {
+		return false
+	}
+	<-c.replayDone
+	return replayIndex != 0 && replayIndex <= c.replayedIndex
+}
+
+// replaySession sends the resume result followed by the pushes missed while the session was detached.
+// It must run once the client is registered, live pushes wait for it in waitReplay.
+func (c *Client) replaySession() error {
+	defer close(c.replayDone)
+	var resp ResumeSessionResp
+	resp.ResumeToken = c.session.token
+	resp.Resumed = c.resumed
+	resp.Complete = true
+	var frames []Resp
+	if c.resumed {
+		frames, resp.Complete, c.replayedIndex = c.session.store.pending(c.session)
+		resp.Replayed = len(frames)
+	}
+	data, err := json.Marshal(&resp)
+	if err != nil {
+		return errs.WrapMsg(err, "json marshal failed")
+	}
+	if err := c.writeBinaryMsg(Resp{ReqIdentifier: WsResumeSession, OperationID: c.ctx.GetOperationID(), Data: data}); err != nil {
+		return err
+	}
+	for _, frame := range frames {
+		if err := c.writeBinaryMsg(frame); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *Client) KickOnlineMessage() error {
@@ -317,11 +422,32 @@ func (c *Client) KickOnlineMessage() error {
 		ReqIdentifier: WSKickOnlineMsg,
 	}
 	log.ZDebug(c.ctx, "KickOnlineMessage debug ")
+	c.discardSession()
 	err := c.writeBinaryMsg(resp)
 	c.close()
 	return err
 }
 
+// DrainClose tells the client to reconnect to another node after reconnectAfter and closes the connection.
+// The hint is sent as a WsReconnectHint frame, which every transport carries, and again as the WebSocket close reason.
+func (c *Client) DrainClose(reconnectAfter time.Duration) error {
+	if c.closed.Load() {
+		return nil
+	}
+	data, err := json.Marshal(&ReconnectHint{ReconnectAfter: reconnectAfter.Milliseconds(), Reason: "draining"})
+	if err != nil {
+		return errs.WrapMsg(err, "json marshal failed")
+	}
+	// A session is only resumable on this node, the client has to start a new one elsewhere.
+	c.discardSession()
+	err = c.writeBinaryMsg(Resp{ReqIdentifier: WsReconnectHint, OperationID: c.ctx.GetOperationID(), Data: data})
+	if err == nil {
+		err = c.writeCloseMsg(websocket.CloseServiceRestart, string(data))
+	}
+	c.close()
+	return err
+}
+
 func (c *Client) PushUserOnlineStatus(data []byte) error {
 	resp := Resp{
 		ReqIdentifier: WsSubUserOnlineStatus,
@@ -330,12 +456,20 @@ func (c *Client) PushUserOnlineStatus(data []byte) error {
 	return c.writeBinaryMsg(resp)
 }
 
+func (c *Client) PushEphemeralEvent(data []byte) error {
+	resp := Resp{
+		ReqIdentifier: WsPushEphemeralEvent,
+		Data:          data,
+	}
+	return c.writeBinaryMsg(resp)
+}
+
 func (c *Client) writeBinaryMsg(resp Resp) error {
 	if c.closed.Load() {
 		return nil
 	}
 
-	encodedBuf, err := c.longConnServer.Encode(resp)
+	encodedBuf, err := c.encoder.Encode(resp)
 	if err != nil {
 		return err
 	}
@@ -349,14 +483,22 @@ func (c *Client) writeBinaryMsg(resp Resp) error {
 	}
 
 	if c.IsCompress {
-		resultBuf, compressErr := c.longConnServer.CompressWithPool(encodedBuf)
+		resultBuf, compressErr := c.compressor.CompressWithPool(encodedBuf)
 		if compressErr != nil {
 			return compressErr
 		}
 		return c.conn.WriteMessage(MessageBinary, resultBuf)
 	}
 
-	return c.conn.WriteMessage(MessageBinary, encodedBuf)
+	return c.conn.WriteMessage(c.frameType(), encodedBuf)
+}
+
+// frameType returns the websocket frame type the client speaks, text for uncompressed JSON and binary otherwise.
+func (c *Client) frameType() int {
+	if _, ok := c.encoder.(*JsonEncoder); ok && !c.IsCompress {
+		return MessageText
+	}
+	return MessageBinary
 }
 
 // Actively initiate Heartbeat when platform in Web.
@@ -397,6 +539,22 @@ func (c *Client) writePingMsg() error {
 	return c.conn.WriteMessage(PingMessage, nil)
 }
 
+func (c *Client) writeCloseMsg(code int, text string) error {
+	if c.closed.Load() {
+		return nil
+	}
+
+	c.w.Lock()
+	defer c.w.Unlock()
+
+	err := c.conn.SetWriteDeadline(writeWait)
+	if err != nil {
+		return err
+	}
+
+	return c.conn.WriteMessage(CloseMessage, websocket.FormatCloseMessage(code, text))
+}
+
 func (c *Client) writePongMsg(appData string) error {
 	log.ZDebug(c.ctx, "write Pong Msg in Server", "appData", appData)
 	if c.closed.Load() {
@@ -20,6 +20,7 @@ import (
 	"io"
 	"sync"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/openimsdk/tools/errs"
 )
 
@@ -35,6 +36,24 @@ type Compressor interface {
 	DecompressWithPool(compressedData []byte) ([]byte, error)
 }
 
+// GetCompressor returns the application level Compressor for the given compression protocol name.
+// DeflateCompressionProtocol is handled by the WebSocket transport and has no Compressor.
+func GetCompressor(compression string) (Compressor, bool) {
+	switch compression {
+	case GzipCompressionProtocol:
+		return gzipCompressor, true
+	case ZstdCompressionProtocol:
+		return zstdCompressor, true
+	default:
+		return nil, false
+	}
+}
+
+var (
+	gzipCompressor = NewGzipCompressor()
+	zstdCompressor = NewZstdCompressor()
+)
+
 type GzipCompressor struct {
 	compressProtocol string
 }
@@ -80,7 +99,7 @@ func (g *GzipCompressor) DeCompress(compressedData []byte) ([]byte, error) {
 	if err != nil {
 		return nil, errs.WrapMsg(err, "GzipCompressor.DeCompress: NewReader creation failed")
 	}
-	decompressedData, err := io.ReadAll(reader)
+	decompressedData, err := readDecoded(reader)
 	if err != nil {
 		return nil, errs.WrapMsg(err, "GzipCompressor.DeCompress: reading from gzip reader failed")
 	}
@@ -101,7 +120,7 @@ func (g *GzipCompressor) DecompressWithPool(compressedData []byte) ([]byte, erro
 		return nil, errs.WrapMsg(err, "GzipCompressor.DecompressWithPool: resetting gzip reader failed")
 	}
 
-	decompressedData, err := io.ReadAll(reader)
+	decompressedData, err := readDecoded(reader)
 	if err != nil {
 		return nil, errs.WrapMsg(err, "GzipCompressor.DecompressWithPool: reading from pooled gzip reader failed")
 	}
@@ -111,3 +130,69 @@ func (g *GzipCompressor) DecompressWithPool(compressedData []byte) ([]byte, erro
 	}
 	return decompressedData, nil
 }
+
+// readDecoded reads a decompressing reader to the end, failing once the output passes maxDecodedMessageSize.
+func readDecoded(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxDecodedMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDecodedMessageSize {
+		return nil, errs.New("decompressed message too large", "limit", maxDecodedMessageSize)
+	}
+	return data, nil
+}
+
+// ZstdCompressor shares one encoder and decoder between all connections,
+// EncodeAll and DecodeAll are safe for concurrent use and pool their state internally.
+type ZstdCompressor struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func NewZstdCompressor() *ZstdCompressor {
+	return &ZstdCompressor{}
+}
+
+func (z *ZstdCompressor) init() error {
+	z.once.Do(func() {
+		z.encoder, z.err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		if z.err != nil {
+			z.err = errs.WrapMsg(z.err, "ZstdCompressor: creating encoder failed")
+			return
+		}
+		z.decoder, z.err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(maxDecodedMessageSize))
+		if z.err != nil {
+			z.err = errs.WrapMsg(z.err, "ZstdCompressor: creating decoder failed")
+		}
+	})
+	return z.err
+}
+
+func (z *ZstdCompressor) Compress(rawData []byte) ([]byte, error) {
+	if err := z.init(); err != nil {
+		return nil, err
+	}
+	return z.encoder.EncodeAll(rawData, make([]byte, 0, len(rawData))), nil
+}
+
+func (z *ZstdCompressor) CompressWithPool(rawData []byte) ([]byte, error) {
+	return z.Compress(rawData)
+}
+
+func (z *ZstdCompressor) DeCompress(compressedData []byte) ([]byte, error) {
+	if err := z.init(); err != nil {
+		return nil, err
+	}
+	decompressedData, err := z.decoder.DecodeAll(compressedData, nil)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "ZstdCompressor.DeCompress: decoding failed")
+	}
+	return decompressedData, nil
+}
+
+func (z *ZstdCompressor) DecompressWithPool(compressedData []byte) ([]byte, error) {
+	return z.DeCompress(compressedData)
+}
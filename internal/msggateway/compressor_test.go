@@ -17,6 +17,7 @@ package msggateway
 import (
 	"crypto/rand"
 	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"unsafe"
@@ -86,6 +87,70 @@ func TestCompressDecompressWithConcurrency(t *testing.T) {
 	wg.Wait()
 }
 
+func TestGetCompressorRoundTrip(t *testing.T) {
+	for _, name := range []string{GzipCompressionProtocol, ZstdCompressionProtocol} {
+		compressor, ok := GetCompressor(name)
+		assert.True(t, ok, name)
+		for i := 0; i < 200; i++ {
+			src := mockRandom()
+			dest, err := compressor.CompressWithPool(src)
+			assert.NoError(t, err, name)
+			res, err := compressor.DecompressWithPool(dest)
+			assert.NoError(t, err, name)
+			assert.EqualValues(t, src, res, name)
+		}
+	}
+	_, ok := GetCompressor(DeflateCompressionProtocol)
+	assert.False(t, ok)
+}
+
+func TestDecompressRejectsOversizedMessage(t *testing.T) {
+	src := make([]byte, maxDecodedMessageSize+1)
+	for _, name := range []string{GzipCompressionProtocol, ZstdCompressionProtocol} {
+		compressor, ok := GetCompressor(name)
+		assert.True(t, ok, name)
+		dest, err := compressor.CompressWithPool(src)
+		assert.NoError(t, err, name)
+		assert.Less(t, len(dest), maxMessageSize, name)
+		_, err = compressor.DecompressWithPool(dest)
+		assert.Error(t, err, name)
+		_, err = compressor.DeCompress(dest)
+		assert.Error(t, err, name)
+
+		dest, err = compressor.CompressWithPool(src[:maxDecodedMessageSize])
+		assert.NoError(t, err, name)
+		res, err := compressor.DecompressWithPool(dest)
+		assert.NoError(t, err, name)
+		assert.Len(t, res, maxDecodedMessageSize, name)
+	}
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	cases := []struct {
+		query     string
+		extension string
+		expect    string
+	}{
+		{query: "", expect: ""},
+		{query: "gzip", expect: GzipCompressionProtocol},
+		{query: "br,zstd,gzip", expect: ZstdCompressionProtocol},
+		{query: "deflate", expect: ""},
+		{query: "deflate,gzip", expect: GzipCompressionProtocol},
+		{query: "deflate", extension: "permessage-deflate; client_max_window_bits", expect: DeflateCompressionProtocol},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/?compression="+c.query, nil)
+		if c.extension != "" {
+			r.Header.Set("Sec-WebSocket-Extensions", c.extension)
+		}
+		w := httptest.NewRecorder()
+		ctx := newContext(w, r)
+		assert.Equal(t, c.expect, ctx.NegotiateCompression(), c.query)
+		assert.Equal(t, c.expect, ctx.GetCompression(), c.query)
+		assert.Equal(t, c.expect, w.Header().Get(Compression), c.query)
+	}
+}
+
 func BenchmarkCompress(b *testing.B) {
 	src := mockRandom()
 	compressor := NewGzipCompressor()
@@ -133,6 +198,16 @@ func BenchmarkDecompressWithSyncPool(b *testing.B) {
 	}
 }
 
+func BenchmarkZstdCompress(b *testing.B) {
+	src := mockRandom()
+
+	compressor := NewZstdCompressor()
+	for i := 0; i < b.N; i++ {
+		_, err := compressor.CompressWithPool(src)
+		assert.Equal(b, nil, err)
+	}
+}
+
 func TestName(t *testing.T) {
 	t.Log(unsafe.Sizeof(Client{}))
 
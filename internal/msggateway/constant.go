@@ -25,12 +25,32 @@ const (
 	OperationID             = "operationID"
 	Compression             = "compression"
 	GzipCompressionProtocol = "gzip"
-	BackgroundStatus        = "isBackground"
-	SendResponse            = "isMsgResp"
+	ZstdCompressionProtocol = "zstd"
+	// DeflateCompressionProtocol selects the standard WebSocket permessage-deflate extension.
+	DeflateCompressionProtocol = "deflate"
+	Encoding                   = "encoding"
+	GobEncodingProtocol        = "gob"
+	JsonEncodingProtocol       = "json"
+	ProtobufEncodingProtocol   = "protobuf"
+	BackgroundStatus           = "isBackground"
+	SendResponse               = "isMsgResp"
+	IsResumable                = "isResumable"
+	ResumeToken                = "resumeToken"
+	Sid                        = "sid"
+	Language                   = "language"
 )
 
 const (
 	WebSocket = iota + 1
+	ServerSentEvents
+	LongPolling
+)
+
+const (
+	// HTTP fallback transport paths, used when WebSocket upgrades are blocked.
+	SSEPath      = "/sse"
+	LongPollPath = "/poll"
+	UplinkPath   = "/send"
 )
 
 const (
@@ -41,11 +61,15 @@ const (
 	WSSendSignalMsg       = 1004
 	WSPullMsg             = 1005
 	WSGetConvMaxReadSeq   = 1006
+	WsSendEphemeralEvent  = 1007
 	WSPushMsg             = 2001
 	WSKickOnlineMsg       = 2002
 	WsLogoutMsg           = 2003
 	WsSetBackgroundStatus = 2004
 	WsSubUserOnlineStatus = 2005
+	WsResumeSession       = 2006
+	WsReconnectHint       = 2007
+	WsPushEphemeralEvent  = 2008
 	WSDataError           = 3001
 )
 
@@ -61,4 +85,31 @@ const (
 
 	// Maximum message size allowed from peer.
 	maxMessageSize = 51200
+
+	// Maximum size a compressed message from the peer may decode to.
+	maxDecodedMessageSize = maxMessageSize * 10
+
+	// Interval for evicting resumable sessions whose grace window has passed.
+	resumeGCInterval = 30 * time.Second
+
+	// Maximum size of the type and content of an ephemeral event.
+	ephemeralEventMaxSize = 1024
+
+	// Interval for reporting drain progress.
+	drainReportInterval = 5 * time.Second
+
+	// Downlink frames queued for an HTTP fallback connection before writes fail.
+	httpLongConnBufferSize = 256
+
+	// Separates the node from the id in the sid of an HTTP fallback connection.
+	sidNodeSeparator = "."
+
+	// Period of SSE keepalive comments, must be less than pongWait.
+	sseKeepAlivePeriod = 15 * time.Second
+
+	// Time a long-poll request waits for the first frame, must be less than pongWait.
+	longPollWait = 25 * time.Second
+
+	// Maximum number of frames returned by one long-poll request.
+	longPollMaxFrames = 64
 )
@@ -15,10 +15,13 @@
 package msggateway
 
 import (
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/openimsdk/protocol/constant"
@@ -34,6 +37,8 @@ type UserConnContext struct {
 	Method     string
 	RemoteAddr string
 	ConnID     string
+	// Compression is the algorithm chosen by NegotiateCompression.
+	Compression string
 }
 
 func (c *UserConnContext) Deadline() (deadline time.Time, ok bool) {
@@ -60,6 +65,16 @@ func (c *UserConnContext) Value(key any) any {
 		return constant.PlatformIDToName(stringutil.StringToInt(c.GetPlatformID()))
 	case constant.RemoteAddr:
 		return c.RemoteAddr
+	case constant.RpcCustomHeader:
+		if c.GetTenantID() == "" {
+			return nil
+		}
+		return []string{tenant.Key}
+	case tenant.Key:
+		if tenantID := c.GetTenantID(); tenantID != "" {
+			return []string{tenantID}
+		}
+		return nil
 	default:
 		return ""
 	}
@@ -140,19 +155,67 @@ func (c *UserConnContext) GetToken() string {
 	return c.Req.URL.Query().Get(Token)
 }
 
-func (c *UserConnContext) GetCompression() bool {
-	compression, exists := c.Query(Compression)
-	if exists && compression == GzipCompressionProtocol {
-		return true
-	} else {
-		compression, exists := c.GetHeader(Compression)
-		if exists && compression == GzipCompressionProtocol {
+// NegotiateCompression picks the first supported algorithm from the comma separated list offered by the
+// client through the query or header, stores it on the context and echoes it in the upgrade response.
+// Deflate is only accepted when the client also offered the permessage-deflate extension.
+func (c *UserConnContext) NegotiateCompression() string {
+	offer, exists := c.Query(Compression)
+	if !exists {
+		offer, _ = c.GetHeader(Compression)
+	}
+	c.Compression = ""
+	for _, compression := range strings.Split(offer, ",") {
+		compression = strings.TrimSpace(compression)
+		if _, ok := GetCompressor(compression); ok {
+			c.Compression = compression
+			break
+		}
+		if compression == DeflateCompressionProtocol && c.offersPerMessageDeflate() {
+			c.Compression = compression
+			break
+		}
+	}
+	if c.Compression != "" {
+		c.SetHeader(Compression, c.Compression)
+	}
+	return c.Compression
+}
+
+func (c *UserConnContext) offersPerMessageDeflate() bool {
+	for _, ext := range c.Req.Header.Values("Sec-WebSocket-Extensions") {
+		if strings.Contains(ext, "permessage-deflate") {
 			return true
 		}
 	}
 	return false
 }
 
+// GetCompression returns the compression algorithm negotiated for this connection, empty means none.
+func (c *UserConnContext) GetCompression() string {
+	return c.Compression
+}
+
+// GetEncoding returns the frame encoding requested by the client through the query or header,
+// falling back to gob which is what the Go SDK speaks.
+func (c *UserConnContext) GetEncoding() string {
+	if encoding, exists := c.Query(Encoding); exists {
+		return encoding
+	}
+	if encoding, exists := c.GetHeader(Encoding); exists {
+		return encoding
+	}
+	return GobEncodingProtocol
+}
+
+// GetLanguage returns the language the client reads the pulled messages in, empty when it did not ask for one.
+func (c *UserConnContext) GetLanguage() string {
+	if language, exists := c.Query(Language); exists {
+		return language
+	}
+	language, _ := c.GetHeader(Language)
+	return language
+}
+
 func (c *UserConnContext) ShouldSendResp() bool {
 	errResp, exists := c.Query(SendResponse)
 	if exists {
@@ -166,6 +229,28 @@ func (c *UserConnContext) ShouldSendResp() bool {
 	return false
 }
 
+// GetResumeToken returns the token of the session the client wants to resume.
+func (c *UserConnContext) GetResumeToken() (string, bool) {
+	return c.Query(ResumeToken)
+}
+
+// IsResumable reports whether the client asked for a resumable session.
+func (c *UserConnContext) IsResumable() bool {
+	if _, ok := c.GetResumeToken(); ok {
+		return true
+	}
+	b, err := strconv.ParseBool(c.Req.URL.Query().Get(IsResumable))
+	if err != nil {
+		return false
+	}
+	return b
+}
+
+// GetTenantID returns the tenant carried by the token, the auth rpc rejects the token if it was not signed with it.
+func (c *UserConnContext) GetTenantID() string {
+	return authverify.GetTenantIDFromToken(c.GetToken())
+}
+
 func (c *UserConnContext) SetToken(token string) {
 	c.Req.URL.RawQuery = Token + "=" + token
 }
@@ -195,5 +280,8 @@ func (c *UserConnContext) ParseEssentialArgs() error {
 		return servererrs.ErrConnArgsErr.WrapMsg("platformID is not int")
 
 	}
+	if _, ok := GetEncoder(c.GetEncoding()); !ok {
+		return servererrs.ErrConnArgsErr.WrapMsg("encoding is not supported", "encoding", c.GetEncoding())
+	}
 	return nil
 }
@@ -0,0 +1,199 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openimsdk/tools/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ReconnectHint is sent with WsReconnectHint, and as the close frame reason, to connections closed by a drain.
+type ReconnectHint struct {
+	// ReconnectAfter is the delay in milliseconds the client should wait before reconnecting.
+	ReconnectAfter int64  `json:"reconnectAfter"`
+	Reason         string `json:"reason"`
+}
+
+// DrainProgress is a snapshot of the drain of this gateway node.
+type DrainProgress struct {
+	Draining  bool
+	Done      bool
+	StartedAt time.Time
+	// Total is the number of connections when the drain started.
+	Total  int64
+	Closed int64
+	// Remaining is the number of connections still registered on this node.
+	Remaining int64
+}
+
+// drainer closes the connections of a draining node gradually, so their clients do not all reconnect at once.
+type drainer struct {
+	window          time.Duration
+	reconnectJitter time.Duration
+	draining        atomic.Bool
+	startedAt       atomic.Int64 // unix nano
+	total           atomic.Int64
+	closed          atomic.Int64
+	done            chan struct{}
+	once            sync.Once
+}
+
+func newDrainer(window, reconnectJitter time.Duration) *drainer {
+	return &drainer{
+		window:          window,
+		reconnectJitter: reconnectJitter,
+		done:            make(chan struct{}),
+	}
+}
+
+// start switches the node to draining, it returns false if a drain is already running.
+func (d *drainer) start() bool {
+	if !d.draining.CompareAndSwap(false, true) {
+		return false
+	}
+	d.startedAt.Store(time.Now().UnixNano())
+	return true
+}
+
+// interval is the pause between two closes when n connections are spread over the window.
+func (d *drainer) interval(n int) time.Duration {
+	if n <= 0 || d.window <= 0 {
+		return 0
+	}
+	return d.window / time.Duration(n)
+}
+
+// reconnectAfter picks a random reconnect delay so clients closed together still spread over the other nodes.
+func (d *drainer) reconnectAfter() time.Duration {
+	if d.reconnectJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d.reconnectJitter)))
+}
+
+func (d *drainer) since() time.Duration {
+	return time.Since(time.Unix(0, d.startedAt.Load()))
+}
+
+func (d *drainer) finish() {
+	d.once.Do(func() { close(d.done) })
+}
+
+func (d *drainer) finished() bool {
+	select {
+	case <-d.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Drain stops accepting connections, removes the node from discovery and starts closing its clients
+// gradually over the drain window. It returns false if the node is already draining.
+func (ws *WsServer) Drain(ctx context.Context) bool {
+	if !ws.drain.start() {
+		return false
+	}
+	log.ZInfo(ctx, "msg gateway drain start", "conns", ws.onlineUserConnNum.Load(), "window", ws.drain.window)
+	// k8s routes by service name and has no registry to move the node in.
+	if ws.disCov != nil && ws.msgGatewayConfig.Discovery.Enable != "k8s" {
+		ws.markDraining(ctx)
+	}
+	go ws.drainClients(ctx)
+	return true
+}
+
+// markDraining moves the node to the draining gateway service in discovery. Other gateways and the auth
+// service stop looking it up, while the push service keeps reaching the connections not closed yet.
+func (ws *WsServer) markDraining(ctx context.Context) {
+	target := ws.disCov.GetSelfConnTarget()
+	if err := ws.disCov.UnRegister(); err != nil {
+		log.ZWarn(ctx, "msg gateway drain unregister failed", err)
+		return
+	}
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		log.ZWarn(ctx, "msg gateway drain register target invalid", err, "target", target)
+		return
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		log.ZWarn(ctx, "msg gateway drain register port invalid", err, "target", target)
+		return
+	}
+	serviceName := ws.msgGatewayConfig.Share.RpcRegisterName.DrainingMessageGateway()
+	if err := ws.disCov.Register(serviceName, host, port, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+		// The node is already out of discovery, pushes for its open connections are lost and their clients sync by seq.
+		log.ZWarn(ctx, "msg gateway drain register failed", err, "serviceName", serviceName)
+	}
+}
+
+// DrainDone is closed once every client registered on the node has been closed by the drain.
+func (ws *WsServer) DrainDone() <-chan struct{} {
+	return ws.drain.done
+}
+
+func (ws *WsServer) DrainProgress() DrainProgress {
+	progress := DrainProgress{
+		Draining:  ws.drain.draining.Load(),
+		Done:      ws.drain.finished(),
+		Total:     ws.drain.total.Load(),
+		Closed:    ws.drain.closed.Load(),
+		Remaining: ws.onlineUserConnNum.Load(),
+	}
+	if startedAt := ws.drain.startedAt.Load(); startedAt > 0 {
+		progress.StartedAt = time.Unix(0, startedAt)
+	}
+	return progress
+}
+
+func (ws *WsServer) drainClients(ctx context.Context) {
+	defer ws.drain.finish()
+	clients := ws.clients.GetAllClients()
+	rand.Shuffle(len(clients), func(i, j int) { clients[i], clients[j] = clients[j], clients[i] })
+	ws.drain.total.Store(int64(len(clients)))
+	interval := ws.drain.interval(len(clients))
+	for i, client := range clients {
+		if i > 0 && interval > 0 {
+			time.Sleep(interval)
+		}
+		ws.drainClient(ctx, client)
+	}
+	// Connections registered while the drain started are closed without spreading, they are only a handful.
+	for _, client := range ws.clients.GetAllClients() {
+		if client.closed.Load() {
+			continue
+		}
+		ws.drain.total.Add(1)
+		ws.drainClient(ctx, client)
+	}
+	log.ZInfo(ctx, "msg gateway drain done", "closed", ws.drain.closed.Load(), "cost", ws.drain.since())
+}
+
+func (ws *WsServer) drainClient(ctx context.Context, client *Client) {
+	if err := client.DrainClose(ws.drain.reconnectAfter()); err != nil {
+		log.ZWarn(ctx, "drain close client failed", err, "userID", client.UserID, "platformID", client.PlatformID)
+	}
+	ws.drain.closed.Add(1)
+}
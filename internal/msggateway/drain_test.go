@@ -0,0 +1,47 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainer(t *testing.T) {
+	d := newDrainer(time.Minute, 10*time.Second)
+	assert.True(t, d.start())
+	assert.False(t, d.start(), "drain already running")
+
+	assert.Equal(t, time.Duration(0), d.interval(0))
+	assert.Equal(t, time.Minute, d.interval(1))
+	assert.Equal(t, 6*time.Second, d.interval(10))
+	for i := 0; i < 100; i++ {
+		after := d.reconnectAfter()
+		assert.True(t, after >= 0 && after < 10*time.Second)
+	}
+
+	assert.False(t, d.finished())
+	d.finish()
+	d.finish()
+	assert.True(t, d.finished())
+}
+
+func TestDrainerNoWindow(t *testing.T) {
+	d := newDrainer(0, 0)
+	assert.Equal(t, time.Duration(0), d.interval(100))
+	assert.Equal(t, time.Duration(0), d.reconnectAfter())
+}
@@ -17,6 +17,11 @@ package msggateway
 import (
 	"bytes"
 	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/openimsdk/tools/errs"
 )
@@ -26,6 +31,20 @@ type Encoder interface {
 	Decode(encodeData []byte, decodeData any) error
 }
 
+// GetEncoder returns the Encoder registered for the given encoding protocol name.
+func GetEncoder(encoding string) (Encoder, bool) {
+	switch encoding {
+	case GobEncodingProtocol:
+		return NewGobEncoder(), true
+	case JsonEncodingProtocol:
+		return NewJsonEncoder(), true
+	case ProtobufEncodingProtocol:
+		return NewProtobufEncoder(), true
+	default:
+		return nil, false
+	}
+}
+
 type GobEncoder struct{}
 
 func NewGobEncoder() *GobEncoder {
@@ -49,3 +68,211 @@ func (g *GobEncoder) Decode(encodeData []byte, decodeData any) error {
 	}
 	return nil
 }
+
+// JsonEncoder encodes frames as JSON objects using the json tags of Req and Resp.
+// The Data field is carried as a base64 string.
+type JsonEncoder struct{}
+
+func NewJsonEncoder() *JsonEncoder {
+	return &JsonEncoder{}
+}
+
+func (j *JsonEncoder) Encode(data any) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "JsonEncoder.Encode failed", "action", "encode")
+	}
+	return b, nil
+}
+
+func (j *JsonEncoder) Decode(encodeData []byte, decodeData any) error {
+	if err := json.Unmarshal(encodeData, decodeData); err != nil {
+		return errs.WrapMsg(err, "JsonEncoder.Decode failed", "action", "decode")
+	}
+	return nil
+}
+
+// ProtobufEncoder encodes frames in protobuf wire format. The layout is equivalent to:
+//
+//	message Req {
+//	  int32  reqIdentifier = 1;
+//	  string token         = 2;
+//	  string sendID        = 3;
+//	  string operationID   = 4;
+//	  string msgIncr       = 5;
+//	  bytes  data          = 6;
+//	}
+//
+//	message Resp {
+//	  int32  reqIdentifier = 1;
+//	  string msgIncr       = 2;
+//	  string operationID   = 3;
+//	  int64  errCode       = 4;
+//	  string errMsg        = 5;
+//	  bytes  data          = 6;
+//	}
+type ProtobufEncoder struct{}
+
+func NewProtobufEncoder() *ProtobufEncoder {
+	return &ProtobufEncoder{}
+}
+
+func (p *ProtobufEncoder) Encode(data any) ([]byte, error) {
+	switch v := data.(type) {
+	case Req:
+		return marshalReq(&v), nil
+	case *Req:
+		return marshalReq(v), nil
+	case Resp:
+		return marshalResp(&v), nil
+	case *Resp:
+		return marshalResp(v), nil
+	case proto.Message:
+		b, err := proto.Marshal(v)
+		if err != nil {
+			return nil, errs.WrapMsg(err, "ProtobufEncoder.Encode failed", "action", "encode")
+		}
+		return b, nil
+	default:
+		return nil, errs.New("ProtobufEncoder.Encode unsupported type", "type", fmt.Sprintf("%T", data)).Wrap()
+	}
+}
+
+func (p *ProtobufEncoder) Decode(encodeData []byte, decodeData any) error {
+	var err error
+	switch v := decodeData.(type) {
+	case *Req:
+		err = unmarshalReq(encodeData, v)
+	case *Resp:
+		err = unmarshalResp(encodeData, v)
+	case proto.Message:
+		err = proto.Unmarshal(encodeData, v)
+	default:
+		return errs.New("ProtobufEncoder.Decode unsupported type", "type", fmt.Sprintf("%T", decodeData)).Wrap()
+	}
+	if err != nil {
+		return errs.WrapMsg(err, "ProtobufEncoder.Decode failed", "action", "decode")
+	}
+	return nil
+}
+
+func marshalReq(r *Req) []byte {
+	b := make([]byte, 0, len(r.Token)+len(r.SendID)+len(r.OperationID)+len(r.MsgIncr)+len(r.Data)+32)
+	b = appendVarintField(b, 1, uint64(r.ReqIdentifier))
+	b = appendStringField(b, 2, r.Token)
+	b = appendStringField(b, 3, r.SendID)
+	b = appendStringField(b, 4, r.OperationID)
+	b = appendStringField(b, 5, r.MsgIncr)
+	b = appendBytesField(b, 6, r.Data)
+	return b
+}
+
+func unmarshalReq(b []byte, r *Req) error {
+	*r = Req{}
+	return rangeFields(b, func(num protowire.Number, v uint64, bs []byte) {
+		switch num {
+		case 1:
+			r.ReqIdentifier = int32(v)
+		case 2:
+			r.Token = string(bs)
+		case 3:
+			r.SendID = string(bs)
+		case 4:
+			r.OperationID = string(bs)
+		case 5:
+			r.MsgIncr = string(bs)
+		case 6:
+			r.Data = append([]byte(nil), bs...)
+		}
+	})
+}
+
+func marshalResp(r *Resp) []byte {
+	b := make([]byte, 0, len(r.MsgIncr)+len(r.OperationID)+len(r.ErrMsg)+len(r.Data)+32)
+	b = appendVarintField(b, 1, uint64(r.ReqIdentifier))
+	b = appendStringField(b, 2, r.MsgIncr)
+	b = appendStringField(b, 3, r.OperationID)
+	b = appendVarintField(b, 4, uint64(r.ErrCode))
+	b = appendStringField(b, 5, r.ErrMsg)
+	b = appendBytesField(b, 6, r.Data)
+	return b
+}
+
+func unmarshalResp(b []byte, r *Resp) error {
+	*r = Resp{}
+	return rangeFields(b, func(num protowire.Number, v uint64, bs []byte) {
+		switch num {
+		case 1:
+			r.ReqIdentifier = int32(v)
+		case 2:
+			r.MsgIncr = string(bs)
+		case 3:
+			r.OperationID = string(bs)
+		case 4:
+			r.ErrCode = int(int64(v))
+		case 5:
+			r.ErrMsg = string(bs)
+		case 6:
+			r.Data = append([]byte(nil), bs...)
+		}
+	})
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendStringField(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendBytesField(b []byte, num protowire.Number, bs []byte) []byte {
+	if len(bs) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, bs)
+}
+
+// rangeFields walks the top-level fields of a protobuf message. Varint fields are passed in v,
+// length-delimited fields in bs. Unknown fields are skipped so that newer clients stay compatible.
+func rangeFields(b []byte, fn func(num protowire.Number, v uint64, bs []byte)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			fn(num, v, nil)
+			b = b[n:]
+		case protowire.BytesType:
+			bs, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			fn(num, 0, bs)
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEncoders() map[string]Encoder {
+	encoders := make(map[string]Encoder)
+	for _, name := range []string{GobEncodingProtocol, JsonEncodingProtocol, ProtobufEncodingProtocol} {
+		encoder, ok := GetEncoder(name)
+		if !ok {
+			panic("encoder not registered: " + name)
+		}
+		encoders[name] = encoder
+	}
+	return encoders
+}
+
+func TestEncoderReqRoundTrip(t *testing.T) {
+	src := Req{
+		ReqIdentifier: WSSendMsg,
+		Token:         "token",
+		SendID:        "10001",
+		OperationID:   "op_1",
+		MsgIncr:       "10001_1",
+		Data:          mockRandom(),
+	}
+	for name, encoder := range testEncoders() {
+		t.Run(name, func(t *testing.T) {
+			data, err := encoder.Encode(src)
+			assert.NoError(t, err)
+			dst := getReq()
+			defer freeReq(dst)
+			assert.NoError(t, encoder.Decode(data, dst))
+			assert.Equal(t, src, *dst)
+		})
+	}
+}
+
+func TestEncoderRespRoundTrip(t *testing.T) {
+	src := Resp{
+		ReqIdentifier: WSPushMsg,
+		MsgIncr:       "10001_1",
+		OperationID:   "op_1",
+		ErrCode:       1501,
+		ErrMsg:        "token expired",
+		Data:          mockRandom(),
+	}
+	for name, encoder := range testEncoders() {
+		t.Run(name, func(t *testing.T) {
+			data, err := encoder.Encode(src)
+			assert.NoError(t, err)
+			var dst Resp
+			assert.NoError(t, encoder.Decode(data, &dst))
+			assert.Equal(t, src, dst)
+		})
+	}
+}
+
+func TestProtobufEncoderNegativeErrCode(t *testing.T) {
+	encoder := NewProtobufEncoder()
+	data, err := encoder.Encode(&Resp{ErrCode: -1})
+	assert.NoError(t, err)
+	var dst Resp
+	assert.NoError(t, encoder.Decode(data, &dst))
+	assert.Equal(t, -1, dst.ErrCode)
+}
+
+func TestProtobufEncoderSkipsUnknownFields(t *testing.T) {
+	encoder := NewProtobufEncoder()
+	data, err := encoder.Encode(&Req{ReqIdentifier: WSGetNewestSeq, SendID: "10001"})
+	assert.NoError(t, err)
+	// field 15, varint 1
+	data = append(data, 0x78, 0x01)
+	var dst Req
+	assert.NoError(t, encoder.Decode(data, &dst))
+	assert.Equal(t, int32(WSGetNewestSeq), dst.ReqIdentifier)
+	assert.Equal(t, "10001", dst.SendID)
+}
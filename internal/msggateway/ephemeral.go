@@ -0,0 +1,331 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/cachekey"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+	"github.com/redis/go-redis/v9"
+)
+
+// ephemeralGroupCache is the part of rpccache.GroupLocalCache the ephemeral events use.
+type ephemeralGroupCache interface {
+	GetGroupInfo(ctx context.Context, groupID string) (*sdkws.GroupInfo, error)
+	GetGroupMemberIDMap(ctx context.Context, groupID string) (map[string]struct{}, error)
+	GetGroupMember(ctx context.Context, groupID, userID string) (*sdkws.GroupMemberFullInfo, error)
+	GetGroupMemberIDs(ctx context.Context, groupID string) ([]string, error)
+}
+
+// ephemeralFriendCache is the part of rpccache.FriendLocalCache the ephemeral events use.
+type ephemeralFriendCache interface {
+	IsFriend(ctx context.Context, possibleFriendUserID, userID string) (bool, error)
+	IsBlack(ctx context.Context, possibleBlackUserID, userID string) (bool, error)
+}
+
+// EphemeralEvent is a short-lived signal for a conversation, such as a typing indicator.
+// It is sent with WsSendEphemeralEvent and delivered with WsPushEphemeralEvent to online connections only,
+// it is never stored and gets no seq.
+type EphemeralEvent struct {
+	// SessionType is constant.SingleChatType with RecvID, or constant.ReadGroupChatType with GroupID.
+	SessionType int32  `json:"sessionType"`
+	RecvID      string `json:"recvID,omitempty"`
+	GroupID     string `json:"groupID,omitempty"`
+	// Type is defined by the clients, e.g. "typing".
+	Type    string `json:"type"`
+	Content string `json:"content,omitempty"`
+	// SendID, SenderPlatformID, SendTime and TenantID are set by the gateway.
+	SendID           string `json:"sendID"`
+	SenderPlatformID int    `json:"senderPlatformID"`
+	SendTime         int64  `json:"sendTime"`
+	TenantID         string `json:"tenantID,omitempty"`
+}
+
+func (e *EphemeralEvent) check() error {
+	if e.Type == "" {
+		return errs.ErrArgs.WrapMsg("ephemeral event type is empty")
+	}
+	if len(e.Type)+len(e.Content) > ephemeralEventMaxSize {
+		return errs.ErrArgs.WrapMsg("ephemeral event is too large", "maxSize", ephemeralEventMaxSize)
+	}
+	switch e.SessionType {
+	case constant.SingleChatType:
+		if e.RecvID == "" {
+			return errs.ErrArgs.WrapMsg("recvID is empty")
+		}
+	case constant.ReadGroupChatType:
+		if e.GroupID == "" {
+			return errs.ErrArgs.WrapMsg("groupID is empty")
+		}
+	default:
+		return errs.ErrArgs.WrapMsg("ephemeral event sessionType not supported", "sessionType", e.SessionType)
+	}
+	return nil
+}
+
+// SendEphemeralEvent publishes the event of client to the channel of each recipient,
+// only the gateway nodes the recipient is connected to are subscribed to it.
+func (ws *WsServer) SendEphemeralEvent(ctx context.Context, client *Client, data *Req) ([]byte, error) {
+	var event EphemeralEvent
+	if err := json.Unmarshal(data.Data, &event); err != nil {
+		return nil, errs.ErrArgs.WrapMsg(err.Error())
+	}
+	if err := event.check(); err != nil {
+		return nil, err
+	}
+	if err := ws.verifyEphemeralEvent(ctx, client.UserID, &event); err != nil {
+		return nil, err
+	}
+	event.SendID = client.UserID
+	event.SenderPlatformID = client.PlatformID
+	event.SendTime = time.Now().UnixMilli()
+	event.TenantID = client.TenantID
+	payload, err := json.Marshal(&event)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "json marshal failed")
+	}
+	userIDs, err := ws.ephemeralRecipients(ctx, &event)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	_, err = ws.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, userID := range userIDs {
+			pipe.Publish(ctx, ephemeralChannel(event.TenantID, userID), payload)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errs.WrapMsg(err, "publish ephemeral event failed")
+	}
+	return nil, nil
+}
+
+// ephemeralRecipients returns the users the event is delivered to, the sender excluded.
+func (ws *WsServer) ephemeralRecipients(ctx context.Context, event *EphemeralEvent) ([]string, error) {
+	var userIDs []string
+	switch event.SessionType {
+	case constant.SingleChatType:
+		userIDs = []string{event.RecvID}
+	case constant.ReadGroupChatType:
+		memberIDs, err := ws.groupCache.GetGroupMemberIDs(ctx, event.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = memberIDs
+	}
+	return datautil.Filter(userIDs, func(userID string) (string, bool) {
+		return userID, userID != event.SendID
+	}), nil
+}
+
+// verifyEphemeralEvent applies the send permission checks of SendMsg to an event of sendID,
+// a user who may not message the peer or the group may not signal it either.
+func (ws *WsServer) verifyEphemeralEvent(ctx context.Context, sendID string, event *EphemeralEvent) error {
+	if datautil.Contain(sendID, authverify.AdminUserID(ctx, ws.msgGatewayConfig.Share.IMAdminUserID)...) {
+		return nil
+	}
+	switch event.SessionType {
+	case constant.SingleChatType:
+		black, err := ws.friendCache.IsBlack(ctx, sendID, event.RecvID)
+		if err != nil {
+			return err
+		}
+		if black {
+			return servererrs.ErrBlockedByPeer.Wrap()
+		}
+		if ws.msgGatewayConfig.MsgConfig.FriendVerify {
+			friend, err := ws.friendCache.IsFriend(ctx, sendID, event.RecvID)
+			if err != nil {
+				return err
+			}
+			if !friend {
+				return servererrs.ErrNotPeersFriend.Wrap()
+			}
+		}
+		return nil
+	case constant.ReadGroupChatType:
+		groupInfo, err := ws.groupCache.GetGroupInfo(ctx, event.GroupID)
+		if err != nil {
+			return err
+		}
+		if groupInfo.Status == constant.GroupStatusDismissed {
+			return servererrs.ErrDismissedAlready.Wrap()
+		}
+		members, err := ws.groupCache.GetGroupMemberIDMap(ctx, event.GroupID)
+		if err != nil {
+			return err
+		}
+		if _, ok := members[sendID]; !ok {
+			return servererrs.ErrNotInGroupYet.Wrap()
+		}
+		member, err := ws.groupCache.GetGroupMember(ctx, event.GroupID, sendID)
+		if err != nil {
+			if errs.ErrRecordNotFound.Is(err) {
+				return servererrs.ErrNotInGroupYet.WrapMsg(err.Error())
+			}
+			return err
+		}
+		if member.RoleLevel == constant.GroupOwner {
+			return nil
+		}
+		if member.MuteEndTime >= time.Now().UnixMilli() {
+			return servererrs.ErrMutedInGroup.Wrap()
+		}
+		if groupInfo.Status == constant.GroupStatusMuted && member.RoleLevel != constant.GroupAdmin {
+			return servererrs.ErrMutedGroup.Wrap()
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// subscribeEphemeralEvents delivers the events published to the channels of the users connected to this node.
+func (ws *WsServer) subscribeEphemeralEvents(ctx context.Context) {
+	pubsub := ws.rdb.Subscribe(ctx)
+	defer pubsub.Close()
+	if err := ws.ephemeral.start(ctx, pubsub); err != nil {
+		log.ZWarn(ctx, "subscribe ephemeral event channels failed", err)
+	}
+	for message := range pubsub.Channel() {
+		tenantID, userID, ok := ws.ephemeral.user(message.Channel)
+		if !ok {
+			continue
+		}
+		ws.deliverEphemeralEvent(ctx, tenantID, userID, []byte(message.Payload))
+	}
+}
+
+// deliverEphemeralEvent pushes the event to the local foreground connections of userID.
+func (ws *WsServer) deliverEphemeralEvent(ctx context.Context, tenantID string, userID string, data []byte) {
+	clients, ok := ws.clients.GetAll(userID)
+	if !ok {
+		return
+	}
+	ctx = tenant.WithTenantID(ctx, tenantID)
+	for _, client := range tenantClients(clients, tenantID) {
+		if client == nil || client.IsBackground {
+			continue
+		}
+		if err := client.PushEphemeralEvent(data); err != nil {
+			log.ZWarn(ctx, "push ephemeral event failed", err, "userID", userID, "platformID", client.PlatformID)
+		}
+	}
+}
+
+// ephemeralChannel returns the channel of the ephemeral events to userID of tenantID,
+// the tenant hook of redis does not prefix channels.
+func ephemeralChannel(tenantID string, userID string) string {
+	return tenant.PrefixTenant(tenantID, cachekey.GetEphemeralEventChannel(userID))
+}
+
+// ephemeralPubSub is implemented by *redis.PubSub.
+type ephemeralPubSub interface {
+	Subscribe(ctx context.Context, channels ...string) error
+	Unsubscribe(ctx context.Context, channels ...string) error
+}
+
+// ephemeralRouter keeps the node subscribed to the ephemeral event channel of each user connected to it.
+type ephemeralRouter struct {
+	lock   sync.Mutex
+	pubsub ephemeralPubSub
+	users  map[string]*ephemeralUser // by ephemeralChannel
+}
+
+type ephemeralUser struct {
+	tenantID string
+	userID   string
+	refs     int
+}
+
+func newEphemeralRouter() *ephemeralRouter {
+	return &ephemeralRouter{users: make(map[string]*ephemeralUser)}
+}
+
+// start subscribes pubsub to the channels of the users connected before it was created.
+func (r *ephemeralRouter) start(ctx context.Context, pubsub ephemeralPubSub) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.pubsub = pubsub
+	if len(r.users) == 0 {
+		return nil
+	}
+	return pubsub.Subscribe(ctx, datautil.Keys(r.users)...)
+}
+
+// acquire subscribes to the channel of the user on its first connection on this node.
+func (r *ephemeralRouter) acquire(ctx context.Context, tenantID string, userID string) {
+	channel := ephemeralChannel(tenantID, userID)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	user, ok := r.users[channel]
+	if !ok {
+		user = &ephemeralUser{tenantID: tenantID, userID: userID}
+		r.users[channel] = user
+		if r.pubsub != nil {
+			// A failed subscription is retried by the pubsub when it reconnects.
+			if err := r.pubsub.Subscribe(ctx, channel); err != nil {
+				log.ZWarn(ctx, "subscribe ephemeral event channel failed", err, "channel", channel)
+			}
+		}
+	}
+	user.refs++
+}
+
+// release unsubscribes from the channel of the user after its last connection on this node is unregistered.
+func (r *ephemeralRouter) release(ctx context.Context, tenantID string, userID string) {
+	channel := ephemeralChannel(tenantID, userID)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	user, ok := r.users[channel]
+	if !ok {
+		return
+	}
+	user.refs--
+	if user.refs > 0 {
+		return
+	}
+	delete(r.users, channel)
+	if r.pubsub != nil {
+		if err := r.pubsub.Unsubscribe(ctx, channel); err != nil {
+			log.ZWarn(ctx, "unsubscribe ephemeral event channel failed", err, "channel", channel)
+		}
+	}
+}
+
+// user returns the user the events of channel are sent to, false if it is no longer connected to this node.
+func (r *ephemeralRouter) user(channel string) (string, string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	user, ok := r.users[channel]
+	if !ok {
+		return "", "", false
+	}
+	return user.tenantID, user.userID, true
+}
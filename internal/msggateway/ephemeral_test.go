@@ -0,0 +1,225 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEphemeralEventCheck(t *testing.T) {
+	assert.NoError(t, (&EphemeralEvent{SessionType: constant.SingleChatType, RecvID: "u2", Type: "typing"}).check())
+	assert.NoError(t, (&EphemeralEvent{SessionType: constant.ReadGroupChatType, GroupID: "g1", Type: "typing"}).check())
+
+	assert.Error(t, (&EphemeralEvent{SessionType: constant.SingleChatType, RecvID: "u2"}).check(), "type is empty")
+	assert.Error(t, (&EphemeralEvent{SessionType: constant.SingleChatType, Type: "typing"}).check(), "recvID is empty")
+	assert.Error(t, (&EphemeralEvent{SessionType: constant.ReadGroupChatType, Type: "typing"}).check(), "groupID is empty")
+	assert.Error(t, (&EphemeralEvent{SessionType: constant.NotificationChatType, RecvID: "u2", Type: "typing"}).check(), "session type not supported")
+
+	large := &EphemeralEvent{SessionType: constant.SingleChatType, RecvID: "u2", Type: "typing", Content: strings.Repeat("x", ephemeralEventMaxSize)}
+	assert.Error(t, large.check(), "too large")
+}
+
+type memoryFriendCache struct {
+	friends map[string]bool // "a:b", b is a friend of a
+	blacks  map[string]bool // "a:b", a is blocked by b
+}
+
+func (m *memoryFriendCache) IsFriend(_ context.Context, possibleFriendUserID, userID string) (bool, error) {
+	return m.friends[possibleFriendUserID+":"+userID], nil
+}
+
+func (m *memoryFriendCache) IsBlack(_ context.Context, possibleBlackUserID, userID string) (bool, error) {
+	return m.blacks[possibleBlackUserID+":"+userID], nil
+}
+
+type memoryGroupCache struct {
+	group   *sdkws.GroupInfo
+	members map[string]*sdkws.GroupMemberFullInfo
+}
+
+func (m *memoryGroupCache) GetGroupInfo(_ context.Context, _ string) (*sdkws.GroupInfo, error) {
+	return m.group, nil
+}
+
+func (m *memoryGroupCache) GetGroupMemberIDMap(_ context.Context, _ string) (map[string]struct{}, error) {
+	res := make(map[string]struct{}, len(m.members))
+	for userID := range m.members {
+		res[userID] = struct{}{}
+	}
+	return res, nil
+}
+
+func (m *memoryGroupCache) GetGroupMember(_ context.Context, _ string, userID string) (*sdkws.GroupMemberFullInfo, error) {
+	member, ok := m.members[userID]
+	if !ok {
+		return nil, errs.ErrRecordNotFound.WrapMsg("member not found")
+	}
+	return member, nil
+}
+
+func (m *memoryGroupCache) GetGroupMemberIDs(_ context.Context, _ string) ([]string, error) {
+	userIDs := make([]string, 0, len(m.members))
+	for userID := range m.members {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+func TestVerifyEphemeralEventSingleChat(t *testing.T) {
+	ctx := context.Background()
+	ws := &WsServer{
+		msgGatewayConfig: &Config{},
+		friendCache: &memoryFriendCache{
+			friends: map[string]bool{"u1:u2": true},
+			blacks:  map[string]bool{"u3:u2": true},
+		},
+	}
+	event := func(recvID string) *EphemeralEvent {
+		return &EphemeralEvent{SessionType: constant.SingleChatType, RecvID: recvID, Type: "typing"}
+	}
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "u1", event("u2")))
+	assert.True(t, servererrs.ErrBlockedByPeer.Is(ws.verifyEphemeralEvent(ctx, "u3", event("u2"))))
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "u4", event("u2")), "friendship is only checked with friendVerify")
+
+	ws.msgGatewayConfig.MsgConfig.FriendVerify = true
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "u1", event("u2")))
+	assert.True(t, servererrs.ErrNotPeersFriend.Is(ws.verifyEphemeralEvent(ctx, "u4", event("u2"))))
+
+	ws.msgGatewayConfig.Share.IMAdminUserID = []string{"admin"}
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "admin", event("u2")))
+}
+
+func TestVerifyEphemeralEventGroupChat(t *testing.T) {
+	ctx := context.Background()
+	groupCache := &memoryGroupCache{
+		group: &sdkws.GroupInfo{GroupID: "g1", Status: constant.GroupOk},
+		members: map[string]*sdkws.GroupMemberFullInfo{
+			"owner":  {UserID: "owner", RoleLevel: constant.GroupOwner},
+			"admin":  {UserID: "admin", RoleLevel: constant.GroupAdmin},
+			"member": {UserID: "member", RoleLevel: constant.GroupOrdinaryUsers},
+			"muted":  {UserID: "muted", RoleLevel: constant.GroupOrdinaryUsers, MuteEndTime: time.Now().Add(time.Hour).UnixMilli()},
+		},
+	}
+	ws := &WsServer{msgGatewayConfig: &Config{}, groupCache: groupCache}
+	event := &EphemeralEvent{SessionType: constant.ReadGroupChatType, GroupID: "g1", Type: "typing"}
+
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "member", event))
+	assert.True(t, servererrs.ErrNotInGroupYet.Is(ws.verifyEphemeralEvent(ctx, "stranger", event)))
+	assert.True(t, servererrs.ErrMutedInGroup.Is(ws.verifyEphemeralEvent(ctx, "muted", event)))
+
+	groupCache.group.Status = constant.GroupStatusMuted
+	assert.True(t, servererrs.ErrMutedGroup.Is(ws.verifyEphemeralEvent(ctx, "member", event)))
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "admin", event), "admins may signal a muted group")
+	assert.NoError(t, ws.verifyEphemeralEvent(ctx, "owner", event), "owners may signal a muted group")
+}
+
+func TestEphemeralRecipients(t *testing.T) {
+	ws := &WsServer{groupCache: &memoryGroupCache{members: map[string]*sdkws.GroupMemberFullInfo{"u1": {}, "u2": {}, "u3": {}}}}
+	userIDs, err := ws.ephemeralRecipients(context.Background(), &EphemeralEvent{SessionType: constant.ReadGroupChatType, GroupID: "g1", SendID: "u1"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, userIDs)
+
+	userIDs, err = ws.ephemeralRecipients(context.Background(), &EphemeralEvent{SessionType: constant.SingleChatType, RecvID: "u2", SendID: "u1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, userIDs)
+}
+
+type memoryPubSub struct {
+	channels map[string]bool
+}
+
+func (m *memoryPubSub) Subscribe(_ context.Context, channels ...string) error {
+	for _, channel := range channels {
+		m.channels[channel] = true
+	}
+	return nil
+}
+
+func (m *memoryPubSub) Unsubscribe(_ context.Context, channels ...string) error {
+	for _, channel := range channels {
+		delete(m.channels, channel)
+	}
+	return nil
+}
+
+func TestEphemeralRouter(t *testing.T) {
+	ctx := context.Background()
+	router := newEphemeralRouter()
+	router.acquire(ctx, "", "u1")
+
+	pubsub := &memoryPubSub{channels: make(map[string]bool)}
+	assert.NoError(t, router.start(ctx, pubsub))
+	assert.Equal(t, map[string]bool{ephemeralChannel("", "u1"): true}, pubsub.channels, "users connected before start")
+
+	router.acquire(ctx, "t1", "u2")
+	router.acquire(ctx, "t1", "u2")
+	assert.True(t, pubsub.channels[ephemeralChannel("t1", "u2")])
+	tenantID, userID, ok := router.user(ephemeralChannel("t1", "u2"))
+	assert.True(t, ok)
+	assert.Equal(t, "t1", tenantID)
+	assert.Equal(t, "u2", userID)
+
+	router.release(ctx, "t1", "u2")
+	assert.True(t, pubsub.channels[ephemeralChannel("t1", "u2")], "a connection of the user is left")
+	router.release(ctx, "t1", "u2")
+	assert.False(t, pubsub.channels[ephemeralChannel("t1", "u2")])
+	_, _, ok = router.user(ephemeralChannel("t1", "u2"))
+	assert.False(t, ok)
+}
+
+type recordConn struct {
+	LongConn
+	messages [][]byte
+}
+
+func (r *recordConn) SetWriteDeadline(time.Duration) error {
+	return nil
+}
+
+func (r *recordConn) WriteMessage(_ int, message []byte) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func TestDeliverEphemeralEvent(t *testing.T) {
+	ws := &WsServer{clients: newUserMap()}
+	foreground := &recordConn{}
+	background := &recordConn{}
+	ws.clients.Set("u2", &Client{UserID: "u2", PlatformID: constant.IOSPlatformID, w: new(sync.Mutex), conn: foreground, encoder: NewJsonEncoder()})
+	ws.clients.Set("u2", &Client{UserID: "u2", PlatformID: constant.AndroidPlatformID, IsBackground: true, w: new(sync.Mutex), conn: background, encoder: NewJsonEncoder()})
+
+	data := []byte(`{"sessionType":1,"recvID":"u2","type":"typing","sendID":"u1"}`)
+	ws.deliverEphemeralEvent(context.Background(), "", "u2", data)
+	ws.deliverEphemeralEvent(context.Background(), "", "u3", data)
+
+	assert.Empty(t, background.messages, "background connections get no ephemeral events")
+	if assert.Len(t, foreground.messages, 1) {
+		var resp Resp
+		assert.NoError(t, json.Unmarshal(foreground.messages[0], &resp))
+		assert.Equal(t, int32(WsPushEphemeralEvent), resp.ReqIdentifier)
+		assert.Equal(t, data, resp.Data)
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/tools/apiresp"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+)
+
+var ErrReadTimeout = errs.New("http long connection read timeout")
+
+// HttpLongConnOpenResp is the first payload of a fallback connection, the sid must be passed
+// to every following poll and uplink request. The session lives on the gateway node which opened it,
+// the sid is "<node>.<id>" so that a load balancer can route the requests of a session to its node.
+type HttpLongConnOpenResp struct {
+	Sid string `json:"sid"`
+}
+
+// HttpLongConnPollResp carries the downlink frames of one long-poll request, each frame is
+// exactly what a WebSocket client would receive as a binary message.
+type HttpLongConnPollResp struct {
+	Frames [][]byte `json:"frames"`
+}
+
+// HttpLongConn is a LongConn for clients behind proxies that block WebSocket upgrades.
+// Downlink frames are streamed as Server-Sent Events or handed out to long-poll requests,
+// uplink frames arrive as HTTP POST bodies. Frames use the same Req/Resp encoding as WebSocket.
+type HttpLongConn struct {
+	protocolType int
+	// nodeID is the gateway node the connection is opened on, it prefixes the sid.
+	nodeID      string
+	sid         string
+	token       string
+	send        chan []byte
+	recv        chan []byte
+	done        chan struct{}
+	closeOnce   sync.Once
+	onClose     func()
+	readTimeout atomic.Int64
+	deadline    atomic.Int64
+	readLimit   atomic.Int64
+}
+
+func newHttpLongConn(protocolType int, nodeID, token string, onClose func()) *HttpLongConn {
+	return &HttpLongConn{
+		protocolType: protocolType,
+		nodeID:       nodeID,
+		token:        token,
+		send:         make(chan []byte, httpLongConnBufferSize),
+		recv:         make(chan []byte),
+		done:         make(chan struct{}),
+		onClose:      onClose,
+	}
+}
+
+func (h *HttpLongConn) Close() error {
+	h.closeOnce.Do(func() {
+		close(h.done)
+		if h.onClose != nil {
+			h.onClose()
+		}
+	})
+	return nil
+}
+
+func (h *HttpLongConn) closed() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// GenerateLongConn opens the downlink. For SSE the response becomes the event stream and the
+// sid is sent as the "open" event, for long-polling the sid is returned as JSON.
+func (h *HttpLongConn) GenerateLongConn(w http.ResponseWriter, r *http.Request) error {
+	id, err := newRandomToken()
+	if err != nil {
+		return errs.WrapMsg(err, "HttpLongConn.GenerateLongConn: generate sid failed")
+	}
+	sid := h.nodeID + sidNodeSeparator + id
+	h.sid = sid
+	h.touch()
+	switch h.protocolType {
+	case ServerSentEvents:
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return errs.New("HttpLongConn.GenerateLongConn: streaming unsupported").Wrap()
+		}
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "event: open\ndata: %s\n\n", sid); err != nil {
+			return errs.WrapMsg(err, "HttpLongConn.GenerateLongConn: write open event failed")
+		}
+		flusher.Flush()
+		return nil
+	case LongPolling:
+		return writeJson(w, &HttpLongConnOpenResp{Sid: sid})
+	default:
+		return errs.New("HttpLongConn.GenerateLongConn: unknown protocol type", "protocolType", h.protocolType).Wrap()
+	}
+}
+
+// ServeStream writes queued frames as SSE events until the request or the connection ends.
+func (h *HttpLongConn) ServeStream(ctx context.Context, w http.ResponseWriter) {
+	defer h.Close()
+	flusher := w.(http.Flusher)
+	keepAlive := time.NewTicker(sseKeepAlivePeriod)
+	defer keepAlive.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			return
+		case frame := <-h.send:
+			_, err = fmt.Fprintf(w, "data: %s\n\n", base64.StdEncoding.EncodeToString(frame))
+		case <-keepAlive.C:
+			_, err = io.WriteString(w, ": ping\n\n")
+		}
+		if err != nil {
+			log.ZDebug(ctx, "sse write failed", "sid", h.sid, "err", err)
+			return
+		}
+		flusher.Flush()
+		h.touch()
+	}
+}
+
+// Poll answers a long-poll request with the queued frames, waiting up to longPollWait for the first one.
+func (h *HttpLongConn) Poll(ctx context.Context, w http.ResponseWriter) error {
+	h.touch()
+	defer h.touch()
+	timer := time.NewTimer(longPollWait)
+	defer timer.Stop()
+	var resp HttpLongConnPollResp
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-h.done:
+		return ErrConnClosed
+	case <-timer.C:
+		return writeJson(w, &resp)
+	case frame := <-h.send:
+		resp.Frames = append(resp.Frames, frame)
+	}
+drain:
+	for len(resp.Frames) < longPollMaxFrames {
+		select {
+		case frame := <-h.send:
+			resp.Frames = append(resp.Frames, frame)
+		default:
+			break drain
+		}
+	}
+	return writeJson(w, &resp)
+}
+
+// Deliver hands an uplink frame to the reading client.
+func (h *HttpLongConn) Deliver(ctx context.Context, frame []byte) error {
+	h.touch()
+	timer := time.NewTimer(writeWait)
+	defer timer.Stop()
+	select {
+	case h.recv <- frame:
+		return nil
+	case <-h.done:
+		return ErrConnClosed
+	case <-ctx.Done():
+		return errs.Wrap(context.Cause(ctx))
+	case <-timer.C:
+		return errs.New("HttpLongConn.Deliver: client is busy", "sid", h.sid).Wrap()
+	}
+}
+
+func (h *HttpLongConn) WriteMessage(messageType int, message []byte) error {
+	if messageType != MessageBinary && messageType != MessageText {
+		// Control frames are replaced by SSE keepalives and poll requests.
+		return nil
+	}
+	select {
+	case <-h.done:
+		return ErrConnClosed
+	default:
+	}
+	select {
+	case h.send <- message:
+		return nil
+	default:
+		return errs.New("HttpLongConn.WriteMessage: send buffer is full", "sid", h.sid).Wrap()
+	}
+}
+
+func (h *HttpLongConn) ReadMessage() (int, []byte, error) {
+	for {
+		wait := time.Until(time.Unix(0, h.deadline.Load()))
+		if h.readTimeout.Load() > 0 && wait <= 0 {
+			return 0, nil, ErrReadTimeout
+		}
+		if wait <= 0 {
+			wait = pongWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case frame := <-h.recv:
+			timer.Stop()
+			return MessageBinary, frame, nil
+		case <-h.done:
+			timer.Stop()
+			return CloseMessage, nil, ErrConnClosed
+		case <-timer.C:
+		}
+	}
+}
+
+// touch extends the read deadline, an open event stream or a pending poll keeps the connection alive.
+func (h *HttpLongConn) touch() {
+	h.deadline.Store(time.Now().Add(time.Duration(h.readTimeout.Load())).UnixNano())
+}
+
+func (h *HttpLongConn) SetReadDeadline(timeout time.Duration) error {
+	h.readTimeout.Store(int64(timeout))
+	h.touch()
+	return nil
+}
+
+func (h *HttpLongConn) SetWriteDeadline(timeout time.Duration) error {
+	if timeout <= 0 {
+		return errs.New("timeout must be greater than 0")
+	}
+	return nil
+}
+
+func (h *HttpLongConn) Dial(urlStr string, _ http.Header) (*http.Response, error) {
+	return nil, errs.New("HttpLongConn does not support dial", "url", urlStr).Wrap()
+}
+
+func (h *HttpLongConn) IsNil() bool {
+	return h.closed()
+}
+
+func (h *HttpLongConn) SetConnNil() {
+	_ = h.Close()
+}
+
+func (h *HttpLongConn) SetReadLimit(limit int64) {
+	h.readLimit.Store(limit)
+}
+
+func (h *HttpLongConn) SetPongHandler(_ PingPongHandler) {}
+
+func (h *HttpLongConn) SetPingHandler(_ PingPongHandler) {}
+
+func writeJson(w http.ResponseWriter, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errs.WrapMsg(err, "json marshal failed")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		return errs.WrapMsg(err, "write response failed")
+	}
+	return nil
+}
+
+// gatewayNodeID returns the node put in the sids opened by the gateway listening on port, it is the hostname,
+// the pod name on Kubernetes, and the port since one process listens on several.
+func gatewayNodeID(port int) string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname, _ = newRandomToken()
+	}
+	return hostname + ":" + strconv.Itoa(port)
+}
+
+// allowCORS answers preflight requests, browsers send them before cross origin uplink POSTs.
+func allowCORS(w http.ResponseWriter, r *http.Request) bool {
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", "*")
+	header.Set("Access-Control-Allow-Headers", "*")
+	header.Set("Access-Control-Expose-Headers", Compression)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return false
+	}
+	return true
+}
+
+// authConn runs the checks wsHandler does before upgrading.
+func (ws *WsServer) authConn(connContext *UserConnContext) error {
+	if ws.drain.draining.Load() {
+		return servererrs.ErrConnDraining.WrapMsg("gateway is draining, connect to another node")
+	}
+	if ws.onlineUserConnNum.Load() >= ws.wsMaxConnNum {
+		return servererrs.ErrConnOverMaxNumLimit.WrapMsg("over max conn num limit")
+	}
+	if err := connContext.ParseEssentialArgs(); err != nil {
+		return err
+	}
+	resp, err := ws.authClient.ParseToken(connContext, connContext.GetToken())
+	if err != nil {
+		return err
+	}
+	return ws.validateRespWithRequest(connContext, resp)
+}
+
+// openHttpLongConn authenticates the request, opens the downlink and starts the client.
+func (ws *WsServer) openHttpLongConn(w http.ResponseWriter, r *http.Request, protocolType int) (*HttpLongConn, bool) {
+	connContext := newContext(w, r)
+	if err := ws.authConn(connContext); err != nil {
+		httpError(connContext, err)
+		return nil, false
+	}
+	if compression := connContext.NegotiateCompression(); compression == DeflateCompressionProtocol {
+		connContext.Compression = ""
+		w.Header().Del(Compression)
+	}
+	var conn *HttpLongConn
+	conn = newHttpLongConn(protocolType, ws.nodeID, connContext.GetToken(), func() {
+		ws.httpConns.Delete(conn.sid)
+	})
+	if err := conn.GenerateLongConn(w, r); err != nil {
+		log.ZWarn(connContext, "http long connection fails", err, "protocolType", protocolType)
+		httpError(connContext, err)
+		return nil, false
+	}
+	ws.httpConns.Store(conn.sid, conn)
+	log.ZDebug(connContext, "new http long conn", "sid", conn.sid, "protocolType", protocolType)
+	ws.startClient(connContext, conn)
+	return conn, true
+}
+
+// sidNode returns the gateway node a sid was opened on.
+func sidNode(sid string) string {
+	if i := strings.LastIndex(sid, sidNodeSeparator); i >= 0 {
+		return sid[:i]
+	}
+	return ""
+}
+
+// lookupHttpLongConn finds the connection of a poll or uplink request, the token must be the one it was opened with.
+// A request for a session of another node is refused with that node, it was routed to the wrong node.
+func (ws *WsServer) lookupHttpLongConn(connContext *UserConnContext) (*HttpLongConn, error) {
+	sid, _ := connContext.Query(Sid)
+	if node := sidNode(sid); node != ws.nodeID {
+		return nil, servererrs.ErrConnArgsErr.WrapMsg("sid opened on another gateway node, route the request to it", "sid", sid,
+			"node", node, "self", ws.nodeID)
+	}
+	v, ok := ws.httpConns.Load(sid)
+	if !ok {
+		return nil, servererrs.ErrConnArgsErr.WrapMsg("sid not exist or closed", "sid", sid)
+	}
+	conn := v.(*HttpLongConn)
+	if conn.token != connContext.GetToken() {
+		return nil, servererrs.ErrTokenInvalid.WrapMsg("token not match sid", "sid", sid)
+	}
+	return conn, nil
+}
+
+func (ws *WsServer) sseHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowCORS(w, r) {
+		return
+	}
+	conn, ok := ws.openHttpLongConn(w, r, ServerSentEvents)
+	if !ok {
+		return
+	}
+	// The handler must stay alive for the lifetime of the event stream.
+	conn.ServeStream(r.Context(), w)
+}
+
+func (ws *WsServer) pollHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowCORS(w, r) {
+		return
+	}
+	connContext := newContext(w, r)
+	if _, ok := connContext.Query(Sid); !ok {
+		ws.openHttpLongConn(w, r, LongPolling)
+		return
+	}
+	conn, err := ws.lookupHttpLongConn(connContext)
+	if err != nil {
+		httpError(connContext, err)
+		return
+	}
+	if err := conn.Poll(r.Context(), w); err != nil {
+		httpError(connContext, err)
+	}
+}
+
+func (ws *WsServer) uplinkHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowCORS(w, r) {
+		return
+	}
+	connContext := newContext(w, r)
+	if r.Method != http.MethodPost {
+		httpError(connContext, servererrs.ErrConnArgsErr.WrapMsg("uplink must be POST"))
+		return
+	}
+	conn, err := ws.lookupHttpLongConn(connContext)
+	if err != nil {
+		httpError(connContext, err)
+		return
+	}
+	limit := conn.readLimit.Load()
+	if limit <= 0 {
+		limit = maxMessageSize
+	}
+	frame, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		httpError(connContext, servererrs.ErrConnArgsErr.WrapMsg(err.Error()))
+		return
+	}
+	if err := conn.Deliver(r.Context(), frame); err != nil {
+		httpError(connContext, err)
+		return
+	}
+	// The Resp of the request is delivered on the downlink, like it is for WebSocket clients.
+	if err := writeJson(w, apiresp.ParseError(nil)); err != nil {
+		log.ZWarn(connContext, "uplink write response failed", err)
+	}
+}
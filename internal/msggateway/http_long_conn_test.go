@@ -0,0 +1,124 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpLongConnPoll(t *testing.T) {
+	conn := newHttpLongConn(LongPolling, "node", "token", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, conn.GenerateLongConn(w, httptest.NewRequest(http.MethodGet, LongPollPath, nil)))
+	var open HttpLongConnOpenResp
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &open))
+	assert.NotEmpty(t, open.Sid)
+
+	assert.NoError(t, conn.WriteMessage(MessageBinary, []byte("frame1")))
+	assert.NoError(t, conn.WriteMessage(PingMessage, nil))
+	assert.NoError(t, conn.WriteMessage(MessageBinary, []byte("frame2")))
+
+	w = httptest.NewRecorder()
+	assert.NoError(t, conn.Poll(context.Background(), w))
+	var resp HttpLongConnPollResp
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, [][]byte{[]byte("frame1"), []byte("frame2")}, resp.Frames)
+
+	assert.NoError(t, conn.Close())
+	assert.Error(t, conn.WriteMessage(MessageBinary, []byte("frame3")))
+	assert.True(t, conn.IsNil())
+}
+
+func TestHttpLongConnUplink(t *testing.T) {
+	conn := newHttpLongConn(LongPolling, "node", "token", nil)
+	assert.NoError(t, conn.SetReadDeadline(time.Second))
+	go func() {
+		_ = conn.Deliver(context.Background(), []byte("req"))
+	}()
+	messageType, message, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, MessageBinary, messageType)
+	assert.Equal(t, []byte("req"), message)
+
+	assert.NoError(t, conn.SetReadDeadline(50*time.Millisecond))
+	_, _, err = conn.ReadMessage()
+	assert.ErrorIs(t, err, ErrReadTimeout)
+}
+
+func TestHttpLongConnServerSentEvents(t *testing.T) {
+	conn := newHttpLongConn(ServerSentEvents, "node", "token", nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := conn.GenerateLongConn(w, r); err != nil {
+			t.Error(err)
+			return
+		}
+		conn.ServeStream(r.Context(), w)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	assert.NoError(t, conn.WriteMessage(MessageBinary, []byte{0, 1, 2}))
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, "event: open", lines[0])
+	assert.Equal(t, "data: "+conn.sid, lines[1])
+	assert.Equal(t, "data: "+base64.StdEncoding.EncodeToString([]byte{0, 1, 2}), lines[2])
+	assert.NoError(t, conn.Close())
+}
+
+func TestLookupHttpLongConnNode(t *testing.T) {
+	nodeA := &WsServer{nodeID: "gateway-0:10001"}
+	nodeB := &WsServer{nodeID: "gateway-1:10001"}
+	conn := newHttpLongConn(LongPolling, nodeA.nodeID, "token", nil)
+	assert.NoError(t, conn.GenerateLongConn(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, LongPollPath, nil)))
+	nodeA.httpConns.Store(conn.sid, conn)
+	assert.Equal(t, nodeA.nodeID, sidNode(conn.sid))
+
+	lookup := func(ws *WsServer, sid string) (*HttpLongConn, error) {
+		r := httptest.NewRequest(http.MethodGet, LongPollPath+"?"+Sid+"="+sid+"&"+Token+"=token", nil)
+		return ws.lookupHttpLongConn(newContext(httptest.NewRecorder(), r))
+	}
+	found, err := lookup(nodeA, conn.sid)
+	assert.NoError(t, err)
+	assert.Same(t, conn, found)
+
+	_, err = lookup(nodeB, conn.sid)
+	assert.ErrorContains(t, err, "another gateway node", "the request was routed to the wrong node")
+	assert.ErrorContains(t, err, nodeA.nodeID)
+
+	_, err = lookup(nodeB, nodeB.nodeID+sidNodeSeparator+"unknown")
+	assert.ErrorContains(t, err, "sid not exist")
+}
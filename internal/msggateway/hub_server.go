@@ -17,8 +17,10 @@ package msggateway
 import (
 	"context"
 	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/startrpc"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/msggateway"
@@ -30,13 +32,18 @@ import (
 	"github.com/openimsdk/tools/mq/memamq"
 	"github.com/openimsdk/tools/utils/datautil"
 	"google.golang.org/grpc"
+	"os"
+	"os/signal"
 	"sync/atomic"
+	"time"
 )
 
 func (s *Server) InitServer(ctx context.Context, config *Config, disCov discovery.SvcDiscoveryRegistry, server *grpc.Server) error {
 	s.LongConnServer.SetDiscoveryRegistry(disCov, config)
 	msggateway.RegisterMsgGatewayServer(server, s)
 	s.userRcp = rpcclient.NewUserRpcClient(disCov, config.Share.RpcRegisterName.User, config.Share.IMAdminUserID)
+	s.groupRcp = rpcclient.NewGroupRpcClient(disCov, config.Share.RpcRegisterName.Group)
+	s.friendRcp = rpcclient.NewFriendRpcClient(disCov, config.Share.RpcRegisterName.Friend)
 	if s.ready != nil {
 		return s.ready(s)
 	}
@@ -44,13 +51,19 @@ func (s *Server) InitServer(ctx context.Context, config *Config, disCov discover
 }
 
 func (s *Server) Start(ctx context.Context, index int, conf *Config) error {
-	return startrpc.Start(ctx, &conf.Discovery, &conf.MsgGateway.Prometheus, conf.MsgGateway.ListenIP,
+	return startrpc.StartWithBeforeStop(ctx, &conf.Discovery, &conf.MsgGateway.Prometheus, conf.MsgGateway.ListenIP,
 		conf.MsgGateway.RPC.RegisterIP,
 		conf.MsgGateway.RPC.Ports, index,
 		conf.Share.RpcRegisterName.MessageGateway,
 		&conf.Share,
 		conf,
 		s.InitServer,
+		func() {
+			// Drain while the rpc server still serves, so the draining registration points at a live port.
+			if conf.MsgGateway.Drain.OnShutdown {
+				s.Drain(ctx)
+			}
+		},
 	)
 }
 
@@ -61,6 +74,8 @@ type Server struct {
 	pushTerminal   map[int]struct{}
 	ready          func(srv *Server) error
 	userRcp        rpcclient.UserRpcClient
+	groupRcp       rpcclient.GroupRpcClient
+	friendRcp      rpcclient.FriendRpcClient
 	queue          *memamq.MemoryQueue
 }
 
@@ -82,6 +97,53 @@ func NewServer(rpcPort int, longConnServer LongConnServer, conf *Config, ready f
 	return s
 }
 
+// DrainOnSignal drains the node when one of drainSignals is received, e.g. from a preStop hook.
+func (s *Server) DrainOnSignal(ctx context.Context) {
+	if len(drainSignals) == 0 {
+		return
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, drainSignals...)
+	defer signal.Stop(sigs)
+	select {
+	case <-ctx.Done():
+	case sig := <-sigs:
+		log.ZInfo(ctx, "msg gateway drain signal received", "signal", sig.String())
+		s.Drain(ctx)
+	}
+}
+
+// Drain starts draining the long connection server, or joins a drain already running,
+// and reports its progress until every connection is closed or the drain window has passed.
+func (s *Server) Drain(ctx context.Context) {
+	s.LongConnServer.Drain(ctx)
+	ticker := time.NewTicker(drainReportInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Duration(s.config.MsgGateway.Drain.Window)*time.Second + writeWait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.LongConnServer.DrainDone():
+			s.reportDrain(ctx)
+			return
+		case <-ticker.C:
+			s.reportDrain(ctx)
+		case <-timer.C:
+			progress := s.LongConnServer.DrainProgress()
+			log.ZWarn(ctx, "msg gateway drain window passed", nil, "total", progress.Total,
+				"closed", progress.Closed, "remaining", progress.Remaining)
+			return
+		}
+	}
+}
+
+func (s *Server) reportDrain(ctx context.Context) {
+	progress := s.LongConnServer.DrainProgress()
+	prommetrics.MsgGatewayDrainRemainingGauge.Set(float64(progress.Remaining))
+	log.ZInfo(ctx, "msg gateway drain progress", "total", progress.Total, "closed", progress.Closed,
+		"remaining", progress.Remaining, "done", progress.Done, "elapsed", time.Since(progress.StartedAt))
+}
+
 func (s *Server) OnlinePushMsg(
 	context context.Context,
 	req *msggateway.OnlinePushMsgReq,
@@ -102,6 +164,7 @@ func (s *Server) GetUsersOnlineStatus(
 		if !ok {
 			continue
 		}
+		clients = tenantClients(clients, tenant.GetTenantID(ctx))
 
 		uresp := new(msggateway.GetUsersOnlineStatusResp_SuccessResult)
 		uresp.UserID = userID
@@ -132,8 +195,12 @@ func (s *Server) OnlineBatchPushOneMsg(ctx context.Context, req *msggateway.Onli
 }
 
 func (s *Server) pushToUser(ctx context.Context, userID string, msgData *sdkws.MsgData) *msggateway.SingleMsgToUserResults {
+	// The push is recorded for the sessions of the tenant of ctx before the connections are looked up,
+	// a connection registered in between replays it instead of missing it.
+	replayIndex := s.LongConnServer.RecordPush(ctx, userID, msgData)
 	clients, ok := s.LongConnServer.GetUserAllCons(userID)
-	if !ok {
+	clients = tenantClients(clients, tenant.GetTenantID(ctx))
+	if !ok || len(clients) == 0 {
 		log.ZDebug(ctx, "push user not online", "userID", userID)
 		return &msggateway.SingleMsgToUserResults{
 			UserID: userID,
@@ -153,10 +220,14 @@ func (s *Server) pushToUser(ctx context.Context, userID string, msgData *sdkws.M
 		}
 		if !client.IsBackground ||
 			(client.IsBackground && client.PlatformID != constant.IOSPlatformID) {
-			err := client.PushMessage(ctx, msgData)
+			var err error
+			if !client.waitReplay(replayIndex) {
+				err = client.PushMessage(ctx, msgData)
+			}
 			if err != nil {
 				userPlatform.ResultCode = int64(servererrs.ErrPushMsgErr.Code())
 			} else {
+				client.ackPush(replayIndex)
 				if _, ok := s.pushTerminal[client.PlatformID]; ok {
 					result.OnlinePush = true
 				}
@@ -226,7 +297,8 @@ func (s *Server) KickUserOffline(
 ) (*msggateway.KickUserOfflineResp, error) {
 	for _, v := range req.KickUserIDList {
 		clients, _, ok := s.LongConnServer.GetUserPlatformCons(v, int(req.PlatformID))
-		if !ok {
+		clients = tenantClients(clients, tenant.GetTenantID(ctx))
+		if !ok || len(clients) == 0 {
 			log.ZDebug(ctx, "conn not exist", "userID", v, "platformID", req.PlatformID)
 			continue
 		}
@@ -251,6 +323,11 @@ func (s *Server) MultiTerminalLoginCheck(ctx context.Context, req *msggateway.Mu
 		client := &Client{}
 		client.ctx = tempUserCtx
 		client.UserID = req.UserID
+		client.TenantID = tenant.GetTenantID(ctx)
+		if tenant.Enabled() {
+			oldClients = tenantClients(oldClients, client.TenantID)
+			clientOK = len(oldClients) > 0
+		}
 		client.PlatformID = int(req.PlatformID)
 		i := &kickHandler{
 			clientOK:   clientOK,
@@ -17,6 +17,8 @@ package msggateway
 import (
 	"context"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/redis"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpccache"
 	"github.com/openimsdk/tools/db/redisutil"
 	"github.com/openimsdk/tools/utils/datautil"
@@ -26,11 +28,13 @@ import (
 )
 
 type Config struct {
-	MsgGateway     config.MsgGateway
-	Share          config.Share
-	RedisConfig    config.Redis
-	WebhooksConfig config.Webhooks
-	Discovery      config.Discovery
+	MsgGateway       config.MsgGateway
+	MsgConfig        config.Msg
+	Share            config.Share
+	RedisConfig      config.Redis
+	WebhooksConfig   config.Webhooks
+	LocalCacheConfig config.LocalCache
+	Discovery        config.Discovery
 }
 
 // Start run ws server.
@@ -45,29 +49,37 @@ func Start(ctx context.Context, index int, conf *Config) error {
 	if err != nil {
 		return err
 	}
+	tenant.Init(&conf.Share)
 	rdb, err := redisutil.NewRedisClient(ctx, conf.RedisConfig.Build())
 	if err != nil {
 		return err
 	}
+	redis.AddTenantHook(rdb)
 	longServer := NewWsServer(
 		conf,
 		WithPort(wsPort),
 		WithMaxConnNum(int64(conf.MsgGateway.LongConnSvr.WebsocketMaxConnNum)),
 		WithHandshakeTimeout(time.Duration(conf.MsgGateway.LongConnSvr.WebsocketTimeout)*time.Second),
 		WithMessageMaxMsgLength(conf.MsgGateway.LongConnSvr.WebsocketMaxMsgLen),
+		WithResume(time.Duration(conf.MsgGateway.LongConnSvr.ResumeGraceWindow)*time.Second, conf.MsgGateway.LongConnSvr.ResumeBufferSize),
+		WithDrain(time.Duration(conf.MsgGateway.Drain.Window)*time.Second, time.Duration(conf.MsgGateway.Drain.ReconnectJitter)*time.Second),
 	)
 
 	hubServer := NewServer(rpcPort, longServer, conf, func(srv *Server) error {
 		longServer.online, _ = rpccache.NewOnlineCache(srv.userRcp, nil, rdb, false, longServer.subscriberUserOnlineStatusChanges)
+		longServer.groupCache = rpccache.NewGroupLocalCache(srv.groupRcp, &conf.LocalCacheConfig, rdb)
+		longServer.friendCache = rpccache.NewFriendLocalCache(srv.friendRcp, &conf.LocalCacheConfig, rdb)
+		longServer.rdb = rdb
+		go longServer.subscribeEphemeralEvents(ctx)
 		return nil
 	})
 
 	go longServer.ChangeOnlineStatus(4)
+	go hubServer.DrainOnSignal(ctx)
 
 	netDone := make(chan error)
 	go func() {
-		err = hubServer.Start(ctx, index, conf)
-		netDone <- err
+		netDone <- hubServer.Start(ctx, index, conf)
 	}()
 	return hubServer.LongConnServer.Run(netDone)
 }
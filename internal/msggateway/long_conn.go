@@ -51,14 +51,16 @@ type LongConn interface {
 	GenerateLongConn(w http.ResponseWriter, r *http.Request) error
 }
 type GWebSocket struct {
-	protocolType     int
-	conn             *websocket.Conn
-	handshakeTimeout time.Duration
-	writeBufferSize  int
+	protocolType      int
+	conn              *websocket.Conn
+	handshakeTimeout  time.Duration
+	writeBufferSize   int
+	enableCompression bool
 }
 
-func newGWebSocket(protocolType int, handshakeTimeout time.Duration, wbs int) *GWebSocket {
-	return &GWebSocket{protocolType: protocolType, handshakeTimeout: handshakeTimeout, writeBufferSize: wbs}
+// newGWebSocket creates a gorilla based LongConn, enableCompression turns on permessage-deflate negotiation.
+func newGWebSocket(protocolType int, handshakeTimeout time.Duration, wbs int, enableCompression bool) *GWebSocket {
+	return &GWebSocket{protocolType: protocolType, handshakeTimeout: handshakeTimeout, writeBufferSize: wbs, enableCompression: enableCompression}
 }
 
 func (d *GWebSocket) Close() error {
@@ -67,14 +69,16 @@ func (d *GWebSocket) Close() error {
 
 func (d *GWebSocket) GenerateLongConn(w http.ResponseWriter, r *http.Request) error {
 	upgrader := &websocket.Upgrader{
-		HandshakeTimeout: d.handshakeTimeout,
-		CheckOrigin:      func(r *http.Request) bool { return true },
+		HandshakeTimeout:  d.handshakeTimeout,
+		CheckOrigin:       func(r *http.Request) bool { return true },
+		EnableCompression: d.enableCompression,
 	}
 	if d.writeBufferSize > 0 { // default is 4kb.
 		upgrader.WriteBufferSize = d.writeBufferSize
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	// Headers set on the context (e.g. the negotiated compression) are echoed in the upgrade response.
+	conn, err := upgrader.Upgrade(w, r, w.Header())
 	if err != nil {
 		// The upgrader.Upgrade method usually returns enough error messages to diagnose problems that may occur during the upgrade
 		return errs.WrapMsg(err, "GenerateLongConn: WebSocket upgrade failed")
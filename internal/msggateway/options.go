@@ -29,6 +29,14 @@ type (
 		messageMaxMsgLength int
 		// Websocket write buffer, default: 4096, 4kb.
 		writeBufferSize int
+		// How long a dropped session can be resumed, 0 disables resuming
+		resumeGraceWindow time.Duration
+		// Maximum number of pushes kept per user for replay after a resume
+		resumeBufferSize int
+		// Time over which connections are closed when the node drains
+		drainWindow time.Duration
+		// Upper bound of the random reconnect delay hinted to drained clients
+		drainReconnectJitter time.Duration
 	}
 )
 
@@ -61,3 +69,17 @@ func WithWriteBufferSize(size int) Option {
 		opt.writeBufferSize = size
 	}
 }
+
+func WithResume(graceWindow time.Duration, bufferSize int) Option {
+	return func(opt *configs) {
+		opt.resumeGraceWindow = graceWindow
+		opt.resumeBufferSize = bufferSize
+	}
+}
+
+func WithDrain(window, reconnectJitter time.Duration) Option {
+	return func(opt *configs) {
+		opt.drainWindow = window
+		opt.drainReconnectJitter = reconnectJitter
+	}
+}
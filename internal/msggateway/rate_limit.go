@@ -0,0 +1,147 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"math"
+	"strconv"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+)
+
+const (
+	rateLimitScopeConn = "conn"
+	rateLimitScopeUser = "user"
+)
+
+type userLimiters struct {
+	refs     int
+	limiters map[int32]*rate.Limiter
+}
+
+// rateLimiter applies token buckets to uplink requests per connection and per user.
+// Connection buckets live on the Client, user buckets are shared by all connections of the user on this node.
+type rateLimiter struct {
+	limits map[int32]config.MsgGatewayRateLimit
+	lock   sync.Mutex
+	users  map[string]*userLimiters // by tenantUserKey
+}
+
+func newRateLimiter(conf *config.MsgGateway) *rateLimiter {
+	r := &rateLimiter{
+		limits: make(map[int32]config.MsgGatewayRateLimit),
+		users:  make(map[string]*userLimiters),
+	}
+	if !conf.RateLimit.Enable {
+		return r
+	}
+	for _, limit := range conf.RateLimit.Limits {
+		r.limits[limit.ReqIdentifier] = limit
+	}
+	return r
+}
+
+func newLimiter(r float64, burst int) *rate.Limiter {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(r)))
+	}
+	return rate.NewLimiter(rate.Limit(r), burst)
+}
+
+// allow reports whether the client may send a request with reqIdentifier now.
+// It is only called from the client's read goroutine, so the connection buckets need no lock.
+func (r *rateLimiter) allow(client *Client, reqIdentifier int32) error {
+	limit, ok := r.limits[reqIdentifier]
+	if !ok {
+		return nil
+	}
+	if limit.ConnRate > 0 {
+		if client.limiters == nil {
+			client.limiters = make(map[int32]*rate.Limiter)
+		}
+		limiter, ok := client.limiters[reqIdentifier]
+		if !ok {
+			limiter = newLimiter(limit.ConnRate, limit.ConnBurst)
+			client.limiters[reqIdentifier] = limiter
+		}
+		if !limiter.Allow() {
+			return r.reject(reqIdentifier, rateLimitScopeConn)
+		}
+	}
+	if limit.UserRate > 0 {
+		if !r.userLimiter(tenantUserKey(client.TenantID, client.UserID), reqIdentifier, limit).Allow() {
+			return r.reject(reqIdentifier, rateLimitScopeUser)
+		}
+	}
+	return nil
+}
+
+func (r *rateLimiter) userLimiter(userKey string, reqIdentifier int32, limit config.MsgGatewayRateLimit) *rate.Limiter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	user, ok := r.users[userKey]
+	if !ok {
+		user = &userLimiters{limiters: make(map[int32]*rate.Limiter)}
+		r.users[userKey] = user
+	}
+	limiter, ok := user.limiters[reqIdentifier]
+	if !ok {
+		limiter = newLimiter(limit.UserRate, limit.UserBurst)
+		user.limiters[reqIdentifier] = limiter
+	}
+	return limiter
+}
+
+func (r *rateLimiter) reject(reqIdentifier int32, scope string) error {
+	prommetrics.MsgGatewayRateLimitedCounter.WithLabelValues(strconv.Itoa(int(reqIdentifier)), scope).Inc()
+	return servererrs.ErrReqRateLimit.WrapMsg("request rate limited", "reqIdentifier", reqIdentifier, "scope", scope)
+}
+
+// acquire keeps the user's buckets while one of its connections is registered, userKey is its tenantUserKey.
+func (r *rateLimiter) acquire(userKey string) {
+	if len(r.limits) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	user, ok := r.users[userKey]
+	if !ok {
+		user = &userLimiters{limiters: make(map[int32]*rate.Limiter)}
+		r.users[userKey] = user
+	}
+	user.refs++
+}
+
+// release drops the user's buckets after its last connection on this node is unregistered.
+func (r *rateLimiter) release(userKey string) {
+	if len(r.limits) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	user, ok := r.users[userKey]
+	if !ok {
+		return
+	}
+	user.refs--
+	if user.refs <= 0 {
+		delete(r.users, userKey)
+	}
+}
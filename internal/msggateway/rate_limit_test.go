@@ -0,0 +1,61 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"testing"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var conf config.MsgGateway
+	conf.RateLimit.Enable = true
+	conf.RateLimit.Limits = []config.MsgGatewayRateLimit{
+		{ReqIdentifier: WSSendMsg, ConnRate: 0.001, ConnBurst: 2, UserRate: 0.001, UserBurst: 3},
+	}
+	limiter := newRateLimiter(&conf)
+	c1 := &Client{UserID: "u1"}
+	c2 := &Client{UserID: "u1"}
+	limiter.acquire("u1")
+	limiter.acquire("u1")
+
+	assert.NoError(t, limiter.allow(c1, WSSendMsg))
+	assert.NoError(t, limiter.allow(c1, WSSendMsg))
+	err := limiter.allow(c1, WSSendMsg)
+	assert.True(t, servererrs.ErrReqRateLimit.Is(err), "conn bucket")
+
+	assert.NoError(t, limiter.allow(c2, WSSendMsg))
+	err = limiter.allow(c2, WSSendMsg)
+	assert.True(t, servererrs.ErrReqRateLimit.Is(err), "user bucket")
+
+	assert.NoError(t, limiter.allow(c1, WSGetNewestSeq), "unlisted identifiers are not limited")
+
+	limiter.release("u1")
+	limiter.release("u1")
+	assert.Empty(t, limiter.users)
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	var conf config.MsgGateway
+	conf.RateLimit.Limits = []config.MsgGatewayRateLimit{{ReqIdentifier: WSSendMsg, ConnRate: 0.001, ConnBurst: 1}}
+	limiter := newRateLimiter(&conf)
+	c := &Client{UserID: "u1"}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.allow(c, WSSendMsg))
+	}
+}
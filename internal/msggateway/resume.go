@@ -0,0 +1,245 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// ResumeSessionResp is sent with WsResumeSession after the upgrade of a resumable connection.
+type ResumeSessionResp struct {
+	ResumeToken string `json:"resumeToken"`
+	// Resumed reports whether the connection continued an earlier session instead of starting a new one.
+	Resumed bool `json:"resumed"`
+	// Complete is false when pushes were evicted from the replay buffer, the client should sync by seq.
+	Complete bool `json:"complete"`
+	Replayed int  `json:"replayed"`
+}
+
+type replayEntry struct {
+	index uint64
+	resp  Resp
+}
+
+// replayBuffer is a bounded per-user ring of the push frames sent to that user.
+type replayBuffer struct {
+	entries []replayEntry
+	start   int
+	next    uint64
+}
+
+func (b *replayBuffer) add(resp Resp, size int) uint64 {
+	b.next++
+	entry := replayEntry{index: b.next, resp: resp}
+	if len(b.entries) < size {
+		b.entries = append(b.entries, entry)
+	} else {
+		b.entries[b.start] = entry
+		b.start = (b.start + 1) % len(b.entries)
+	}
+	return b.next
+}
+
+// after returns the frames recorded after index in push order, complete is false if some were evicted.
+func (b *replayBuffer) after(index uint64) (resps []Resp, complete bool) {
+	complete = true
+	for i := 0; i < len(b.entries); i++ {
+		entry := b.entries[(b.start+i)%len(b.entries)]
+		if entry.index <= index {
+			continue
+		}
+		if len(resps) == 0 && entry.index != index+1 {
+			complete = false
+		}
+		resps = append(resps, entry.resp)
+	}
+	return resps, complete
+}
+
+type resumeSession struct {
+	store      *resumeStore
+	token      string
+	userKey    string // tenantUserKey of the user
+	platformID int
+	// client is the connection currently attached to the session, nil while waiting for a resume.
+	client     *Client
+	acked      uint64
+	detachedAt time.Time
+}
+
+// resumeStore keeps resumable sessions and their replay buffers on this gateway node.
+type resumeStore struct {
+	lock     sync.Mutex
+	grace    time.Duration
+	size     int
+	sessions map[string]*resumeSession
+	// users and buffers are keyed by tenantUserKey, a session never sees the pushes of another tenant.
+	users   map[string]map[*resumeSession]struct{}
+	buffers map[string]*replayBuffer
+}
+
+func newResumeStore(grace time.Duration, size int) *resumeStore {
+	return &resumeStore{
+		grace:    grace,
+		size:     size,
+		sessions: make(map[string]*resumeSession),
+		users:    make(map[string]map[*resumeSession]struct{}),
+		buffers:  make(map[string]*replayBuffer),
+	}
+}
+
+func (s *resumeStore) enabled() bool {
+	return s.grace > 0 && s.size > 0
+}
+
+func newRandomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// open creates a new session attached to client.
+func (s *resumeStore) open(client *Client) (*resumeSession, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &resumeSession{
+		store:      s,
+		token:      token,
+		userKey:    tenantUserKey(client.TenantID, client.UserID),
+		platformID: client.PlatformID,
+		client:     client,
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if buffer, ok := s.buffers[session.userKey]; ok {
+		session.acked = buffer.next
+	}
+	s.sessions[token] = session
+	userSessions, ok := s.users[session.userKey]
+	if !ok {
+		userSessions = make(map[*resumeSession]struct{})
+		s.users[session.userKey] = userSessions
+	}
+	userSessions[session] = struct{}{}
+	return session, nil
+}
+
+// resume attaches client to the session of token. prev is the connection still attached to it,
+// which happens when the server has not noticed the old socket dropping yet.
+func (s *resumeStore) resume(token string, client *Client) (session *resumeSession, prev *Client, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	session, ok = s.sessions[token]
+	if !ok || session.userKey != tenantUserKey(client.TenantID, client.UserID) || session.platformID != client.PlatformID {
+		return nil, nil, false
+	}
+	if session.client == nil && time.Since(session.detachedAt) > s.grace {
+		s.remove(session)
+		return nil, nil, false
+	}
+	prev = session.client
+	session.client = client
+	session.detachedAt = time.Time{}
+	return session, prev, true
+}
+
+// pending returns the frames the session missed since its last acknowledged push
+// and the replay index they run up to.
+func (s *resumeStore) pending(session *resumeSession) (resps []Resp, complete bool, index uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	buffer, ok := s.buffers[session.userKey]
+	if !ok {
+		return nil, true, session.acked
+	}
+	resps, complete = buffer.after(session.acked)
+	session.acked = buffer.next
+	return resps, complete, buffer.next
+}
+
+// record appends a push frame to the replay buffer of userKey and returns its index,
+// it is a no-op returning 0 when the user has no resumable session on this node.
+func (s *resumeStore) record(userKey string, resp Resp) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.users[userKey]) == 0 {
+		return 0
+	}
+	buffer, ok := s.buffers[userKey]
+	if !ok {
+		buffer = &replayBuffer{entries: make([]replayEntry, 0, s.size)}
+		s.buffers[userKey] = buffer
+	}
+	return buffer.add(resp, s.size)
+}
+
+// ack marks the push at index as written to client.
+func (s *resumeStore) ack(client *Client, session *resumeSession, index uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if session.client == client && index > session.acked {
+		session.acked = index
+	}
+}
+
+// detach starts the grace window of the session after its connection closed.
+func (s *resumeStore) detach(client *Client, session *resumeSession) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if session.client != client {
+		// Already taken over by a resumed connection.
+		return
+	}
+	session.client = nil
+	session.detachedAt = time.Now()
+}
+
+// drop forgets the session, used when the connection was kicked or logged out.
+func (s *resumeStore) drop(client *Client, session *resumeSession) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if session.client != client {
+		return
+	}
+	s.remove(session)
+}
+
+// gc removes sessions whose grace window has passed and buffers nobody can resume from.
+func (s *resumeStore) gc(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, session := range s.sessions {
+		if session.client == nil && now.Sub(session.detachedAt) > s.grace {
+			s.remove(session)
+		}
+	}
+}
+
+func (s *resumeStore) remove(session *resumeSession) {
+	delete(s.sessions, session.token)
+	userSessions := s.users[session.userKey]
+	delete(userSessions, session)
+	if len(userSessions) == 0 {
+		delete(s.users, session.userKey)
+		delete(s.buffers, session.userKey)
+	}
+}
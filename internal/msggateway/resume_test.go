@@ -0,0 +1,116 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msggateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pushResp(operationID string) Resp {
+	return Resp{ReqIdentifier: WSPushMsg, OperationID: operationID}
+}
+
+func TestResumeStoreReplay(t *testing.T) {
+	store := newResumeStore(time.Minute, 4)
+	oldClient := &Client{UserID: "u1", PlatformID: 1}
+	session, err := store.open(oldClient)
+	assert.NoError(t, err)
+
+	store.ack(oldClient, session, store.record("u1", pushResp("1")))
+	store.record("u1", pushResp("2"))
+	store.detach(oldClient, session)
+	store.record("u1", pushResp("3"))
+	assert.Equal(t, uint64(0), store.record("u2", pushResp("x")), "users without session are not buffered")
+
+	newClient := &Client{UserID: "u1", PlatformID: 1}
+	resumed, prev, ok := store.resume(session.token, newClient)
+	assert.True(t, ok)
+	assert.Nil(t, prev)
+	frames, complete, _ := store.pending(resumed)
+	assert.True(t, complete)
+	assert.Equal(t, []Resp{pushResp("2"), pushResp("3")}, frames)
+
+	frames, complete, _ = store.pending(resumed)
+	assert.True(t, complete)
+	assert.Empty(t, frames)
+}
+
+func TestResumeStoreEvicted(t *testing.T) {
+	store := newResumeStore(time.Minute, 2)
+	client := &Client{UserID: "u1", PlatformID: 1}
+	session, err := store.open(client)
+	assert.NoError(t, err)
+	store.detach(client, session)
+	for _, id := range []string{"1", "2", "3"} {
+		store.record("u1", pushResp(id))
+	}
+	_, _, ok := store.resume(session.token, client)
+	assert.True(t, ok)
+	frames, complete, _ := store.pending(session)
+	assert.False(t, complete)
+	assert.Equal(t, []Resp{pushResp("2"), pushResp("3")}, frames)
+}
+
+func TestResumeStoreRejected(t *testing.T) {
+	store := newResumeStore(time.Minute, 4)
+	client := &Client{UserID: "u1", PlatformID: 1}
+	session, err := store.open(client)
+	assert.NoError(t, err)
+
+	_, _, ok := store.resume(session.token, &Client{UserID: "u2", PlatformID: 1})
+	assert.False(t, ok, "other user")
+	_, _, ok = store.resume(session.token, &Client{UserID: "u1", PlatformID: 2})
+	assert.False(t, ok, "other platform")
+
+	takeover := &Client{UserID: "u1", PlatformID: 1}
+	_, prev, ok := store.resume(session.token, takeover)
+	assert.True(t, ok)
+	assert.Equal(t, client, prev)
+	store.detach(client, session)
+	assert.Equal(t, takeover, session.client, "superseded connection must not detach the session")
+
+	store.drop(takeover, session)
+	_, _, ok = store.resume(session.token, &Client{UserID: "u1", PlatformID: 1})
+	assert.False(t, ok, "dropped")
+
+	expired, err := store.open(client)
+	assert.NoError(t, err)
+	store.detach(client, expired)
+	store.gc(time.Now().Add(2 * time.Minute))
+	_, _, ok = store.resume(expired.token, client)
+	assert.False(t, ok, "expired")
+}
+
+func TestResumeStoreTenant(t *testing.T) {
+	store := newResumeStore(time.Minute, 4)
+	client := &Client{UserID: "u1", TenantID: "app1", PlatformID: 1}
+	session, err := store.open(client)
+	assert.NoError(t, err)
+	store.detach(client, session)
+
+	assert.Equal(t, uint64(0), store.record(tenantUserKey("app2", "u1"), pushResp("other")), "same userID in another tenant")
+	store.record(tenantUserKey("app1", "u1"), pushResp("1"))
+
+	_, _, ok := store.resume(session.token, &Client{UserID: "u1", TenantID: "app2", PlatformID: 1})
+	assert.False(t, ok, "other tenant")
+	_, _, ok = store.resume(session.token, &Client{UserID: "u1", TenantID: "app1", PlatformID: 1})
+	assert.True(t, ok)
+	frames, complete, _ := store.pending(session)
+	assert.True(t, complete)
+	assert.Equal(t, []Resp{pushResp("1")}, frames)
+}
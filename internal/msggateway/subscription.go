@@ -2,6 +2,7 @@ package msggateway
 
 import (
 	"context"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/utils/datautil"
@@ -53,7 +54,7 @@ type subClient struct {
 
 type Subscription struct {
 	lock    sync.RWMutex
-	userIDs map[string]*subClient // subscribe to the user's client connection
+	userIDs map[string]*subClient // subscribe to the user's client connection, by tenantUserKey
 }
 
 func (s *Subscription) DelClient(client *Client) {
@@ -70,21 +71,23 @@ func (s *Subscription) DelClient(client *Client) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	for _, userID := range userIDs {
-		sub, ok := s.userIDs[userID]
+		key := tenantUserKey(client.TenantID, userID)
+		sub, ok := s.userIDs[key]
 		if !ok {
 			continue
 		}
 		delete(sub.clients, addr)
 		if len(sub.clients) == 0 {
-			delete(s.userIDs, userID)
+			delete(s.userIDs, key)
 		}
 	}
 }
 
-func (s *Subscription) GetClient(userID string) []*Client {
+// GetClient returns the connections of tenantID subscribed to userID.
+func (s *Subscription) GetClient(tenantID, userID string) []*Client {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
-	cs, ok := s.userIDs[userID]
+	cs, ok := s.userIDs[tenantUserKey(tenantID, userID)]
 	if !ok {
 		return nil
 	}
@@ -127,27 +130,30 @@ func (s *Subscription) Sub(client *Client, addUserIDs, delUserIDs []string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	for userID := range del {
-		sub, ok := s.userIDs[userID]
+		key := tenantUserKey(client.TenantID, userID)
+		sub, ok := s.userIDs[key]
 		if !ok {
 			continue
 		}
 		delete(sub.clients, addr)
 		if len(sub.clients) == 0 {
-			delete(s.userIDs, userID)
+			delete(s.userIDs, key)
 		}
 	}
 	for userID := range add {
-		sub, ok := s.userIDs[userID]
+		key := tenantUserKey(client.TenantID, userID)
+		sub, ok := s.userIDs[key]
 		if !ok {
 			sub = &subClient{clients: make(map[string]*Client)}
-			s.userIDs[userID] = sub
+			s.userIDs[key] = sub
 		}
 		sub.clients[addr] = client
 	}
 }
 
+// pushUserIDOnlineStatus pushes the status change to the subscribers of userID in the tenant of ctx.
 func (ws *WsServer) pushUserIDOnlineStatus(ctx context.Context, userID string, platformIDs []int32) {
-	clients := ws.subscription.GetClient(userID)
+	clients := ws.subscription.GetClient(tenant.GetTenantID(ctx), userID)
 	if len(clients) == 0 {
 		return
 	}
@@ -14,6 +14,7 @@ type UserMap interface {
 	UserState() <-chan UserState
 	GetAllUserStatus(deadline time.Time, nowtime time.Time) []UserState
 	RecvSubChange(userID string, platformIDs []int32) bool
+	GetAllClients() []*Client
 }
 
 type UserState struct {
@@ -100,6 +101,17 @@ func (u *userMap) GetAll(userID string) ([]*Client, bool) {
 	return result.Clients, true
 }
 
+// GetAllClients returns every client registered on this node.
+func (u *userMap) GetAllClients() []*Client {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	var clients []*Client
+	for _, userPlatform := range u.data {
+		clients = append(clients, userPlatform.Clients...)
+	}
+	return clients
+}
+
 func (u *userMap) Get(userID string, platformID int) ([]*Client, bool, bool) {
 	u.lock.RLock()
 	defer u.lock.RUnlock()
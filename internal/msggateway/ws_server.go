@@ -29,13 +29,16 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/msggateway"
+	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/tools/discovery"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/utils/stringutil"
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -50,6 +53,12 @@ type LongConnServer interface {
 	UnRegister(c *Client)
 	SetKickHandlerInfo(i *kickHandler)
 	SubUserOnlineStatus(ctx context.Context, client *Client, data *Req) ([]byte, error)
+	SendEphemeralEvent(ctx context.Context, client *Client, data *Req) ([]byte, error)
+	RecordPush(ctx context.Context, userID string, msgData *sdkws.MsgData) uint64
+	AllowRequest(client *Client, reqIdentifier int32) error
+	Drain(ctx context.Context) bool
+	DrainDone() <-chan struct{}
+	DrainProgress() DrainProgress
 	Compressor
 	Encoder
 	MessageHandler
@@ -64,7 +73,16 @@ type WsServer struct {
 	kickHandlerChan   chan *kickHandler
 	clients           UserMap
 	online            *rpccache.OnlineCache
+	groupCache        ephemeralGroupCache
+	friendCache       ephemeralFriendCache
+	ephemeral         *ephemeralRouter
+	rdb               redis.UniversalClient
 	subscription      *Subscription
+	resume            *resumeStore
+	httpConns         sync.Map // sid -> *HttpLongConn
+	nodeID            string
+	rateLimit         *rateLimiter
+	drain             *drainer
 	clientPool        sync.Pool
 	onlineUserNum     atomic.Int64
 	onlineUserConnNum atomic.Int64
@@ -123,6 +141,25 @@ func (ws *WsServer) GetUserPlatformCons(userID string, platform int) ([]*Client,
 	return ws.clients.Get(userID, platform)
 }
 
+// tenantUserKey keys the per user state of the node, users of different tenants may share a userID.
+func tenantUserKey(tenantID, userID string) string {
+	return tenant.PrefixTenant(tenantID, userID)
+}
+
+// tenantClients keeps the clients of tenantID, users of different tenants may share a userID on one node.
+func tenantClients(clients []*Client, tenantID string) []*Client {
+	if !tenant.Enabled() {
+		return clients
+	}
+	res := make([]*Client, 0, len(clients))
+	for _, client := range clients {
+		if client != nil && client.TenantID == tenantID {
+			res = append(res, client)
+		}
+	}
+	return res
+}
+
 func NewWsServer(msgGatewayConfig *Config, opts ...Option) *WsServer {
 	var config configs
 	for _, o := range opts {
@@ -134,6 +171,7 @@ func NewWsServer(msgGatewayConfig *Config, opts ...Option) *WsServer {
 	return &WsServer{
 		msgGatewayConfig: msgGatewayConfig,
 		port:             config.port,
+		nodeID:           gatewayNodeID(config.port),
 		wsMaxConnNum:     config.maxConnNum,
 		writeBufferSize:  config.writeBufferSize,
 		handshakeTimeout: config.handshakeTimeout,
@@ -148,6 +186,10 @@ func NewWsServer(msgGatewayConfig *Config, opts ...Option) *WsServer {
 		validate:        v,
 		clients:         newUserMap(),
 		subscription:    newSubscription(),
+		resume:          newResumeStore(config.resumeGraceWindow, config.resumeBufferSize),
+		rateLimit:       newRateLimiter(&msgGatewayConfig.MsgGateway),
+		ephemeral:       newEphemeralRouter(),
+		drain:           newDrainer(config.drainWindow, config.drainReconnectJitter),
 		Compressor:      NewGzipCompressor(),
 		Encoder:         NewGobEncoder(),
 		webhookClient:   webhook.NewWebhookClient(msgGatewayConfig.WebhooksConfig.URL),
@@ -164,12 +206,19 @@ func (ws *WsServer) Run(done chan error) error {
 	server := http.Server{Addr: ":" + stringutil.IntToString(ws.port), Handler: nil}
 
 	go func() {
+		resumeGCTicker := time.NewTicker(resumeGCInterval)
+		defer resumeGCTicker.Stop()
 		for {
 			select {
 			case <-shutdownDone:
 				return
+			case now := <-resumeGCTicker.C:
+				ws.resume.gc(now)
 			case client = <-ws.registerChan:
 				ws.registerClient(client)
+				if client.registered != nil {
+					close(client.registered)
+				}
 			case client = <-ws.unregisterChan:
 				ws.unregisterClient(client)
 			case onlineInfo := <-ws.kickHandlerChan:
@@ -180,6 +229,9 @@ func (ws *WsServer) Run(done chan error) error {
 	netDone := make(chan struct{}, 1)
 	go func() {
 		http.HandleFunc("/", ws.wsHandler)
+		http.HandleFunc(SSEPath, ws.sseHandler)
+		http.HandleFunc(LongPollPath, ws.pollHandler)
+		http.HandleFunc(UplinkPath, ws.uplinkHandler)
 		err := server.ListenAndServe()
 		defer close(netDone)
 		if err != nil && err != http.ErrServerClosed {
@@ -252,7 +304,25 @@ func (ws *WsServer) registerClient(client *Client) {
 		clientOK   bool
 		oldClients []*Client
 	)
+	ws.rateLimit.acquire(tenantUserKey(client.TenantID, client.UserID))
+	ws.ephemeral.acquire(client.ctx, client.TenantID, client.UserID)
 	oldClients, userOK, clientOK = ws.clients.Get(client.UserID, client.PlatformID)
+	if tenant.Enabled() {
+		oldClients = tenantClients(oldClients, client.TenantID)
+		clientOK = len(oldClients) > 0
+	}
+	if client.resumed {
+		// A resumed session is the same login, it must neither kick other terminals nor notify other nodes.
+		ws.clients.Set(client.UserID, client)
+		if !userOK {
+			prommetrics.OnlineUserGauge.Add(1)
+			ws.onlineUserNum.Add(1)
+		}
+		ws.onlineUserConnNum.Add(1)
+		log.ZDebug(client.ctx, "user resumed", "userID", client.UserID, "platformID", client.PlatformID,
+			"online user Num", ws.onlineUserNum.Load(), "online user conn Num", ws.onlineUserConnNum.Load())
+		return
+	}
 	if !userOK {
 		ws.clients.Set(client.UserID, client)
 		log.ZDebug(client.ctx, "user not exist", "userID", client.UserID, "platformID", client.PlatformID)
@@ -343,6 +413,7 @@ func (ws *WsServer) multiTerminalLoginChecker(clientOK bool, oldClients []*Clien
 			[]string{newClient.ctx.GetOperationID(), newClient.ctx.GetUserID(),
 				constant.PlatformIDToName(newClient.PlatformID), newClient.ctx.GetConnID()},
 		)
+		ctx = tenant.WithTenantID(ctx, newClient.TenantID)
 		if _, err := ws.authClient.InvalidateToken(ctx, newClient.token, newClient.UserID, newClient.PlatformID); err != nil {
 			log.ZWarn(newClient.ctx, "InvalidateToken err", err, "userID", newClient.UserID,
 				"platformID", newClient.PlatformID)
@@ -359,6 +430,11 @@ func (ws *WsServer) unregisterClient(client *Client) {
 	}
 	ws.onlineUserConnNum.Add(-1)
 	ws.subscription.DelClient(client)
+	ws.rateLimit.release(tenantUserKey(client.TenantID, client.UserID))
+	ws.ephemeral.release(client.ctx, client.TenantID, client.UserID)
+	if client.session != nil {
+		ws.resume.detach(client, client.session)
+	}
 	//ws.SetUserOnlineStatus(client.ctx, client, constant.Offline)
 	log.ZDebug(client.ctx, "user offline", "close reason", client.closedErr, "online user Num",
 		ws.onlineUserNum.Load(), "online user conn Num",
@@ -383,6 +459,12 @@ func (ws *WsServer) wsHandler(w http.ResponseWriter, r *http.Request) {
 	// Create a new connection context
 	connContext := newContext(w, r)
 
+	// Refuse new connections while the node drains, the client reconnects through another node
+	if ws.drain.draining.Load() {
+		httpError(connContext, servererrs.ErrConnDraining.WrapMsg("gateway is draining, connect to another node"))
+		return
+	}
+
 	// Check if the current number of online user connections exceeds the maximum limit
 	if ws.onlineUserConnNum.Load() >= ws.wsMaxConnNum {
 		// If it exceeds the maximum connection number, return an error via HTTP and stop processing
@@ -406,7 +488,7 @@ func (ws *WsServer) wsHandler(w http.ResponseWriter, r *http.Request) {
 		shouldSendError := connContext.ShouldSendResp()
 		if shouldSendError {
 			// Create a WebSocket connection object and attempt to send the error message via WebSocket
-			wsLongConn := newGWebSocket(WebSocket, ws.handshakeTimeout, ws.writeBufferSize)
+			wsLongConn := newGWebSocket(WebSocket, ws.handshakeTimeout, ws.writeBufferSize, false)
 			if err := wsLongConn.RespondWithError(err, w, r); err == nil {
 				// If the error message is successfully sent via WebSocket, stop processing
 				return
@@ -425,9 +507,12 @@ func (ws *WsServer) wsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.ZDebug(connContext, "new conn", "token", connContext.GetToken())
+	// Negotiate the compression algorithm, permessage-deflate is applied by the WebSocket transport itself
+	compression := connContext.NegotiateCompression()
+
+	log.ZDebug(connContext, "new conn", "token", connContext.GetToken(), "compression", compression)
 	// Create a WebSocket long connection object
-	wsLongConn := newGWebSocket(WebSocket, ws.handshakeTimeout, ws.writeBufferSize)
+	wsLongConn := newGWebSocket(WebSocket, ws.handshakeTimeout, ws.writeBufferSize, compression == DeflateCompressionProtocol)
 	if err := wsLongConn.GenerateLongConn(w, r); err != nil {
 		//If the creation of the long connection fails, the error is handled internally during the handshake process.
 		log.ZWarn(connContext, "long connection fails", err)
@@ -444,11 +529,79 @@ func (ws *WsServer) wsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Retrieve a client object from the client pool, reset its state, and associate it with the current WebSocket long connection
+	ws.startClient(connContext, wsLongConn)
+}
+
+// startClient binds a pooled client to the long connection, registers it and starts reading.
+func (ws *WsServer) startClient(connContext *UserConnContext, conn LongConn) {
+	// Retrieve a client object from the client pool, reset its state, and associate it with the current long connection
 	client := ws.clientPool.Get().(*Client)
-	client.ResetClient(connContext, wsLongConn, ws)
+	client.ResetClient(connContext, conn, ws)
 
-	// Register the client with the server and start message processing
+	// Attach the client to a resumable session if it asked for one
+	if connContext.IsResumable() && ws.resume.enabled() {
+		if err := ws.attachSession(client); err != nil {
+			log.ZWarn(connContext, "attach resumable session failed", err)
+		}
+	}
+
+	// Register the client with the server and start message processing.
+	// The replay runs once the client is visible to pushes, so a push recorded after it is pushed live,
+	// while live pushes hold back until the replay was written and skip what it already sent.
+	if client.session != nil {
+		client.registered = make(chan struct{})
+		client.replayDone = make(chan struct{})
+	}
 	ws.registerChan <- client
+	if client.session != nil {
+		<-client.registered
+		if err := client.replaySession(); err != nil {
+			log.ZWarn(connContext, "replay resumable session failed", err)
+		}
+	}
 	go client.readMessage()
 }
+
+// attachSession continues the session named by the client's resume token, or opens a new one
+// when the token is missing, unknown or its grace window has passed.
+func (ws *WsServer) attachSession(client *Client) error {
+	if token, ok := client.ctx.GetResumeToken(); ok {
+		session, prev, ok := ws.resume.resume(token, client)
+		if ok {
+			client.session = session
+			client.resumed = true
+			if prev != nil {
+				// The old socket has not been noticed as dropped yet, close it without a kick.
+				log.ZDebug(client.ctx, "resume takes over connection", "old remote addr", prev.ctx.GetRemoteAddr())
+				prev.close()
+			}
+			return nil
+		}
+		log.ZDebug(client.ctx, "resume token not resumable, open new session")
+	}
+	session, err := ws.resume.open(client)
+	if err != nil {
+		return err
+	}
+	client.session = session
+	return nil
+}
+
+// AllowRequest applies the configured per connection and per user rate limits to an uplink request.
+func (ws *WsServer) AllowRequest(client *Client, reqIdentifier int32) error {
+	return ws.rateLimit.allow(client, reqIdentifier)
+}
+
+// RecordPush keeps a push for replay to the resumable sessions of the user in the tenant of ctx
+// and returns its replay index.
+func (ws *WsServer) RecordPush(ctx context.Context, userID string, msgData *sdkws.MsgData) uint64 {
+	if !ws.resume.enabled() {
+		return 0
+	}
+	resp, err := newPushMessageResp(ctx, msgData)
+	if err != nil {
+		log.ZWarn(ctx, "RecordPush marshal failed", err, "userID", userID)
+		return 0
+	}
+	return ws.resume.record(tenantUserKey(tenant.GetTenantID(ctx), userID), resp)
+}
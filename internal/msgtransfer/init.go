@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
@@ -33,7 +34,11 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	discRegister "github.com/openimsdk/open-im-server/v3/pkg/common/discoveryregister"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/webhook"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgsearch"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/translate"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/mw"
@@ -75,6 +80,8 @@ func Start(ctx context.Context, index int, config *Config) error {
 	if err != nil {
 		return err
 	}
+	redis.AddTenantHook(rdb)
+	tenant.Init(&config.Share)
 	client, err := discRegister.NewDiscoveryRegister(&config.Discovery, &config.Share)
 	if err != nil {
 		return err
@@ -107,7 +114,12 @@ func Start(ctx context.Context, index int, config *Config) error {
 	if err != nil {
 		return err
 	}
-	historyMongoCH, err := NewOnlineHistoryMongoConsumerHandler(&config.KafkaConfig, msgTransferDatabase)
+	msgSearch, err := msgsearch.New(&config.Share.MsgSearch, "msgtransfer_"+strconv.Itoa(index))
+	if err != nil {
+		return err
+	}
+	translator := translate.NewWebhookTranslator(webhook.NewWebhookClient(config.WebhooksConfig.URL), &config.WebhooksConfig.TranslateMsg)
+	historyMongoCH, err := NewOnlineHistoryMongoConsumerHandler(&config.KafkaConfig, msgTransferDatabase, msgSearch, translator, &config.Share.MsgTranslate)
 	if err != nil {
 		return err
 	}
@@ -159,12 +171,16 @@ func (m *MsgTransfer) Start(index int, config *Config) error {
 		m.historyCH.redisMessageBatches.Close()
 		m.historyCH.historyConsumerGroup.Close()
 		m.historyMongoCH.historyConsumerGroup.Close()
+		m.historyMongoCH.closeMsgSearch()
+		m.historyMongoCH.closeQueues()
 		return nil
 	case <-netDone:
 		m.cancel()
 		m.historyCH.redisMessageBatches.Close()
 		m.historyCH.historyConsumerGroup.Close()
 		m.historyMongoCH.historyConsumerGroup.Close()
+		m.historyMongoCH.closeMsgSearch()
+		m.historyMongoCH.closeQueues()
 		close(netDone)
 		return netErr
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/go-redis/redis"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
 	"github.com/openimsdk/open-im-server/v3/pkg/tools/batcher"
@@ -96,10 +97,12 @@ func NewOnlineHistoryRedisConsumerHandler(kafkaConf *config.Kafka, database cont
 }
 func (och *OnlineHistoryRedisConsumerHandler) do(ctx context.Context, channelID int, val *batcher.Msg[sarama.ConsumerMessage]) {
 	ctx = mcontext.WithTriggerIDContext(ctx, val.TriggerID())
+	// Every message of a batch has the same key, so the same tenant.
+	ctx, key := tenant.FromMQKey(ctx, val.Key())
 	ctxMessages := och.parseConsumerMessages(ctx, val.Val())
 	ctx = withAggregationCtx(ctx, ctxMessages)
 	log.ZInfo(ctx, "msg arrived channel", "channel id", channelID, "msgList length", len(ctxMessages),
-		"key", val.Key())
+		"key", key)
 	och.doSetReadSeq(ctx, ctxMessages)
 
 	storageMsgList, notStorageMsgList, storageNotificationList, notStorageNotificationList :=
@@ -108,10 +111,33 @@ func (och *OnlineHistoryRedisConsumerHandler) do(ctx context.Context, channelID
 		len(notStorageMsgList), "storageNotificationList", len(storageNotificationList), "notStorageNotificationList",
 		len(notStorageNotificationList))
 
-	conversationIDMsg := msgprocessor.GetChatConversationIDByMsg(ctxMessages[0].message)
+	// Thread replies share the key of their conversation but have their own seq stream.
+	conversationIDs, storageMsgs, notStorageMsgs := groupByChatConversation(storageMsgList, notStorageMsgList)
+	for _, conversationID := range conversationIDs {
+		och.handleMsg(ctx, key, conversationID, storageMsgs[conversationID], notStorageMsgs[conversationID])
+	}
 	conversationIDNotification := msgprocessor.GetNotificationConversationIDByMsg(ctxMessages[0].message)
-	och.handleMsg(ctx, val.Key(), conversationIDMsg, storageMsgList, notStorageMsgList)
-	och.handleNotification(ctx, val.Key(), conversationIDNotification, storageNotificationList, notStorageNotificationList)
+	och.handleNotification(ctx, key, conversationIDNotification, storageNotificationList, notStorageNotificationList)
+}
+
+// groupByChatConversation splits the messages of a batch by chat conversation, keeping the order of the batch.
+func groupByChatConversation(storageList, notStorageList []*ContextMsg) (conversationIDs []string, storageMsgs, notStorageMsgs map[string][]*ContextMsg) {
+	storageMsgs = make(map[string][]*ContextMsg)
+	notStorageMsgs = make(map[string][]*ContextMsg)
+	add := func(msgs map[string][]*ContextMsg, list []*ContextMsg) {
+		for _, msg := range list {
+			conversationID := msgprocessor.GetChatConversationIDByMsg(msg.message)
+			if _, ok := storageMsgs[conversationID]; !ok {
+				if _, ok := notStorageMsgs[conversationID]; !ok {
+					conversationIDs = append(conversationIDs, conversationID)
+				}
+			}
+			msgs[conversationID] = append(msgs[conversationID], msg)
+		}
+	}
+	add(storageMsgs, storageList)
+	add(notStorageMsgs, notStorageList)
+	return
 }
 
 func (och *OnlineHistoryRedisConsumerHandler) doSetReadSeq(ctx context.Context, msgs []*ContextMsg) {
@@ -184,7 +210,7 @@ func (och *OnlineHistoryRedisConsumerHandler) parseConsumerMessages(ctx context.
 		}
 		log.ZDebug(ctx, "consumer.kafka.GetContextWithMQHeader", "len", len(consumerMessages[i].Headers),
 			"header", strings.Join(arr, ", "))
-		ctxMsg.ctx = kafka.GetContextWithMQHeader(consumerMessages[i].Headers)
+		ctxMsg.ctx, _ = tenant.FromMQKey(kafka.GetContextWithMQHeader(consumerMessages[i].Headers), string(consumerMessages[i].Key))
 		ctxMsg.message = msgFromMQ
 		log.ZDebug(ctx, "message parse finish", "message", msgFromMQ, "key",
 			string(consumerMessages[i].Key))
@@ -257,7 +283,8 @@ func (och *OnlineHistoryRedisConsumerHandler) handleMsg(ctx context.Context, key
 		}
 		log.ZInfo(ctx, "BatchInsertChat2Cache end")
 
-		if isNewConversation {
+		// A thread is shown in the conversation of its parent message, it has no conversation of its own.
+		if isNewConversation && !msgprocessor.IsThreadConversationID(conversationID) {
 			switch msg.SessionType {
 			case constant.ReadGroupChatType:
 				log.ZDebug(ctx, "group chat first create conversation", "conversationID",
@@ -16,23 +16,50 @@ package msgtransfer
 
 import (
 	"context"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgsearch"
+	"github.com/openimsdk/open-im-server/v3/pkg/translate"
 	pbmsg "github.com/openimsdk/protocol/msg"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/mq/kafka"
+	"github.com/openimsdk/tools/mq/memamq"
 	"google.golang.org/protobuf/proto"
 )
 
 type OnlineHistoryMongoConsumerHandler struct {
 	historyConsumerGroup *kafka.MConsumerGroup
 	msgTransferDatabase  controller.MsgTransferDatabase
+	// msgSearch is nil when the full text search is not enabled.
+	msgSearch msgsearch.MsgSearchIndex
+	// translator is nil when the translation is not enabled.
+	translator     translate.Translator
+	translateConf  *config.MsgTranslate
+	translateQueue *memamq.MemoryQueue
+	// threadQueue retries the thread reply counts whose parent msg is not stored yet.
+	threadQueue *memamq.MemoryQueue
 }
 
-func NewOnlineHistoryMongoConsumerHandler(kafkaConf *config.Kafka, database controller.MsgTransferDatabase) (*OnlineHistoryMongoConsumerHandler, error) {
+const (
+	translateWorkerCount = 4
+	translateBufferSize  = 1000
+
+	threadWorkerCount = 2
+	threadBufferSize  = 1000
+	threadRetryTimes  = 5
+	threadRetryDelay  = time.Second
+)
+
+func NewOnlineHistoryMongoConsumerHandler(kafkaConf *config.Kafka, database controller.MsgTransferDatabase, msgSearch msgsearch.MsgSearchIndex,
+	translator translate.Translator, translateConf *config.MsgTranslate) (*OnlineHistoryMongoConsumerHandler, error) {
 	historyConsumerGroup, err := kafka.NewMConsumerGroup(kafkaConf.Build(), kafkaConf.ToMongoGroupID, []string{kafkaConf.ToMongoTopic}, true)
 	if err != nil {
 		return nil, err
@@ -41,6 +68,13 @@ func NewOnlineHistoryMongoConsumerHandler(kafkaConf *config.Kafka, database cont
 	mc := &OnlineHistoryMongoConsumerHandler{
 		historyConsumerGroup: historyConsumerGroup,
 		msgTransferDatabase:  database,
+		msgSearch:            msgSearch,
+		translateConf:        translateConf,
+		threadQueue:          memamq.NewMemoryQueue(threadWorkerCount, threadBufferSize),
+	}
+	if translator != nil && len(translate.NormalizeLanguages(translateConf.Languages)) > 0 {
+		mc.translator = translator
+		mc.translateQueue = memamq.NewMemoryQueue(translateWorkerCount, translateBufferSize)
 	}
 	return mc, nil
 }
@@ -72,6 +106,8 @@ func (mc *OnlineHistoryMongoConsumerHandler) handleChatWs2Mongo(ctx context.Cont
 		prommetrics.MsgInsertMongoFailedCounter.Inc()
 	} else {
 		prommetrics.MsgInsertMongoSuccessCounter.Inc()
+		mc.indexMsgs(ctx, msgFromMQ.ConversationID, msgFromMQ.MsgData)
+		mc.translateMsgs(ctx, msgFromMQ.ConversationID, msgFromMQ.MsgData)
 	}
 	var seqs []int64
 	for _, msg := range msgFromMQ.MsgData {
@@ -89,6 +125,103 @@ func (mc *OnlineHistoryMongoConsumerHandler) handleChatWs2Mongo(ctx context.Cont
 			msgFromMQ.ConversationID,
 		)
 	}
+	if msgprocessor.IsThreadConversationID(msgFromMQ.ConversationID) {
+		mc.addThreadReplies(ctx, msgFromMQ.ConversationID, msgFromMQ.MsgData)
+	}
+}
+
+// addThreadReplies updates the parent msg of a thread with its replies. The parent msg is stored by
+// another partition and may not be in mongo yet, in which case the update is retried on the thread queue.
+func (mc *OnlineHistoryMongoConsumerHandler) addThreadReplies(ctx context.Context, threadID string, msgs []*sdkws.MsgData) {
+	err := mc.msgTransferDatabase.AddThreadReplies(ctx, threadID, msgs)
+	if err == nil {
+		return
+	}
+	if !errs.ErrRecordNotFound.Is(err) {
+		log.ZError(ctx, "add thread replies err", err, "conversationID", threadID)
+		return
+	}
+	err = mc.threadQueue.PushCtx(ctx, func() {
+		for i := 0; i < threadRetryTimes; i++ {
+			time.Sleep(threadRetryDelay * time.Duration(i+1))
+			err := mc.msgTransferDatabase.AddThreadReplies(ctx, threadID, msgs)
+			if err == nil {
+				return
+			}
+			if !errs.ErrRecordNotFound.Is(err) {
+				log.ZError(ctx, "add thread replies err", err, "conversationID", threadID)
+				return
+			}
+		}
+		log.ZWarn(ctx, "thread parent msg not stored, reply count not updated", nil, "conversationID", threadID)
+	})
+	if err != nil {
+		log.ZWarn(ctx, "push thread replies task err", err, "conversationID", threadID)
+	}
+}
+
+// indexMsgs adds the persisted msgs to the full text index.
+func (mc *OnlineHistoryMongoConsumerHandler) indexMsgs(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) {
+	if mc.msgSearch == nil {
+		return
+	}
+	docs := make([]*msgsearch.Doc, 0, len(msgs))
+	for _, msg := range msgs {
+		if doc, ok := msgsearch.NewDoc(tenant.GetTenantID(ctx), conversationID, msg); ok {
+			docs = append(docs, doc)
+		}
+	}
+	if err := mc.msgSearch.Index(ctx, docs); err != nil {
+		log.ZError(ctx, "index msgs for search err", err, "conversationID", conversationID)
+	}
+}
+
+// closeMsgSearch flushes the msgs buffered by the full text index.
+func (mc *OnlineHistoryMongoConsumerHandler) closeMsgSearch() {
+	if mc.msgSearch == nil {
+		return
+	}
+	if err := mc.msgSearch.Close(); err != nil {
+		log.ZError(context.Background(), "close msg search index err", err)
+	}
+}
+
+// translateMsgs translates the persisted text msgs into the languages translated on send,
+// the translations run on the translate queue so that they do not hold back the consumer.
+func (mc *OnlineHistoryMongoConsumerHandler) translateMsgs(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) {
+	if mc.translator == nil {
+		return
+	}
+	languages := translate.NormalizeLanguages(mc.translateConf.Languages)
+	for _, msg := range msgs {
+		translateMsg, ok := translate.NewMsg(conversationID, msg, mc.translateConf.MaxTextLength)
+		if !ok {
+			continue
+		}
+		content := string(msg.Content)
+		err := mc.translateQueue.PushCtx(ctx, func() {
+			translations, err := mc.translator.Translate(ctx, translateMsg, languages)
+			if err != nil {
+				log.ZWarn(ctx, "translate msg err", err, "conversationID", conversationID, "seq", translateMsg.Seq)
+				return
+			}
+			if err := mc.msgTransferDatabase.SetMsgTranslations(ctx, conversationID, translateMsg.Seq, content, translations); err != nil {
+				log.ZError(ctx, "set msg translations err", err, "conversationID", conversationID, "seq", translateMsg.Seq)
+			}
+		})
+		if err != nil {
+			log.ZWarn(ctx, "push translate task err", err, "conversationID", conversationID, "seq", translateMsg.Seq)
+		}
+	}
+}
+
+// closeQueues waits for the queued translations and thread reply counts.
+func (mc *OnlineHistoryMongoConsumerHandler) closeQueues() {
+	mc.threadQueue.Stop()
+	if mc.translateQueue == nil {
+		return
+	}
+	mc.translateQueue.Stop()
 }
 
 func (*OnlineHistoryMongoConsumerHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
@@ -101,9 +234,9 @@ func (mc *OnlineHistoryMongoConsumerHandler) ConsumeClaim(
 	log.ZDebug(context.Background(), "online new session msg come", "highWaterMarkOffset",
 		claim.HighWaterMarkOffset(), "topic", claim.Topic(), "partition", claim.Partition())
 	for msg := range claim.Messages() {
-		ctx := mc.historyConsumerGroup.GetContextFromMsg(msg)
+		ctx, key := tenant.FromMQKey(mc.historyConsumerGroup.GetContextFromMsg(msg), string(msg.Key))
 		if len(msg.Value) != 0 {
-			mc.handleChatWs2Mongo(ctx, msg, string(msg.Key), sess)
+			mc.handleChatWs2Mongo(ctx, msg, key, sess)
 		} else {
 			log.ZError(ctx, "mongo msg get from kafka but is nil", nil, "conversationID", msg.Key)
 		}
@@ -0,0 +1,67 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/pushext"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+func (p pushServer) GetDeadLetters(ctx context.Context, req *pushext.GetDeadLettersReq) (*pushext.GetDeadLettersResp, error) {
+	if err := authverify.CheckAdmin(ctx, p.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	total, retries, err := p.retryDatabase.GetPushDeadLetters(ctx, req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &pushext.GetDeadLettersResp{
+		Total: total,
+		DeadLetters: datautil.Slice(retries, func(retry *model.PushRetry) *pushext.DeadLetter {
+			return &pushext.DeadLetter{
+				RetryID:     retry.RetryID,
+				Provider:    retry.Provider,
+				UserIDs:     retry.UserIDs,
+				Title:       retry.Title,
+				Content:     retry.Content,
+				ClientMsgID: retry.ClientMsgID,
+				Attempts:    int32(retry.Attempts),
+				LastError:   retry.LastError,
+				CreateTime:  retry.CreateTime,
+				UpdateTime:  retry.UpdateTime,
+			}
+		}),
+	}, nil
+}
+
+// ReplayDeadLetters queues the dead letters to be pushed again now, they are dead-lettered again
+// after failing as many attempts as a new push.
+func (p pushServer) ReplayDeadLetters(ctx context.Context, req *pushext.ReplayDeadLettersReq) (*pushext.ReplayDeadLettersResp, error) {
+	if err := authverify.CheckAdmin(ctx, p.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	retries, err := p.retryDatabase.ReplayPushDeadLetters(ctx, datautil.Distinct(req.RetryIDs))
+	if err != nil {
+		return nil, err
+	}
+	retryIDs := datautil.Slice(retries, func(retry *model.PushRetry) string { return retry.RetryID })
+	log.ZInfo(ctx, "replay offline push dead letters", "retryIDs", retryIDs)
+	return &pushext.ReplayDeadLettersResp{RetryIDs: retryIDs}, nil
+}
@@ -0,0 +1,181 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/convert"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/localcache"
+	"github.com/openimsdk/open-im-server/v3/pkg/localcache/lru"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/notifypref"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+const (
+	// notificationSettingCacheTTL is how long the settings of a user are kept in the local cache,
+	// a change of the settings applies to the offline pushes after it at the latest.
+	notificationSettingCacheTTL  = time.Second * 30
+	notificationSettingCacheSize = 10240
+)
+
+// notificationSettings applies the notification settings the users keep in the user rpc to their offline pushes.
+type notificationSettings struct {
+	userRpcClient *rpcclient.UserRpcClient
+	// cache holds the settings by tenant and user ID, nil for the users without a setting.
+	cache lru.LRU[string, *model.NotificationSetting]
+	// limitsPlatforms is true when the offline pusher can push to some platforms of a user only.
+	limitsPlatforms bool
+	now             func() time.Time
+}
+
+func newNotificationSettings(userRpcClient *rpcclient.UserRpcClient, pushConf *config.Push) *notificationSettings {
+	return &notificationSettings{
+		userRpcClient:   userRpcClient,
+		cache:           newNotificationSettingCache(),
+		limitsPlatforms: offlinepush.LimitsPlatforms(pushConf),
+		now:             time.Now,
+	}
+}
+
+func newNotificationSettingCache() lru.LRU[string, *model.NotificationSetting] {
+	return lru.NewLayLRU[string, *model.NotificationSetting](notificationSettingCacheSize, notificationSettingCacheTTL, time.Second,
+		localcache.EmptyTarget{}, nil)
+}
+
+// get returns the settings of userIDs by user ID. The users without a setting are missing, and every user is
+// when the settings could not be got, so that they are notified rather than missing a message.
+func (n *notificationSettings) get(ctx context.Context, userIDs []string) map[string]*model.NotificationSetting {
+	keys := tenant.PrefixKeys(ctx, userIDs)
+	keyUserIDs := make(map[string]string, len(keys))
+	for i, key := range keys {
+		keyUserIDs[key] = userIDs[i]
+	}
+	cached, err := n.cache.GetBatch(keys, func(keys []string) (map[string]*model.NotificationSetting, error) {
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		extSettings, err := n.userRpcClient.GetNotificationSettings(ctx, datautil.Slice(keys, func(key string) string { return keyUserIDs[key] }))
+		if err != nil {
+			return nil, err
+		}
+		// the users without a setting are cached too, so that they are not got again
+		settings := make(map[string]*model.NotificationSetting, len(keys))
+		for _, key := range keys {
+			if setting, ok := extSettings[keyUserIDs[key]]; ok {
+				settings[key] = convert.NotificationSettingExt2DB(setting)
+			} else {
+				settings[key] = nil
+			}
+		}
+		return settings, nil
+	})
+	if err != nil {
+		log.ZWarn(ctx, "get notification settings failed", err, "userIDs", userIDs)
+		return nil
+	}
+	settings := make(map[string]*model.NotificationSetting, len(cached))
+	for key, setting := range cached {
+		if setting != nil {
+			settings[keyUserIDs[key]] = setting
+		}
+	}
+	return settings
+}
+
+// filter returns the users of userIDs whose settings, got by get, let msg be notified to them now.
+func (n *notificationSettings) filter(ctx context.Context, msg *sdkws.MsgData, userIDs []string, settings map[string]*model.NotificationSetting) []string {
+	if len(settings) == 0 {
+		return userIDs
+	}
+	now := n.now()
+	isGroup := msg.SessionType == constant.ReadGroupChatType || msg.SessionType == constant.WriteGroupChatType
+	atAll := datautil.Contain(constant.AtAllString, msg.AtUserIDList...)
+	text := msgprocessor.GetText(msg)
+	return datautil.Filter(userIDs, func(userID string) (string, bool) {
+		notifyMsg := notifypref.Message{
+			Text:      text,
+			IsGroup:   isGroup,
+			Mentioned: atAll || datautil.Contain(userID, msg.AtUserIDList...),
+		}
+		if !notifypref.ShouldNotify(settings[userID], notifyMsg, now) {
+			log.ZDebug(ctx, "offline push silenced by notification setting", "userID", userID, "clientMsgID", msg.ClientMsgID)
+			return "", false
+		}
+		return userID, true
+	})
+}
+
+// push pushes the notification of a message to userIDs through pusher, without the content of the message on
+// the platforms where the users hide it. When pusher can not push to some platforms of a user only, the content
+// is hidden on every platform of the users who hide it on any. settings are the ones got by get.
+func (n *notificationSettings) push(ctx context.Context, pusher offlinepush.OfflinePusher, userIDs []string,
+	settings map[string]*model.NotificationSetting, title, content string, opts *options.Opts) error {
+	var (
+		shownUserIDs []string
+		// hiddenUserIDs groups the users hiding the content by their platforms hiding it
+		hiddenUserIDs     = make(map[string][]string)
+		hiddenPlatformIDs = make(map[string][]int)
+	)
+	for _, userID := range userIDs {
+		setting := settings[userID]
+		if setting == nil || len(setting.HidePreviewPlatformIDs) == 0 {
+			shownUserIDs = append(shownUserIDs, userID)
+			continue
+		}
+		platformIDs := datautil.Slice(setting.HidePreviewPlatformIDs, func(platformID int32) int { return int(platformID) })
+		sort.Ints(platformIDs)
+		key := fmt.Sprint(platformIDs)
+		hiddenUserIDs[key] = append(hiddenUserIDs[key], userID)
+		hiddenPlatformIDs[key] = platformIDs
+	}
+	var errList []error
+	if len(shownUserIDs) > 0 {
+		errList = append(errList, pusher.Push(ctx, shownUserIDs, title, content, opts))
+	}
+	hiddenContent := constant.ContentType2PushContent[constant.Common]
+	for key, groupUserIDs := range hiddenUserIDs {
+		hiddenCtx, hiddenOpts := ctx, *opts
+		if n.limitsPlatforms {
+			platformIDs := hiddenPlatformIDs[key]
+			shownPlatformIDs := datautil.Filter(thirdext.DevicePlatformIDs, func(platformID int) (int, bool) {
+				return platformID, !datautil.Contain(platformID, platformIDs...)
+			})
+			if len(shownPlatformIDs) > 0 {
+				errList = append(errList, pusher.Push(offlinepush.WithPlatformIDs(ctx, shownPlatformIDs), groupUserIDs, title, content, opts))
+				// the badge was counted by the push to the other platforms
+				hiddenOpts.IOSBadgeCount = false
+			}
+			hiddenCtx = offlinepush.WithPlatformIDs(ctx, platformIDs)
+		}
+		errList = append(errList, pusher.Push(hiddenCtx, groupUserIDs, hiddenContent, hiddenContent, &hiddenOpts))
+	}
+	return errors.Join(errList...)
+}
@@ -0,0 +1,147 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/userext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type settingsClient struct {
+	userext.UserExtClient
+	settings []*userext.NotificationSetting
+	// gotUserIDs are the users whose settings were got
+	gotUserIDs []string
+}
+
+func (s *settingsClient) GetNotificationSettings(_ context.Context, in *userext.GetNotificationSettingsReq, _ ...grpc.CallOption) (*userext.GetNotificationSettingsResp, error) {
+	s.gotUserIDs = append(s.gotUserIDs, in.UserIDs...)
+	var resp userext.GetNotificationSettingsResp
+	for _, setting := range s.settings {
+		for _, userID := range in.UserIDs {
+			if setting.UserID == userID {
+				resp.Settings = append(resp.Settings, setting)
+			}
+		}
+	}
+	return &resp, nil
+}
+
+type recordedPush struct {
+	platformIDs   []int
+	userIDs       []string
+	content       string
+	iosBadgeCount bool
+}
+
+type recordingPusher struct {
+	pushes []recordedPush
+}
+
+func (r *recordingPusher) Push(ctx context.Context, userIDs []string, _, content string, opts *options.Opts) error {
+	r.pushes = append(r.pushes, recordedPush{
+		platformIDs:   offlinepush.PlatformIDs(ctx),
+		userIDs:       userIDs,
+		content:       content,
+		iosBadgeCount: opts.IOSBadgeCount,
+	})
+	return nil
+}
+
+func newTestNotificationSettings(limitsPlatforms bool, settings ...*userext.NotificationSetting) *notificationSettings {
+	return &notificationSettings{
+		userRpcClient:   &rpcclient.UserRpcClient{ExtClient: &settingsClient{settings: settings}},
+		cache:           newNotificationSettingCache(),
+		limitsPlatforms: limitsPlatforms,
+		now: func() time.Time {
+			return time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+		},
+	}
+}
+
+func TestNotificationSettingsFilter(t *testing.T) {
+	n := newTestNotificationSettings(true,
+		&userext.NotificationSetting{UserID: "quiet", DNDWindows: []*userext.DNDWindow{{Start: "22:00", End: "07:00"}}},
+		&userext.NotificationSetting{UserID: "mentions", GroupMentionOnly: true},
+		&userext.NotificationSetting{UserID: "alerts", GroupMentionOnly: true, Keywords: []string{"outage"}},
+	)
+	ctx := context.Background()
+	userIDs := []string{"quiet", "mentions", "alerts", "default"}
+
+	msg := &sdkws.MsgData{SessionType: constant.ReadGroupChatType, ContentType: constant.Text, Content: []byte(`{"content":"hello"}`)}
+	assert.Equal(t, []string{"default"}, n.filter(ctx, msg, userIDs, n.get(ctx, userIDs)))
+
+	msg.AtUserIDList = []string{"mentions"}
+	assert.Equal(t, []string{"mentions", "default"}, n.filter(ctx, msg, userIDs, n.get(ctx, userIDs)))
+
+	msg.AtUserIDList = nil
+	msg.Content = []byte(`{"content":"Outage in eu-west"}`)
+	assert.Equal(t, []string{"alerts", "default"}, n.filter(ctx, msg, userIDs, n.get(ctx, userIDs)))
+
+	msg = &sdkws.MsgData{SessionType: constant.SingleChatType, ContentType: constant.Text, Content: []byte(`{"content":"hello"}`)}
+	assert.Equal(t, []string{"mentions", "alerts", "default"}, n.filter(ctx, msg, userIDs, n.get(ctx, userIDs)))
+}
+
+func TestNotificationSettingsPushHidesPreview(t *testing.T) {
+	hidden := constant.ContentType2PushContent[constant.Common]
+	settings := []*userext.NotificationSetting{
+		{UserID: "ios", HidePreviewPlatformIDs: []int32{constant.IOSPlatformID, constant.IPadPlatformID}},
+		{UserID: "all", HidePreviewPlatformIDs: []int32{
+			constant.IOSPlatformID, constant.AndroidPlatformID, constant.IPadPlatformID, constant.AndroidPadPlatformID,
+		}},
+	}
+	opts := &options.Opts{IOSBadgeCount: true}
+	ctx := context.Background()
+	userIDs := []string{"default", "ios", "all"}
+
+	pusher := &recordingPusher{}
+	n := newTestNotificationSettings(true, settings...)
+	assert.NoError(t, n.push(ctx, pusher, userIDs, n.get(ctx, userIDs), "title", "hello", opts))
+	assert.ElementsMatch(t, []recordedPush{
+		{userIDs: []string{"default"}, content: "hello", iosBadgeCount: true},
+		{platformIDs: []int{constant.AndroidPlatformID, constant.AndroidPadPlatformID}, userIDs: []string{"ios"}, content: "hello", iosBadgeCount: true},
+		{platformIDs: []int{constant.IOSPlatformID, constant.IPadPlatformID}, userIDs: []string{"ios"}, content: hidden},
+		{platformIDs: []int{constant.IOSPlatformID, constant.AndroidPlatformID, constant.AndroidPadPlatformID, constant.IPadPlatformID},
+			userIDs: []string{"all"}, content: hidden, iosBadgeCount: true},
+	}, pusher.pushes)
+
+	pusher = &recordingPusher{}
+	n = newTestNotificationSettings(false, settings...)
+	assert.NoError(t, n.push(ctx, pusher, []string{"ios"}, n.get(ctx, []string{"ios"}), "title", "hello", opts))
+	assert.Equal(t, []recordedPush{{userIDs: []string{"ios"}, content: hidden, iosBadgeCount: true}}, pusher.pushes)
+}
+
+func TestNotificationSettingsGetCaches(t *testing.T) {
+	n := newTestNotificationSettings(true, &userext.NotificationSetting{UserID: "quiet", GroupMentionOnly: true})
+	client := n.userRpcClient.ExtClient.(*settingsClient)
+	ctx := context.Background()
+
+	settings := n.get(ctx, []string{"quiet", "default"})
+	assert.Len(t, settings, 1)
+	assert.True(t, settings["quiet"].GroupMentionOnly)
+	settings = n.get(ctx, []string{"quiet", "default", "other"})
+	assert.Len(t, settings, 1)
+	assert.Equal(t, []string{"quiet", "default", "other"}, client.gotUserIDs, "the cached users, with or without a setting, are not got again")
+}
@@ -0,0 +1,307 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apns
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	productionHost  = "https://api.push.apple.com"
+	developmentHost = "https://api.sandbox.push.apple.com"
+
+	// tokenRefresh is how long a provider token is used, APNs rejects the tokens older than an hour
+	// and the tokens renewed more often than every 20 minutes.
+	tokenRefresh = time.Minute * 50
+
+	// pushConcurrency is the number of notifications sent at once over the HTTP/2 connection.
+	pushConcurrency = 16
+
+	// collapseIDMaxLen is the max length of the apns-collapse-id header.
+	collapseIDMaxLen = 64
+)
+
+// Terminal is the platforms whose device tokens are pushed to through APNs.
+var Terminal = []int{constant.IOSPlatformID, constant.IPadPlatformID}
+
+// APNs pushes to the device tokens set by the iOS clients with the token-based authentication of Apple.
+type APNs struct {
+	httpClient *http.Client
+	cache      cache.ThirdCache
+	host       string
+	topic      string
+	keyID      string
+	teamID     string
+	key        *ecdsa.PrivateKey
+	terminal   []int
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	tokenTime time.Time
+}
+
+// NewClient loads the .p8 key of pushConf.APNs, its path is relative to the configuration directory.
+// It pushes to the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, configPath string, platformIDs []int) (*APNs, error) {
+	conf := pushConf.APNs
+	if conf.KeyFilePath == "" || conf.KeyID == "" || conf.TeamID == "" || conf.BundleID == "" {
+		return nil, errs.New("no APNs config").Wrap()
+	}
+	data, err := os.ReadFile(filepath.Join(configPath, conf.KeyFilePath))
+	if err != nil {
+		return nil, errs.WrapMsg(err, "read APNs key failed", "path", conf.KeyFilePath)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "parse APNs key failed", "path", conf.KeyFilePath)
+	}
+	host := developmentHost
+	if pushConf.IOSPush.Production {
+		host = productionHost
+	}
+	httpClient := &http.Client{
+		Timeout: time.Second * 10,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     &tls.Config{MinVersion: tls.VersionTLS12},
+			ForceAttemptHTTP2:   true,
+			MaxIdleConnsPerHost: pushConcurrency,
+			IdleConnTimeout:     time.Minute * 5,
+		},
+	}
+	a := newClient(httpClient, cache, host, conf.BundleID, conf.KeyID, conf.TeamID, key)
+	if len(platformIDs) > 0 {
+		a.terminal = platformIDs
+	}
+	return a, nil
+}
+
+func newClient(httpClient *http.Client, cache cache.ThirdCache, host, topic, keyID, teamID string, key *ecdsa.PrivateKey) *APNs {
+	return &APNs{
+		httpClient: httpClient,
+		cache:      cache,
+		host:       host,
+		topic:      topic,
+		keyID:      keyID,
+		teamID:     teamID,
+		key:        key,
+		terminal:   Terminal,
+		now:        time.Now,
+	}
+}
+
+type payload struct {
+	Aps aps    `json:"aps"`
+	Ex  string `json:"ex,omitempty"`
+}
+
+type aps struct {
+	Alert alert  `json:"alert"`
+	Sound string `json:"sound,omitempty"`
+	Badge *int   `json:"badge,omitempty"`
+}
+
+type alert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// Error is a notification rejected by APNs.
+type Error struct {
+	Status int
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return "apns status " + strconv.Itoa(e.Status) + " " + e.Reason
+}
+
+// InvalidToken reports whether the device token will never be accepted again.
+func (e *Error) InvalidToken() bool {
+	switch e.Reason {
+	case "BadDeviceToken", "DeviceTokenNotForTopic", "Unregistered":
+		return true
+	default:
+		return e.Status == http.StatusGone
+	}
+}
+
+// providerTokenRejected reports whether the provider token must be signed again.
+func (e *Error) providerTokenRejected() bool {
+	return e.Reason == "ExpiredProviderToken" || e.Reason == "InvalidProviderToken"
+}
+
+func (a *APNs) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	var failures pushutil.Failures
+	var collapseID string
+	if opts.Signal != nil && len(opts.Signal.ClientMsgID) <= collapseIDMaxLen {
+		collapseID = opts.Signal.ClientMsgID
+	}
+	var g errgroup.Group
+	g.SetLimit(pushConcurrency)
+	for _, userID := range userIDs {
+		tokens := pushutil.GetTokens(ctx, a.cache, []string{userID}, a.terminal)
+		if len(tokens) == 0 {
+			continue
+		}
+		body, err := a.payload(ctx, userID, title, content, opts)
+		if err != nil {
+			failures.Add(1, tokens, err)
+			continue
+		}
+		for _, token := range tokens {
+			token := token
+			g.Go(func() error {
+				err := a.send(ctx, token.Token, collapseID, body)
+				if err == nil {
+					return nil
+				}
+				failures.Add(1, []pushutil.Token{token}, err)
+				if apnsErr, ok := err.(*Error); ok && apnsErr.InvalidToken() {
+					log.ZInfo(ctx, "APNs token rejected", "userID", token.UserID, "platformID", token.PlatformID, "reason", apnsErr.Reason)
+					pushutil.DeleteTokens(ctx, a.cache, []pushutil.Token{token})
+				}
+				return nil
+			})
+		}
+	}
+	_ = g.Wait()
+	return failures.Err()
+}
+
+// payload returns the notification pushed to userID, the badge is the unread count of the user.
+func (a *APNs) payload(ctx context.Context, userID string, title, content string, opts *options.Opts) ([]byte, error) {
+	p := payload{
+		Aps: aps{
+			Alert: alert{Title: title, Body: content},
+			Sound: opts.IOSPushSound,
+		},
+		Ex: opts.Ex,
+	}
+	var (
+		badge int
+		err   error
+	)
+	if opts.IOSBadgeCount {
+		badge, err = a.cache.IncrUserBadgeUnreadCountSum(ctx, userID)
+	} else {
+		badge, err = a.cache.GetUserBadgeUnreadCountSum(ctx, userID)
+		if errs.Unwrap(err) == redis.Nil {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if badge > 0 {
+		p.Aps.Badge = &badge
+	}
+	data, err := json.Marshal(&p)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return data, nil
+}
+
+func (a *APNs) send(ctx context.Context, deviceToken string, collapseID string, body []byte) error {
+	token, err := a.providerToken()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.host+"/3/device/"+deviceToken, bytes.NewReader(body))
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", a.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("content-type", "application/json")
+	if collapseID != "" {
+		req.Header.Set("apns-collapse-id", collapseID)
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return errs.WrapMsg(err, "apns request failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	var res struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&res)
+	apnsErr := &Error{Status: resp.StatusCode, Reason: res.Reason}
+	if apnsErr.providerTokenRejected() {
+		a.resetProviderToken(token)
+	}
+	return apnsErr
+}
+
+// providerToken returns the signed provider token, it is signed again when it is about to expire.
+func (a *APNs) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	if a.token != "" && now.Sub(a.tokenTime) < tokenRefresh {
+		return a.token, nil
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.teamID,
+		"iat": now.Unix(),
+	})
+	t.Header["kid"] = a.keyID
+	token, err := t.SignedString(a.key)
+	if err != nil {
+		return "", errs.WrapMsg(err, "sign APNs provider token failed")
+	}
+	a.token = token
+	a.tokenTime = now
+	return token, nil
+}
+
+// resetProviderToken drops token rejected by APNs, unless another request already replaced it.
+func (a *APNs) resetProviderToken(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.token = ""
+	}
+}
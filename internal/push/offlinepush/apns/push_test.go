@@ -0,0 +1,266 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apns
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryThirdCache struct {
+	cache.ThirdCache
+	mu     sync.Mutex
+	tokens map[string]string
+	badges map[string]int
+}
+
+func (m *memoryThirdCache) key(account string, platformID int) string {
+	return account + ":" + strconv.Itoa(platformID)
+}
+
+func (m *memoryThirdCache) GetFcmToken(_ context.Context, account string, platformID int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[m.key(account, platformID)]
+	if !ok {
+		return "", errs.ErrRecordNotFound.Wrap()
+	}
+	return token, nil
+}
+
+func (m *memoryThirdCache) GetDevices(_ context.Context, userID string) ([]*model.Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var devices []*model.Device
+	for key, token := range m.tokens {
+		account, platform, _ := strings.Cut(key, ":")
+		if account != userID {
+			continue
+		}
+		platformID, _ := strconv.Atoi(platform)
+		devices = append(devices, &model.Device{UserID: account, PlatformID: platformID, Token: token})
+	}
+	return devices, nil
+}
+
+func (m *memoryThirdCache) DelFcmToken(_ context.Context, account string, platformID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, m.key(account, platformID))
+	return nil
+}
+
+func (m *memoryThirdCache) IncrUserBadgeUnreadCountSum(_ context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.badges[userID]++
+	return m.badges[userID], nil
+}
+
+func (m *memoryThirdCache) GetUserBadgeUnreadCountSum(_ context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.badges[userID], nil
+}
+
+type pushed struct {
+	token   string
+	header  http.Header
+	payload payload
+}
+
+// newStub returns an APNs stub over HTTP/2 which rejects the device token "gone".
+func newStub(t *testing.T, key *ecdsa.PrivateKey) (*httptest.Server, func() []pushed) {
+	var (
+		mu   sync.Mutex
+		msgs []pushed
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		assert.Equal(t, http.MethodPost, r.Method)
+		auth := strings.TrimPrefix(r.Header.Get("authorization"), "bearer ")
+		token, err := jwt.Parse(auth, func(token *jwt.Token) (any, error) {
+			assert.Equal(t, "key1", token.Header["kid"])
+			return &key.PublicKey, nil
+		})
+		if !assert.NoError(t, err) || !token.Valid {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"reason":"InvalidProviderToken"}`))
+			return
+		}
+		deviceToken := strings.TrimPrefix(r.URL.Path, "/3/device/")
+		if deviceToken == "gone" {
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason":"Unregistered"}`))
+			return
+		}
+		var p payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		mu.Lock()
+		msgs = append(msgs, pushed{token: deviceToken, header: r.Header.Clone(), payload: p})
+		mu.Unlock()
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, func() []pushed {
+		mu.Lock()
+		defer mu.Unlock()
+		return msgs
+	}
+}
+
+func TestPush(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	server, received := newStub(t, key)
+	c := &memoryThirdCache{
+		tokens: map[string]string{
+			"u1:" + strconv.Itoa(constant.IOSPlatformID):  "t1",
+			"u1:" + strconv.Itoa(constant.IPadPlatformID): "gone",
+			"u2:" + strconv.Itoa(constant.IOSPlatformID):  "t2",
+		},
+		badges: map[string]int{"u2": 4},
+	}
+	a := newClient(server.Client(), c, server.URL, "io.openim.app", "key1", "team1", key)
+	opts := &options.Opts{IOSPushSound: "default", IOSBadgeCount: true, Ex: "ex", Signal: &options.Signal{ClientMsgID: "m1"}}
+	err = a.Push(context.Background(), []string{"u1", "u2", "u3"}, "title", "content", opts)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"u1"}, pushutil.FailedUserIDs(err, []string{"u1", "u2", "u3"}), "only the user of the rejected token failed")
+
+	msgs := received()
+	assert.Len(t, msgs, 2)
+	for _, msg := range msgs {
+		assert.Equal(t, "io.openim.app", msg.header.Get("apns-topic"))
+		assert.Equal(t, "alert", msg.header.Get("apns-push-type"))
+		assert.Equal(t, "m1", msg.header.Get("apns-collapse-id"))
+		assert.Equal(t, "title", msg.payload.Aps.Alert.Title)
+		assert.Equal(t, "content", msg.payload.Aps.Alert.Body)
+		assert.Equal(t, "default", msg.payload.Aps.Sound)
+		assert.Equal(t, "ex", msg.payload.Ex)
+		switch msg.token {
+		case "t1":
+			assert.Equal(t, 1, *msg.payload.Aps.Badge)
+		case "t2":
+			assert.Equal(t, 5, *msg.payload.Aps.Badge)
+		default:
+			t.Errorf("unexpected token %s", msg.token)
+		}
+	}
+	_, err = c.GetFcmToken(context.Background(), "u1", constant.IPadPlatformID)
+	assert.Error(t, err, "the unregistered token is deleted")
+
+	assert.NoError(t, a.Push(context.Background(), []string{"u1"}, "title", "content", &options.Opts{}))
+	msgs = received()
+	assert.Len(t, msgs, 3)
+	assert.Equal(t, 1, *msgs[2].payload.Aps.Badge, "badge is not incremented")
+}
+
+// replacedTokenCache is a cache in which the client replaced the tokens of replaced after they were read.
+type replacedTokenCache struct {
+	*memoryThirdCache
+	replaced map[string]string
+}
+
+func (r *replacedTokenCache) GetFcmToken(ctx context.Context, account string, platformID int) (string, error) {
+	if token, ok := r.replaced[r.key(account, platformID)]; ok {
+		return token, nil
+	}
+	return r.memoryThirdCache.GetFcmToken(ctx, account, platformID)
+}
+
+func TestPushKeepsReplacedToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	server, _ := newStub(t, key)
+	ipad := "u1:" + strconv.Itoa(constant.IPadPlatformID)
+	c := &replacedTokenCache{
+		memoryThirdCache: &memoryThirdCache{tokens: map[string]string{ipad: "gone"}},
+		replaced:         map[string]string{ipad: "new"},
+	}
+	a := newClient(server.Client(), c, server.URL, "io.openim.app", "key1", "team1", key)
+	assert.Error(t, a.Push(context.Background(), []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Equal(t, "gone", c.tokens[ipad], "the token set since the push is not deleted")
+}
+
+func TestProviderToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	a := newClient(http.DefaultClient, nil, developmentHost, "io.openim.app", "key1", "team1", key)
+	now := time.Unix(1000, 0)
+	a.now = func() time.Time { return now }
+	token, err := a.providerToken()
+	assert.NoError(t, err)
+	again, err := a.providerToken()
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	a.resetProviderToken(token)
+	now = now.Add(time.Second)
+	again, err = a.providerToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, again)
+
+	now = now.Add(tokenRefresh)
+	renewed, err := a.providerToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, again, renewed)
+}
+
+func TestNewClient(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "key.p8"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	var conf config.Push
+	_, err = NewClient(&conf, nil, dir, nil)
+	assert.Error(t, err)
+	conf.APNs.KeyFilePath = "key.p8"
+	conf.APNs.KeyID = "key1"
+	conf.APNs.TeamID = "team1"
+	conf.APNs.BundleID = "io.openim.app"
+	conf.IOSPush.Production = true
+	a, err := NewClient(&conf, nil, dir, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, productionHost, a.host)
+	assert.True(t, a.key.Equal(key))
+}
@@ -0,0 +1,151 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+	"github.com/redis/go-redis/v9"
+)
+
+// deviceRouter pushes to each device the users registered through the provider it was registered with.
+type deviceRouter struct {
+	cache           cache.ThirdCache
+	defaultProvider string
+	pushers         map[string]OfflinePusher
+}
+
+func newDeviceRouter(pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath string) (*deviceRouter, error) {
+	conf := pushConf.Devices
+	if len(conf.Providers) == 0 {
+		return nil, errs.New("no push device providers").Wrap()
+	}
+	if conf.Default != "" && !datautil.Contain(conf.Default, conf.Providers...) {
+		return nil, errs.New("default push device provider is not one of the providers", "default", conf.Default).Wrap()
+	}
+	r := &deviceRouter{
+		cache:           cache,
+		defaultProvider: conf.Default,
+		pushers:         make(map[string]OfflinePusher, len(conf.Providers)),
+	}
+	for _, provider := range datautil.Distinct(conf.Providers) {
+		tokens := &deviceTokens{ThirdCache: cache, provider: provider, isDefault: provider == conf.Default}
+		pusher, err := newTokenPusher(provider, pushConf, tokens, fcmConfigPath, thirdext.DevicePlatformIDs)
+		if err != nil {
+			return nil, err
+		}
+		r.pushers[provider] = pusher
+	}
+	return r, nil
+}
+
+func (r *deviceRouter) provider(device *model.Device) string {
+	if device.Provider == "" {
+		return r.defaultProvider
+	}
+	return device.Provider
+}
+
+func (r *deviceRouter) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	var errList []error
+	devices := make(map[string][]*model.Device, len(userIDs))
+	providerUserIDs := make(map[string][]string)
+	for _, userID := range userIDs {
+		userDevices, err := r.cache.GetDevices(ctx, userID)
+		if err != nil {
+			errList = append(errList, &pushutil.UsersError{UserIDs: []string{userID}, Err: err})
+			continue
+		}
+		devices[userID] = userDevices
+		providers := make(map[string]struct{}, len(userDevices))
+		for _, device := range userDevices {
+			provider := r.provider(device)
+			if _, ok := r.pushers[provider]; !ok {
+				log.ZWarn(ctx, "push device provider not configured", nil, "userID", userID, "platformID", device.PlatformID, "provider", device.Provider)
+				continue
+			}
+			if _, ok := providers[provider]; ok {
+				continue
+			}
+			providers[provider] = struct{}{}
+			providerUserIDs[provider] = append(providerUserIDs[provider], userID)
+		}
+	}
+	// the pushers read the tokens from the devices read above instead of reading them again
+	ctx = context.WithValue(ctx, devicesKey{}, devices)
+	return errors.Join(append(errList, pushProviders(ctx, r.pushers, providerUserIDs, title, content, opts))...)
+}
+
+func (r *deviceRouter) PushProvider(ctx context.Context, provider string, userIDs []string, title, content string, opts *options.Opts) error {
+	pusher, err := providerPusher(r.pushers, provider)
+	if err != nil {
+		return err
+	}
+	return pusher.Push(ctx, userIDs, title, content, opts)
+}
+
+type devicesKey struct{}
+
+// deviceTokens is the cache of the pusher of provider, the tokens it reads are the ones of the devices
+// registered with provider, or without one when provider is the default.
+type deviceTokens struct {
+	cache.ThirdCache
+	provider  string
+	isDefault bool
+}
+
+// GetDevices returns the devices of userID registered with the provider of d, from the devices read by the router when it has.
+func (d *deviceTokens) GetDevices(ctx context.Context, userID string) ([]*model.Device, error) {
+	devices, _ := ctx.Value(devicesKey{}).(map[string][]*model.Device)
+	userDevices, ok := devices[userID]
+	if !ok {
+		var err error
+		userDevices, err = d.ThirdCache.GetDevices(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return datautil.Filter(userDevices, func(device *model.Device) (*model.Device, bool) {
+		return device, device.Provider == d.provider || (device.Provider == "" && d.isDefault)
+	}), nil
+}
+
+func (d *deviceTokens) GetFcmToken(ctx context.Context, account string, platformID int) (string, error) {
+	devices, err := d.GetDevices(ctx, account)
+	if err != nil {
+		return "", err
+	}
+	return deviceToken(devices, platformID)
+}
+
+// deviceToken returns the token of the device of platformID among devices, the error is redis.Nil when there is none.
+func deviceToken(devices []*model.Device, platformID int) (string, error) {
+	for _, device := range devices {
+		if device.PlatformID == platformID {
+			return device.Token, nil
+		}
+	}
+	return "", errs.Wrap(redis.Nil)
+}
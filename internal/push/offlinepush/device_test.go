@@ -0,0 +1,104 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryDeviceCache struct {
+	cache.ThirdCache
+	devices map[string][]*model.Device
+	errs    map[string]error
+	reads   int
+}
+
+func (m *memoryDeviceCache) GetDevices(_ context.Context, userID string) ([]*model.Device, error) {
+	m.reads++
+	if err := m.errs[userID]; err != nil {
+		return nil, err
+	}
+	return m.devices[userID], nil
+}
+
+// tokenPusher records the tokens of the users it is asked to push to.
+type tokenPusher struct {
+	cache  cache.ThirdCache
+	mu     sync.Mutex
+	tokens []pushutil.Token
+}
+
+func (p *tokenPusher) Push(ctx context.Context, userIDs []string, _, _ string, _ *options.Opts) error {
+	tokens := pushutil.GetTokens(ctx, p.cache, userIDs, thirdext.DevicePlatformIDs)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokens = append(p.tokens, tokens...)
+	return nil
+}
+
+func TestDeviceRouterPush(t *testing.T) {
+	c := &memoryDeviceCache{devices: map[string][]*model.Device{
+		"u1": {
+			{UserID: "u1", PlatformID: constant.IOSPlatformID, Provider: "apns", Token: "ios"},
+			{UserID: "u1", PlatformID: constant.AndroidPadPlatformID, Provider: "fcm", Token: "apad"},
+		},
+		"u2": {
+			{UserID: "u2", PlatformID: constant.AndroidPlatformID, Token: "android"},
+			{UserID: "u2", PlatformID: constant.IPadPlatformID, Provider: "jpush", Token: "ipad"},
+		},
+	}}
+	pushers := map[string]*tokenPusher{
+		"apns": {cache: &deviceTokens{ThirdCache: c, provider: "apns"}},
+		"fcm":  {cache: &deviceTokens{ThirdCache: c, provider: "fcm", isDefault: true}},
+	}
+	r := &deviceRouter{cache: c, defaultProvider: "fcm", pushers: map[string]OfflinePusher{}}
+	for provider, pusher := range pushers {
+		r.pushers[provider] = pusher
+	}
+
+	err := r.Push(context.Background(), []string{"u1", "u2", "u3"}, "title", "content", &options.Opts{})
+	assert.NoError(t, err)
+	assert.Equal(t, []pushutil.Token{{UserID: "u1", PlatformID: constant.IOSPlatformID, Token: "ios"}}, pushers["apns"].tokens)
+	assert.ElementsMatch(t, []pushutil.Token{
+		{UserID: "u1", PlatformID: constant.AndroidPadPlatformID, Token: "apad"},
+		{UserID: "u2", PlatformID: constant.AndroidPlatformID, Token: "android"},
+	}, pushers["fcm"].tokens)
+	assert.Equal(t, 3, c.reads)
+}
+
+func TestDeviceRouterPushGetDevicesFailed(t *testing.T) {
+	c := &memoryDeviceCache{
+		devices: map[string][]*model.Device{"u1": {{UserID: "u1", PlatformID: constant.AndroidPlatformID, Token: "android"}}},
+		errs:    map[string]error{"u2": errors.New("redis unavailable")},
+	}
+	pusher := &tokenPusher{cache: &deviceTokens{ThirdCache: c, provider: "fcm", isDefault: true}}
+	r := &deviceRouter{cache: c, defaultProvider: "fcm", pushers: map[string]OfflinePusher{"fcm": pusher}}
+
+	err := r.Push(context.Background(), []string{"u1", "u2"}, "title", "content", &options.Opts{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"u2"}, pushutil.FailedUserIDs(err, []string{"u1", "u2"}))
+	assert.Equal(t, []pushutil.Token{{UserID: "u1", PlatformID: constant.AndroidPlatformID, Token: "android"}}, pusher.tokens)
+}
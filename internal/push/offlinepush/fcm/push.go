@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
 	"github.com/openimsdk/tools/utils/httputil"
 	"path/filepath"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/api/option"
 )
@@ -37,13 +39,15 @@ const SinglePushCountLimit = 400
 var Terminal = []int{constant.IOSPlatformID, constant.AndroidPlatformID, constant.WebPlatformID}
 
 type Fcm struct {
-	fcmMsgCli *messaging.Client
-	cache     cache.ThirdCache
+	fcmMsgCli   *messaging.Client
+	cache       cache.ThirdCache
+	platformIDs []int
 }
 
 // NewClient initializes a new FCM client using the Firebase Admin SDK.
 // It requires the FCM service account credentials file located within the project's configuration directory.
-func NewClient(pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath string) (*Fcm, error) {
+// It pushes to the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath string, platformIDs []int) (*Fcm, error) {
 	var opt option.ClientOption
 	switch {
 	case len(pushConf.FCM.FilePath) != 0:
@@ -71,21 +75,20 @@ func NewClient(pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath stri
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
-	return &Fcm{fcmMsgCli: fcmMsgClient, cache: cache}, nil
+	if len(platformIDs) == 0 {
+		platformIDs = Terminal
+	}
+	return &Fcm{fcmMsgCli: fcmMsgClient, cache: cache, platformIDs: platformIDs}, nil
 }
 
 func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
 	// accounts->registrationToken
-	allTokens := make(map[string][]string, 0)
+	allTokens := make(map[string][]string, len(userIDs))
 	for _, account := range userIDs {
-		var personTokens []string
-		for _, v := range Terminal {
-			Token, err := f.cache.GetFcmToken(ctx, account, v)
-			if err == nil {
-				personTokens = append(personTokens, Token)
-			}
-		}
-		allTokens[account] = personTokens
+		allTokens[account] = nil
+	}
+	for _, token := range pushutil.GetTokens(ctx, f.cache, userIDs, f.platformIDs) {
+		allTokens[token.UserID] = append(allTokens[token.UserID], token.Token)
 	}
 	Success := 0
 	Fail := 0
@@ -93,32 +96,40 @@ func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string,
 	notification.Body = content
 	notification.Title = title
 	var messages []*messaging.Message
+	// messageUserIDs are the users of messages
+	var messageUserIDs []string
+	var failedUserIDs []string
 	var sendErrBuilder strings.Builder
 	var msgErrBuilder strings.Builder
-	for userID, personTokens := range allTokens {
-		apns := &messaging.APNSConfig{Payload: &messaging.APNSPayload{Aps: &messaging.Aps{Sound: opts.IOSPushSound}}}
-		messageCount := len(messages)
-		if messageCount >= SinglePushCountLimit {
-			response, err := f.fcmMsgCli.SendEach(ctx, messages)
-			if err != nil {
-				Fail = Fail + messageCount
-				// Record push error
-				sendErrBuilder.WriteString(err.Error())
-				sendErrBuilder.WriteByte('.')
-			} else {
-				Success = Success + response.SuccessCount
-				Fail = Fail + response.FailureCount
-				if response.FailureCount != 0 {
-					// Record message error
-					for i := range response.Responses {
-						if !response.Responses[i].Success {
-							msgErrBuilder.WriteString(response.Responses[i].Error.Error())
-							msgErrBuilder.WriteByte('.')
-						}
+	sendEach := func() {
+		response, err := f.fcmMsgCli.SendEach(ctx, messages)
+		if err != nil {
+			Fail = Fail + len(messages)
+			failedUserIDs = append(failedUserIDs, messageUserIDs...)
+			// Record push error
+			sendErrBuilder.WriteString(err.Error())
+			sendErrBuilder.WriteByte('.')
+		} else {
+			Success = Success + response.SuccessCount
+			Fail = Fail + response.FailureCount
+			if response.FailureCount != 0 {
+				// Record message error
+				for i := range response.Responses {
+					if !response.Responses[i].Success {
+						failedUserIDs = append(failedUserIDs, messageUserIDs[i])
+						msgErrBuilder.WriteString(response.Responses[i].Error.Error())
+						msgErrBuilder.WriteByte('.')
 					}
 				}
 			}
-			messages = messages[0:0]
+		}
+		messages = messages[0:0]
+		messageUserIDs = messageUserIDs[0:0]
+	}
+	for userID, personTokens := range allTokens {
+		apns := &messaging.APNSConfig{Payload: &messaging.APNSPayload{Aps: &messaging.Aps{Sound: opts.IOSPushSound}}}
+		if len(messages) >= SinglePushCountLimit {
+			sendEach()
 		}
 		if opts.IOSBadgeCount {
 			unreadCountSum, err := f.cache.IncrUserBadgeUnreadCountSum(ctx, userID)
@@ -127,6 +138,7 @@ func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string,
 			} else {
 				// log.Error(operationID, "IncrUserBadgeUnreadCountSum redis err", err.Error(), uid)
 				Fail++
+				failedUserIDs = append(failedUserIDs, userID)
 				continue
 			}
 		} else {
@@ -139,6 +151,7 @@ func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string,
 			} else {
 				// log.Error(operationID, "GetUserBadgeUnreadCountSum redis err", err.Error(), uid)
 				Fail++
+				failedUserIDs = append(failedUserIDs, userID)
 				continue
 			}
 		}
@@ -150,21 +163,18 @@ func (f *Fcm) Push(ctx context.Context, userIDs []string, title, content string,
 				APNS:         apns,
 			}
 			messages = append(messages, temp)
+			messageUserIDs = append(messageUserIDs, userID)
 		}
 	}
-	messageCount := len(messages)
-	if messageCount > 0 {
-		response, err := f.fcmMsgCli.SendEach(ctx, messages)
-		if err != nil {
-			Fail = Fail + messageCount
-		} else {
-			Success = Success + response.SuccessCount
-			Fail = Fail + response.FailureCount
-		}
+	if len(messages) > 0 {
+		sendEach()
 	}
 	if Fail != 0 {
-		return errs.New(fmt.Sprintf("%d message send failed;send err:%s;message err:%s",
-			Fail, sendErrBuilder.String(), msgErrBuilder.String())).Wrap()
+		return &pushutil.UsersError{
+			UserIDs: datautil.Distinct(failedUserIDs),
+			Err: errs.New(fmt.Sprintf("%d message send failed;send err:%s;message err:%s",
+				Fail, sendErrBuilder.String(), msgErrBuilder.String())).Wrap(),
+		}
 	}
 	return nil
 }
@@ -0,0 +1,244 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package huawei
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+const (
+	authURL = "https://oauth-login.cloud.huawei.com/oauth2/v3/token"
+	pushURL = "https://push-api.cloud.huawei.com/v1/%s/messages:send"
+
+	// SinglePushCountLimit is the number of tokens of a message.
+	SinglePushCountLimit = 1000
+
+	// Result codes of Push Kit.
+	successCode          = "80000000"
+	partialSuccessCode   = "80100000"
+	tokenExpiredCode     = "80200003"
+	allTokensInvalidCode = "80300007"
+
+	// clickActionOpenApp opens the app when the notification is tapped.
+	clickActionOpenApp = 3
+
+	// tokenExpireAhead is how long before its expiry an access token is renewed.
+	tokenExpireAhead = time.Minute
+)
+
+// Terminal is the platforms whose device tokens are pushed to by default.
+var Terminal = []int{constant.AndroidPlatformID, constant.AndroidPadPlatformID}
+
+// Huawei pushes through Huawei Push Kit.
+type Huawei struct {
+	httpClient  *http.Client
+	cache       cache.ThirdCache
+	appID       string
+	secret      string
+	authURL     string
+	pushURL     string
+	platformIDs []int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	expireAt time.Time
+}
+
+// NewClient returns the pusher to the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, platformIDs []int) (*Huawei, error) {
+	if pushConf.Huawei.AppID == "" || pushConf.Huawei.ClientSecret == "" {
+		return nil, errs.New("no huawei push config").Wrap()
+	}
+	return newClient(pushutil.NewHTTPClient(time.Second*10), cache, pushConf.Huawei.AppID, pushConf.Huawei.ClientSecret,
+		authURL, fmt.Sprintf(pushURL, pushConf.Huawei.AppID), platformIDs), nil
+}
+
+func newClient(httpClient *http.Client, cache cache.ThirdCache, appID, secret, authURL, pushURL string, platformIDs []int) *Huawei {
+	if len(platformIDs) == 0 {
+		platformIDs = Terminal
+	}
+	return &Huawei{
+		httpClient:  httpClient,
+		cache:       cache,
+		appID:       appID,
+		secret:      secret,
+		authURL:     authURL,
+		pushURL:     pushURL,
+		platformIDs: platformIDs,
+		now:         time.Now,
+	}
+}
+
+type authResp struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Error       int    `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+type sendReq struct {
+	ValidateOnly bool    `json:"validate_only"`
+	Message      message `json:"message"`
+}
+
+type message struct {
+	Android android  `json:"android"`
+	Token   []string `json:"token"`
+}
+
+type android struct {
+	Notification notification `json:"notification"`
+}
+
+type notification struct {
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	ClickAction clickAction `json:"click_action"`
+}
+
+type clickAction struct {
+	Type int `json:"type"`
+}
+
+type sendResp struct {
+	Code      string `json:"code"`
+	Msg       string `json:"msg"`
+	RequestID string `json:"requestId"`
+}
+
+// partialResult is the msg of a partially successful send.
+type partialResult struct {
+	Success       int      `json:"success"`
+	Failure       int      `json:"failure"`
+	IllegalTokens []string `json:"illegal_tokens"`
+}
+
+func (h *Huawei) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	tokens := pushutil.GetTokens(ctx, h.cache, userIDs, h.platformIDs)
+	var failures pushutil.Failures
+	for i := 0; i < len(tokens); i += SinglePushCountLimit {
+		batch := tokens[i:min(i+SinglePushCountLimit, len(tokens))]
+		req := &sendReq{
+			Message: message{
+				Android: android{Notification: notification{
+					Title:       title,
+					Body:        content,
+					ClickAction: clickAction{Type: clickActionOpenApp},
+				}},
+				Token: datautil.Slice(batch, func(token pushutil.Token) string { return token.Token }),
+			},
+		}
+		h.send(ctx, batch, req, &failures)
+	}
+	return failures.Err()
+}
+
+func (h *Huawei) send(ctx context.Context, batch []pushutil.Token, req *sendReq, failures *pushutil.Failures) {
+	var resp sendResp
+	for retry := true; ; retry = false {
+		accessToken, err := h.accessToken(ctx)
+		if err != nil {
+			failures.Add(len(batch), batch, err)
+			return
+		}
+		resp = sendResp{}
+		err = pushutil.PostJSON(ctx, h.httpClient, h.pushURL, map[string]string{"Authorization": "Bearer " + accessToken}, req, &resp)
+		var statusErr *pushutil.StatusError
+		if errors.As(err, &statusErr) && statusErr.Status == http.StatusUnauthorized {
+			resp.Code = tokenExpiredCode
+		} else if err != nil {
+			failures.Add(len(batch), batch, err)
+			return
+		}
+		if resp.Code != tokenExpiredCode {
+			break
+		}
+		h.resetAccessToken(accessToken)
+		if !retry {
+			failures.Add(len(batch), batch, errs.New("huawei access token rejected").Wrap())
+			return
+		}
+	}
+	switch resp.Code {
+	case successCode:
+	case partialSuccessCode:
+		var result partialResult
+		if err := json.Unmarshal([]byte(resp.Msg), &result); err != nil {
+			failures.Add(len(batch), batch, errs.WrapMsg(err, "huawei partial result", "msg", resp.Msg))
+			return
+		}
+		illegal := datautil.SliceSet(result.IllegalTokens)
+		pushutil.DeleteTokens(ctx, h.cache, datautil.Filter(batch, func(token pushutil.Token) (pushutil.Token, bool) {
+			_, ok := illegal[token.Token]
+			return token, ok
+		}))
+		failures.Add(result.Failure, batch, errs.New("huawei push partially failed", "requestID", resp.RequestID).Wrap())
+	case allTokensInvalidCode:
+		pushutil.DeleteTokens(ctx, h.cache, batch)
+		failures.Add(len(batch), batch, errs.New("huawei tokens invalid", "requestID", resp.RequestID).Wrap())
+	default:
+		failures.Add(len(batch), batch, errs.New("huawei push failed", "code", resp.Code, "msg", resp.Msg, "requestID", resp.RequestID).Wrap())
+	}
+}
+
+// accessToken returns the OAuth access token of the app, it is renewed before it expires.
+func (h *Huawei) accessToken(ctx context.Context) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	if h.token != "" && now.Before(h.expireAt) {
+		return h.token, nil
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {h.appID},
+		"client_secret": {h.secret},
+	}
+	var resp authResp
+	if err := pushutil.PostForm(ctx, h.httpClient, h.authURL, nil, form, &resp); err != nil {
+		return "", err
+	}
+	if resp.AccessToken == "" {
+		return "", errs.New("huawei auth failed", "error", resp.Error, "desc", resp.ErrorDesc).Wrap()
+	}
+	h.token = resp.AccessToken
+	h.expireAt = now.Add(time.Duration(resp.ExpiresIn)*time.Second - tokenExpireAhead)
+	return h.token, nil
+}
+
+// resetAccessToken drops token rejected by Push Kit, unless another request already replaced it.
+func (h *Huawei) resetAccessToken(token string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.token == token {
+		h.token = ""
+	}
+}
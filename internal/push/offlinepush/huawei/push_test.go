@@ -0,0 +1,142 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package huawei
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryThirdCache struct {
+	cache.ThirdCache
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (m *memoryThirdCache) key(account string, platformID int) string {
+	return account + ":" + strconv.Itoa(platformID)
+}
+
+func (m *memoryThirdCache) GetFcmToken(_ context.Context, account string, platformID int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[m.key(account, platformID)]
+	if !ok {
+		return "", errs.ErrRecordNotFound.Wrap()
+	}
+	return token, nil
+}
+
+func (m *memoryThirdCache) GetDevices(_ context.Context, userID string) ([]*model.Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var devices []*model.Device
+	for key, token := range m.tokens {
+		account, platform, _ := strings.Cut(key, ":")
+		if account != userID {
+			continue
+		}
+		platformID, _ := strconv.Atoi(platform)
+		devices = append(devices, &model.Device{UserID: account, PlatformID: platformID, Token: token})
+	}
+	return devices, nil
+}
+
+func (m *memoryThirdCache) DelFcmToken(_ context.Context, account string, platformID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, m.key(account, platformID))
+	return nil
+}
+
+type stub struct {
+	mu       sync.Mutex
+	auths    int
+	expired  bool
+	lastReq  sendReq
+	response sendResp
+}
+
+func (s *stub) server(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		s.auths++
+		_ = json.NewEncoder(w).Encode(&authResp{AccessToken: "at" + strconv.Itoa(s.auths), ExpiresIn: 3600})
+	})
+	mux.HandleFunc("/send", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.expired {
+			s.expired = false
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "Bearer at"+strconv.Itoa(s.auths), r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&s.lastReq))
+		_ = json.NewEncoder(w).Encode(&s.response)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestPushPartialSuccess(t *testing.T) {
+	s := &stub{}
+	result, _ := json.Marshal(&partialResult{Success: 1, Failure: 1, IllegalTokens: []string{"t2"}})
+	s.response = sendResp{Code: partialSuccessCode, Msg: string(result)}
+	srv := s.server(t)
+	defer srv.Close()
+
+	c := &memoryThirdCache{tokens: map[string]string{
+		"u1:" + strconv.Itoa(constant.AndroidPlatformID): "t1",
+		"u2:" + strconv.Itoa(constant.AndroidPlatformID): "t2",
+	}}
+	h := newClient(srv.Client(), c, "app", "secret", srv.URL+"/auth", srv.URL+"/send", nil)
+	err := h.Push(context.Background(), []string{"u1", "u2"}, "title", "content", &options.Opts{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, s.lastReq.Message.Token)
+	assert.Equal(t, "title", s.lastReq.Message.Android.Notification.Title)
+	assert.Equal(t, map[string]string{"u1:" + strconv.Itoa(constant.AndroidPlatformID): "t1"}, c.tokens)
+}
+
+func TestPushRenewsRejectedAccessToken(t *testing.T) {
+	s := &stub{response: sendResp{Code: successCode}}
+	srv := s.server(t)
+	defer srv.Close()
+
+	c := &memoryThirdCache{tokens: map[string]string{"u1:" + strconv.Itoa(constant.AndroidPadPlatformID): "t1"}}
+	h := newClient(srv.Client(), c, "app", "secret", srv.URL+"/auth", srv.URL+"/send", nil)
+	assert.NoError(t, h.Push(context.Background(), []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Equal(t, 1, s.auths)
+
+	s.expired = true
+	assert.NoError(t, h.Push(context.Background(), []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Equal(t, 2, s.auths)
+}
@@ -16,19 +16,34 @@ package offlinepush
 
 import (
 	"context"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/apns"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/dummy"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/fcm"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/getui"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/huawei"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/jpush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/oppo"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/vivo"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/webhook"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/xiaomi"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/tools/errs"
 )
 
 const (
 	geTUI    = "geTui"
 	firebase = "fcm"
 	jPush    = "jpush"
+	apple    = "apns"
+	hook     = "webhook"
+	hms      = "huawei"
+	mi       = "xiaomi"
+	heyTap   = "oppo"
+	vivoPush = "vivo"
+	// deviceRouting pushes every registered device through its own provider.
+	deviceRouting = "device"
 )
 
 // OfflinePusher Offline Pusher.
@@ -36,17 +51,49 @@ type OfflinePusher interface {
 	Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error
 }
 
+// NewOfflinePusher creates the pusher named by pushConf.Enable, a device router when it is device,
+// or a platform router when pushConf.Platforms assigns providers to device platforms.
 func NewOfflinePusher(pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath string) (OfflinePusher, error) {
+	if pushConf.Enable == deviceRouting {
+		return newDeviceRouter(pushConf, cache, fcmConfigPath)
+	}
+	if len(pushConf.Platforms) > 0 {
+		return newPlatformRouter(pushConf, cache, fcmConfigPath)
+	}
 	var offlinePusher OfflinePusher
 	switch pushConf.Enable {
 	case geTUI:
 		offlinePusher = getui.NewClient(pushConf, cache)
-	case firebase:
-		return fcm.NewClient(pushConf, cache, fcmConfigPath)
 	case jPush:
 		offlinePusher = jpush.NewClient(pushConf)
+	case firebase, apple, hook, hms, mi, heyTap, vivoPush:
+		return newTokenPusher(pushConf.Enable, pushConf, cache, fcmConfigPath, nil)
 	default:
 		offlinePusher = dummy.NewClient()
 	}
 	return offlinePusher, nil
 }
+
+// newTokenPusher creates a provider that pushes to the device tokens of platformIDs, or of every platform when it is nil.
+// geTui and jpush address users by alias and cannot be limited to platforms.
+func newTokenPusher(provider string, pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath string, platformIDs []int) (OfflinePusher, error) {
+	cache = platformTokens{ThirdCache: cache}
+	switch provider {
+	case firebase:
+		return fcm.NewClient(pushConf, cache, fcmConfigPath, platformIDs)
+	case apple:
+		return apns.NewClient(pushConf, cache, fcmConfigPath, platformIDs)
+	case hook:
+		return webhook.NewClient(pushConf, cache, platformIDs)
+	case hms:
+		return huawei.NewClient(pushConf, cache, platformIDs)
+	case mi:
+		return xiaomi.NewClient(pushConf, cache, platformIDs)
+	case heyTap:
+		return oppo.NewClient(pushConf, cache, platformIDs)
+	case vivoPush:
+		return vivo.NewClient(pushConf, cache, platformIDs)
+	default:
+		return nil, errs.New("push provider does not support per platform routing", "provider", provider).Wrap()
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oppo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	host    = "https://api.push.oppomobile.com"
+	authURL = "/server/v1/auth"
+	pushURL = "/server/v1/message/notification/unicast_batch"
+
+	// SinglePushCountLimit is the number of messages of a batch.
+	SinglePushCountLimit = 1000
+
+	// tokenExpire is how long an auth token is used, OPPO keeps them valid for 24 hours.
+	tokenExpire = time.Hour * 23
+
+	// Result codes of OPPO Push.
+	invalidAuthTokenCode = 11
+	invalidRegIDCode     = 10000
+
+	// targetTypeRegID sends to a registration id.
+	targetTypeRegID = 2
+	// clickActionLaunch opens the app when the notification is tapped.
+	clickActionLaunch = 0
+)
+
+// Terminal is the platforms whose device tokens are pushed to by default.
+var Terminal = []int{constant.AndroidPlatformID, constant.AndroidPadPlatformID}
+
+// OPPO pushes through OPPO Push.
+type OPPO struct {
+	httpClient   *http.Client
+	cache        cache.ThirdCache
+	appKey       string
+	masterSecret string
+	host         string
+	platformIDs  []int
+	now          func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	expireAt time.Time
+}
+
+// NewClient returns the pusher to the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, platformIDs []int) (*OPPO, error) {
+	if pushConf.OPPO.AppKey == "" || pushConf.OPPO.MasterSecret == "" {
+		return nil, errs.New("no oppo push config").Wrap()
+	}
+	return newClient(pushutil.NewHTTPClient(time.Second*10), cache, pushConf.OPPO.AppKey, pushConf.OPPO.MasterSecret, host, platformIDs), nil
+}
+
+func newClient(httpClient *http.Client, cache cache.ThirdCache, appKey, masterSecret, host string, platformIDs []int) *OPPO {
+	if len(platformIDs) == 0 {
+		platformIDs = Terminal
+	}
+	return &OPPO{
+		httpClient:   httpClient,
+		cache:        cache,
+		appKey:       appKey,
+		masterSecret: masterSecret,
+		host:         host,
+		platformIDs:  platformIDs,
+		now:          time.Now,
+	}
+}
+
+type resp[T any] struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
+
+type authData struct {
+	AuthToken string `json:"auth_token"`
+}
+
+type unicastMsg struct {
+	TargetType   int          `json:"target_type"`
+	TargetValue  string       `json:"target_value"`
+	Notification notification `json:"notification"`
+}
+
+type notification struct {
+	Title            string `json:"title"`
+	Content          string `json:"content"`
+	ClickActionType  int    `json:"click_action_type"`
+	ActionParameters string `json:"action_parameters,omitempty"`
+}
+
+type unicastResult struct {
+	MessageID      string `json:"messageId"`
+	RegistrationID string `json:"registrationId"`
+	ErrorCode      int    `json:"errorCode"`
+	ErrorMessage   string `json:"errorMessage"`
+}
+
+func (o *OPPO) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	tokens := pushutil.GetTokens(ctx, o.cache, userIDs, o.platformIDs)
+	var failures pushutil.Failures
+	for i := 0; i < len(tokens); i += SinglePushCountLimit {
+		batch := tokens[i:min(i+SinglePushCountLimit, len(tokens))]
+		msgs := make([]*unicastMsg, 0, len(batch))
+		for _, token := range batch {
+			msgs = append(msgs, &unicastMsg{
+				TargetType:  targetTypeRegID,
+				TargetValue: token.Token,
+				Notification: notification{
+					Title:            title,
+					Content:          content,
+					ClickActionType:  clickActionLaunch,
+					ActionParameters: opts.Ex,
+				},
+			})
+		}
+		data, err := json.Marshal(msgs)
+		if err != nil {
+			failures.Add(len(batch), batch, errs.Wrap(err))
+			continue
+		}
+		o.send(ctx, batch, url.Values{"messages": {string(data)}}, &failures)
+	}
+	return failures.Err()
+}
+
+func (o *OPPO) send(ctx context.Context, batch []pushutil.Token, form url.Values, failures *pushutil.Failures) {
+	var res resp[[]unicastResult]
+	for retry := true; ; retry = false {
+		authToken, err := o.authToken(ctx)
+		if err != nil {
+			failures.Add(len(batch), batch, err)
+			return
+		}
+		res = resp[[]unicastResult]{}
+		if err := pushutil.PostForm(ctx, o.httpClient, o.host+pushURL, map[string]string{"auth_token": authToken}, form, &res); err != nil {
+			failures.Add(len(batch), batch, err)
+			return
+		}
+		if res.Code != invalidAuthTokenCode {
+			break
+		}
+		o.resetAuthToken(authToken)
+		if !retry {
+			failures.Add(len(batch), batch, errs.New("oppo auth token rejected").Wrap())
+			return
+		}
+	}
+	if res.Code != 0 {
+		failures.Add(len(batch), batch, errs.New("oppo push failed", "code", res.Code, "message", res.Message).Wrap())
+		return
+	}
+	tokens := make(map[string]pushutil.Token, len(batch))
+	for _, token := range batch {
+		tokens[token.Token] = token
+	}
+	var invalid []pushutil.Token
+	for _, result := range res.Data {
+		if result.ErrorCode == 0 {
+			continue
+		}
+		err := errs.New("oppo push failed", "code", result.ErrorCode, "message", result.ErrorMessage).Wrap()
+		token, ok := tokens[result.RegistrationID]
+		if !ok {
+			failures.Add(1, batch, err)
+			continue
+		}
+		failures.Add(1, []pushutil.Token{token}, err)
+		if result.ErrorCode == invalidRegIDCode {
+			invalid = append(invalid, token)
+		}
+	}
+	pushutil.DeleteTokens(ctx, o.cache, invalid)
+}
+
+// authToken returns the auth token of the app, it is renewed before it expires.
+func (o *OPPO) authToken(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	if o.token != "" && now.Before(o.expireAt) {
+		return o.token, nil
+	}
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	sign := sha256.Sum256([]byte(o.appKey + timestamp + o.masterSecret))
+	form := url.Values{
+		"app_key":   {o.appKey},
+		"timestamp": {timestamp},
+		"sign":      {hex.EncodeToString(sign[:])},
+	}
+	var res resp[authData]
+	if err := pushutil.PostForm(ctx, o.httpClient, o.host+authURL, nil, form, &res); err != nil {
+		return "", err
+	}
+	if res.Code != 0 || res.Data.AuthToken == "" {
+		return "", errs.New("oppo auth failed", "code", res.Code, "message", res.Message).Wrap()
+	}
+	o.token = res.Data.AuthToken
+	o.expireAt = now.Add(tokenExpire)
+	return o.token, nil
+}
+
+// resetAuthToken drops token rejected by OPPO Push, unless another request already replaced it.
+func (o *OPPO) resetAuthToken(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token == token {
+		o.token = ""
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenProviders are the providers pushing to the device tokens of the users, the others address users by alias.
+var tokenProviders = []string{firebase, apple, hook, hms, mi, heyTap, vivoPush}
+
+type platformIDsKey struct{}
+
+// WithPlatformIDs limits the pushes with ctx to the devices of platformIDs.
+// Only the pushers to device tokens can honor it, see LimitsPlatforms.
+func WithPlatformIDs(ctx context.Context, platformIDs []int) context.Context {
+	return context.WithValue(ctx, platformIDsKey{}, platformIDs)
+}
+
+// PlatformIDs returns the platforms the pushes with ctx are limited to, nil when they are not limited.
+func PlatformIDs(ctx context.Context) []int {
+	platformIDs, _ := ctx.Value(platformIDsKey{}).([]int)
+	return platformIDs
+}
+
+// LimitsPlatforms reports whether the pusher created from pushConf honors WithPlatformIDs.
+func LimitsPlatforms(pushConf *config.Push) bool {
+	if pushConf.Enable == deviceRouting || len(pushConf.Platforms) > 0 {
+		return true
+	}
+	return datautil.Contain(pushConf.Enable, tokenProviders...)
+}
+
+// platformTokens is the cache of a pusher to device tokens, it hides the tokens of the platforms
+// the push is not limited to.
+type platformTokens struct {
+	cache.ThirdCache
+}
+
+func (p platformTokens) GetDevices(ctx context.Context, userID string) ([]*model.Device, error) {
+	devices, err := p.ThirdCache.GetDevices(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if platformIDs, ok := ctx.Value(platformIDsKey{}).([]int); ok {
+		devices = datautil.Filter(devices, func(device *model.Device) (*model.Device, bool) {
+			return device, datautil.Contain(device.PlatformID, platformIDs...)
+		})
+	}
+	return devices, nil
+}
+
+func (p platformTokens) GetFcmToken(ctx context.Context, account string, platformID int) (string, error) {
+	if platformIDs, ok := ctx.Value(platformIDsKey{}).([]int); ok && !datautil.Contain(platformID, platformIDs...) {
+		return "", errs.Wrap(redis.Nil)
+	}
+	return p.ThirdCache.GetFcmToken(ctx, account, platformID)
+}
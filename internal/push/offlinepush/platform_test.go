@@ -0,0 +1,55 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlatformTokens(t *testing.T) {
+	c := &memoryDeviceCache{devices: map[string][]*model.Device{
+		"u1": {
+			{UserID: "u1", PlatformID: constant.AndroidPlatformID, Token: "android"},
+			{UserID: "u1", PlatformID: constant.AndroidPadPlatformID, Token: "apad"},
+		},
+	}}
+	pusher := &tokenPusher{cache: platformTokens{ThirdCache: &deviceTokens{ThirdCache: c, provider: "fcm", isDefault: true}}}
+
+	ctx := WithPlatformIDs(context.Background(), []int{constant.AndroidPadPlatformID})
+	assert.Equal(t, []int{constant.AndroidPadPlatformID}, PlatformIDs(ctx))
+	assert.NoError(t, pusher.Push(ctx, []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Equal(t, []pushutil.Token{{UserID: "u1", PlatformID: constant.AndroidPadPlatformID, Token: "apad"}}, pusher.tokens)
+
+	pusher.tokens = nil
+	assert.Nil(t, PlatformIDs(context.Background()))
+	assert.NoError(t, pusher.Push(context.Background(), []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Len(t, pusher.tokens, 2)
+}
+
+func TestLimitsPlatforms(t *testing.T) {
+	assert.True(t, LimitsPlatforms(&config.Push{Enable: apple}))
+	assert.True(t, LimitsPlatforms(&config.Push{Enable: deviceRouting}))
+	assert.True(t, LimitsPlatforms(&config.Push{Enable: jPush, Platforms: map[string]string{"ios": apple}}))
+	assert.False(t, LimitsPlatforms(&config.Push{Enable: geTUI}))
+	assert.False(t, LimitsPlatforms(&config.Push{Enable: jPush}))
+}
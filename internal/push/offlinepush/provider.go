@@ -0,0 +1,106 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/tools/errs"
+	"golang.org/x/sync/errgroup"
+)
+
+// ProviderPusher is implemented by the pushers routing to several providers.
+type ProviderPusher interface {
+	OfflinePusher
+	// PushProvider pushes through provider only, it retries the push of a provider which failed.
+	PushProvider(ctx context.Context, provider string, userIDs []string, title, content string, opts *options.Opts) error
+}
+
+// ProviderError is the error of the push through one provider of a ProviderPusher.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Provider + ": " + e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ProviderErrors splits the error of a push by provider, an error not attributed to a provider is keyed by "".
+func ProviderErrors(err error) map[string]error {
+	errMap := make(map[string]error)
+	var split func(err error)
+	split = func(err error) {
+		if providerErr, ok := err.(*ProviderError); ok {
+			errMap[providerErr.Provider] = errors.Join(errMap[providerErr.Provider], providerErr.Err)
+			return
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				split(err)
+			}
+			return
+		}
+		if err != nil {
+			errMap[""] = errors.Join(errMap[""], err)
+		}
+	}
+	split(err)
+	return errMap
+}
+
+// pushProviders pushes through every provider of providerUserIDs concurrently, the errors are ProviderErrors.
+// An error which does not tell the users it failed for is attributed to all the users of its provider.
+func pushProviders(ctx context.Context, pushers map[string]OfflinePusher, providerUserIDs map[string][]string,
+	title, content string, opts *options.Opts) error {
+	providers := make([]string, 0, len(providerUserIDs))
+	for provider := range providerUserIDs {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	errList := make([]error, len(providers))
+	var g errgroup.Group
+	for i, provider := range providers {
+		i, provider := i, provider
+		g.Go(func() error {
+			if err := pushers[provider].Push(ctx, providerUserIDs[provider], title, content, opts); err != nil {
+				var usersErr *pushutil.UsersError
+				if !errors.As(err, &usersErr) {
+					err = &pushutil.UsersError{UserIDs: providerUserIDs[provider], Err: err}
+				}
+				errList[i] = &ProviderError{Provider: provider, Err: err}
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return errors.Join(errList...)
+}
+
+func providerPusher(pushers map[string]OfflinePusher, provider string) (OfflinePusher, error) {
+	pusher, ok := pushers[provider]
+	if !ok {
+		return nil, errs.New("push provider not configured", "provider", provider).Wrap()
+	}
+	return pusher, nil
+}
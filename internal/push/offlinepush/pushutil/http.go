@@ -0,0 +1,95 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+// maxErrBody is the length of the body of a failed response kept in the error.
+const maxErrBody = 256
+
+// StatusError is a response of a provider without a 2xx status.
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return "http status " + strconv.Itoa(e.Status) + " " + e.Body
+}
+
+// NewHTTPClient returns the client of the requests to a provider.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// PostJSON posts input as json and decodes the response into output when it is not nil,
+// a response without a 2xx status is returned as a *StatusError.
+func PostJSON(ctx context.Context, client *http.Client, url string, header map[string]string, input any, output any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return errs.WrapMsg(err, "json marshal failed")
+	}
+	return post(ctx, client, url, "application/json; charset=utf-8", header, body, output)
+}
+
+// PostForm posts form url encoded and decodes the json response into output when it is not nil.
+func PostForm(ctx context.Context, client *http.Client, url string, header map[string]string, form url.Values, output any) error {
+	return post(ctx, client, url, "application/x-www-form-urlencoded", header, []byte(form.Encode()), output)
+}
+
+func post(ctx context.Context, client *http.Client, url string, contentType string, header map[string]string, body []byte, output any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errs.WrapMsg(err, "new request failed", "url", url)
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return errs.WrapMsg(err, "http request failed", "url", url)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errs.WrapMsg(err, "read response failed", "url", url)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		if len(data) > maxErrBody {
+			data = data[:maxErrBody]
+		}
+		return &StatusError{Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if output == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, output); err != nil {
+		return errs.WrapMsg(err, "json unmarshal response failed", "url", url, "body", string(data))
+	}
+	return nil
+}
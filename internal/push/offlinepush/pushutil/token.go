@@ -0,0 +1,161 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pushutil holds what the offline pushers sending to the device tokens of the users share.
+package pushutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// Token is the device token set by a client of a user.
+type Token struct {
+	UserID     string
+	PlatformID int
+	Token      string
+}
+
+// GetTokens returns the device tokens of userIDs set by the clients of platformIDs, the devices of each user are read once.
+func GetTokens(ctx context.Context, cache cache.ThirdCache, userIDs []string, platformIDs []int) []Token {
+	var tokens []Token
+	for _, userID := range userIDs {
+		devices, err := cache.GetDevices(ctx, userID)
+		if err != nil {
+			log.ZWarn(ctx, "get devices failed", err, "userID", userID)
+			continue
+		}
+		for _, platformID := range platformIDs {
+			for _, device := range devices {
+				if device.PlatformID == platformID && device.Token != "" {
+					tokens = append(tokens, Token{UserID: userID, PlatformID: platformID, Token: device.Token})
+					break
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+// DeleteTokens deletes the device tokens rejected by a provider as invalid,
+// unless the client set another token since they were read.
+func DeleteTokens(ctx context.Context, cache cache.ThirdCache, tokens []Token) {
+	for _, token := range tokens {
+		if current, err := cache.GetFcmToken(ctx, token.UserID, token.PlatformID); err != nil || current != token.Token {
+			continue
+		}
+		log.ZInfo(ctx, "delete invalid device token", "userID", token.UserID, "platformID", token.PlatformID)
+		if err := cache.DelFcmToken(ctx, token.UserID, token.PlatformID); err != nil {
+			log.ZWarn(ctx, "delete device token failed", err, "userID", token.UserID, "platformID", token.PlatformID)
+		}
+	}
+}
+
+// Failures counts the notifications which could not be sent and the users they were sent to, it is safe for concurrent use.
+type Failures struct {
+	mu      sync.Mutex
+	count   int
+	userIDs []string
+	users   map[string]struct{}
+	msg     strings.Builder
+}
+
+// Add records n notifications to the devices of tokens failed with err.
+func (f *Failures) Add(n int, tokens []Token, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count += n
+	if f.users == nil {
+		f.users = make(map[string]struct{})
+	}
+	for _, token := range tokens {
+		if _, ok := f.users[token.UserID]; !ok {
+			f.users[token.UserID] = struct{}{}
+			f.userIDs = append(f.userIDs, token.UserID)
+		}
+	}
+	f.msg.WriteString(err.Error())
+	f.msg.WriteByte('.')
+}
+
+// Err returns the error of the push, a UsersError of the users a notification failed for, nil when every notification was sent.
+func (f *Failures) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		return nil
+	}
+	return &UsersError{
+		UserIDs: f.userIDs,
+		Err:     errs.New(fmt.Sprintf("%d message send failed;message err:%s", f.count, f.msg.String())).Wrap(),
+	}
+}
+
+// UsersError is the error of a push which failed for UserIDs only, the push to the other users went through.
+type UsersError struct {
+	UserIDs []string
+	Err     error
+}
+
+func (e *UsersError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsersError) Unwrap() error {
+	return e.Err
+}
+
+// FailedUserIDs returns the users of userIDs the push failed for with err. They are all of userIDs
+// unless every error joined in err is a UsersError.
+func FailedUserIDs(err error, userIDs []string) []string {
+	failed := make(map[string]struct{})
+	var all bool
+	var split func(err error)
+	split = func(err error) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				split(err)
+			}
+			return
+		}
+		var usersErr *UsersError
+		if !errors.As(err, &usersErr) {
+			all = true
+			return
+		}
+		for _, userID := range usersErr.UserIDs {
+			failed[userID] = struct{}{}
+		}
+	}
+	split(err)
+	if all {
+		return userIDs
+	}
+	failedUserIDs := datautil.Filter(userIDs, func(userID string) (string, bool) {
+		_, ok := failed[userID]
+		return userID, ok
+	})
+	if len(failedUserIDs) == 0 {
+		return userIDs
+	}
+	return failedUserIDs
+}
@@ -0,0 +1,91 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+)
+
+// platformRouter pushes through one provider per group of device platforms.
+type platformRouter struct {
+	pushers map[string]OfflinePusher
+}
+
+func newPlatformRouter(pushConf *config.Push, cache cache.ThirdCache, fcmConfigPath string) (*platformRouter, error) {
+	platforms, err := parsePlatforms(pushConf.Platforms)
+	if err != nil {
+		return nil, err
+	}
+	r := &platformRouter{pushers: make(map[string]OfflinePusher, len(platforms))}
+	for provider, platformIDs := range platforms {
+		pusher, err := newTokenPusher(provider, pushConf, cache, fcmConfigPath, platformIDs)
+		if err != nil {
+			return nil, err
+		}
+		r.pushers[provider] = pusher
+	}
+	return r, nil
+}
+
+// parsePlatforms groups the platform IDs of the platform name to provider mapping by provider.
+// Platform names are matched case-insensitively because the config loader lowercases map keys.
+func parsePlatforms(conf map[string]string) (map[string][]int, error) {
+	platforms := make(map[string][]int)
+	for name, provider := range conf {
+		platformID := 0
+		for _, id := range thirdext.DevicePlatformIDs {
+			if strings.EqualFold(constant.PlatformIDToName(id), name) {
+				platformID = id
+				break
+			}
+		}
+		if platformID == 0 {
+			return nil, errs.New("push platform has no offline push token", "platform", name).Wrap()
+		}
+		if provider == "" {
+			continue
+		}
+		platforms[provider] = append(platforms[provider], platformID)
+	}
+	for _, ids := range platforms {
+		sort.Ints(ids)
+	}
+	return platforms, nil
+}
+
+func (r *platformRouter) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	providerUserIDs := make(map[string][]string, len(r.pushers))
+	for provider := range r.pushers {
+		providerUserIDs[provider] = userIDs
+	}
+	return pushProviders(ctx, r.pushers, providerUserIDs, title, content, opts)
+}
+
+func (r *platformRouter) PushProvider(ctx context.Context, provider string, userIDs []string, title, content string, opts *options.Opts) error {
+	pusher, err := providerPusher(r.pushers, provider)
+	if err != nil {
+		return err
+	}
+	return pusher.Push(ctx, userIDs, title, content, opts)
+}
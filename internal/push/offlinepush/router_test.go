@@ -0,0 +1,39 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offlinepush
+
+import (
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	platforms, err := parsePlatforms(map[string]string{
+		"ios":     "apns",
+		"ipad":    "apns",
+		"android": "huawei",
+		"apad":    "",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]int{
+		"apns":   {constant.IOSPlatformID, constant.IPadPlatformID},
+		"huawei": {constant.AndroidPlatformID},
+	}, platforms)
+
+	_, err = parsePlatforms(map[string]string{"web": "webhook"})
+	assert.Error(t, err)
+}
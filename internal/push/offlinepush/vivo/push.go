@@ -0,0 +1,225 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vivo
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/idutil"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	host    = "https://api-push.vivo.com.cn"
+	authURL = "/message/auth"
+	pushURL = "/message/send"
+
+	// pushConcurrency is the number of notifications sent at once, vivo sends to a single regId per request.
+	pushConcurrency = 16
+
+	// tokenExpire is how long an auth token is used, vivo keeps them valid for a day.
+	tokenExpire = time.Hour * 23
+
+	// Result codes of vivo Push.
+	invalidAuthTokenCode = 10000
+	invalidRegIDCode     = 10302
+
+	// notifyTypeAll plays the sound and vibrates.
+	notifyTypeAll = 4
+	// skipTypeLaunch opens the app when the notification is tapped.
+	skipTypeLaunch = 1
+	// classificationSystem marks the notifications as system messages, which IM messages are to vivo.
+	classificationSystem = 1
+)
+
+// Terminal is the platforms whose device tokens are pushed to by default.
+var Terminal = []int{constant.AndroidPlatformID, constant.AndroidPadPlatformID}
+
+// Vivo pushes through vivo Push.
+type Vivo struct {
+	httpClient  *http.Client
+	cache       cache.ThirdCache
+	appID       string
+	appKey      string
+	appSecret   string
+	host        string
+	platformIDs []int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	expireAt time.Time
+}
+
+// NewClient returns the pusher to the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, platformIDs []int) (*Vivo, error) {
+	conf := pushConf.Vivo
+	if conf.AppID == "" || conf.AppKey == "" || conf.AppSecret == "" {
+		return nil, errs.New("no vivo push config").Wrap()
+	}
+	return newClient(pushutil.NewHTTPClient(time.Second*10), cache, conf.AppID, conf.AppKey, conf.AppSecret, host, platformIDs), nil
+}
+
+func newClient(httpClient *http.Client, cache cache.ThirdCache, appID, appKey, appSecret, host string, platformIDs []int) *Vivo {
+	if len(platformIDs) == 0 {
+		platformIDs = Terminal
+	}
+	return &Vivo{
+		httpClient:  httpClient,
+		cache:       cache,
+		appID:       appID,
+		appKey:      appKey,
+		appSecret:   appSecret,
+		host:        host,
+		platformIDs: platformIDs,
+		now:         time.Now,
+	}
+}
+
+type authReq struct {
+	AppID     string `json:"appId"`
+	AppKey    string `json:"appKey"`
+	Timestamp int64  `json:"timestamp"`
+	Sign      string `json:"sign"`
+}
+
+type authResp struct {
+	Result    int    `json:"result"`
+	Desc      string `json:"desc"`
+	AuthToken string `json:"authToken"`
+}
+
+type sendReq struct {
+	RegID           string            `json:"regId"`
+	NotifyType      int               `json:"notifyType"`
+	Title           string            `json:"title"`
+	Content         string            `json:"content"`
+	SkipType        int               `json:"skipType"`
+	Classification  int               `json:"classification"`
+	RequestID       string            `json:"requestId"`
+	ClientCustomMap map[string]string `json:"clientCustomMap,omitempty"`
+}
+
+type sendResp struct {
+	Result int    `json:"result"`
+	Desc   string `json:"desc"`
+	TaskID string `json:"taskId"`
+}
+
+func (v *Vivo) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	var (
+		failures pushutil.Failures
+		g        errgroup.Group
+		mu       sync.Mutex
+		invalid  []pushutil.Token
+	)
+	g.SetLimit(pushConcurrency)
+	for _, token := range pushutil.GetTokens(ctx, v.cache, userIDs, v.platformIDs) {
+		token := token
+		req := &sendReq{
+			RegID:          token.Token,
+			NotifyType:     notifyTypeAll,
+			Title:          title,
+			Content:        content,
+			SkipType:       skipTypeLaunch,
+			Classification: classificationSystem,
+			RequestID:      idutil.GetMsgIDByMD5(token.UserID),
+		}
+		if opts.Ex != "" {
+			req.ClientCustomMap = map[string]string{"ex": opts.Ex}
+		}
+		g.Go(func() error {
+			result, err := v.send(ctx, req)
+			if err != nil {
+				failures.Add(1, []pushutil.Token{token}, err)
+				return nil
+			}
+			if result.Result != 0 {
+				failures.Add(1, []pushutil.Token{token}, errs.New("vivo push failed", "result", result.Result, "desc", result.Desc).Wrap())
+				if result.Result == invalidRegIDCode {
+					mu.Lock()
+					invalid = append(invalid, token)
+					mu.Unlock()
+				}
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	pushutil.DeleteTokens(ctx, v.cache, invalid)
+	return failures.Err()
+}
+
+// send posts req, it signs in again once when the auth token is rejected.
+func (v *Vivo) send(ctx context.Context, req *sendReq) (*sendResp, error) {
+	for retry := true; ; retry = false {
+		authToken, err := v.authToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var resp sendResp
+		if err := pushutil.PostJSON(ctx, v.httpClient, v.host+pushURL, map[string]string{"authToken": authToken}, req, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Result != invalidAuthTokenCode || !retry {
+			return &resp, nil
+		}
+		v.resetAuthToken(authToken)
+	}
+}
+
+// authToken returns the auth token of the app, it is renewed before it expires.
+func (v *Vivo) authToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.now()
+	if v.token != "" && now.Before(v.expireAt) {
+		return v.token, nil
+	}
+	req := &authReq{AppID: v.appID, AppKey: v.appKey, Timestamp: now.UnixMilli()}
+	sign := md5.Sum([]byte(req.AppID + req.AppKey + strconv.FormatInt(req.Timestamp, 10) + v.appSecret))
+	req.Sign = hex.EncodeToString(sign[:])
+	var resp authResp
+	if err := pushutil.PostJSON(ctx, v.httpClient, v.host+authURL, nil, req, &resp); err != nil {
+		return "", err
+	}
+	if resp.Result != 0 || resp.AuthToken == "" {
+		return "", errs.New("vivo auth failed", "result", resp.Result, "desc", resp.Desc).Wrap()
+	}
+	v.token = resp.AuthToken
+	v.expireAt = now.Add(tokenExpire)
+	return v.token, nil
+}
+
+// resetAuthToken drops token rejected by vivo Push, unless another request already replaced it.
+func (v *Vivo) resetAuthToken(token string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token == token {
+		v.token = ""
+	}
+}
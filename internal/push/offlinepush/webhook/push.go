@@ -0,0 +1,127 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+)
+
+const defaultTimeout = time.Second * 5
+
+// Terminal is the platforms whose device tokens are posted by default.
+var Terminal = []int{constant.IOSPlatformID, constant.AndroidPlatformID, constant.IPadPlatformID, constant.AndroidPadPlatformID}
+
+// Webhook posts the notifications to an endpoint which delivers them.
+type Webhook struct {
+	httpClient  *http.Client
+	cache       cache.ThirdCache
+	url         string
+	platformIDs []int
+}
+
+// Req is the notification posted to the endpoint, Devices are the device tokens the users set.
+type Req struct {
+	UserIDs       []string  `json:"userIDs"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	Ex            string    `json:"ex"`
+	IOSPushSound  string    `json:"iosPushSound"`
+	IOSBadgeCount bool      `json:"iosBadgeCount"`
+	ClientMsgID   string    `json:"clientMsgID"`
+	Devices       []*Device `json:"devices"`
+}
+
+type Device struct {
+	UserID     string `json:"userID"`
+	PlatformID int    `json:"platformID"`
+	Platform   string `json:"platform"`
+	Token      string `json:"token"`
+}
+
+// Resp is the answer of the endpoint, the InvalidDevices tokens are deleted.
+type Resp struct {
+	ErrCode        int       `json:"errCode"`
+	ErrMsg         string    `json:"errMsg"`
+	InvalidDevices []*Device `json:"invalidDevices"`
+}
+
+// NewClient returns the pusher posting to pushConf.Webhook.URL the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, platformIDs []int) (*Webhook, error) {
+	if pushConf.Webhook.URL == "" {
+		return nil, errs.New("no push webhook url").Wrap()
+	}
+	timeout := defaultTimeout
+	if pushConf.Webhook.Timeout > 0 {
+		timeout = time.Duration(pushConf.Webhook.Timeout) * time.Second
+	}
+	if len(platformIDs) == 0 {
+		platformIDs = Terminal
+	}
+	return &Webhook{
+		httpClient:  pushutil.NewHTTPClient(timeout),
+		cache:       cache,
+		url:         pushConf.Webhook.URL,
+		platformIDs: platformIDs,
+	}, nil
+}
+
+func (w *Webhook) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	req := &Req{
+		UserIDs:       userIDs,
+		Title:         title,
+		Content:       content,
+		Ex:            opts.Ex,
+		IOSPushSound:  opts.IOSPushSound,
+		IOSBadgeCount: opts.IOSBadgeCount,
+	}
+	if opts.Signal != nil {
+		req.ClientMsgID = opts.Signal.ClientMsgID
+	}
+	for _, token := range pushutil.GetTokens(ctx, w.cache, userIDs, w.platformIDs) {
+		req.Devices = append(req.Devices, &Device{
+			UserID:     token.UserID,
+			PlatformID: token.PlatformID,
+			Platform:   constant.PlatformIDToName(token.PlatformID),
+			Token:      token.Token,
+		})
+	}
+	var resp Resp
+	if err := pushutil.PostJSON(ctx, w.httpClient, w.url, nil, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.InvalidDevices) > 0 {
+		invalid := make([]pushutil.Token, 0, len(resp.InvalidDevices))
+		for _, device := range resp.InvalidDevices {
+			invalid = append(invalid, pushutil.Token{UserID: device.UserID, PlatformID: device.PlatformID, Token: device.Token})
+		}
+		pushutil.DeleteTokens(ctx, w.cache, invalid)
+	}
+	if resp.ErrCode != 0 {
+		return errs.New("push webhook failed", "errCode", resp.ErrCode, "errMsg", resp.ErrMsg).Wrap()
+	}
+	return nil
+}
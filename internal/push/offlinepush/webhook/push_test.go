@@ -0,0 +1,120 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryThirdCache struct {
+	cache.ThirdCache
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (m *memoryThirdCache) key(account string, platformID int) string {
+	return account + ":" + strconv.Itoa(platformID)
+}
+
+func (m *memoryThirdCache) GetFcmToken(_ context.Context, account string, platformID int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[m.key(account, platformID)]
+	if !ok {
+		return "", errs.ErrRecordNotFound.Wrap()
+	}
+	return token, nil
+}
+
+func (m *memoryThirdCache) GetDevices(_ context.Context, userID string) ([]*model.Device, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var devices []*model.Device
+	for key, token := range m.tokens {
+		account, platform, _ := strings.Cut(key, ":")
+		if account != userID {
+			continue
+		}
+		platformID, _ := strconv.Atoi(platform)
+		devices = append(devices, &model.Device{UserID: account, PlatformID: platformID, Token: token})
+	}
+	return devices, nil
+}
+
+func (m *memoryThirdCache) DelFcmToken(_ context.Context, account string, platformID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, m.key(account, platformID))
+	return nil
+}
+
+func TestPush(t *testing.T) {
+	var got Req
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_ = json.NewEncoder(w).Encode(&Resp{InvalidDevices: []*Device{{UserID: "u2", PlatformID: constant.AndroidPlatformID, Token: "t2"}}})
+	}))
+	defer srv.Close()
+
+	c := &memoryThirdCache{tokens: map[string]string{
+		"u1:" + strconv.Itoa(constant.IOSPlatformID):     "t1",
+		"u2:" + strconv.Itoa(constant.AndroidPlatformID): "t2",
+		"u2:" + strconv.Itoa(constant.IPadPlatformID):    "t3",
+	}}
+	var conf config.Push
+	conf.Webhook.URL = srv.URL
+	w, err := NewClient(&conf, c, []int{constant.IOSPlatformID, constant.AndroidPlatformID})
+	assert.NoError(t, err)
+
+	err = w.Push(context.Background(), []string{"u1", "u2"}, "title", "content", &options.Opts{Ex: "ex"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, got.UserIDs)
+	assert.Equal(t, "ex", got.Ex)
+	assert.Len(t, got.Devices, 2)
+	assert.Equal(t, "t1", got.Devices[0].Token)
+	assert.Equal(t, "t2", got.Devices[1].Token)
+	assert.Equal(t, map[string]string{
+		"u1:" + strconv.Itoa(constant.IOSPlatformID):  "t1",
+		"u2:" + strconv.Itoa(constant.IPadPlatformID): "t3",
+	}, c.tokens)
+}
+
+func TestPushFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&Resp{ErrCode: 1, ErrMsg: "down"})
+	}))
+	defer srv.Close()
+
+	var conf config.Push
+	conf.Webhook.URL = srv.URL
+	w, err := NewClient(&conf, &memoryThirdCache{tokens: map[string]string{}}, nil)
+	assert.NoError(t, err)
+	assert.Error(t, w.Push(context.Background(), []string{"u1"}, "title", "content", &options.Opts{}))
+}
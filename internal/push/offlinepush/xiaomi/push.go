@@ -0,0 +1,129 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xiaomi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+const (
+	pushURL = "https://api.xmpush.xiaomi.com/v3/message/regid"
+
+	// SinglePushCountLimit is the number of registration ids of a message.
+	SinglePushCountLimit = 1000
+
+	// notifyTypeAll plays the sound, vibrates and lights the led.
+	notifyTypeAll = "-1"
+	// notifyEffectLaunch opens the app when the notification is tapped.
+	notifyEffectLaunch = "1"
+)
+
+// Terminal is the platforms whose device tokens are pushed to by default.
+var Terminal = []int{constant.AndroidPlatformID, constant.AndroidPadPlatformID}
+
+// Xiaomi pushes through Xiaomi Push.
+type Xiaomi struct {
+	httpClient  *http.Client
+	cache       cache.ThirdCache
+	appSecret   string
+	packageName string
+	pushURL     string
+	platformIDs []int
+}
+
+// NewClient returns the pusher to the tokens of platformIDs, Terminal when it is empty.
+func NewClient(pushConf *config.Push, cache cache.ThirdCache, platformIDs []int) (*Xiaomi, error) {
+	if pushConf.Xiaomi.AppSecret == "" || pushConf.Xiaomi.PackageName == "" {
+		return nil, errs.New("no xiaomi push config").Wrap()
+	}
+	return newClient(pushutil.NewHTTPClient(time.Second*10), cache, pushConf.Xiaomi.AppSecret, pushConf.Xiaomi.PackageName, pushURL, platformIDs), nil
+}
+
+func newClient(httpClient *http.Client, cache cache.ThirdCache, appSecret, packageName, pushURL string, platformIDs []int) *Xiaomi {
+	if len(platformIDs) == 0 {
+		platformIDs = Terminal
+	}
+	return &Xiaomi{
+		httpClient:  httpClient,
+		cache:       cache,
+		appSecret:   appSecret,
+		packageName: packageName,
+		pushURL:     pushURL,
+		platformIDs: platformIDs,
+	}
+}
+
+type sendResp struct {
+	Result      string `json:"result"`
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Reason      string `json:"reason"`
+	Data        struct {
+		ID string `json:"id"`
+		// BadRegIDs are the invalid registration ids, separated by commas.
+		BadRegIDs string `json:"bad_regids"`
+	} `json:"data"`
+}
+
+func (x *Xiaomi) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	tokens := pushutil.GetTokens(ctx, x.cache, userIDs, x.platformIDs)
+	var failures pushutil.Failures
+	for i := 0; i < len(tokens); i += SinglePushCountLimit {
+		batch := tokens[i:min(i+SinglePushCountLimit, len(tokens))]
+		form := url.Values{
+			"registration_id":         {strings.Join(datautil.Slice(batch, func(token pushutil.Token) string { return token.Token }), ",")},
+			"restricted_package_name": {x.packageName},
+			"title":                   {title},
+			"description":             {content},
+			"pass_through":            {"0"},
+			"notify_type":             {notifyTypeAll},
+			"extra.notify_effect":     {notifyEffectLaunch},
+		}
+		if opts.Ex != "" {
+			form.Set("payload", opts.Ex)
+		}
+		var resp sendResp
+		if err := pushutil.PostForm(ctx, x.httpClient, x.pushURL, map[string]string{"Authorization": "key=" + x.appSecret}, form, &resp); err != nil {
+			failures.Add(len(batch), batch, err)
+			continue
+		}
+		if resp.Code != 0 {
+			failures.Add(len(batch), batch, errs.New("xiaomi push failed", "code", resp.Code, "desc", resp.Description, "reason", resp.Reason).Wrap())
+			continue
+		}
+		if resp.Data.BadRegIDs != "" {
+			bad := datautil.SliceSet(strings.Split(resp.Data.BadRegIDs, ","))
+			invalid := datautil.Filter(batch, func(token pushutil.Token) (pushutil.Token, bool) {
+				_, ok := bad[token.Token]
+				return token, ok
+			})
+			pushutil.DeleteTokens(ctx, x.cache, invalid)
+			failures.Add(len(invalid), invalid, errs.New("xiaomi bad regids", "msgID", resp.Data.ID).Wrap())
+		}
+	}
+	return failures.Err()
+}
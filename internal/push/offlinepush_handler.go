@@ -7,9 +7,12 @@ import (
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
 	"github.com/openimsdk/protocol/constant"
 	pbpush "github.com/openimsdk/protocol/push"
 	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/discovery"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/mq/kafka"
@@ -20,12 +23,15 @@ import (
 type OfflinePushConsumerHandler struct {
 	OfflinePushConsumerGroup *kafka.MConsumerGroup
 	offlinePusher            offlinepush.OfflinePusher
+	notificationSettings     *notificationSettings
 }
 
-func NewOfflinePushConsumerHandler(config *Config, offlinePusher offlinepush.OfflinePusher) (*OfflinePushConsumerHandler, error) {
+func NewOfflinePushConsumerHandler(config *Config, offlinePusher offlinepush.OfflinePusher, client discovery.SvcDiscoveryRegistry) (*OfflinePushConsumerHandler, error) {
 	var offlinePushConsumerHandler OfflinePushConsumerHandler
 	var err error
 	offlinePushConsumerHandler.offlinePusher = offlinePusher
+	userRpcClient := rpcclient.NewUserRpcClient(client, config.Share.RpcRegisterName.User, config.Share.IMAdminUserID)
+	offlinePushConsumerHandler.notificationSettings = newNotificationSettings(&userRpcClient, &config.RpcConfig)
 	offlinePushConsumerHandler.OfflinePushConsumerGroup, err = kafka.NewMConsumerGroup(config.KafkaConfig.Build(), config.KafkaConfig.ToOfflineGroupID,
 		[]string{config.KafkaConfig.ToOfflinePushTopic}, true)
 	if err != nil {
@@ -38,7 +44,7 @@ func (*OfflinePushConsumerHandler) Setup(sarama.ConsumerGroupSession) error   {
 func (*OfflinePushConsumerHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
 func (o *OfflinePushConsumerHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for msg := range claim.Messages() {
-		ctx := o.OfflinePushConsumerGroup.GetContextFromMsg(msg)
+		ctx, _ := tenant.FromMQKey(o.OfflinePushConsumerGroup.GetContextFromMsg(msg), string(msg.Key))
 		o.handleMsg2OfflinePush(ctx, msg.Value)
 		sess.MarkMessage(msg, "")
 	}
@@ -113,7 +119,8 @@ func (o *OfflinePushConsumerHandler) offlinePushMsg(ctx context.Context, msg *sd
 	if err != nil {
 		return err
 	}
-	err = o.offlinePusher.Push(ctx, offlinePushUserIDs, title, content, opts)
+	settings := o.notificationSettings.get(ctx, offlinePushUserIDs)
+	err = o.notificationSettings.push(ctx, o.offlinePusher, offlinePushUserIDs, settings, title, content, opts)
 	if err != nil {
 		prommetrics.MsgOfflinePushFailedCounter.Inc()
 		return err
@@ -70,6 +70,12 @@ func (d *DefaultAllNode) GetConnsAndOnlinePush(ctx context.Context, msg *sdkws.M
 	if err != nil {
 		return nil, err
 	}
+	// Draining nodes still hold connections until the drain closes them.
+	if draining, err := d.disCov.GetConns(ctx, d.config.Share.RpcRegisterName.DrainingMessageGateway()); err == nil {
+		conns = append(conns, draining...)
+	} else {
+		log.ZDebug(ctx, "get draining gateway conn failed", "err", err)
+	}
 
 	var (
 		mu         sync.Mutex
@@ -7,6 +7,7 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/redis"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/pushext"
 	pbpush "github.com/openimsdk/protocol/push"
 	"github.com/openimsdk/tools/db/redisutil"
 	"github.com/openimsdk/tools/discovery"
@@ -15,10 +16,12 @@ import (
 
 type pushServer struct {
 	database      controller.PushDatabase
+	retryDatabase controller.PushRetryDatabase
 	disCov        discovery.SvcDiscoveryRegistry
 	offlinePusher offlinepush.OfflinePusher
 	pushCh        *ConsumerHandler
 	offlinePushCh *OfflinePushConsumerHandler
+	config        *Config
 }
 
 type Config struct {
@@ -51,11 +54,14 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 	if err != nil {
 		return err
 	}
+	redis.AddTenantHook(rdb)
 	cacheModel := redis.NewThirdCache(rdb)
-	offlinePusher, err := offlinepush.NewOfflinePusher(&config.RpcConfig, cacheModel, config.FcmConfigPath)
+	pusher, err := offlinepush.NewOfflinePusher(&config.RpcConfig, cacheModel, config.FcmConfigPath)
 	if err != nil {
 		return err
 	}
+	retryDatabase := controller.NewPushRetryDatabase(redis.NewPushRetryCache(rdb), config.RpcConfig.Retry.MaxDeadLetters)
+	offlinePusher := newRetryPusher(pusher, &config.RpcConfig, retryDatabase)
 
 	database := controller.NewPushDatabase(cacheModel, &config.KafkaConfig)
 
@@ -64,18 +70,26 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 		return err
 	}
 
-	offlinePushConsumer, err := NewOfflinePushConsumerHandler(config, offlinePusher)
+	offlinePushConsumer, err := NewOfflinePushConsumerHandler(config, offlinePusher, client)
 	if err != nil {
 		return err
 	}
 
-	pbpush.RegisterPushMsgServiceServer(server, &pushServer{
+	srv := &pushServer{
 		database:      database,
+		retryDatabase: retryDatabase,
 		disCov:        client,
 		offlinePusher: offlinePusher,
 		pushCh:        consumer,
 		offlinePushCh: offlinePushConsumer,
-	})
+		config:        config,
+	}
+	pbpush.RegisterPushMsgServiceServer(server, srv)
+	pushext.RegisterPushExtServer(server, srv)
+
+	if config.RpcConfig.Retry.Enable {
+		go offlinePusher.Run(ctx, &config.Share)
+	}
 
 	go consumer.pushConsumerGroup.RegisterHandleAndConsumer(ctx, consumer)
 
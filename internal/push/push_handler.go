@@ -7,8 +7,11 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
 	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/webhook"
 	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpccache"
@@ -44,6 +47,7 @@ type ConsumerHandler struct {
 	conversationRpcClient  rpcclient.ConversationRpcClient
 	groupRpcClient         rpcclient.GroupRpcClient
 	webhookClient          *webhook.Client
+	notificationSettings   *notificationSettings
 	config                 *Config
 }
 
@@ -67,6 +71,7 @@ func NewConsumerHandler(config *Config, database controller.PushDatabase, offlin
 	consumerHandler.conversationRpcClient = rpcclient.NewConversationRpcClient(client, config.Share.RpcRegisterName.Conversation)
 	consumerHandler.conversationLocalCache = rpccache.NewConversationLocalCache(consumerHandler.conversationRpcClient, &config.LocalCacheConfig, rdb)
 	consumerHandler.webhookClient = webhook.NewWebhookClient(config.WebhooksConfig.URL)
+	consumerHandler.notificationSettings = newNotificationSettings(&userRpcClient, &config.RpcConfig)
 	consumerHandler.config = config
 	consumerHandler.pushDatabase = database
 	consumerHandler.onlineCache, err = rpccache.NewOnlineCache(userRpcClient, consumerHandler.groupLocalCache, rdb, config.RpcConfig.FullUserCache, nil)
@@ -124,7 +129,7 @@ func (c *ConsumerHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim s
 	log.ZInfo(ctx, "begin consume messages")
 
 	for msg := range claim.Messages() {
-		ctx := c.pushConsumerGroup.GetContextFromMsg(msg)
+		ctx, _ := tenant.FromMQKey(c.pushConsumerGroup.GetContextFromMsg(msg), string(msg.Key))
 		c.handleMs2PsChat(ctx, msg.Value)
 		sess.MarkMessage(msg, "")
 	}
@@ -165,7 +170,11 @@ func (c *ConsumerHandler) Push2User(ctx context.Context, userIDs []string, msg *
 			return nil
 		}
 	}
-	offlinePushUserID := []string{msg.RecvID}
+	settings := c.notificationSettings.get(ctx, []string{msg.RecvID})
+	offlinePushUserID := c.notificationSettings.filter(ctx, msg, []string{msg.RecvID}, settings)
+	if len(offlinePushUserID) == 0 {
+		return nil
+	}
 
 	//receiver offline push
 	if err = c.webhookBeforeOfflinePush(ctx, &c.config.WebhooksConfig.BeforeOfflinePush,
@@ -173,7 +182,7 @@ func (c *ConsumerHandler) Push2User(ctx context.Context, userIDs []string, msg *
 		return err
 	}
 	log.ZInfo(ctx, "webhookBeforeOfflinePush end")
-	err = c.offlinePushMsg(ctx, msg, offlinePushUserID)
+	err = c.offlinePushMsg(ctx, msg, offlinePushUserID, settings)
 	if err != nil {
 		log.ZWarn(ctx, "offlinePushMsg failed", err, "offlinePushUserID", offlinePushUserID, "msg", msg)
 		return nil
@@ -253,6 +262,9 @@ func (c *ConsumerHandler) Push2Group(ctx context.Context, groupID string, msg *s
 		return err
 	}
 	log.ZInfo(ctx, "filterGroupMessageOfflinePush end")
+	// the settings are cached, the offline push handler gets them again without asking the user rpc
+	settings := c.notificationSettings.get(ctx, needOfflinePushUserIDs)
+	needOfflinePushUserIDs = c.notificationSettings.filter(ctx, msg, needOfflinePushUserIDs, settings)
 
 	// Use offline push messaging
 	if len(needOfflinePushUserIDs) > 0 {
@@ -315,8 +327,8 @@ func (c *ConsumerHandler) groupMessagesHandler(ctx context.Context, groupID stri
 					return err
 				}
 				log.ZDebug(ctx, "GroupDismissedNotificationInfo****", "groupID", groupID, "num", len(*pushToUserIDs), "list", pushToUserIDs)
-				if len(c.config.Share.IMAdminUserID) > 0 {
-					ctx = mcontext.WithOpUserIDContext(ctx, c.config.Share.IMAdminUserID[0])
+				if imAdminUserID := authverify.AdminUserID(ctx, c.config.Share.IMAdminUserID); len(imAdminUserID) > 0 {
+					ctx = mcontext.WithOpUserIDContext(ctx, imAdminUserID[0])
 				}
 				defer func(groupID string) {
 					if err = c.groupRpcClient.DismissGroup(ctx, groupID); err != nil {
@@ -329,12 +341,13 @@ func (c *ConsumerHandler) groupMessagesHandler(ctx context.Context, groupID stri
 	return err
 }
 
-func (c *ConsumerHandler) offlinePushMsg(ctx context.Context, msg *sdkws.MsgData, offlinePushUserIDs []string) error {
+func (c *ConsumerHandler) offlinePushMsg(ctx context.Context, msg *sdkws.MsgData, offlinePushUserIDs []string,
+	settings map[string]*model.NotificationSetting) error {
 	title, content, opts, err := c.getOfflinePushInfos(msg)
 	if err != nil {
 		return err
 	}
-	err = c.offlinePusher.Push(ctx, offlinePushUserIDs, title, content, opts)
+	err = c.notificationSettings.push(ctx, c.offlinePusher, offlinePushUserIDs, settings, title, content, opts)
 	if err != nil {
 		prommetrics.MsgOfflinePushFailedCounter.Inc()
 		return err
@@ -0,0 +1,245 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/utils/idutil"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// retryBatch is the number of due retries taken at once.
+	retryBatch = 100
+	// retryPollInterval is how often the due retries are taken.
+	retryPollInterval = time.Second
+	// retryLeaseTime is how long a taken batch of retries may take to push, the ones not done by then are taken again.
+	retryLeaseTime = 5 * time.Minute
+
+	defaultRetryMaxAttempts     = 5
+	defaultRetryInitialInterval = 10 * time.Second
+	defaultRetryMaxInterval     = 10 * time.Minute
+)
+
+// retryPusher pushes through pusher and queues the failed pushes to be pushed again with exponential backoff,
+// a push is dead-lettered once it failed maxAttempts times. When the push went through several providers,
+// only the failed providers are pushed again, and only to the users they failed for when they tell them.
+type retryPusher struct {
+	pusher offlinepush.OfflinePusher
+	// provider labels the failures which are not attributed to a provider.
+	provider        string
+	enable          bool
+	maxAttempts     int
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	concurrency     int
+	database        controller.PushRetryDatabase
+	now             func() time.Time
+}
+
+func newRetryPusher(pusher offlinepush.OfflinePusher, pushConf *config.Push, database controller.PushRetryDatabase) *retryPusher {
+	r := &retryPusher{
+		pusher:          pusher,
+		provider:        pushConf.Enable,
+		enable:          pushConf.Retry.Enable,
+		maxAttempts:     pushConf.Retry.MaxAttempts,
+		initialInterval: time.Duration(pushConf.Retry.InitialInterval) * time.Second,
+		maxInterval:     time.Duration(pushConf.Retry.MaxInterval) * time.Second,
+		concurrency:     pushConf.MaxConcurrentWorkers,
+		database:        database,
+		now:             time.Now,
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = defaultRetryMaxAttempts
+	}
+	if r.initialInterval <= 0 {
+		r.initialInterval = defaultRetryInitialInterval
+	}
+	if r.maxInterval < r.initialInterval {
+		r.maxInterval = max(defaultRetryMaxInterval, r.initialInterval)
+	}
+	if r.concurrency <= 0 {
+		r.concurrency = 1
+	}
+	return r
+}
+
+func (r *retryPusher) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	err := r.pusher.Push(ctx, userIDs, title, content, opts)
+	if err != nil {
+		now := r.now().UnixMilli()
+		retry := &model.PushRetry{
+			UserIDs:       userIDs,
+			PlatformIDs:   offlinepush.PlatformIDs(ctx),
+			Title:         title,
+			Content:       content,
+			Ex:            opts.Ex,
+			IOSPushSound:  opts.IOSPushSound,
+			IOSBadgeCount: opts.IOSBadgeCount,
+			CreateTime:    now,
+		}
+		if opts.Signal != nil {
+			retry.ClientMsgID = opts.Signal.ClientMsgID
+		}
+		_ = r.fail(ctx, retry, err)
+	}
+	return err
+}
+
+// backoff returns the delay before the retry of a push which failed attempts times.
+func (r *retryPusher) backoff(attempts int) time.Duration {
+	delay := r.initialInterval
+	for i := 1; i < attempts && delay < r.maxInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, r.maxInterval)
+}
+
+func (r *retryPusher) label(provider string) string {
+	if provider == "" {
+		return r.provider
+	}
+	return provider
+}
+
+// fail counts the failure of each provider of err, and queues a retry for each of them or dead-letters it.
+// It returns the error of the last retry which could not be queued or dead-lettered.
+func (r *retryPusher) fail(ctx context.Context, retry *model.PushRetry, err error) (storeErr error) {
+	for provider, err := range offlinepush.ProviderErrors(err) {
+		if provider == "" {
+			provider = retry.Provider
+		}
+		label := r.label(provider)
+		prommetrics.OfflinePushProviderFailedCounter.WithLabelValues(label).Inc()
+		if !r.enable {
+			continue
+		}
+		next := *retry
+		if next.RetryID == "" || next.Provider != provider {
+			next.RetryID = idutil.OperationIDGenerator()
+		}
+		next.Provider = provider
+		next.UserIDs = pushutil.FailedUserIDs(err, retry.UserIDs)
+		next.Attempts++
+		next.LastError = err.Error()
+		next.UpdateTime = r.now().UnixMilli()
+		if next.Attempts >= r.maxAttempts {
+			log.ZWarn(ctx, "offline push dead-lettered", err, "retryID", next.RetryID, "provider", label, "attempts", next.Attempts,
+				"userIDs", next.UserIDs, "clientMsgID", next.ClientMsgID)
+			prommetrics.OfflinePushDeadLetterCounter.WithLabelValues(label).Inc()
+			if err := r.database.DeadLetterPush(ctx, &next); err != nil {
+				log.ZError(ctx, "dead-letter offline push failed", err, "retryID", next.RetryID)
+				storeErr = err
+			}
+			continue
+		}
+		delay := r.backoff(next.Attempts)
+		log.ZInfo(ctx, "offline push queued to retry", "retryID", next.RetryID, "provider", label, "attempts", next.Attempts,
+			"delay", delay, "clientMsgID", next.ClientMsgID)
+		prommetrics.OfflinePushRetryCounter.WithLabelValues(label).Inc()
+		if err := r.database.RetryPush(ctx, &next, delay); err != nil {
+			log.ZError(ctx, "queue offline push retry failed", err, "retryID", next.RetryID)
+			storeErr = err
+		}
+	}
+	return storeErr
+}
+
+// Run pushes the due retries of the default app and of every tenant until ctx is done.
+func (r *retryPusher) Run(ctx context.Context, share *config.Share) {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.pushDue(ctx)
+		if share.MultiTenant.Enable {
+			for _, t := range share.MultiTenant.Tenants {
+				r.pushDue(tenant.WithTenantID(ctx, t.ID))
+			}
+		}
+	}
+}
+
+// pushDue pushes the retries which are due until none is left. A retry stays leased until it was pushed,
+// queued again or dead-lettered, so it is pushed again if the pusher stops in between.
+func (r *retryPusher) pushDue(ctx context.Context) {
+	for {
+		retries, err := r.database.LeaseDuePushRetries(ctx, retryLeaseTime, retryBatch)
+		if err != nil {
+			log.ZError(ctx, "take due offline push retries failed", err)
+			return
+		}
+		var g errgroup.Group
+		g.SetLimit(r.concurrency)
+		for _, retry := range retries {
+			retry := retry
+			g.Go(func() error {
+				ctx := mcontext.SetOperationID(ctx, retry.RetryID)
+				if err := r.retry(ctx, retry); err != nil {
+					return nil
+				}
+				if err := r.database.DonePushRetry(ctx, retry.RetryID); err != nil {
+					log.ZError(ctx, "remove offline push retry failed", err, "retryID", retry.RetryID)
+				}
+				return nil
+			})
+		}
+		_ = g.Wait()
+		if len(retries) < retryBatch {
+			return
+		}
+	}
+}
+
+// retry pushes retry again, the error is the one of queueing or dead-lettering it when the push failed again.
+func (r *retryPusher) retry(ctx context.Context, retry *model.PushRetry) error {
+	// the badge was counted by the first attempt, the retries show it as it is
+	opts := &options.Opts{
+		Signal:       &options.Signal{ClientMsgID: retry.ClientMsgID},
+		IOSPushSound: retry.IOSPushSound,
+		Ex:           retry.Ex,
+	}
+	if len(retry.PlatformIDs) > 0 {
+		ctx = offlinepush.WithPlatformIDs(ctx, retry.PlatformIDs)
+	}
+	var err error
+	if providerPusher, ok := r.pusher.(offlinepush.ProviderPusher); ok && retry.Provider != "" {
+		err = providerPusher.PushProvider(ctx, retry.Provider, retry.UserIDs, retry.Title, retry.Content, opts)
+	} else {
+		err = r.pusher.Push(ctx, retry.UserIDs, retry.Title, retry.Content, opts)
+	}
+	if err != nil {
+		return r.fail(ctx, retry, err)
+	}
+	log.ZInfo(ctx, "offline push retry succeeded", "retryID", retry.RetryID, "provider", r.label(retry.Provider),
+		"attempts", retry.Attempts, "clientMsgID", retry.ClientMsgID)
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/options"
+	"github.com/openimsdk/open-im-server/v3/internal/push/offlinepush/pushutil"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRetryDatabase struct {
+	controller.PushRetryDatabase
+	mu     sync.Mutex
+	queued []*model.PushRetry
+	delays []time.Duration
+	dead   []*model.PushRetry
+	done   []string
+	err    error
+}
+
+func (m *memoryRetryDatabase) RetryPush(_ context.Context, retry *model.PushRetry, delay time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.queued = append(m.queued, retry)
+	m.delays = append(m.delays, delay)
+	return nil
+}
+
+func (m *memoryRetryDatabase) LeaseDuePushRetries(_ context.Context, _ time.Duration, limit int) ([]*model.PushRetry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := min(limit, len(m.queued))
+	retries := m.queued[:n]
+	m.queued = m.queued[n:]
+	return retries, nil
+}
+
+func (m *memoryRetryDatabase) DonePushRetry(_ context.Context, retryID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.done = append(m.done, retryID)
+	return nil
+}
+
+func (m *memoryRetryDatabase) DeadLetterPush(_ context.Context, retry *model.PushRetry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dead = append(m.dead, retry)
+	return nil
+}
+
+// providerPusher fails the providers of fails, the whole push when it has no provider.
+type providerPusher struct {
+	mu        sync.Mutex
+	fails     map[string]int
+	pushed    []string
+	platforms [][]int
+}
+
+func (p *providerPusher) Push(ctx context.Context, userIDs []string, title, content string, opts *options.Opts) error {
+	return errors.Join(
+		p.PushProvider(ctx, "apns", userIDs, title, content, opts),
+		p.PushProvider(ctx, "fcm", userIDs, title, content, opts),
+	)
+}
+
+func (p *providerPusher) PushProvider(ctx context.Context, provider string, _ []string, _, _ string, _ *options.Opts) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pushed = append(p.pushed, provider)
+	p.platforms = append(p.platforms, offlinepush.PlatformIDs(ctx))
+	if p.fails[provider] > 0 {
+		p.fails[provider]--
+		return &offlinepush.ProviderError{Provider: provider, Err: errors.New("unavailable")}
+	}
+	return nil
+}
+
+func newTestRetryPusher(pusher offlinepush.OfflinePusher, maxAttempts int) (*retryPusher, *memoryRetryDatabase) {
+	var conf config.Push
+	conf.Enable = "device"
+	conf.Retry.Enable = true
+	conf.Retry.MaxAttempts = maxAttempts
+	conf.Retry.InitialInterval = 10
+	conf.Retry.MaxInterval = 600
+	db := &memoryRetryDatabase{}
+	return newRetryPusher(pusher, &conf, db), db
+}
+
+func TestRetryPusherBackoff(t *testing.T) {
+	r, _ := newTestRetryPusher(&providerPusher{}, 5)
+	assert.Equal(t, 10*time.Second, r.backoff(1))
+	assert.Equal(t, 20*time.Second, r.backoff(2))
+	assert.Equal(t, 40*time.Second, r.backoff(3))
+	assert.Equal(t, 600*time.Second, r.backoff(7))
+	assert.Equal(t, 600*time.Second, r.backoff(100))
+}
+
+func TestRetryPusherRetriesFailedProvider(t *testing.T) {
+	pusher := &providerPusher{fails: map[string]int{"apns": 2}}
+	r, db := newTestRetryPusher(pusher, 5)
+	ctx := context.Background()
+
+	err := r.Push(offlinepush.WithPlatformIDs(ctx, []int{1}), []string{"u1"}, "title", "content", &options.Opts{Signal: &options.Signal{ClientMsgID: "m1"}, IOSBadgeCount: true})
+	assert.Error(t, err)
+	assert.Len(t, db.queued, 1)
+	retryID := db.queued[0].RetryID
+	assert.Equal(t, "apns", db.queued[0].Provider)
+	assert.Equal(t, 1, db.queued[0].Attempts)
+	assert.Equal(t, "m1", db.queued[0].ClientMsgID)
+	assert.Equal(t, []int{1}, db.queued[0].PlatformIDs)
+
+	r.pushDue(ctx)
+	assert.Len(t, db.queued, 1)
+	assert.Equal(t, retryID, db.queued[0].RetryID)
+	assert.Equal(t, 2, db.queued[0].Attempts)
+	assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second}, db.delays)
+
+	r.pushDue(ctx)
+	assert.Empty(t, db.queued)
+	assert.Empty(t, db.dead)
+	assert.Equal(t, []string{retryID, retryID}, db.done)
+	assert.Equal(t, []string{"apns", "fcm", "apns", "apns"}, pusher.pushed)
+	assert.Equal(t, [][]int{{1}, {1}, {1}, {1}}, pusher.platforms)
+}
+
+func TestRetryPusherDeadLetters(t *testing.T) {
+	pusher := &providerPusher{fails: map[string]int{"apns": 10, "fcm": 10}}
+	r, db := newTestRetryPusher(pusher, 2)
+	ctx := context.Background()
+
+	assert.Error(t, r.Push(ctx, []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Len(t, db.queued, 2)
+
+	r.pushDue(ctx)
+	assert.Empty(t, db.queued)
+	assert.Len(t, db.dead, 2)
+	for _, retry := range db.dead {
+		assert.Equal(t, 2, retry.Attempts)
+		assert.Equal(t, "unavailable", retry.LastError)
+	}
+}
+
+// usersPusher fails the push to the users of fails.
+type usersPusher struct {
+	fails  []string
+	pushed [][]string
+}
+
+func (p *usersPusher) Push(_ context.Context, userIDs []string, _, _ string, _ *options.Opts) error {
+	p.pushed = append(p.pushed, userIDs)
+	return &pushutil.UsersError{UserIDs: p.fails, Err: errors.New("unavailable")}
+}
+
+func TestRetryPusherRetriesFailedUsers(t *testing.T) {
+	pusher := &usersPusher{fails: []string{"u2"}}
+	r, db := newTestRetryPusher(pusher, 5)
+	ctx := context.Background()
+
+	assert.Error(t, r.Push(ctx, []string{"u1", "u2", "u3"}, "title", "content", &options.Opts{}))
+	assert.Len(t, db.queued, 1)
+	assert.Equal(t, []string{"u2"}, db.queued[0].UserIDs)
+
+	pusher.fails = nil
+	r.pushDue(ctx)
+	assert.Equal(t, [][]string{{"u1", "u2", "u3"}, {"u2"}}, pusher.pushed)
+	assert.Len(t, db.queued, 1, "a failure which does not tell the users retries all of them")
+	assert.Equal(t, []string{"u2"}, db.queued[0].UserIDs)
+}
+
+func TestRetryPusherKeepsLeaseWhenNotQueued(t *testing.T) {
+	pusher := &providerPusher{fails: map[string]int{"apns": 2}}
+	r, db := newTestRetryPusher(pusher, 5)
+	ctx := context.Background()
+
+	assert.Error(t, r.Push(ctx, []string{"u1"}, "title", "content", &options.Opts{}))
+	assert.Len(t, db.queued, 1)
+	leased := db.queued[0]
+
+	db.err = errors.New("redis unavailable")
+	r.pushDue(ctx)
+	assert.Empty(t, db.queued)
+	assert.Empty(t, db.done, "a retry which failed again and could not be queued stays leased")
+
+	// the lease expired, the retry is due again
+	db.err = nil
+	db.queued = []*model.PushRetry{leased}
+	r.pushDue(ctx)
+	assert.Empty(t, db.queued)
+	assert.Equal(t, []string{leased.RetryID}, db.done)
+}
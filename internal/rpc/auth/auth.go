@@ -26,6 +26,7 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
 	pbauth "github.com/openimsdk/protocol/auth"
 	"github.com/openimsdk/protocol/constant"
@@ -33,7 +34,6 @@ import (
 	"github.com/openimsdk/tools/discovery"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
-	"github.com/openimsdk/tools/tokenverify"
 	"google.golang.org/grpc"
 )
 
@@ -56,6 +56,7 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 	if err != nil {
 		return err
 	}
+	redis2.AddTenantHook(rdb)
 	userRpcClient := rpcclient.NewUserRpcClient(client, config.Share.RpcRegisterName.User, config.Share.IMAdminUserID)
 	pbauth.RegisterAuthServer(server, &authServer{
 		userRpcClient:  &userRpcClient,
@@ -74,7 +75,12 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 func (s *authServer) UserToken(ctx context.Context, req *pbauth.UserTokenReq) (*pbauth.UserTokenResp, error) {
 	resp := pbauth.UserTokenResp{}
 	if req.Secret != s.config.Share.Secret {
-		return nil, errs.ErrNoPermission.WrapMsg("secret invalid")
+		// Each tenant's app server gets tokens with its own secret, the token then carries the tenant.
+		t, ok := tenant.GetBySecret(req.Secret)
+		if !ok {
+			return nil, errs.ErrNoPermission.WrapMsg("secret invalid")
+		}
+		ctx = tenant.WithTenantID(ctx, t.ID)
 	}
 	if _, err := s.userRpcClient.GetUserInfo(ctx, req.UserID); err != nil {
 		return nil, err
@@ -95,7 +101,7 @@ func (s *authServer) GetUserToken(ctx context.Context, req *pbauth.GetUserTokenR
 	}
 	resp := pbauth.GetUserTokenResp{}
 
-	if authverify.IsManagerUserID(req.UserID, s.config.Share.IMAdminUserID) {
+	if authverify.IsManagerUserID(req.UserID, authverify.AdminUserID(ctx, s.config.Share.IMAdminUserID)) {
 		return nil, errs.ErrNoPermission.WrapMsg("don't get Admin token")
 	}
 	if _, err := s.userRpcClient.GetUserInfo(ctx, req.UserID); err != nil {
@@ -110,11 +116,21 @@ func (s *authServer) GetUserToken(ctx context.Context, req *pbauth.GetUserTokenR
 	return &resp, nil
 }
 
-func (s *authServer) parseToken(ctx context.Context, tokensString string) (claims *tokenverify.Claims, err error) {
-	claims, err = tokenverify.GetClaimFromToken(tokensString, authverify.Secret(s.config.Share.Secret))
+func (s *authServer) parseToken(ctx context.Context, tokensString string) (claims *authverify.Claims, err error) {
+	claims, err = authverify.GetClaimFromToken(tokensString, authverify.Secret(s.config.Share.Secret))
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
+	// A caller routing the request by the unverified tenant of the token must have read the signed one.
+	if tenantID := tenant.GetTenantID(ctx); tenantID != "" && tenantID != claims.TenantID {
+		return nil, servererrs.ErrTokenInvalid.WrapMsg("token tenant mismatch")
+	}
+	if claims.TenantID != "" {
+		if _, ok := tenant.Get(claims.TenantID); !ok {
+			return nil, servererrs.ErrTenantNotFound.WrapMsg("tenant not found", "tenantID", claims.TenantID)
+		}
+		ctx = tenant.WithTenantID(ctx, claims.TenantID)
+	}
 	m, err := s.authDatabase.GetTokensWithoutError(ctx, claims.UserID, claims.PlatformID)
 	if err != nil {
 		return nil, err
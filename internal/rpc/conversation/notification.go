@@ -18,7 +18,9 @@ import (
 	"context"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/conversationext"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/sdkws"
 )
@@ -68,3 +70,16 @@ func (c *ConversationNotificationSender) ConversationUnreadChangeNotification(
 
 	c.Notification(ctx, userID, userID, constant.ConversationUnreadNotification, tips)
 }
+
+// PinnedMsgNotification tells the members of the conversation that its pinned messages changed.
+func (c *ConversationNotificationSender) PinnedMsgNotification(ctx context.Context, opUserID string,
+	conversation *model.Conversation, tips *conversationext.PinnedMsgTips,
+) {
+	switch conversation.ConversationType {
+	case constant.SingleChatType:
+		c.Notification(ctx, opUserID, conversation.UserID, conversationext.PinnedMsgNotification, tips)
+	case constant.ReadGroupChatType:
+		c.NotificationWithSessionType(ctx, opUserID, conversation.GroupID, conversationext.PinnedMsgNotification,
+			constant.ReadGroupChatType, tips)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversation
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/conversationext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/msg"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// defaultMaxPinnedMsgs is used when pinMsg.maxNum is not configured.
+const defaultMaxPinnedMsgs = 50
+
+func (c *conversationServer) PinMsg(ctx context.Context, req *conversationext.PinMsgReq) (*conversationext.PinMsgResp, error) {
+	conversation, err := c.checkPinMsg(ctx, req.UserID, req.ConversationID, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	maxNum := c.config.RpcConfig.PinMsg.MaxNum
+	if maxNum <= 0 {
+		maxNum = defaultMaxPinnedMsgs
+	}
+	pinned := &model.PinnedMsg{Seq: req.Seq, PinUserID: req.UserID, PinTime: time.Now()}
+	changed, err := c.pinnedMsgDatabase.PinMsg(ctx, req.ConversationID, pinned, maxNum)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		c.pinnedMsgChanged(ctx, req.UserID, conversation, req.Seq, true)
+	}
+	return &conversationext.PinMsgResp{}, nil
+}
+
+func (c *conversationServer) UnpinMsg(ctx context.Context, req *conversationext.UnpinMsgReq) (*conversationext.UnpinMsgResp, error) {
+	conversation, err := c.checkPinPermission(ctx, req.UserID, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := c.pinnedMsgDatabase.UnpinMsg(ctx, req.ConversationID, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		c.pinnedMsgChanged(ctx, req.UserID, conversation, req.Seq, false)
+	}
+	return &conversationext.UnpinMsgResp{}, nil
+}
+
+// GetPinnedMsgs returns the pinned messages of a conversation of the user with their content.
+func (c *conversationServer) GetPinnedMsgs(ctx context.Context, req *conversationext.GetPinnedMsgsReq) (*conversationext.GetPinnedMsgsResp, error) {
+	conversation, err := c.getUserConversation(ctx, req.UserID, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	// The conversation of a group is kept after the user leaves it, only current members can see its pinned msgs.
+	if conversation.ConversationType == constant.ReadGroupChatType && !authverify.IsAppManagerUid(ctx, c.config.Share.IMAdminUserID) {
+		if _, err := c.groupRpcClient.GetGroupMemberInfo(ctx, conversation.GroupID, req.UserID); err != nil {
+			return nil, err
+		}
+	}
+	pinned, err := c.pinnedMsgDatabase.GetPinnedMsgs(ctx, req.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &conversationext.GetPinnedMsgsResp{Msgs: make([]*conversationext.PinnedMsg, 0, len(pinned))}
+	if len(pinned) == 0 {
+		return resp, nil
+	}
+	seqs := datautil.Slice(pinned, func(p *model.PinnedMsg) int64 { return p.Seq })
+	msgs, err := c.getSeqMsgs(ctx, req.UserID, req.ConversationID, seqs)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pinned {
+		msgData, ok := msgs[p.Seq]
+		if !ok || msgData.ClientMsgID == "" || msgData.Status == constant.MsgDeleted {
+			continue
+		}
+		resp.Msgs = append(resp.Msgs, &conversationext.PinnedMsg{
+			Seq:       p.Seq,
+			PinUserID: p.PinUserID,
+			PinTime:   p.PinTime.UnixMilli(),
+			MsgData:   msgData,
+		})
+	}
+	return resp, nil
+}
+
+// checkPinMsg checks the user can pin in the conversation and the message can be pinned.
+func (c *conversationServer) checkPinMsg(ctx context.Context, userID string, conversationID string, seq int64) (*model.Conversation, error) {
+	conversation, err := c.checkPinPermission(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := c.getSeqMsgs(ctx, userID, conversationID, []int64{seq})
+	if err != nil {
+		return nil, err
+	}
+	msgData, ok := msgs[seq]
+	if !ok || msgData.ClientMsgID == "" || msgData.Status == constant.MsgDeleted {
+		return nil, errs.ErrRecordNotFound.WrapMsg("msg not found", "conversationID", conversationID, "seq", seq)
+	}
+	if msgData.ContentType == constant.MsgRevokeNotification {
+		return nil, servererrs.ErrMsgAlreadyRevoke.WrapMsg("msg already revoke")
+	}
+	if msgData.ContentType >= constant.NotificationBegin && msgData.ContentType <= constant.NotificationEnd {
+		return nil, errs.ErrArgs.WrapMsg("notification can not be pinned", "contentType", msgData.ContentType)
+	}
+	return conversation, nil
+}
+
+// checkPinPermission checks the user can change the pinned messages of the conversation,
+// in a group it depends on the role of the user when pinMsg.groupAdminOnly is set.
+func (c *conversationServer) checkPinPermission(ctx context.Context, userID string, conversationID string) (*model.Conversation, error) {
+	conversation, err := c.getUserConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	switch conversation.ConversationType {
+	case constant.SingleChatType:
+	case constant.ReadGroupChatType:
+		if authverify.IsAppManagerUid(ctx, c.config.Share.IMAdminUserID) {
+			break
+		}
+		member, err := c.groupRpcClient.GetGroupMemberInfo(ctx, conversation.GroupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if c.config.RpcConfig.PinMsg.GroupAdminOnly && member.RoleLevel != constant.GroupOwner && member.RoleLevel != constant.GroupAdmin {
+			return nil, errs.ErrNoPermission.WrapMsg("only the group owner and admins can pin msgs")
+		}
+	default:
+		return nil, errs.ErrArgs.WrapMsg("conversation type not supported", "conversationType", conversation.ConversationType)
+	}
+	return conversation, nil
+}
+
+func (c *conversationServer) getUserConversation(ctx context.Context, userID string, conversationID string) (*model.Conversation, error) {
+	if err := authverify.CheckAccessV3(ctx, userID, c.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	conversations, err := c.conversationDatabase.FindConversations(ctx, userID, []string{conversationID})
+	if err != nil {
+		return nil, err
+	}
+	if len(conversations) == 0 {
+		return nil, errs.ErrRecordNotFound.WrapMsg("conversation not found", "conversationID", conversationID)
+	}
+	return conversations[0], nil
+}
+
+func (c *conversationServer) getSeqMsgs(ctx context.Context, userID string, conversationID string, seqs []int64) (map[int64]*sdkws.MsgData, error) {
+	resp, err := c.msgRpcClient.GetSeqMessage(ctx, &msg.GetSeqMessageReq{
+		UserID:        userID,
+		Conversations: []*msg.ConversationSeqs{{ConversationID: conversationID, Seqs: seqs}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]*sdkws.MsgData, len(seqs))
+	if pullMsgs := resp.Msgs[conversationID]; pullMsgs != nil {
+		for _, msgData := range pullMsgs.Msgs {
+			if msgData != nil {
+				res[msgData.Seq] = msgData
+			}
+		}
+	}
+	return res, nil
+}
+
+func (c *conversationServer) pinnedMsgChanged(ctx context.Context, userID string, conversation *model.Conversation, seq int64, isPinned bool) {
+	tips := &conversationext.PinnedMsgTips{
+		ConversationID: conversation.ConversationID,
+		Seq:            seq,
+		OpUserID:       userID,
+		IsPinned:       isPinned,
+	}
+	if pinned, err := c.pinnedMsgDatabase.GetPinnedMsgs(ctx, conversation.ConversationID); err == nil {
+		tips.PinnedSeqs = datautil.Slice(pinned, func(p *model.PinnedMsg) int64 { return p.Seq })
+	}
+	c.conversationNotificationSender.PinnedMsgNotification(ctx, userID, conversation, tips)
+}
@@ -236,7 +236,7 @@ func (g *GroupNotificationSender) fillOpUserByUserID(ctx context.Context, userID
 		return errs.ErrInternalServer.WrapMsg("**sdkws.GroupMemberFullInfo is nil")
 	}
 	if groupID != "" {
-		if authverify.IsManagerUserID(userID, g.config.Share.IMAdminUserID) {
+		if authverify.IsManagerUserID(userID, authverify.AdminUserID(ctx, g.config.Share.IMAdminUserID)) {
 			*opUser = &sdkws.GroupMemberFullInfo{
 				GroupID:        groupID,
 				UserID:         userID,
@@ -100,7 +100,7 @@ func (m *msgServer) MarkMsgsAsRead(ctx context.Context, req *msg.MarkMsgsAsReadR
 	if hasReadSeq > maxSeq {
 		return nil, errs.ErrArgs.WrapMsg("hasReadSeq must not be bigger than maxSeq")
 	}
-	conversation, err := m.ConversationLocalCache.GetConversation(ctx, req.UserID, req.ConversationID)
+	conversation, err := m.ConversationLocalCache.GetConversation(ctx, req.UserID, parentConversationID(req.ConversationID))
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +116,9 @@ func (m *msgServer) MarkMsgsAsRead(ctx context.Context, req *msg.MarkMsgsAsReadR
 		if err != nil {
 			return nil, err
 		}
+		if conversation.ConversationType == constant.ReadGroupChatType {
+			m.groupMsgsRead(ctx, req.ConversationID, conversation.GroupID, req.UserID, currentHasReadSeq, hasReadSeq)
+		}
 	}
 
 	reqCallback := &cbapi.CallbackSingleMsgReadReq{
@@ -131,7 +134,7 @@ func (m *msgServer) MarkMsgsAsRead(ctx context.Context, req *msg.MarkMsgsAsReadR
 }
 
 func (m *msgServer) MarkConversationAsRead(ctx context.Context, req *msg.MarkConversationAsReadReq) (*msg.MarkConversationAsReadResp, error) {
-	conversation, err := m.ConversationLocalCache.GetConversation(ctx, req.UserID, req.ConversationID)
+	conversation, err := m.ConversationLocalCache.GetConversation(ctx, req.UserID, parentConversationID(req.ConversationID))
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +177,9 @@ func (m *msgServer) MarkConversationAsRead(ctx context.Context, req *msg.MarkCon
 			if err != nil {
 				return nil, err
 			}
+			if conversation.ConversationType == constant.ReadGroupChatType {
+				m.groupMsgsRead(ctx, req.ConversationID, conversation.GroupID, req.UserID, hasReadSeq, req.HasReadSeq)
+			}
 			hasReadSeq = req.HasReadSeq
 		}
 		m.sendMarkAsReadNotification(ctx, req.ConversationID, constant.SingleChatType, req.UserID,
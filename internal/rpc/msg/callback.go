@@ -20,6 +20,7 @@ import (
 
 	cbapi "github.com/openimsdk/open-im-server/v3/pkg/callbackstruct"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 	"github.com/openimsdk/protocol/constant"
 	pbchat "github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/protocol/sdkws"
@@ -182,3 +183,25 @@ func (m *msgServer) webhookAfterRevokeMsg(ctx context.Context, after *config.Aft
 	}
 	m.webhookClient.AsyncPost(ctx, callbackReq.GetCallbackCommand(), callbackReq, &cbapi.CallbackAfterRevokeMsgResp{}, after)
 }
+
+func (m *msgServer) webhookBeforeMsgEdit(ctx context.Context, before *config.BeforeConfig, req *msgext.EditMsgReq, msg *sdkws.MsgData) error {
+	return webhook.WithCondition(ctx, before, func(ctx context.Context) error {
+		cbReq := &cbapi.CallbackBeforeMsgEditReq{
+			CallbackCommand: cbapi.CallbackBeforeMsgEditCommand,
+			ConversationID:  req.ConversationID,
+			Seq:             req.Seq,
+			UserID:          req.UserID,
+			SendID:          msg.SendID,
+			SessionType:     msg.SessionType,
+			ContentType:     msg.ContentType,
+			OldContent:      string(msg.Content),
+			Content:         req.Content,
+		}
+		resp := &cbapi.CallbackBeforeMsgEditResp{}
+		if err := m.webhookClient.SyncPost(ctx, cbReq.GetCallbackCommand(), cbReq, resp, before); err != nil {
+			return err
+		}
+		datautil.NotNilReplace(&req.Content, resp.Content)
+		return nil
+	})
+}
@@ -6,12 +6,15 @@ import (
 
 	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/convert"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/retention"
 	pbconversation "github.com/openimsdk/protocol/conversation"
 	"github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/protocol/wrapperspb"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/utils/datautil"
 	"github.com/openimsdk/tools/utils/idutil"
 	"github.com/openimsdk/tools/utils/stringutil"
 	"golang.org/x/sync/errgroup"
@@ -25,11 +28,20 @@ func (m *msgServer) ClearMsg(ctx context.Context, req *msg.ClearMsgReq) (_ *msg.
 	if req.Timestamp > time.Now().UnixMilli() {
 		return nil, errs.ErrArgs.WrapMsg("request millisecond timestamp error")
 	}
+	policies, err := m.RetentionDatabase.GetAllRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var (
-		docNum int
-		msgNum int
-		start  = time.Now()
+		docNum   int
+		msgNum   int
+		start    = time.Now()
+		resolver = retention.NewResolver(policies)
+		skipped  = make(map[string]retention.Rule)
 	)
+	defer func() {
+		m.addRetentionSkips(ctx, retentionOpClearMsg, skipped)
+	}()
 
 	clearMsg := func(ctx context.Context) (bool, error) {
 		docIDs, err := m.MsgDatabase.GetDocIDs(ctx)
@@ -46,14 +58,31 @@ func (m *msgServer) ClearMsg(ctx context.Context, req *msg.ClearMsgReq) (_ *msg.
 		}
 
 		for _, msg := range msgs {
-			index, err := m.MsgDatabase.DeleteDocMsgBefore(ctx, req.Timestamp, msg)
+			conversationID := msg.GetConversationID()
+			rule := resolver.Resolve(conversationID)
+			if rule.LegalHold {
+				skipped[conversationID] = rule
+				continue
+			}
+			cutoff := rule.Cutoff(start, req.Timestamp)
+			if cutoff < req.Timestamp && keepsMsgs(msg, cutoff, req.Timestamp) {
+				skipped[conversationID] = rule
+			}
+			index, err := m.MsgDatabase.DeleteDocMsgBefore(ctx, cutoff, msg)
 			if err != nil {
 				return false, err
 			}
 			if len(index) == 0 {
+				if cutoff < req.Timestamp {
+					// the policy keeps these messages for longer
+					continue
+				}
 				return false, errs.ErrInternalServer.WrapMsg("delete doc msg failed")
 			}
 
+			m.unindexMsgs(ctx, conversationID, datautil.Slice(index, func(i int) int64 {
+				return msg.Msg[i].Msg.Seq
+			}))
 			docNum++
 			msgNum += len(index)
 		}
@@ -67,14 +96,41 @@ func (m *msgServer) ClearMsg(ctx context.Context, req *msg.ClearMsgReq) (_ *msg.
 		return nil, err
 	}
 
+	m.clearShortRetention(ctx, resolver, policies, start, req.Timestamp, skipped)
+
 	log.ZDebug(ctx, "clearing message", "docNum", docNum, "msgNum", msgNum, "cost", time.Since(start))
 
 	return &msg.ClearMsgResp{}, nil
 }
 
+// keepsMsgs reports whether doc has messages sent before ts the cutoff of a retention policy keeps.
+func keepsMsgs(doc *model.MsgDocModel, cutoff int64, ts int64) bool {
+	for _, msg := range doc.Msg {
+		if msg.Msg != nil && msg.Msg.SendTime >= cutoff && msg.Msg.SendTime < ts {
+			return true
+		}
+	}
+	return false
+}
+
 // soft delete for self
 func (m *msgServer) DestructMsgs(ctx context.Context, req *msg.DestructMsgsReq) (_ *msg.DestructMsgsResp, err error) {
 	temp := convert.ConversationsPb2DB(req.Conversations)
+	rules, err := m.RetentionDatabase.GetRetentionRules(ctx, datautil.DistinctAnyGetComparable(temp, func(conversation *model.Conversation) string {
+		return conversation.ConversationID
+	}))
+	if err != nil {
+		return nil, err
+	}
+	skipped := make(map[string]retention.Rule)
+	temp = datautil.Filter(temp, func(conversation *model.Conversation) (*model.Conversation, bool) {
+		if rule := rules[conversation.ConversationID]; rule.LegalHold {
+			skipped[conversation.ConversationID] = rule
+			return nil, false
+		}
+		return conversation, true
+	})
+	m.addRetentionSkips(ctx, retentionOpDestructMsgs, skipped)
 
 	batchNum := 100
 
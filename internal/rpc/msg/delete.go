@@ -45,7 +45,7 @@ func (m *msgServer) ClearConversationsMsg(ctx context.Context, req *msg.ClearCon
 	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
 		return nil, err
 	}
-	if err := m.clearConversation(ctx, req.ConversationIDs, req.UserID, req.DeleteSyncOpt); err != nil {
+	if err := m.clearConversation(ctx, retentionOpClearConversationsMsg, req.ConversationIDs, req.UserID, req.DeleteSyncOpt); err != nil {
 		return nil, err
 	}
 	return &msg.ClearConversationsMsgResp{}, nil
@@ -59,7 +59,7 @@ func (m *msgServer) UserClearAllMsg(ctx context.Context, req *msg.UserClearAllMs
 	if err != nil {
 		return nil, err
 	}
-	if err := m.clearConversation(ctx, conversationIDs, req.UserID, req.DeleteSyncOpt); err != nil {
+	if err := m.clearConversation(ctx, retentionOpUserClearAllMsg, conversationIDs, req.UserID, req.DeleteSyncOpt); err != nil {
 		return nil, err
 	}
 	return &msg.UserClearAllMsgResp{}, nil
@@ -69,11 +69,15 @@ func (m *msgServer) DeleteMsgs(ctx context.Context, req *msg.DeleteMsgsReq) (*ms
 	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
 		return nil, err
 	}
+	if err := m.checkConversationNotHeld(ctx, retentionOpDeleteMsgs, req.ConversationID); err != nil {
+		return nil, err
+	}
 	isSyncSelf, isSyncOther := m.validateDeleteSyncOpt(req.DeleteSyncOpt)
 	if isSyncOther {
 		if err := m.MsgDatabase.DeleteMsgsPhysicalBySeqs(ctx, req.ConversationID, req.Seqs); err != nil {
 			return nil, err
 		}
+		m.unindexMsgs(ctx, req.ConversationID, req.Seqs)
 		conversations, err := m.Conversation.GetConversationsByConversationID(ctx, []string{req.ConversationID})
 		if err != nil {
 			return nil, err
@@ -100,10 +104,14 @@ func (m *msgServer) DeleteMsgs(ctx context.Context, req *msg.DeleteMsgsReq) (*ms
 }
 
 func (m *msgServer) DeleteMsgPhysicalBySeq(ctx context.Context, req *msg.DeleteMsgPhysicalBySeqReq) (*msg.DeleteMsgPhysicalBySeqResp, error) {
+	if err := m.checkConversationNotHeld(ctx, retentionOpDeleteMsgPhysicalBySeq, req.ConversationID); err != nil {
+		return nil, err
+	}
 	err := m.MsgDatabase.DeleteMsgsPhysicalBySeqs(ctx, req.ConversationID, req.Seqs)
 	if err != nil {
 		return nil, err
 	}
+	m.unindexMsgs(ctx, req.ConversationID, req.Seqs)
 	return &msg.DeleteMsgPhysicalBySeqResp{}, nil
 }
 
@@ -111,16 +119,29 @@ func (m *msgServer) DeleteMsgPhysical(ctx context.Context, req *msg.DeleteMsgPhy
 	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
 		return nil, err
 	}
+	conversationIDs, err := m.filterHeldConversations(ctx, retentionOpDeleteMsgPhysical, req.ConversationIDs)
+	if err != nil {
+		return nil, err
+	}
 	remainTime := timeutil.GetCurrentTimestampBySecond() - req.Timestamp
-	for _, conversationID := range req.ConversationIDs {
+	for _, conversationID := range conversationIDs {
 		if err := m.MsgDatabase.DeleteConversationMsgsAndSetMinSeq(ctx, conversationID, remainTime); err != nil {
 			log.ZWarn(ctx, "DeleteConversationMsgsAndSetMinSeq error", err, "conversationID", conversationID, "err", err)
+			continue
 		}
+		m.unindexDeletedMsgs(ctx, conversationID)
 	}
 	return &msg.DeleteMsgPhysicalResp{}, nil
 }
 
-func (m *msgServer) clearConversation(ctx context.Context, conversationIDs []string, userID string, deleteSyncOpt *msg.DeleteSyncOpt) error {
+func (m *msgServer) clearConversation(ctx context.Context, operation string, conversationIDs []string, userID string, deleteSyncOpt *msg.DeleteSyncOpt) error {
+	conversationIDs, err := m.filterHeldConversations(ctx, operation, conversationIDs)
+	if err != nil {
+		return err
+	}
+	if len(conversationIDs) == 0 {
+		return nil
+	}
 	conversations, err := m.Conversation.GetConversationsByConversationID(ctx, conversationIDs)
 	if err != nil {
 		return err
@@ -147,9 +168,13 @@ func (m *msgServer) clearConversation(ctx context.Context, conversationIDs []str
 			m.notificationSender.NotificationWithSessionType(ctx, userID, userID, constant.ClearConversationNotification, constant.SingleChatType, tips)
 		}
 	} else {
-		if err := m.MsgDatabase.SetMinSeqs(ctx, m.getMinSeqs(maxSeqs)); err != nil {
+		minSeqs := m.getMinSeqs(maxSeqs)
+		if err := m.MsgDatabase.SetMinSeqs(ctx, minSeqs); err != nil {
 			return err
 		}
+		for conversationID, minSeq := range minSeqs {
+			m.unindexMsgsBefore(ctx, conversationID, minSeq)
+		}
 		for _, conversation := range existConversations {
 			tips := &sdkws.ClearConversationTips{UserID: userID, ConversationIDs: []string{conversation.ConversationID}}
 			m.notificationSender.NotificationWithSessionType(ctx, userID, m.conversationAndGetRecvID(conversation, userID), constant.ClearConversationNotification, conversation.ConversationType, tips)
@@ -0,0 +1,114 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/mcontext"
+	"google.golang.org/protobuf/proto"
+)
+
+// EditMsg replaces the content of a message. The sender can edit its messages within the configured time window,
+// app managers can edit any message at any time.
+func (m *msgServer) EditMsg(ctx context.Context, req *msgext.EditMsgReq) (*msgext.EditMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, []int64{req.Seq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil || msgs[0].Status == constant.MsgDeleted {
+		return nil, errs.ErrRecordNotFound.WrapMsg("msg not found")
+	}
+	msgData := msgs[0]
+	if msgData.ContentType == constant.MsgRevokeNotification {
+		return nil, servererrs.ErrMsgAlreadyRevoke.WrapMsg("msg already revoke")
+	}
+	if msgData.ContentType >= constant.NotificationBegin && msgData.ContentType <= constant.NotificationEnd {
+		return nil, errs.ErrArgs.WrapMsg("notification can not be edited", "contentType", msgData.ContentType)
+	}
+	if err := msgext.CheckEditContent(msgData.ContentType, req.Content); err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	isAdmin := authverify.IsAppManagerUid(ctx, m.config.Share.IMAdminUserID)
+	if !isAdmin {
+		if req.UserID != msgData.SendID {
+			return nil, errs.ErrNoPermission.WrapMsg("only the sender can edit the msg")
+		}
+		if editExpired(msgData.SendTime, now, m.config.RpcConfig.EditMsg.TimeLimit) {
+			return nil, servererrs.ErrMsgEditExpired.WrapMsg("msg edit time limit exceeded", "sendTime", msgData.SendTime)
+		}
+	}
+	if err := m.webhookBeforeMsgEdit(ctx, &m.config.WebhooksConfig.BeforeMsgEdit, req, msgData); err != nil {
+		return nil, err
+	}
+	// The webhook may have replaced the content.
+	if err := msgext.CheckEditContent(msgData.ContentType, req.Content); err != nil {
+		return nil, err
+	}
+	edited := proto.Clone(msgData).(*sdkws.MsgData)
+	edited.Content = []byte(req.Content)
+	moderated, err := m.moderateMsg(ctx, edited)
+	if err != nil {
+		return nil, err
+	}
+	content := string(edited.Content)
+	// An app manager may edit on behalf of the user, the history keeps who actually edited.
+	editorUserID := mcontext.GetOpUserID(ctx)
+	err = m.MsgDatabase.EditMsg(ctx, req.ConversationID, req.Seq, content, &model.MsgEditModel{
+		Content:      string(msgData.Content),
+		EditorUserID: editorUserID,
+		EditTime:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.addModerationReview(ctx, edited, moderated)
+	m.reindexMsg(ctx, req.ConversationID, edited)
+	tips := msgext.EditMsgTips{
+		EditorUserID:   editorUserID,
+		ClientMsgID:    msgData.ClientMsgID,
+		ConversationID: req.ConversationID,
+		Seq:            req.Seq,
+		SessionType:    msgData.SessionType,
+		Content:        content,
+		EditTime:       now,
+		IsAdminEdit:    isAdmin,
+	}
+	var recvID string
+	if msgData.SessionType == constant.ReadGroupChatType {
+		recvID = msgData.GroupID
+	} else {
+		recvID = msgData.RecvID
+	}
+	m.notificationSender.NotificationWithSessionType(ctx, req.UserID, recvID, msgext.MsgEditNotification, msgData.SessionType, &tips)
+	return &msgext.EditMsgResp{EditTime: now}, nil
+}
+
+// editExpired reports whether a message sent at sendTime can no longer be edited, limit is in seconds and 0 means no limit.
+func editExpired(sendTime, now, limit int64) bool {
+	return limit > 0 && now-sendTime > limit*int64(time.Second/time.Millisecond)
+}
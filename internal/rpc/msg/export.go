@@ -0,0 +1,388 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/convert"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgexport"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/third"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// msgExportClaimTimeout is how long a running export job which stopped reporting progress waits before it is
+// claimed again, the progress is reported after each doc of messages and at least every msgExportProgressInterval.
+const msgExportClaimTimeout = time.Minute * 10
+
+// msgExportStallTimeout cancels a job which did not report progress for that long, so that it stops before its
+// claim expires and another msg rpc runs it again.
+const msgExportStallTimeout = msgExportClaimTimeout - time.Minute
+
+// msgExportProgressInterval is how often the progress is reported within a doc whose objects are slow to download.
+const msgExportProgressInterval = time.Minute
+
+var (
+	// msgExportObjectClient downloads the objects of the messages, an object which is too slow is left out.
+	msgExportObjectClient = &http.Client{Timeout: time.Minute * 2}
+	// msgExportUploadClient uploads the archive, which has to be done before the job stalls.
+	msgExportUploadClient = &http.Client{Timeout: msgExportStallTimeout}
+)
+
+// msgExportGroup is the group of the uploaded archives in the object storage, their names start with it too.
+const msgExportGroup = "msg_export"
+
+// CreateMsgExportJob stores an export job, the cron task hands it to a msg rpc which writes the archive.
+func (m *msgServer) CreateMsgExportJob(ctx context.Context, req *msgext.CreateMsgExportJobReq) (*msgext.CreateMsgExportJobResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	opUserID := mcontext.GetOpUserID(ctx)
+	job := &model.MsgExportJob{
+		JobID:           GetMsgID(opUserID),
+		CreatorUserID:   opUserID,
+		ConversationIDs: datautil.Distinct(req.ConversationIDs),
+		Format:          req.Format,
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		Status:          msgext.MsgExportPending,
+		CreateTime:      time.Now(),
+	}
+	if err := m.MsgExportDatabase.CreateMsgExportJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return &msgext.CreateMsgExportJobResp{JobID: job.JobID}, nil
+}
+
+// GetMsgExportJob returns an export job, the access url of the archive is set when the job is done.
+func (m *msgServer) GetMsgExportJob(ctx context.Context, req *msgext.GetMsgExportJobReq) (*msgext.GetMsgExportJobResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	job, err := m.MsgExportDatabase.TakeMsgExportJob(ctx, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	res := convertMsgExportJob(job)
+	if job.Status == msgext.MsgExportDone {
+		access, err := m.Third.Client.AccessURL(ctx, &third.AccessURLReq{Name: job.ObjectName})
+		if err != nil {
+			return nil, err
+		}
+		res.URL = access.Url
+		res.URLExpireTime = access.ExpireTime
+	}
+	return &msgext.GetMsgExportJobResp{Job: res}, nil
+}
+
+func (m *msgServer) GetMsgExportJobs(ctx context.Context, req *msgext.GetMsgExportJobsReq) (*msgext.GetMsgExportJobsResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	total, jobs, err := m.MsgExportDatabase.FindMsgExportJobs(ctx, req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.GetMsgExportJobsResp{Total: total, Jobs: datautil.Slice(jobs, convertMsgExportJob)}, nil
+}
+
+// RunMsgExportJobs claims pending export jobs and writes them in the background, it returns without waiting for them.
+func (m *msgServer) RunMsgExportJobs(ctx context.Context, req *msgext.RunMsgExportJobsReq) (*msgext.RunMsgExportJobsResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	jobs, err := m.MsgExportDatabase.ClaimMsgExportJobs(ctx, msgExportClaimTimeout, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.RunMsgExportJobsResp{JobIDs: make([]string, 0, len(jobs))}
+	for _, job := range jobs {
+		resp.JobIDs = append(resp.JobIDs, job.JobID)
+		// The job outlives the request, it keeps the tenant and the op user of the context, and is canceled when it stalls.
+		go m.runMsgExportJob(context.WithoutCancel(ctx), job)
+	}
+	return resp, nil
+}
+
+func (m *msgServer) runMsgExportJob(ctx context.Context, job *model.MsgExportJob) {
+	start := time.Now()
+	log.ZInfo(ctx, "msg export job start", "jobID", job.JobID, "format", job.Format, "conversationIDs", job.ConversationIDs)
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stall := time.AfterFunc(msgExportStallTimeout, func() {
+		cancel(errs.New("msg export job stalled", "jobID", job.JobID, "timeout", msgExportStallTimeout).Wrap())
+	})
+	defer stall.Stop()
+	progress := &msgExportProgress{jobID: job.JobID, db: m.MsgExportDatabase, stall: stall, last: start}
+	msgCount, objectName, size, err := m.writeMsgExport(jobCtx, job, progress)
+	if cause := context.Cause(jobCtx); err != nil && cause != nil {
+		err = cause
+	}
+	if err != nil {
+		log.ZError(ctx, "msg export job failed", err, "jobID", job.JobID, "msgCount", msgCount)
+		if err := m.MsgExportDatabase.FailMsgExportJob(ctx, job.JobID, msgCount, err.Error()); err != nil {
+			log.ZError(ctx, "set msg export job failed status failed", err, "jobID", job.JobID)
+		}
+		return
+	}
+	if err := m.MsgExportDatabase.DoneMsgExportJob(ctx, job.JobID, msgCount, objectName, size); err != nil {
+		log.ZError(ctx, "set msg export job done status failed", err, "jobID", job.JobID)
+		return
+	}
+	log.ZInfo(ctx, "msg export job done", "jobID", job.JobID, "msgCount", msgCount, "size", size, "cost", time.Since(start))
+}
+
+// msgExportProgress reports the progress of a job, which renews its claim and delays its stall timeout.
+type msgExportProgress struct {
+	jobID string
+	db    controller.MsgExportDatabase
+	stall *time.Timer
+	last  time.Time
+}
+
+func (p *msgExportProgress) report(ctx context.Context, msgCount int64) error {
+	if err := p.db.ProgressMsgExportJob(ctx, p.jobID, msgCount); err != nil {
+		return err
+	}
+	p.stall.Reset(msgExportStallTimeout)
+	p.last = time.Now()
+	return nil
+}
+
+// reportDue reports the progress if it was not reported for msgExportProgressInterval.
+func (p *msgExportProgress) reportDue(ctx context.Context, msgCount int64) error {
+	if time.Since(p.last) < msgExportProgressInterval {
+		return nil
+	}
+	return p.report(ctx, msgCount)
+}
+
+// writeMsgExport writes the archive of job to a temp file and uploads it to the object storage.
+func (m *msgServer) writeMsgExport(ctx context.Context, job *model.MsgExportJob, progress *msgExportProgress) (msgCount int64, objectName string, size int64, err error) {
+	ext := msgexport.FileExt(job.Format)
+	file, err := os.CreateTemp(m.config.RpcConfig.MsgExport.TempDir, "msg_export_*"+ext)
+	if err != nil {
+		return 0, "", 0, errs.WrapMsg(err, "create msg export file failed")
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	buf := bufio.NewWriter(file)
+	writer, err := msgexport.NewWriter(job.Format, buf, "OpenIM chat history "+job.JobID)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	for _, conversationID := range job.ConversationIDs {
+		err := m.MsgDatabase.RangeMsgs(ctx, conversationID, func(msgs []*model.MsgInfoModel) error {
+			for _, msgInfo := range msgs {
+				msgData := convert.MsgDB2Pb(msgInfo.Msg)
+				if (job.StartTime > 0 && msgData.SendTime < job.StartTime) || (job.EndTime > 0 && msgData.SendTime > job.EndTime) {
+					continue
+				}
+				exported := &msgexport.Msg{
+					ConversationID: conversationID,
+					Seq:            msgData.Seq,
+					ClientMsgID:    msgData.ClientMsgID,
+					ServerMsgID:    msgData.ServerMsgID,
+					SendID:         msgData.SendID,
+					SenderNickname: msgData.SenderNickname,
+					RecvID:         msgData.RecvID,
+					GroupID:        msgData.GroupID,
+					SessionType:    msgData.SessionType,
+					ContentType:    msgData.ContentType,
+					Content:        string(msgData.Content),
+					Text:           msgprocessor.GetSearchText(msgData),
+					SendTime:       msgData.SendTime,
+					Revoked:        msgInfo.Revoke != nil,
+					Objects:        msgprocessor.GetObjectNames(msgData),
+				}
+				var attachments []*msgexport.Attachment
+				if msgexport.IncludesObjects(job.Format) {
+					attachments = m.openMsgExportObjects(ctx, exported.Objects)
+				}
+				err := writer.WriteMsg(exported, attachments)
+				for _, attachment := range attachments {
+					_ = attachment.Data.(io.Closer).Close()
+				}
+				if err != nil {
+					return err
+				}
+				msgCount++
+				if err := progress.reportDue(ctx, msgCount); err != nil {
+					return err
+				}
+			}
+			return progress.report(ctx, msgCount)
+		})
+		if err != nil {
+			return msgCount, "", 0, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return msgCount, "", 0, err
+	}
+	if err := buf.Flush(); err != nil {
+		return msgCount, "", 0, errs.WrapMsg(err, "write msg export file failed")
+	}
+	if size, err = file.Seek(0, io.SeekCurrent); err != nil {
+		return msgCount, "", 0, errs.WrapMsg(err, "seek msg export file failed")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return msgCount, "", 0, errs.WrapMsg(err, "seek msg export file failed")
+	}
+	objectName = path.Join(msgExportGroup, time.Now().Format("20060102"), job.JobID+ext)
+	if err := m.uploadMsgExport(ctx, objectName, msgexport.ContentType(job.Format), file, size); err != nil {
+		return msgCount, "", 0, err
+	}
+	return msgCount, objectName, size, nil
+}
+
+// openMsgExportObjects downloads the objects through their access urls. The objects which can not be downloaded,
+// or are larger than msgExport.maxObjectSize, are left out of the archive and only listed by name.
+func (m *msgServer) openMsgExportObjects(ctx context.Context, names []string) []*msgexport.Attachment {
+	maxSize := m.config.RpcConfig.MsgExport.MaxObjectSize
+	if maxSize <= 0 {
+		return nil
+	}
+	attachments := make([]*msgexport.Attachment, 0, len(names))
+	for _, name := range names {
+		access, err := m.Third.Client.AccessURL(ctx, &third.AccessURLReq{Name: name})
+		if err != nil {
+			log.ZWarn(ctx, "msg export object access url failed", err, "name", name)
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, access.Url, nil)
+		if err != nil {
+			log.ZWarn(ctx, "msg export object request failed", err, "name", name)
+			continue
+		}
+		resp, err := msgExportObjectClient.Do(req)
+		if err != nil {
+			log.ZWarn(ctx, "msg export object download failed", err, "name", name)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 || resp.ContentLength > maxSize {
+			_ = resp.Body.Close()
+			log.ZWarn(ctx, "msg export object left out", nil, "name", name, "status", resp.Status, "size", resp.ContentLength)
+			continue
+		}
+		attachments = append(attachments, &msgexport.Attachment{
+			Name:        name,
+			ContentType: resp.Header.Get("Content-Type"),
+			Data:        resp.Body,
+		})
+	}
+	return attachments
+}
+
+// uploadMsgExport uploads the archive through the form data upload of the third rpc, so it is recorded
+// like any other object and served by AccessURL.
+func (m *msgServer) uploadMsgExport(ctx context.Context, name string, contentType string, file io.Reader, size int64) error {
+	form, err := m.Third.Client.InitiateFormData(ctx, &third.InitiateFormDataReq{
+		Name:        name,
+		Size:        size,
+		ContentType: contentType,
+		Group:       msgExportGroup,
+	})
+	if err != nil {
+		return err
+	}
+	// The form is sent with its length, so the file part is streamed between the buffered head and tail.
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for key, value := range form.FormData {
+		if err := mw.WriteField(key, value); err != nil {
+			return errs.WrapMsg(err, "write form field failed", "key", key)
+		}
+	}
+	if _, err := mw.CreateFormFile(form.File, path.Base(name)); err != nil {
+		return errs.WrapMsg(err, "write form file failed")
+	}
+	head := bytes.Clone(buf.Bytes())
+	buf.Reset()
+	if err := mw.Close(); err != nil {
+		return errs.WrapMsg(err, "close form failed")
+	}
+	tail := buf.Bytes()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, form.Url, io.MultiReader(bytes.NewReader(head), file, bytes.NewReader(tail)))
+	if err != nil {
+		return errs.WrapMsg(err, "new upload request failed")
+	}
+	req.ContentLength = int64(len(head)) + size + int64(len(tail))
+	for _, kv := range form.Header {
+		for _, value := range kv.Values {
+			req.Header.Add(kv.Key, value)
+		}
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := msgExportUploadClient.Do(req)
+	if err != nil {
+		return errs.WrapMsg(err, "upload msg export failed", "name", name)
+	}
+	defer resp.Body.Close()
+	if !uploadSucceeded(resp.StatusCode, form.SuccessCodes) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errs.New("upload msg export failed", "name", name, "status", resp.Status, "body", string(body)).Wrap()
+	}
+	if _, err := m.Third.Client.CompleteFormData(ctx, &third.CompleteFormDataReq{Id: form.Id}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func uploadSucceeded(statusCode int, successCodes []int32) bool {
+	if len(successCodes) == 0 {
+		return statusCode/100 == 2
+	}
+	return datautil.Contain(int32(statusCode), successCodes...)
+}
+
+func convertMsgExportJob(job *model.MsgExportJob) *msgext.MsgExportJob {
+	res := &msgext.MsgExportJob{
+		JobID:           job.JobID,
+		CreatorUserID:   job.CreatorUserID,
+		ConversationIDs: job.ConversationIDs,
+		Format:          job.Format,
+		StartTime:       job.StartTime,
+		EndTime:         job.EndTime,
+		Status:          job.Status,
+		MsgCount:        job.MsgCount,
+		ObjectName:      job.ObjectName,
+		Size:            job.Size,
+		ErrMsg:          job.ErrMsg,
+		CreateTime:      job.CreateTime.UnixMilli(),
+	}
+	if !job.FinishTime.IsZero() {
+		res.FinishTime = job.FinishTime.UnixMilli()
+	}
+	return res
+}
@@ -0,0 +1,240 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	pbmsg "github.com/openimsdk/protocol/msg"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/utils/datautil"
+	"github.com/openimsdk/tools/utils/idutil"
+	"github.com/openimsdk/tools/utils/timeutil"
+)
+
+// mergeAbstractLen is the number of messages summarized in the abstract of a merged history.
+const mergeAbstractLen = 4
+
+// forwardMsg is a message read to be forwarded.
+type forwardMsg struct {
+	conversationID string
+	msg            *sdkws.MsgData
+}
+
+// ForwardMsgs copies messages the user can read into another conversation, or merges them into a single history message,
+// the result is sent through SendMsg like a message sent by the user. The copies sent before a failed one are not taken back.
+func (m *msgServer) ForwardMsgs(ctx context.Context, req *msgext.ForwardMsgsReq) (*msgext.ForwardMsgsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	var msgs []forwardMsg
+	for _, source := range req.Sources {
+		sourceMsgs, err := m.getForwardMsgs(ctx, req.UserID, source)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, sourceMsgs...)
+	}
+	user, err := m.UserLocalCache.GetUserInfo(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	newMsg := func(contentType int32, content []byte) *sdkws.MsgData {
+		return &sdkws.MsgData{
+			SendID:           req.UserID,
+			RecvID:           req.RecvID,
+			GroupID:          req.GroupID,
+			ClientMsgID:      idutil.GetMsgIDByMD5(req.UserID),
+			SenderPlatformID: int32(constant.PlatformNameToID(mcontext.GetOpUserPlatform(ctx))),
+			SenderNickname:   user.Nickname,
+			SenderFaceURL:    user.FaceURL,
+			SessionType:      req.SessionType,
+			MsgFrom:          constant.UserMsgType,
+			ContentType:      contentType,
+			Content:          content,
+			CreateTime:       timeutil.GetCurrentTimestampByMill(),
+			Options:          make(map[string]bool),
+		}
+	}
+	var sends []*sdkws.MsgData
+	if req.Merge {
+		content, err := mergeForwardMsgs(req.Title, msgs)
+		if err != nil {
+			return nil, err
+		}
+		sends = append(sends, newMsg(constant.Merger, content))
+	} else {
+		for _, msg := range msgs {
+			data := newMsg(msg.msg.ContentType, msg.msg.Content)
+			data.AttachedInfo, err = msgext.SetForwardFrom("", &msgext.ForwardFrom{
+				ConversationID: msg.conversationID,
+				Seq:            msg.msg.Seq,
+				ServerMsgID:    msg.msg.ServerMsgID,
+				ClientMsgID:    msg.msg.ClientMsgID,
+				SendID:         msg.msg.SendID,
+				SendTime:       msg.msg.SendTime,
+			})
+			if err != nil {
+				return nil, err
+			}
+			sends = append(sends, data)
+		}
+	}
+	resp := &msgext.ForwardMsgsResp{Msgs: make([]*msgext.ForwardedMsg, 0, len(sends))}
+	for _, data := range sends {
+		sendResp, err := m.SendMsg(ctx, &pbmsg.SendMsgReq{MsgData: data})
+		if err != nil {
+			return nil, err
+		}
+		// The receiver does not receive messages from the user.
+		if sendResp == nil {
+			continue
+		}
+		resp.Msgs = append(resp.Msgs, &msgext.ForwardedMsg{
+			ServerMsgID: sendResp.ServerMsgID,
+			ClientMsgID: sendResp.ClientMsgID,
+			SendTime:    sendResp.SendTime,
+		})
+	}
+	return resp, nil
+}
+
+// getForwardMsgs reads the messages of a source with the rules of GetMsgBySeqs, they are ordered by seq.
+// Every message must be readable by userID and be a message a user sent, not a notification nor a revoked one.
+func (m *msgServer) getForwardMsgs(ctx context.Context, userID string, source *msgext.ForwardSource) ([]forwardMsg, error) {
+	seqs := datautil.Distinct(source.Seqs)
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, source.ConversationID, seqs)
+	if err != nil {
+		return nil, err
+	}
+	seqMsgs := make(map[int64]*sdkws.MsgData, len(msgs))
+	for _, msg := range msgs {
+		if msg != nil && msg.Status != constant.MsgDeleted {
+			seqMsgs[msg.Seq] = msg
+		}
+	}
+	res := make([]forwardMsg, 0, len(seqs))
+	for _, seq := range seqs {
+		msg, ok := seqMsgs[seq]
+		if !ok {
+			return nil, errs.ErrRecordNotFound.WrapMsg("msg not found", "conversationID", source.ConversationID, "seq", seq)
+		}
+		if msg.ContentType == constant.MsgRevokeNotification {
+			return nil, servererrs.ErrMsgAlreadyRevoke.WrapMsg("msg already revoke", "conversationID", source.ConversationID, "seq", seq)
+		}
+		if msg.ContentType >= constant.NotificationBegin && msg.ContentType <= constant.NotificationEnd {
+			return nil, errs.ErrArgs.WrapMsg("notification can not be forwarded", "conversationID", source.ConversationID, "seq", seq)
+		}
+		res = append(res, forwardMsg{conversationID: source.ConversationID, msg: msg})
+	}
+	if err := m.checkMsgReadable(ctx, userID, res[0].msg); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkMsgReadable checks that userID is a member of the conversation of msg.
+func (m *msgServer) checkMsgReadable(ctx context.Context, userID string, msg *sdkws.MsgData) error {
+	switch msg.SessionType {
+	case constant.SingleChatType:
+		if userID != msg.SendID && userID != msg.RecvID {
+			return errs.ErrNoPermission.WrapMsg("not in conversation")
+		}
+	case constant.ReadGroupChatType:
+		members, err := m.GroupLocalCache.GetGroupMemberIDMap(ctx, msg.GroupID)
+		if err != nil {
+			return err
+		}
+		if _, ok := members[userID]; !ok {
+			return errs.ErrNoPermission.WrapMsg("not in group", "groupID", msg.GroupID)
+		}
+	default:
+		return errs.ErrArgs.WrapMsg("msg sessionType not supported", "sessionType", msg.SessionType)
+	}
+	return nil
+}
+
+// mergeForwardMsgs returns the content of the merged history of msgs.
+func mergeForwardMsgs(title string, msgs []forwardMsg) ([]byte, error) {
+	elem := msgext.MergeElem{
+		Title:        title,
+		AbstractList: make([]string, 0, mergeAbstractLen),
+		MultiMessage: make([]*msgext.MergedMsg, 0, len(msgs)),
+	}
+	for _, msg := range msgs {
+		if len(elem.AbstractList) < mergeAbstractLen {
+			elem.AbstractList = append(elem.AbstractList, msg.msg.SenderNickname+": "+forwardAbstract(msg.msg))
+		}
+		elem.MultiMessage = append(elem.MultiMessage, &msgext.MergedMsg{
+			ClientMsgID:      msg.msg.ClientMsgID,
+			ServerMsgID:      msg.msg.ServerMsgID,
+			CreateTime:       msg.msg.CreateTime,
+			SendTime:         msg.msg.SendTime,
+			SessionType:      msg.msg.SessionType,
+			SendID:           msg.msg.SendID,
+			RecvID:           msg.msg.RecvID,
+			GroupID:          msg.msg.GroupID,
+			MsgFrom:          msg.msg.MsgFrom,
+			ContentType:      msg.msg.ContentType,
+			SenderPlatformID: msg.msg.SenderPlatformID,
+			SenderNickname:   msg.msg.SenderNickname,
+			SenderFaceURL:    msg.msg.SenderFaceURL,
+			Content:          string(msg.msg.Content),
+			Seq:              msg.msg.Seq,
+			Ex:               msg.msg.Ex,
+			ConversationID:   msg.conversationID,
+		})
+	}
+	content, err := json.Marshal(&elem)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "marshal merge elem failed")
+	}
+	return content, nil
+}
+
+// forwardAbstract returns the summary of msg in the abstract of a merged history.
+func forwardAbstract(msg *sdkws.MsgData) string {
+	if text := msgprocessor.GetText(msg); text != "" {
+		return text
+	}
+	switch msg.ContentType {
+	case constant.Picture:
+		return "[Picture]"
+	case constant.Voice:
+		return "[Voice]"
+	case constant.Video:
+		return "[Video]"
+	case constant.File:
+		return "[File]"
+	case constant.Card:
+		return "[Card]"
+	case constant.Location:
+		return "[Location]"
+	case constant.Merger:
+		return "[Chat History]"
+	default:
+		return "[Message]"
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/moderation"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	pbmsg "github.com/openimsdk/protocol/msg"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/utils/datautil"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// moderateMsg runs the moderation rules on a message about to be sent, it masks its text in place
+// and returns ErrMsgBlocked when a rule blocks it. The notifications and the messages whose sender is an app manager are not checked,
+// the sender is used rather than the caller since the app managers also send on behalf of the users.
+func (m *msgServer) moderateMsg(ctx context.Context, msg *sdkws.MsgData) (*moderation.Result, error) {
+	if m.Moderation == nil || msgprocessor.IsNotificationByMsg(msg) ||
+		datautil.Contain(msg.SendID, authverify.AdminUserID(ctx, m.config.Share.IMAdminUserID)...) {
+		return nil, nil
+	}
+	text := msgprocessor.GetText(msg)
+	res := m.Moderation.Check(ctx, &moderation.Msg{
+		SenderKey:   tenant.Prefix(ctx, msg.SendID),
+		ContentType: msg.ContentType,
+		Text:        text,
+	})
+	switch {
+	case res.Action == moderation.ActionBlock:
+		reasons := res.Reasons(moderation.ActionBlock)
+		log.ZInfo(ctx, "msg blocked by moderation", "sendID", msg.SendID, "clientMsgID", msg.ClientMsgID, "reasons", reasons)
+		return nil, servererrs.ErrMsgBlocked.WrapMsg(strings.Join(reasons, "; "))
+	case res.Masked() && res.Text != text:
+		if err := msgprocessor.SetText(msg, res.Text); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// addModerationReview queues a sent message flagged by the moderation for the admins to review.
+func (m *msgServer) addModerationReview(ctx context.Context, msg *sdkws.MsgData, res *moderation.Result) {
+	if res == nil || !res.Flagged() {
+		return
+	}
+	review := &model.ModerationReview{
+		ReviewID:       GetMsgID(msg.SendID),
+		ConversationID: msgprocessor.GetConversationIDByMsg(msg),
+		ClientMsgID:    msg.ClientMsgID,
+		ServerMsgID:    msg.ServerMsgID,
+		SendID:         msg.SendID,
+		SessionType:    msg.SessionType,
+		ContentType:    msg.ContentType,
+		Content:        string(msg.Content),
+		Hits: datautil.Slice(res.Hits, func(hit *moderation.Hit) model.ModerationHit {
+			return model.ModerationHit{Rule: hit.Rule, Action: hit.Action.String(), Reason: hit.Reason}
+		}),
+		Status:     msgext.ModerationReviewPending,
+		CreateTime: time.Now(),
+	}
+	if err := m.ModerationDatabase.CreateModerationReview(ctx, review); err != nil {
+		log.ZError(ctx, "add moderation review failed", err, "conversationID", review.ConversationID, "serverMsgID", review.ServerMsgID)
+	}
+}
+
+func (m *msgServer) GetModerationReviews(ctx context.Context, req *msgext.GetModerationReviewsReq) (*msgext.GetModerationReviewsResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	total, reviews, err := m.ModerationDatabase.PageModerationReviews(ctx, req.Status, req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.GetModerationReviewsResp{
+		Total: total,
+		Reviews: datautil.Slice(reviews, func(review *model.ModerationReview) *msgext.ModerationReview {
+			var reviewTime int64
+			if !review.ReviewTime.IsZero() {
+				reviewTime = review.ReviewTime.UnixMilli()
+			}
+			return &msgext.ModerationReview{
+				ReviewID:       review.ReviewID,
+				ConversationID: review.ConversationID,
+				ClientMsgID:    review.ClientMsgID,
+				ServerMsgID:    review.ServerMsgID,
+				SendID:         review.SendID,
+				SessionType:    review.SessionType,
+				ContentType:    review.ContentType,
+				Content:        review.Content,
+				Hits: datautil.Slice(review.Hits, func(hit model.ModerationHit) *msgext.ModerationHit {
+					return &msgext.ModerationHit{Rule: hit.Rule, Action: hit.Action, Reason: hit.Reason}
+				}),
+				Status:         review.Status,
+				ReviewerUserID: review.ReviewerUserID,
+				ReviewTime:     reviewTime,
+				CreateTime:     review.CreateTime.UnixMilli(),
+			}
+		}),
+	}, nil
+}
+
+// ReviewModerationMsg approves a flagged message or removes it, the removed messages are revoked by the admin.
+func (m *msgServer) ReviewModerationMsg(ctx context.Context, req *msgext.ReviewModerationMsgReq) (*msgext.ReviewModerationMsgResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	review, err := m.ModerationDatabase.TakeModerationReview(ctx, req.ReviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != msgext.ModerationReviewPending {
+		return nil, errs.ErrArgs.WrapMsg("msg already reviewed", "reviewID", req.ReviewID, "status", review.Status)
+	}
+	opUserID := mcontext.GetOpUserID(ctx)
+	if req.Status == msgext.ModerationReviewRemoved {
+		seq, err := m.MsgDatabase.GetMsgSeqByServerMsgID(ctx, review.ConversationID, review.ServerMsgID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = m.RevokeMsg(ctx, &pbmsg.RevokeMsgReq{ConversationID: review.ConversationID, Seq: seq, UserID: opUserID})
+		if err != nil && !servererrs.ErrMsgAlreadyRevoke.Is(err) {
+			return nil, err
+		}
+	}
+	err = m.ModerationDatabase.ReviewModerationMsg(ctx, req.ReviewID, msgext.ModerationReviewPending, req.Status, opUserID, time.Now())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errs.ErrArgs.WrapMsg("msg already reviewed", "reviewID", req.ReviewID)
+		}
+		return nil, err
+	}
+	return &msgext.ReviewModerationMsgResp{}, nil
+}
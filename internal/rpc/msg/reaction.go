@@ -0,0 +1,102 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+)
+
+func (m *msgServer) AddReaction(ctx context.Context, req *msgext.AddReactionReq) (*msgext.AddReactionResp, error) {
+	if err := m.setReaction(ctx, req.ConversationID, req.Seq, req.UserID, req.Reaction, true); err != nil {
+		return nil, err
+	}
+	return &msgext.AddReactionResp{}, nil
+}
+
+func (m *msgServer) RemoveReaction(ctx context.Context, req *msgext.RemoveReactionReq) (*msgext.RemoveReactionResp, error) {
+	if err := m.setReaction(ctx, req.ConversationID, req.Seq, req.UserID, req.Reaction, false); err != nil {
+		return nil, err
+	}
+	return &msgext.RemoveReactionResp{}, nil
+}
+
+// setReaction adds or removes the reaction of userID to a message of the conversation,
+// the other members of the conversation are notified when the reactions changed.
+func (m *msgServer) setReaction(ctx context.Context, conversationID string, seq int64, userID string, reaction string, add bool) error {
+	if err := authverify.CheckAccessV3(ctx, userID, m.config.Share.IMAdminUserID); err != nil {
+		return err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, []int64{seq})
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 || msgs[0] == nil || msgs[0].Status == constant.MsgDeleted {
+		return errs.ErrRecordNotFound.WrapMsg("msg not found")
+	}
+	msgData := msgs[0]
+	if msgData.ContentType == constant.MsgRevokeNotification {
+		return servererrs.ErrMsgAlreadyRevoke.WrapMsg("msg already revoke")
+	}
+	if msgData.ContentType >= constant.NotificationBegin && msgData.ContentType <= constant.NotificationEnd {
+		return errs.ErrArgs.WrapMsg("notification can not be reacted to", "contentType", msgData.ContentType)
+	}
+	var recvID string
+	switch msgData.SessionType {
+	case constant.SingleChatType:
+		switch userID {
+		case msgData.SendID:
+			recvID = msgData.RecvID
+		case msgData.RecvID:
+			recvID = msgData.SendID
+		default:
+			return errs.ErrNoPermission.WrapMsg("not in conversation")
+		}
+	case constant.ReadGroupChatType:
+		members, err := m.GroupLocalCache.GetGroupMemberIDMap(ctx, msgData.GroupID)
+		if err != nil {
+			return err
+		}
+		if _, ok := members[userID]; !ok {
+			return errs.ErrNoPermission.WrapMsg("not in group", "groupID", msgData.GroupID)
+		}
+		recvID = msgData.GroupID
+	default:
+		return errs.ErrArgs.WrapMsg("msg sessionType not supported", "sessionType", msgData.SessionType)
+	}
+	changed, err := m.MsgDatabase.SetMsgReaction(ctx, conversationID, seq, reaction, userID, add)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	tips := msgext.ReactionTips{
+		ConversationID: conversationID,
+		Seq:            seq,
+		ClientMsgID:    msgData.ClientMsgID,
+		SessionType:    msgData.SessionType,
+		UserID:         userID,
+		Reaction:       reaction,
+		IsAdd:          add,
+	}
+	m.notificationSender.NotificationWithSessionType(ctx, userID, recvID, msgext.MsgReactionNotification, msgData.SessionType, &tips)
+	return nil
+}
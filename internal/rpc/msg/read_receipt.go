@@ -0,0 +1,193 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultMaxPushReadCountMsgNum is used when groupReadReceipt.maxPushMsgNum is not configured.
+	defaultMaxPushReadCountMsgNum = 20
+	// maxInvalidReadReceiptNum bounds the cached receipts deleted on one read,
+	// the receipts of older msgs expire after groupReadReceipt.cacheExpire.
+	maxInvalidReadReceiptNum = 1000
+
+	readReceiptWorkerCount = 4
+	readReceiptBufferSize  = 1000
+)
+
+// GetGroupMsgReadMembers returns which members of the group have read the message, only the sender
+// of the message and app managers can get it.
+func (m *msgServer) GetGroupMsgReadMembers(ctx context.Context, req *msgext.GetGroupMsgReadMembersReq) (*msgext.GetGroupMsgReadMembersResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, req.ConversationID, []int64{req.Seq})
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0] == nil || msgs[0].SendID == "" || msgs[0].Status == constant.MsgDeleted {
+		return nil, errs.ErrRecordNotFound.WrapMsg("msg not found")
+	}
+	msgData := msgs[0]
+	if msgData.SessionType != constant.ReadGroupChatType {
+		return nil, errs.ErrArgs.WrapMsg("msg is not a group msg", "sessionType", msgData.SessionType)
+	}
+	if msgData.SendID != req.UserID && !authverify.IsAppManagerUid(ctx, m.config.Share.IMAdminUserID) {
+		return nil, errs.ErrNoPermission.WrapMsg("only the sender can get the read members")
+	}
+	receipt, err := m.getGroupReadReceipt(ctx, req.ConversationID, msgData)
+	if err != nil {
+		return nil, err
+	}
+	pageNumber, showNumber := int(req.Pagination.GetPageNumber()), int(req.Pagination.GetShowNumber())
+	return &msgext.GetGroupMsgReadMembersResp{
+		ReadCount:     int64(len(receipt.ReadUserIDs)),
+		UnreadCount:   int64(len(receipt.UnreadUserIDs)),
+		ReadUserIDs:   datautil.Paginate(receipt.ReadUserIDs, pageNumber, showNumber),
+		UnreadUserIDs: datautil.Paginate(receipt.UnreadUserIDs, pageNumber, showNumber),
+	}, nil
+}
+
+// getGroupReadReceipt splits the current group members by their has read seq of the conversation,
+// the result is cached for groupReadReceipt.cacheExpire seconds so that paging through it is cheap.
+func (m *msgServer) getGroupReadReceipt(ctx context.Context, conversationID string, msgData *sdkws.MsgData) (*model.GroupReadReceipt, error) {
+	expire := time.Duration(m.config.RpcConfig.GroupReadReceipt.CacheExpire) * time.Second
+	if expire > 0 {
+		receipt, err := m.MsgDatabase.GetGroupReadReceipt(ctx, conversationID, msgData.Seq)
+		if err == nil {
+			return receipt, nil
+		}
+		if errs.Unwrap(err) != redis.Nil {
+			return nil, err
+		}
+	}
+	memberIDs, err := m.GroupLocalCache.GetGroupMemberIDs(ctx, msgData.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	hasReadSeqs, err := m.MsgDatabase.GetUsersHasReadSeq(ctx, conversationID, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	readUserIDs, unreadUserIDs := msgext.SplitReadMembers(memberIDs, hasReadSeqs, msgData.Seq, msgData.SendID)
+	receipt := &model.GroupReadReceipt{ReadUserIDs: readUserIDs, UnreadUserIDs: unreadUserIDs}
+	if expire > 0 {
+		if err := m.MsgDatabase.SetGroupReadReceipt(ctx, conversationID, msgData.Seq, receipt, expire); err != nil {
+			log.ZWarn(ctx, "set group read receipt cache failed", err, "conversationID", conversationID, "seq", msgData.Seq)
+		}
+	}
+	return receipt, nil
+}
+
+// groupMsgsRead handles userID reading the group msgs in (fromSeq, toSeq]. The cached receipts of the msgs are
+// deleted, and their read counts are pushed to the senders on the read receipt queue so that the read does not wait.
+func (m *msgServer) groupMsgsRead(ctx context.Context, conversationID string, groupID string, userID string, fromSeq int64, toSeq int64) {
+	if toSeq <= fromSeq {
+		return
+	}
+	if m.config.RpcConfig.GroupReadReceipt.CacheExpire > 0 {
+		seqs := make([]int64, 0, min(toSeq-fromSeq, maxInvalidReadReceiptNum))
+		for seq := max(fromSeq, toSeq-maxInvalidReadReceiptNum) + 1; seq <= toSeq; seq++ {
+			seqs = append(seqs, seq)
+		}
+		if err := m.MsgDatabase.DelGroupReadReceipts(ctx, conversationID, seqs); err != nil {
+			log.ZWarn(ctx, "delete group read receipt cache failed", err, "conversationID", conversationID, "fromSeq", fromSeq, "toSeq", toSeq)
+		}
+	}
+	if !m.config.RpcConfig.GroupReadReceipt.PushReadCount {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	err := m.readReceiptQueue.PushCtx(ctx, func() {
+		m.pushGroupMsgReadCount(ctx, conversationID, groupID, userID, fromSeq, toSeq)
+	})
+	if err != nil {
+		log.ZWarn(ctx, "push group msg read count task failed", err, "conversationID", conversationID)
+	}
+}
+
+// pushGroupMsgReadCount pushes the read count of the group messages in (fromSeq, toSeq] which userID just read
+// to their senders, the messages of one sender are aggregated into one notification.
+func (m *msgServer) pushGroupMsgReadCount(ctx context.Context, conversationID string, groupID string, userID string, fromSeq int64, toSeq int64) {
+	maxNum := int64(m.config.RpcConfig.GroupReadReceipt.MaxPushMsgNum)
+	if maxNum <= 0 {
+		maxNum = defaultMaxPushReadCountMsgNum
+	}
+	if toSeq-fromSeq > maxNum {
+		fromSeq = toSeq - maxNum
+	}
+	seqs := make([]int64, 0, toSeq-fromSeq)
+	for seq := fromSeq + 1; seq <= toSeq; seq++ {
+		seqs = append(seqs, seq)
+	}
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, seqs)
+	if err != nil {
+		log.ZWarn(ctx, "get read group msgs failed", err, "conversationID", conversationID, "seqs", seqs)
+		return
+	}
+	msgs = datautil.Filter(msgs, func(msgData *sdkws.MsgData) (*sdkws.MsgData, bool) {
+		if msgData == nil || msgData.SendID == "" || msgData.SendID == userID || msgData.Status == constant.MsgDeleted {
+			return nil, false
+		}
+		if msgData.ContentType >= constant.NotificationBegin && msgData.ContentType <= constant.NotificationEnd {
+			return nil, false
+		}
+		return msgData, true
+	})
+	if len(msgs) == 0 {
+		return
+	}
+	memberIDs, err := m.GroupLocalCache.GetGroupMemberIDs(ctx, groupID)
+	if err != nil {
+		log.ZWarn(ctx, "get group member ids failed", err, "groupID", groupID)
+		return
+	}
+	hasReadSeqs, err := m.MsgDatabase.GetUsersHasReadSeq(ctx, conversationID, memberIDs)
+	if err != nil {
+		log.ZWarn(ctx, "get members has read seq failed", err, "conversationID", conversationID)
+		return
+	}
+	senderCounts := make(map[string][]*msgext.GroupMsgReadCount)
+	for _, msgData := range msgs {
+		readUserIDs, unreadUserIDs := msgext.SplitReadMembers(memberIDs, hasReadSeqs, msgData.Seq, msgData.SendID)
+		senderCounts[msgData.SendID] = append(senderCounts[msgData.SendID], &msgext.GroupMsgReadCount{
+			Seq:         msgData.Seq,
+			ClientMsgID: msgData.ClientMsgID,
+			ReadCount:   int64(len(readUserIDs)),
+			UnreadCount: int64(len(unreadUserIDs)),
+		})
+	}
+	for sendID, counts := range senderCounts {
+		tips := &msgext.GroupMsgReadCountTips{
+			ConversationID: conversationID,
+			GroupID:        groupID,
+			Msgs:           counts,
+		}
+		m.notificationSender.NotificationWithSessionType(ctx, sendID, sendID, msgext.GroupMsgReadCountNotification, constant.SingleChatType, tips)
+	}
+}
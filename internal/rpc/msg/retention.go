@@ -0,0 +1,226 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/retention"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// The operations recorded with the deletions skipped by a retention policy.
+const (
+	retentionOpClearMsg               = "ClearMsg"
+	retentionOpDestructMsgs           = "DestructMsgs"
+	retentionOpDeleteMsgPhysical      = "DeleteMsgPhysical"
+	retentionOpDeleteMsgPhysicalBySeq = "DeleteMsgPhysicalBySeq"
+	retentionOpDeleteMsgs             = "DeleteMsgs"
+	retentionOpClearConversationsMsg  = "ClearConversationsMsg"
+	retentionOpUserClearAllMsg        = "UserClearAllMsg"
+)
+
+func (m *msgServer) SetRetentionPolicy(ctx context.Context, req *msgext.SetRetentionPolicyReq) (*msgext.SetRetentionPolicyResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	policy := &model.RetentionPolicy{
+		TargetType:     req.TargetType,
+		TargetID:       req.TargetID,
+		RetainDays:     req.RetainDays,
+		LegalHold:      req.LegalHold,
+		HoldReason:     req.HoldReason,
+		OperatorUserID: mcontext.GetOpUserID(ctx),
+		UpdateTime:     time.Now(),
+	}
+	if err := m.RetentionDatabase.SetRetentionPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return &msgext.SetRetentionPolicyResp{}, nil
+}
+
+func (m *msgServer) DeleteRetentionPolicy(ctx context.Context, req *msgext.DeleteRetentionPolicyReq) (*msgext.DeleteRetentionPolicyResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	if err := m.RetentionDatabase.DeleteRetentionPolicy(ctx, req.TargetType, req.TargetID); err != nil {
+		return nil, err
+	}
+	return &msgext.DeleteRetentionPolicyResp{}, nil
+}
+
+func (m *msgServer) GetRetentionPolicies(ctx context.Context, req *msgext.GetRetentionPoliciesReq) (*msgext.GetRetentionPoliciesResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	total, policies, err := m.RetentionDatabase.PageRetentionPolicies(ctx, req.TargetType, req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.GetRetentionPoliciesResp{
+		Total: total,
+		Policies: datautil.Slice(policies, func(policy *model.RetentionPolicy) *msgext.RetentionPolicy {
+			return &msgext.RetentionPolicy{
+				TargetType:     policy.TargetType,
+				TargetID:       policy.TargetID,
+				RetainDays:     policy.RetainDays,
+				LegalHold:      policy.LegalHold,
+				HoldReason:     policy.HoldReason,
+				OperatorUserID: policy.OperatorUserID,
+				UpdateTime:     policy.UpdateTime.UnixMilli(),
+			}
+		}),
+	}, nil
+}
+
+func (m *msgServer) GetRetentionSkips(ctx context.Context, req *msgext.GetRetentionSkipsReq) (*msgext.GetRetentionSkipsResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	total, skips, err := m.RetentionDatabase.PageRetentionSkips(ctx, req.ConversationID, req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	return &msgext.GetRetentionSkipsResp{
+		Total: total,
+		Skips: datautil.Slice(skips, func(skip *model.RetentionSkip) *msgext.RetentionSkip {
+			return &msgext.RetentionSkip{
+				ConversationID: skip.ConversationID,
+				Operation:      skip.Operation,
+				Reason:         skip.Reason,
+				OperatorUserID: skip.OperatorUserID,
+				CreateTime:     skip.CreateTime.UnixMilli(),
+			}
+		}),
+	}, nil
+}
+
+// filterHeldConversations returns conversationIDs without the conversations under a legal hold,
+// those are recorded as skipped by operation.
+func (m *msgServer) filterHeldConversations(ctx context.Context, operation string, conversationIDs []string) ([]string, error) {
+	if len(conversationIDs) == 0 {
+		return conversationIDs, nil
+	}
+	rules, err := m.RetentionDatabase.GetRetentionRules(ctx, conversationIDs)
+	if err != nil {
+		return nil, err
+	}
+	released := make([]string, 0, len(conversationIDs))
+	skipped := make(map[string]retention.Rule)
+	for _, conversationID := range conversationIDs {
+		if rule := rules[conversationID]; rule.LegalHold {
+			skipped[conversationID] = rule
+			continue
+		}
+		released = append(released, conversationID)
+	}
+	m.addRetentionSkips(ctx, operation, skipped)
+	return released, nil
+}
+
+// checkConversationNotHeld returns an error when the messages of conversationID are under a legal hold,
+// the deletion is recorded as skipped by operation.
+func (m *msgServer) checkConversationNotHeld(ctx context.Context, operation string, conversationID string) error {
+	released, err := m.filterHeldConversations(ctx, operation, []string{conversationID})
+	if err != nil {
+		return err
+	}
+	if len(released) == 0 {
+		return errs.ErrNoPermission.WrapMsg("the messages of the conversation are under a legal hold", "conversationID", conversationID)
+	}
+	return nil
+}
+
+// addRetentionSkips records the deletions skipped by operation, it only logs when they can not be stored
+// so the deletions which are allowed still go on.
+func (m *msgServer) addRetentionSkips(ctx context.Context, operation string, skipped map[string]retention.Rule) {
+	if len(skipped) == 0 {
+		return
+	}
+	now := time.Now()
+	opUserID := mcontext.GetOpUserID(ctx)
+	skips := make([]*model.RetentionSkip, 0, len(skipped))
+	for conversationID, rule := range skipped {
+		skips = append(skips, &model.RetentionSkip{
+			ConversationID: conversationID,
+			Operation:      operation,
+			Reason:         rule.Reason(),
+			OperatorUserID: opUserID,
+			CreateTime:     now,
+		})
+	}
+	log.ZInfo(ctx, "deletion skipped by retention policy", "operation", operation, "conversationIDs", datautil.Keys(skipped))
+	if err := m.RetentionDatabase.AddRetentionSkips(ctx, skips); err != nil {
+		log.ZWarn(ctx, "add retention skips failed", err, "operation", operation)
+	}
+}
+
+// retentionPolicyConversationIDs returns the conversations the policy is set on.
+func (m *msgServer) retentionPolicyConversationIDs(ctx context.Context, policy *model.RetentionPolicy) ([]string, error) {
+	switch policy.TargetType {
+	case retention.TargetConversation:
+		return []string{policy.TargetID}, nil
+	case retention.TargetGroup:
+		return []string{msgprocessor.GetConversationIDBySessionType(constant.ReadGroupChatType, policy.TargetID)}, nil
+	case retention.TargetUser:
+		return m.ConversationLocalCache.GetConversationIDs(ctx, policy.TargetID)
+	default:
+		return nil, nil
+	}
+}
+
+// clearShortRetention clears the conversations whose policies keep their messages for less time than the global
+// retention, the global clear does not find their messages until they are as old as globalCutoff.
+func (m *msgServer) clearShortRetention(ctx context.Context, resolver *retention.Resolver, policies []*model.RetentionPolicy, now time.Time, globalCutoff int64, skipped map[string]retention.Rule) {
+	cleared := make(map[string]struct{})
+	for _, policy := range policies {
+		if (retention.Rule{RetainDays: policy.RetainDays}).Cutoff(now, globalCutoff) <= globalCutoff {
+			continue
+		}
+		conversationIDs, err := m.retentionPolicyConversationIDs(ctx, policy)
+		if err != nil {
+			log.ZWarn(ctx, "get retention policy conversations failed", err, "targetType", policy.TargetType, "targetID", policy.TargetID)
+			continue
+		}
+		for _, conversationID := range conversationIDs {
+			if _, ok := cleared[conversationID]; ok {
+				continue
+			}
+			cleared[conversationID] = struct{}{}
+			rule := resolver.Resolve(conversationID)
+			if rule.LegalHold {
+				skipped[conversationID] = rule
+				continue
+			}
+			if rule.Cutoff(now, globalCutoff) <= globalCutoff {
+				continue
+			}
+			if err := m.MsgDatabase.DeleteConversationMsgsAndSetMinSeq(ctx, conversationID, rule.RetainDays*24*60*60); err != nil {
+				log.ZWarn(ctx, "clear short retention conversation failed", err, "conversationID", conversationID, "retainDays", rule.RetainDays)
+				continue
+			}
+			m.unindexDeletedMsgs(ctx, conversationID)
+		}
+	}
+}
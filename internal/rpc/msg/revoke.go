@@ -102,11 +102,12 @@ func (m *msgServer) RevokeMsg(ctx context.Context, req *msg.RevokeMsgReq) (*msg.
 	if err != nil {
 		return nil, err
 	}
+	m.unindexMsgs(ctx, req.ConversationID, []int64{req.Seq})
 	revokerUserID := mcontext.GetOpUserID(ctx)
 	var flag bool
 
-	if len(m.config.Share.IMAdminUserID) > 0 {
-		flag = datautil.Contain(revokerUserID, m.config.Share.IMAdminUserID...)
+	if imAdminUserID := authverify.AdminUserID(ctx, m.config.Share.IMAdminUserID); len(imAdminUserID) > 0 {
+		flag = datautil.Contain(revokerUserID, imAdminUserID...)
 	}
 	tips := sdkws.RevokeMsgTips{
 		RevokerUserID:  revokerUserID,
@@ -0,0 +1,209 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	pbmsg "github.com/openimsdk/protocol/msg"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/idutil"
+	"google.golang.org/protobuf/proto"
+)
+
+// scheduledMsgClaimTimeout is how long a scheduled message claimed by a cron task that did not finish it
+// waits before it is claimed again.
+const scheduledMsgClaimTimeout = time.Minute * 5
+
+// ScheduleMsg stores a message which is sent through SendMsg at req.SendTime by the cron task,
+// it is verified now so the sender knows early when it can not be sent.
+func (m *msgServer) ScheduleMsg(ctx context.Context, req *msgext.ScheduleMsgReq) (*msgext.ScheduleMsgResp, error) {
+	msgData := req.MsgReq.MsgData
+	if err := authverify.CheckAccessV3(ctx, msgData.SendID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	if err := m.checkScheduleSendTime(req.SendTime); err != nil {
+		return nil, err
+	}
+	switch msgData.SessionType {
+	case constant.SingleChatType, constant.ReadGroupChatType:
+	default:
+		return nil, errs.ErrArgs.WrapMsg("sessionType can not be scheduled", "sessionType", msgData.SessionType)
+	}
+	if err := m.messageVerification(ctx, req.MsgReq); err != nil {
+		return nil, err
+	}
+	// Checked again when the message goes out, this rejects a blocked message early and stores the masked text.
+	if _, err := m.moderateMsg(ctx, msgData); err != nil {
+		return nil, err
+	}
+	if msgData.ClientMsgID == "" {
+		msgData.ClientMsgID = idutil.GetMsgIDByMD5(msgData.SendID)
+	}
+	// The send time is set by SendMsg when the message goes out.
+	msgData.SendTime = 0
+	data, err := proto.Marshal(req.MsgReq)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "marshal SendMsgReq failed")
+	}
+	scheduled := &model.ScheduledMsg{
+		ScheduleID: GetMsgID(msgData.SendID),
+		SendID:     msgData.SendID,
+		MsgReq:     data,
+		SendTime:   time.UnixMilli(req.SendTime),
+		Status:     msgext.ScheduledMsgPending,
+		CreateTime: time.Now(),
+	}
+	if err := m.ScheduledMsgDatabase.CreateScheduledMsg(ctx, scheduled); err != nil {
+		return nil, err
+	}
+	return &msgext.ScheduleMsgResp{ScheduleID: scheduled.ScheduleID}, nil
+}
+
+func (m *msgServer) GetScheduledMsgs(ctx context.Context, req *msgext.GetScheduledMsgsReq) (*msgext.GetScheduledMsgsResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	total, scheduledMsgs, err := m.ScheduledMsgDatabase.FindScheduledMsgs(ctx, req.UserID, req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.GetScheduledMsgsResp{Total: total, Msgs: make([]*msgext.ScheduledMsg, 0, len(scheduledMsgs))}
+	for _, scheduled := range scheduledMsgs {
+		var msgReq pbmsg.SendMsgReq
+		if err := proto.Unmarshal(scheduled.MsgReq, &msgReq); err != nil {
+			return nil, errs.WrapMsg(err, "unmarshal SendMsgReq failed", "scheduleID", scheduled.ScheduleID)
+		}
+		resp.Msgs = append(resp.Msgs, &msgext.ScheduledMsg{
+			ScheduleID: scheduled.ScheduleID,
+			SendTime:   scheduled.SendTime.UnixMilli(),
+			Status:     scheduled.Status,
+			ErrMsg:     scheduled.ErrMsg,
+			CreateTime: scheduled.CreateTime.UnixMilli(),
+			MsgData:    msgReq.MsgData,
+		})
+	}
+	return resp, nil
+}
+
+// UpdateScheduledMsg changes the send time or the content of a scheduled message which is not being sent,
+// a message which failed to be sent is scheduled again.
+func (m *msgServer) UpdateScheduledMsg(ctx context.Context, req *msgext.UpdateScheduledMsgReq) (*msgext.UpdateScheduledMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	update := map[string]any{"status": msgext.ScheduledMsgPending, "err_msg": ""}
+	if req.SendTime != 0 {
+		if err := m.checkScheduleSendTime(req.SendTime); err != nil {
+			return nil, err
+		}
+		update["send_time"] = time.UnixMilli(req.SendTime)
+	}
+	if req.Content != nil {
+		scheduled, err := m.ScheduledMsgDatabase.TakeScheduledMsg(ctx, req.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if scheduled.SendID != req.UserID {
+			return nil, errs.ErrNoPermission.WrapMsg("not the sender of the scheduled msg")
+		}
+		var msgReq pbmsg.SendMsgReq
+		if err := proto.Unmarshal(scheduled.MsgReq, &msgReq); err != nil {
+			return nil, errs.WrapMsg(err, "unmarshal SendMsgReq failed", "scheduleID", scheduled.ScheduleID)
+		}
+		msgReq.MsgData.Content = []byte(*req.Content)
+		if _, err := m.moderateMsg(ctx, msgReq.MsgData); err != nil {
+			return nil, err
+		}
+		data, err := proto.Marshal(&msgReq)
+		if err != nil {
+			return nil, errs.WrapMsg(err, "marshal SendMsgReq failed")
+		}
+		update["msg_req"] = data
+	}
+	ok, err := m.ScheduledMsgDatabase.UpdateScheduledMsg(ctx, req.ScheduleID, req.UserID, update)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrRecordNotFound.WrapMsg("scheduled msg not found or being sent", "scheduleID", req.ScheduleID)
+	}
+	return &msgext.UpdateScheduledMsgResp{}, nil
+}
+
+func (m *msgServer) CancelScheduledMsg(ctx context.Context, req *msgext.CancelScheduledMsgReq) (*msgext.CancelScheduledMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	ok, err := m.ScheduledMsgDatabase.DeleteScheduledMsg(ctx, req.ScheduleID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errs.ErrRecordNotFound.WrapMsg("scheduled msg not found or being sent", "scheduleID", req.ScheduleID)
+	}
+	return &msgext.CancelScheduledMsgResp{}, nil
+}
+
+// ClaimDueScheduledMsgs is called by the cron task, which sends the claimed messages with SendMsg
+// and reports the results with FinishScheduledMsg.
+func (m *msgServer) ClaimDueScheduledMsgs(ctx context.Context, req *msgext.ClaimDueScheduledMsgsReq) (*msgext.ClaimDueScheduledMsgsResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	scheduledMsgs, err := m.ScheduledMsgDatabase.ClaimDueScheduledMsgs(ctx, scheduledMsgClaimTimeout, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &msgext.ClaimDueScheduledMsgsResp{Msgs: make([]*msgext.DueScheduledMsg, 0, len(scheduledMsgs))}
+	for _, scheduled := range scheduledMsgs {
+		var msgReq pbmsg.SendMsgReq
+		if err := proto.Unmarshal(scheduled.MsgReq, &msgReq); err != nil || msgReq.MsgData == nil {
+			if err := m.ScheduledMsgDatabase.FinishScheduledMsg(ctx, scheduled.ScheduleID, "invalid scheduled msg"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		resp.Msgs = append(resp.Msgs, &msgext.DueScheduledMsg{ScheduleID: scheduled.ScheduleID, MsgReq: &msgReq})
+	}
+	return resp, nil
+}
+
+func (m *msgServer) FinishScheduledMsg(ctx context.Context, req *msgext.FinishScheduledMsgReq) (*msgext.FinishScheduledMsgResp, error) {
+	if err := authverify.CheckAdmin(ctx, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	if err := m.ScheduledMsgDatabase.FinishScheduledMsg(ctx, req.ScheduleID, req.ErrMsg); err != nil {
+		return nil, err
+	}
+	return &msgext.FinishScheduledMsgResp{}, nil
+}
+
+func (m *msgServer) checkScheduleSendTime(sendTime int64) error {
+	now := time.Now()
+	if sendTime <= now.UnixMilli() {
+		return errs.ErrArgs.WrapMsg("sendTime must be in the future", "sendTime", sendTime)
+	}
+	maxDelay := m.config.RpcConfig.ScheduledMsg.MaxDelay
+	if maxDelay > 0 && sendTime > now.Add(time.Duration(maxDelay)*time.Second).UnixMilli() {
+		return errs.ErrArgs.WrapMsg("sendTime is too far in the future", "maxDelay", maxDelay)
+	}
+	return nil
+}
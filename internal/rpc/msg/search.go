@@ -0,0 +1,154 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgsearch"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// maxSearchHits bounds the hits of a search which are checked against the messages of the user, the total of
+// a search is at most it.
+const maxSearchHits = 1000
+
+// FullTextSearchMsg searches the conversations of the user in the full text index. The hits are checked against
+// the messages as the user pulls them before they are paged, so the ones out of the seq range of the user, the
+// deleted and revoked ones, and the ones edited so that the keyword no longer matches, are neither returned nor
+// counted.
+func (m *msgServer) FullTextSearchMsg(ctx context.Context, req *msgext.FullTextSearchMsgReq) (*msgext.FullTextSearchMsgResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, m.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	if m.MsgSearch == nil {
+		return nil, errs.ErrInternalServer.WrapMsg("full text search is not enabled")
+	}
+	conversationIDs, err := m.ConversationLocalCache.GetConversationIDs(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.ConversationIDs) > 0 {
+		owned := datautil.SliceSet(conversationIDs)
+		conversationIDs = datautil.Filter(req.ConversationIDs, func(conversationID string) (string, bool) {
+			_, ok := owned[conversationID]
+			return conversationID, ok
+		})
+	}
+	res, err := m.MsgSearch.Search(ctx, &msgsearch.Query{
+		TenantID:        tenant.GetTenantID(ctx),
+		Keyword:         req.Keyword,
+		ConversationIDs: conversationIDs,
+		SendID:          req.SendID,
+		ContentTypes:    req.ContentTypes,
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		Limit:           maxSearchHits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	conversationSeqs := make(map[string][]int64)
+	for _, hit := range res.Hits {
+		conversationSeqs[hit.ConversationID] = append(conversationSeqs[hit.ConversationID], hit.Seq)
+	}
+	conversationMsgs := make(map[string]map[int64]*sdkws.MsgData, len(conversationSeqs))
+	for conversationID, seqs := range conversationSeqs {
+		// GetMsgBySeqs returns only the msgs between the min and max seq of the user in the conversation.
+		_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, req.UserID, conversationID, seqs)
+		if err != nil {
+			return nil, err
+		}
+		conversationMsgs[conversationID] = datautil.SliceToMap(msgs, func(msg *sdkws.MsgData) int64 {
+			return msg.Seq
+		})
+	}
+	searched := make([]*msgext.SearchedMsg, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		msgData := conversationMsgs[hit.ConversationID][hit.Seq]
+		if msgData == nil || msgData.SendID == "" || msgData.Status == constant.MsgDeleted {
+			continue
+		}
+		if !msgsearch.Match(req.Keyword, msgprocessor.GetSearchText(msgData)) {
+			continue
+		}
+		searched = append(searched, &msgext.SearchedMsg{
+			ConversationID: hit.ConversationID,
+			Score:          hit.Score,
+			MsgData:        msgData,
+		})
+	}
+	return &msgext.FullTextSearchMsgResp{
+		Total: int64(len(searched)),
+		Msgs:  datautil.Paginate(searched, int(req.Pagination.GetPageNumber()), int(req.Pagination.GetShowNumber())),
+	}, nil
+}
+
+// unindexMsgs removes the msgs deleted for everyone from the full text index. A failure is only logged,
+// the search drops the hits of deleted msgs anyway.
+func (m *msgServer) unindexMsgs(ctx context.Context, conversationID string, seqs []int64) {
+	if m.MsgSearch == nil || len(seqs) == 0 {
+		return
+	}
+	if err := m.MsgSearch.Delete(ctx, tenant.GetTenantID(ctx), conversationID, seqs); err != nil {
+		log.ZWarn(ctx, "delete msgs from search index failed", err, "conversationID", conversationID, "seqs", seqs)
+	}
+}
+
+// unindexMsgsBefore removes the msgs of the conversation below minSeq from the full text index.
+func (m *msgServer) unindexMsgsBefore(ctx context.Context, conversationID string, minSeq int64) {
+	if m.MsgSearch == nil {
+		return
+	}
+	if err := m.MsgSearch.DeleteBefore(ctx, tenant.GetTenantID(ctx), conversationID, minSeq); err != nil {
+		log.ZWarn(ctx, "delete msgs from search index failed", err, "conversationID", conversationID, "minSeq", minSeq)
+	}
+}
+
+// unindexDeletedMsgs removes the msgs of the conversation below its current min seq from the full text index.
+func (m *msgServer) unindexDeletedMsgs(ctx context.Context, conversationID string) {
+	if m.MsgSearch == nil {
+		return
+	}
+	minSeq, err := m.MsgDatabase.GetMinSeq(ctx, conversationID)
+	if err != nil {
+		log.ZWarn(ctx, "get min seq failed", err, "conversationID", conversationID)
+		return
+	}
+	m.unindexMsgsBefore(ctx, conversationID, minSeq)
+}
+
+// reindexMsg replaces the indexed text of an edited msg.
+func (m *msgServer) reindexMsg(ctx context.Context, conversationID string, msg *sdkws.MsgData) {
+	if m.MsgSearch == nil {
+		return
+	}
+	doc, ok := msgsearch.NewDoc(tenant.GetTenantID(ctx), conversationID, msg)
+	if !ok {
+		m.unindexMsgs(ctx, conversationID, []int64{msg.Seq})
+		return
+	}
+	if err := m.MsgSearch.Index(ctx, []*msgsearch.Doc{doc}); err != nil {
+		log.ZWarn(ctx, "index edited msg failed", err, "conversationID", conversationID, "seq", msg.Seq)
+	}
+}
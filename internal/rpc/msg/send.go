@@ -54,6 +54,9 @@ func (m *msgServer) sendMsgGroupChat(ctx context.Context, req *pbmsg.SendMsgReq)
 		prommetrics.GroupChatMsgProcessFailedCounter.Inc()
 		return nil, err
 	}
+	if err = m.checkThreadParent(ctx, req.MsgData); err != nil {
+		return nil, err
+	}
 
 	if err = m.webhookBeforeSendGroupMsg(ctx, &m.config.WebhooksConfig.BeforeSendGroupMsg, req); err != nil {
 		return nil, err
@@ -61,10 +64,16 @@ func (m *msgServer) sendMsgGroupChat(ctx context.Context, req *pbmsg.SendMsgReq)
 	if err := m.webhookBeforeMsgModify(ctx, &m.config.WebhooksConfig.BeforeMsgModify, req); err != nil {
 		return nil, err
 	}
+	moderated, err := m.moderateMsg(ctx, req.MsgData)
+	if err != nil {
+		prommetrics.GroupChatMsgProcessFailedCounter.Inc()
+		return nil, err
+	}
 	err = m.MsgDatabase.MsgToMQ(ctx, conversationutil.GenConversationUniqueKeyForGroup(req.MsgData.GroupID), req.MsgData)
 	if err != nil {
 		return nil, err
 	}
+	m.addModerationReview(ctx, req.MsgData, moderated)
 	if req.MsgData.ContentType == constant.AtText {
 		go m.setConversationAtInfo(ctx, req.MsgData)
 	}
@@ -83,7 +92,7 @@ func (m *msgServer) setConversationAtInfo(nctx context.Context, msg *sdkws.MsgDa
 	ctx := mcontext.NewCtx("@@@" + mcontext.GetOperationID(nctx))
 	var atUserID []string
 	conversation := &pbconversation.ConversationReq{
-		ConversationID:   msgprocessor.GetConversationIDByMsg(msg),
+		ConversationID:   parentConversationID(msgprocessor.GetConversationIDByMsg(msg)),
 		ConversationType: msg.SessionType,
 		GroupID:          msg.GroupID,
 	}
@@ -135,6 +144,9 @@ func (m *msgServer) sendMsgSingleChat(ctx context.Context, req *pbmsg.SendMsgReq
 	if err := m.messageVerification(ctx, req); err != nil {
 		return nil, err
 	}
+	if err := m.checkThreadParent(ctx, req.MsgData); err != nil {
+		return nil, err
+	}
 	isSend := true
 	isNotification := msgprocessor.IsNotificationByMsg(req.MsgData)
 	if !isNotification {
@@ -160,10 +172,16 @@ func (m *msgServer) sendMsgSingleChat(ctx context.Context, req *pbmsg.SendMsgReq
 			return nil, err
 		}
 
+		moderated, err := m.moderateMsg(ctx, req.MsgData)
+		if err != nil {
+			prommetrics.SingleChatMsgProcessFailedCounter.Inc()
+			return nil, err
+		}
 		if err := m.MsgDatabase.MsgToMQ(ctx, conversationutil.GenConversationUniqueKeyForSingle(req.MsgData.SendID, req.MsgData.RecvID), req.MsgData); err != nil {
 			prommetrics.SingleChatMsgProcessFailedCounter.Inc()
 			return nil, err
 		}
+		m.addModerationReview(ctx, req.MsgData, moderated)
 		m.webhookAfterSendSingleMsg(ctx, &m.config.WebhooksConfig.AfterSendSingleMsg, req)
 		prommetrics.SingleChatMsgProcessSuccessCounter.Inc()
 		return &pbmsg.SendMsgResp{
@@ -16,6 +16,8 @@ package msg
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/redis"
@@ -26,12 +28,18 @@ import (
 	"github.com/openimsdk/tools/db/redisutil"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/localcache/lru"
+	"github.com/openimsdk/open-im-server/v3/pkg/moderation"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgsearch"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpccache"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/open-im-server/v3/pkg/translate"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/conversation"
 	"github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/tools/discovery"
+	"github.com/openimsdk/tools/mq/memamq"
 	"google.golang.org/grpc"
 )
 
@@ -44,6 +52,14 @@ type (
 	msgServer struct {
 		RegisterCenter         discovery.SvcDiscoveryRegistry   // Service discovery registry for service registration.
 		MsgDatabase            controller.CommonMsgDatabase     // Interface for message database operations.
+		ScheduledMsgDatabase   controller.ScheduledMsgDatabase  // Messages waiting to be sent by the cron task.
+		MsgSearch              msgsearch.MsgSearchIndex         // Full text index of messages, nil when it is disabled.
+		MsgExportDatabase      controller.MsgExportDatabase     // Jobs exporting conversations to archives.
+		Third                  *rpcclient.Third                 // RPC client for third service, it uploads the export archives.
+		RetentionDatabase      controller.RetentionDatabase     // Retention policies and legal holds checked before deleting messages.
+		Translator             translate.Translator             // Translates the pulled text messages, nil when it is disabled.
+		Moderation             *moderation.Pipeline             // Rules checked before the messages are sent, nil when it is disabled.
+		ModerationDatabase     controller.ModerationDatabase    // Messages flagged by the moderation for the admins to review.
 		Conversation           *rpcclient.ConversationRpcClient // RPC client for conversation service.
 		UserLocalCache         *rpccache.UserLocalCache         // Local cache for user data.
 		FriendLocalCache       *rpccache.FriendLocalCache       // Local cache for friend data.
@@ -54,6 +70,9 @@ type (
 		msgNotificationSender  *MsgNotificationSender           // RPC client for sending msg notifications.
 		config                 *Config                          // Global configuration settings.
 		webhookClient          *webhook.Client
+		readReceiptQueue       *memamq.MemoryQueue // Pushes the read counts of group msgs to their senders.
+		translateQueue         *memamq.MemoryQueue // Translates the pulled msgs.
+		translateCache         lru.LRU[string, string]
 	}
 
 	Config struct {
@@ -66,6 +85,8 @@ type (
 		WebhooksConfig     config.Webhooks
 		LocalCacheConfig   config.LocalCache
 		Discovery          config.Discovery
+		// Index is the index of the instance among the msg rpc instances, it names the writer of the search index.
+		Index int
 	}
 )
 
@@ -83,6 +104,7 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 	if err != nil {
 		return err
 	}
+	redis.AddTenantHook(rdb)
 	msgDocModel, err := mgo.NewMsgMongo(mgocli.GetDB())
 	if err != nil {
 		return err
@@ -106,9 +128,45 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 	if err != nil {
 		return err
 	}
+	scheduledMsgModel, err := mgo.NewScheduledMsgMongo(mgocli.GetDB())
+	if err != nil {
+		return err
+	}
+	// The msg rpc writes the deletes and edits of msgs to the index, msgtransfer indexes the new msgs.
+	msgSearch, err := msgsearch.New(&config.Share.MsgSearch, "msg_"+strconv.Itoa(config.Index))
+	if err != nil {
+		return err
+	}
+	msgExportJobModel, err := mgo.NewMsgExportJobMongo(mgocli.GetDB())
+	if err != nil {
+		return err
+	}
+	retentionPolicyModel, err := mgo.NewRetentionPolicyMongo(mgocli.GetDB())
+	if err != nil {
+		return err
+	}
+	retentionSkipModel, err := mgo.NewRetentionSkipMongo(mgocli.GetDB())
+	if err != nil {
+		return err
+	}
+	moderationReviewModel, err := mgo.NewModerationReviewMongo(mgocli.GetDB())
+	if err != nil {
+		return err
+	}
+	moderationPipeline, err := moderation.New(&config.RpcConfig.Moderation)
+	if err != nil {
+		return err
+	}
 	s := &msgServer{
 		Conversation:           &conversationClient,
 		MsgDatabase:            msgDatabase,
+		ScheduledMsgDatabase:   controller.NewScheduledMsgDatabase(scheduledMsgModel),
+		MsgSearch:              msgSearch,
+		MsgExportDatabase:      controller.NewMsgExportDatabase(msgExportJobModel),
+		Third:                  rpcclient.NewThird(client, config.Share.RpcRegisterName.Third, ""),
+		RetentionDatabase:      controller.NewRetentionDatabase(retentionPolicyModel, retentionSkipModel),
+		Moderation:             moderationPipeline,
+		ModerationDatabase:     controller.NewModerationDatabase(moderationReviewModel),
 		RegisterCenter:         client,
 		UserLocalCache:         rpccache.NewUserLocalCache(userRpcClient, &config.LocalCacheConfig, rdb),
 		GroupLocalCache:        rpccache.NewGroupLocalCache(groupRpcClient, &config.LocalCacheConfig, rdb),
@@ -116,12 +174,20 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 		FriendLocalCache:       rpccache.NewFriendLocalCache(friendRpcClient, &config.LocalCacheConfig, rdb),
 		config:                 config,
 		webhookClient:          webhook.NewWebhookClient(config.WebhooksConfig.URL),
+		readReceiptQueue:       memamq.NewMemoryQueue(readReceiptWorkerCount, readReceiptBufferSize),
+		translateQueue:         memamq.NewMemoryQueue(translateWorkerCount, translateBufferSize),
+		translateCache:         newTranslateCache(),
 	}
 
+	s.Translator = translate.NewWebhookTranslator(s.webhookClient, &config.WebhooksConfig.TranslateMsg)
+	if moderationPipeline != nil {
+		go moderationPipeline.Watch(ctx, time.Duration(config.RpcConfig.Moderation.ReloadInterval)*time.Second)
+	}
 	s.notificationSender = rpcclient.NewNotificationSender(&config.NotificationConfig, rpcclient.WithLocalSendMsg(s.SendMsg))
 	s.msgNotificationSender = NewMsgNotificationSender(config, rpcclient.WithLocalSendMsg(s.SendMsg))
 
 	msg.RegisterMsgServer(server, s)
+	msgext.RegisterMsgExtServer(server, s)
 
 	return nil
 }
@@ -34,17 +34,43 @@ func (m *msgServer) PullMessageBySeqs(ctx context.Context, req *sdkws.PullMessag
 	resp.NotificationMsgs = make(map[string]*sdkws.PullMsgs)
 	for _, seq := range req.SeqRanges {
 		if !msgprocessor.IsNotification(seq.ConversationID) {
-			conversation, err := m.ConversationLocalCache.GetConversation(ctx, req.UserID, seq.ConversationID)
+			// A thread is readable by the members of the conversation of its parent message.
+			conversationID, parentSeq, isThread := msgprocessor.ParseThreadConversationID(seq.ConversationID)
+			if !isThread {
+				conversationID = seq.ConversationID
+			}
+			conversation, err := m.ConversationLocalCache.GetConversation(ctx, req.UserID, conversationID)
 			if err != nil {
-				log.ZError(ctx, "GetConversation error", err, "conversationID", seq.ConversationID)
+				log.ZError(ctx, "GetConversation error", err, "conversationID", conversationID)
 				continue
 			}
+			userMaxSeq := conversation.MaxSeq
+			var startTime, endTime int64
+			if isThread {
+				var ok bool
+				startTime, endTime, ok, err = m.threadTimeRange(ctx, req.UserID, conversationID, parentSeq, userMaxSeq)
+				if err != nil {
+					log.ZWarn(ctx, "threadTimeRange error", err, "conversationID", seq.ConversationID)
+					continue
+				}
+				if !ok {
+					log.ZWarn(ctx, "thread parent msg out of user seq range", nil, "conversationID", seq.ConversationID, "userMaxSeq", userMaxSeq)
+					continue
+				}
+				// The seqs of a thread are its own, the user's range applies to the send time of replies.
+				userMaxSeq = 0
+			}
 			minSeq, maxSeq, msgs, err := m.MsgDatabase.GetMsgBySeqsRange(ctx, req.UserID, seq.ConversationID,
-				seq.Begin, seq.End, seq.Num, conversation.MaxSeq)
+				seq.Begin, seq.End, seq.Num, userMaxSeq)
 			if err != nil {
 				log.ZWarn(ctx, "GetMsgBySeqsRange error", err, "conversationID", seq.ConversationID, "seq", seq)
 				continue
 			}
+			if isThread {
+				msgs = datautil.Filter(msgs, func(msg *sdkws.MsgData) (*sdkws.MsgData, bool) {
+					return msg, msg.SendTime >= startTime && (endTime == 0 || msg.SendTime <= endTime)
+				})
+			}
 			var isEnd bool
 			switch req.Order {
 			case sdkws.PullOrder_PullOrderAsc:
@@ -83,6 +109,7 @@ func (m *msgServer) PullMessageBySeqs(ctx context.Context, req *sdkws.PullMessag
 			resp.NotificationMsgs[seq.ConversationID] = &sdkws.PullMsgs{Msgs: notificationMsgs, IsEnd: isEnd}
 		}
 	}
+	m.translatePulledMsgs(ctx, resp.Msgs)
 	return resp, nil
 }
 
@@ -112,6 +139,7 @@ func (m *msgServer) GetSeqMessage(ctx context.Context, req *msg.GetSeqMessageReq
 		}
 		pullMsgs.Msgs = append(pullMsgs.Msgs, msgs...)
 	}
+	m.translatePulledMsgs(ctx, resp.Msgs)
 	return resp, nil
 }
 
@@ -0,0 +1,91 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// checkThreadParent checks that the parent message of a thread reply exists and can be replied to.
+func (m *msgServer) checkThreadParent(ctx context.Context, msg *sdkws.MsgData) error {
+	parentSeq := msgprocessor.GetThreadParentSeq(msg)
+	if parentSeq == 0 {
+		return nil
+	}
+	conversationID, _, _ := msgprocessor.ParseThreadConversationID(msgprocessor.GetChatConversationIDByMsg(msg))
+	_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, msg.SendID, conversationID, []int64{parentSeq})
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 || msgs[0] == nil || msgs[0].Status == constant.MsgDeleted {
+		return errs.ErrRecordNotFound.WrapMsg("thread parent msg not found", "conversationID", conversationID, "seq", parentSeq)
+	}
+	parent := msgs[0]
+	if parent.ContentType == constant.MsgRevokeNotification {
+		return servererrs.ErrMsgAlreadyRevoke.WrapMsg("thread parent msg already revoke")
+	}
+	if parent.ContentType >= constant.NotificationBegin && parent.ContentType <= constant.NotificationEnd {
+		return errs.ErrArgs.WrapMsg("notification can not be replied to in a thread", "contentType", parent.ContentType)
+	}
+	return nil
+}
+
+// parentConversationID returns the conversation of the parent message if conversationID is a thread.
+func parentConversationID(conversationID string) string {
+	if parent, _, ok := msgprocessor.ParseThreadConversationID(conversationID); ok {
+		return parent
+	}
+	return conversationID
+}
+
+// threadTimeRange returns the send time range of the replies of a thread the user can read, bounded by the
+// user's min and max seq in the conversation of the parent message. end is 0 when the user is still in the
+// conversation, ok is false when the parent message is outside the range.
+func (m *msgServer) threadTimeRange(ctx context.Context, userID string, conversationID string, parentSeq int64, userMaxSeq int64) (start int64, end int64, ok bool, err error) {
+	userMinSeq, err := m.MsgDatabase.GetUserMinSeq(ctx, conversationID, userID)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if parentSeq < userMinSeq || (userMaxSeq != 0 && parentSeq > userMaxSeq) {
+		return 0, 0, false, nil
+	}
+	sendTime := func(seq int64) (int64, error) {
+		_, _, msgs, err := m.MsgDatabase.GetMsgBySeqs(ctx, userID, conversationID, []int64{seq})
+		if err != nil {
+			return 0, err
+		}
+		if len(msgs) == 0 || msgs[0] == nil {
+			return 0, nil
+		}
+		return msgs[0].SendTime, nil
+	}
+	if userMinSeq > 1 {
+		if start, err = sendTime(userMinSeq); err != nil {
+			return 0, 0, false, err
+		}
+	}
+	if userMaxSeq != 0 {
+		if end, err = sendTime(userMaxSeq); err != nil {
+			return 0, 0, false, err
+		}
+	}
+	return start, end, true, nil
+}
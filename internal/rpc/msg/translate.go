@@ -0,0 +1,155 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msg
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/localcache"
+	"github.com/openimsdk/open-im-server/v3/pkg/localcache/lru"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/open-im-server/v3/pkg/translate"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/log"
+)
+
+const (
+	// translateWaitTimeout is how long a pull waits for the msgs not translated into the language of the reader yet,
+	// the ones translated later are stored and returned by the next pull.
+	translateWaitTimeout = time.Millisecond * 500
+	// translateTimeout bounds the translation of one msg.
+	translateTimeout = time.Second * 10
+	// translateCacheTTL is how long a translation, or a failure to translate, is kept in the local cache,
+	// so that a msg which can not be translated is not sent to the translator on every pull.
+	translateCacheTTL = time.Minute * 5
+
+	translateCacheSize   = 10240
+	translateWorkerCount = 8
+	translateBufferSize  = 1000
+)
+
+func newTranslateCache() lru.LRU[string, string] {
+	return lru.NewExpirationLRU[string, string](translateCacheSize, translateCacheTTL, translateCacheTTL, localcache.EmptyTarget{}, nil)
+}
+
+type pulledTranslation struct {
+	msg  *sdkws.MsgData
+	text string
+}
+
+// translatePulledMsgs leaves in the attached info of the pulled msgs only their translation into the language of
+// the reader. The text msgs which are not translated into it yet are translated on the translate queue, the pull
+// waits for them for translateWaitTimeout and returns the others without translation.
+func (m *msgServer) translatePulledMsgs(ctx context.Context, pulled map[string]*sdkws.PullMsgs) {
+	language := translate.GetLanguage(ctx)
+	results := make(chan pulledTranslation, translateBufferSize)
+	pending := make(map[*sdkws.MsgData]struct{})
+	for conversationID, pullMsgs := range pulled {
+		for _, msg := range pullMsgs.GetMsgs() {
+			if msg == nil {
+				continue
+			}
+			translations, err := msgext.GetTranslations(msg.AttachedInfo)
+			if err != nil {
+				log.ZWarn(ctx, "get msg translations failed", err, "conversationID", conversationID, "seq", msg.Seq)
+				continue
+			}
+			if language == "" {
+				if len(translations) > 0 {
+					m.setPulledTranslation(ctx, msg, language, "")
+				}
+				continue
+			}
+			if text, ok := translations[language]; ok {
+				m.setPulledTranslation(ctx, msg, language, text)
+				continue
+			}
+			translateMsg, ok := translate.NewMsg(conversationID, msg, m.config.Share.MsgTranslate.MaxTextLength)
+			if m.Translator == nil || !ok || len(pending) == cap(results) {
+				m.setPulledTranslation(ctx, msg, language, "")
+				continue
+			}
+			msg, content := msg, string(msg.Content)
+			err = m.translateQueue.PushCtx(ctx, func() {
+				results <- pulledTranslation{msg: msg, text: m.translateMsg(ctx, translateMsg, content, language)}
+			})
+			if err != nil {
+				log.ZWarn(ctx, "push translate task failed", err, "conversationID", conversationID, "seq", msg.Seq)
+				m.setPulledTranslation(ctx, msg, language, "")
+				continue
+			}
+			pending[msg] = struct{}{}
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	timer := time.NewTimer(translateWaitTimeout)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			delete(pending, res.msg)
+			m.setPulledTranslation(ctx, res.msg, language, res.text)
+		case <-timer.C:
+			for msg := range pending {
+				m.setPulledTranslation(ctx, msg, language, "")
+			}
+			return
+		}
+	}
+}
+
+// translateMsg translates msg into language and stores the translation, it returns an empty text when msg can
+// not be translated. The result is cached, so a msg is translated once however often it is pulled meanwhile.
+func (m *msgServer) translateMsg(ctx context.Context, msg *translate.Msg, content string, language string) string {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(content))
+	key := tenant.PrefixTenant(tenant.GetTenantID(ctx), msg.ConversationID+":"+strconv.FormatInt(msg.Seq, 10)+":"+language+":"+strconv.FormatUint(hash.Sum64(), 36))
+	text, _ := m.translateCache.Get(key, func() (string, error) {
+		// The pull does not wait for the translation, it keeps the tenant and the operation id of the context.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), translateTimeout)
+		defer cancel()
+		translations, err := m.Translator.Translate(ctx, msg, []string{language})
+		if err != nil {
+			log.ZWarn(ctx, "translate msg failed", err, "conversationID", msg.ConversationID, "seq", msg.Seq, "language", language)
+			return "", nil
+		}
+		if err := m.MsgDatabase.SetMsgTranslations(ctx, msg.ConversationID, msg.Seq, content, translations); err != nil {
+			log.ZWarn(ctx, "set msg translations failed", err, "conversationID", msg.ConversationID, "seq", msg.Seq)
+		}
+		return translations[language], nil
+	})
+	return text
+}
+
+// setPulledTranslation replaces the translations in the attached info of msg by its translation into language,
+// they are removed when text is empty.
+func (m *msgServer) setPulledTranslation(ctx context.Context, msg *sdkws.MsgData, language string, text string) {
+	var translations map[string]string
+	if text != "" {
+		translations = map[string]string{language: text}
+	}
+	attachedInfo, err := msgext.SetTranslations(msg.AttachedInfo, translations)
+	if err != nil {
+		log.ZWarn(ctx, "set pulled msg translation failed", err, "seq", msg.Seq)
+		return
+	}
+	msg.AttachedInfo = attachedInfo
+}
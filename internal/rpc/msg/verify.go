@@ -16,6 +16,7 @@ package msg
 
 import (
 	"context"
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
 	"github.com/openimsdk/tools/utils/datautil"
 	"github.com/openimsdk/tools/utils/encrypt"
@@ -52,7 +53,7 @@ type MessageRevoked struct {
 func (m *msgServer) messageVerification(ctx context.Context, data *msg.SendMsgReq) error {
 	switch data.MsgData.SessionType {
 	case constant.SingleChatType:
-		if datautil.Contain(data.MsgData.SendID, m.config.Share.IMAdminUserID...) {
+		if datautil.Contain(data.MsgData.SendID, authverify.AdminUserID(ctx, m.config.Share.IMAdminUserID)...) {
 			return nil
 		}
 		if data.MsgData.ContentType <= constant.NotificationEnd &&
@@ -90,7 +91,7 @@ func (m *msgServer) messageVerification(ctx context.Context, data *msg.SendMsgRe
 			return nil
 		}
 
-		if datautil.Contain(data.MsgData.SendID, m.config.Share.IMAdminUserID...) {
+		if datautil.Contain(data.MsgData.SendID, authverify.AdminUserID(ctx, m.config.Share.IMAdminUserID)...) {
 			return nil
 		}
 		if data.MsgData.ContentType <= constant.NotificationEnd &&
@@ -75,6 +75,7 @@ func Start(ctx context.Context, config *Config, client discovery.SvcDiscoveryReg
 	if err != nil {
 		return err
 	}
+	redis.AddTenantHook(rdb)
 
 	friendMongoDB, err := mgo.NewFriendMongo(mgocli.GetDB())
 	if err != nil {
@@ -0,0 +1,75 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package third
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+func (t *thirdServer) RegisterDevice(ctx context.Context, req *thirdext.RegisterDeviceReq) (*thirdext.RegisterDeviceResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, t.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	device := &model.Device{
+		UserID:     req.UserID,
+		PlatformID: int(req.PlatformID),
+		Provider:   req.Provider,
+		Token:      req.Token,
+		UpdateTime: now,
+	}
+	if req.ExpireTime > 0 {
+		device.ExpireTime = now + req.ExpireTime
+	}
+	if err := t.thirdDatabase.RegisterDevice(ctx, device); err != nil {
+		return nil, err
+	}
+	return &thirdext.RegisterDeviceResp{}, nil
+}
+
+func (t *thirdServer) UnregisterDevice(ctx context.Context, req *thirdext.UnregisterDeviceReq) (*thirdext.UnregisterDeviceResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, t.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	if err := t.thirdDatabase.UnregisterDevice(ctx, req.UserID, int(req.PlatformID)); err != nil {
+		return nil, err
+	}
+	return &thirdext.UnregisterDeviceResp{}, nil
+}
+
+func (t *thirdServer) GetDevices(ctx context.Context, req *thirdext.GetDevicesReq) (*thirdext.GetDevicesResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, t.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	devices, err := t.thirdDatabase.GetDevices(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &thirdext.GetDevicesResp{Devices: datautil.Slice(devices, func(device *model.Device) *thirdext.Device {
+		return &thirdext.Device{
+			PlatformID: int32(device.PlatformID),
+			Provider:   device.Provider,
+			Token:      device.Token,
+			ExpireTime: device.ExpireTime,
+			UpdateTime: device.UpdateTime,
+		}
+	})}, nil
+}
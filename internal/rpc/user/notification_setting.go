@@ -0,0 +1,74 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/convert"
+	"github.com/openimsdk/open-im-server/v3/pkg/notifypref"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/userext"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+func (s *userServer) SetNotificationSetting(ctx context.Context, req *userext.SetNotificationSettingReq) (*userext.SetNotificationSettingResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.Setting.UserID, s.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	setting := convert.NotificationSettingExt2DB(req.Setting)
+	setting.Keywords = datautil.Distinct(datautil.Slice(setting.Keywords, strings.TrimSpace))
+	setting.HidePreviewPlatformIDs = datautil.Distinct(setting.HidePreviewPlatformIDs)
+	setting.UpdateTime = time.Now()
+	if err := notifypref.Check(setting); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.FindWithError(ctx, []string{setting.UserID}); err != nil {
+		return nil, err
+	}
+	if err := s.notificationSettingDB.SetNotificationSetting(ctx, setting); err != nil {
+		return nil, err
+	}
+	return &userext.SetNotificationSettingResp{}, nil
+}
+
+func (s *userServer) DeleteNotificationSetting(ctx context.Context, req *userext.DeleteNotificationSettingReq) (*userext.DeleteNotificationSettingResp, error) {
+	if err := authverify.CheckAccessV3(ctx, req.UserID, s.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	if err := s.notificationSettingDB.DeleteNotificationSetting(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	return &userext.DeleteNotificationSettingResp{}, nil
+}
+
+// GetNotificationSettings returns the settings of the user to the user, and the ones of any users to an admin.
+func (s *userServer) GetNotificationSettings(ctx context.Context, req *userext.GetNotificationSettingsReq) (*userext.GetNotificationSettingsResp, error) {
+	userIDs := datautil.Distinct(req.UserIDs)
+	if len(userIDs) == 1 {
+		if err := authverify.CheckAccessV3(ctx, userIDs[0], s.config.Share.IMAdminUserID); err != nil {
+			return nil, err
+		}
+	} else if err := authverify.CheckAdmin(ctx, s.config.Share.IMAdminUserID); err != nil {
+		return nil, err
+	}
+	settings, err := s.notificationSettingDB.GetNotificationSettings(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &userext.GetNotificationSettingsResp{Settings: datautil.Slice(settings, convert.NotificationSettingDB2Ext)}, nil
+}
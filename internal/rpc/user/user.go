@@ -39,7 +39,9 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/convert"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/controller"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/rpcclient"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/userext"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/sdkws"
 	pbuser "github.com/openimsdk/protocol/user"
@@ -54,6 +56,7 @@ import (
 type userServer struct {
 	online                   cache.OnlineCache
 	db                       controller.UserDatabase
+	notificationSettingDB    controller.NotificationSettingDatabase
 	friendNotificationSender *relation.FriendNotificationSender
 	userNotificationSender   *UserNotificationSender
 	friendRpcClient          *rpcclient.FriendRpcClient
@@ -84,6 +87,7 @@ func Start(ctx context.Context, config *Config, client registry.SvcDiscoveryRegi
 	if err != nil {
 		return err
 	}
+	redis.AddTenantHook(rdb)
 	users := make([]*tablerelation.User, 0)
 
 	for _, v := range config.Share.IMAdminUserID {
@@ -95,6 +99,11 @@ func Start(ctx context.Context, config *Config, client registry.SvcDiscoveryRegi
 	}
 	userCache := redis.NewUserCacheRedis(rdb, &config.LocalCacheConfig, userDB, redis.GetRocksCacheOptions())
 	database := controller.NewUserDatabase(userDB, userCache, mgocli.GetTx())
+	notificationSettingDB, err := mgo.NewNotificationSettingMongo(mgocli.GetDB())
+	if err != nil {
+		return err
+	}
+	notificationSettingCache := redis.NewNotificationSettingCacheRedis(rdb, notificationSettingDB, redis.GetRocksCacheOptions())
 	friendRpcClient := rpcclient.NewFriendRpcClient(client, config.Share.RpcRegisterName.Friend)
 	groupRpcClient := rpcclient.NewGroupRpcClient(client, config.Share.RpcRegisterName.Group)
 	msgRpcClient := rpcclient.NewMessageRpcClient(client, config.Share.RpcRegisterName.Msg)
@@ -102,6 +111,7 @@ func Start(ctx context.Context, config *Config, client registry.SvcDiscoveryRegi
 	u := &userServer{
 		online:                   redis.NewUserOnline(rdb),
 		db:                       database,
+		notificationSettingDB:    controller.NewNotificationSettingDatabase(notificationSettingDB, notificationSettingCache),
 		RegisterCenter:           client,
 		friendRpcClient:          &friendRpcClient,
 		groupRpcClient:           &groupRpcClient,
@@ -111,7 +121,22 @@ func Start(ctx context.Context, config *Config, client registry.SvcDiscoveryRegi
 		webhookClient:            webhook.NewWebhookClient(config.WebhooksConfig.URL),
 	}
 	pbuser.RegisterUserServer(server, u)
-	return u.db.InitOnce(context.Background(), users)
+	userext.RegisterUserExtServer(server, u)
+	if err := u.db.InitOnce(context.Background(), users); err != nil {
+		return err
+	}
+	if config.Share.MultiTenant.Enable {
+		for _, t := range config.Share.MultiTenant.Tenants {
+			admins := make([]*tablerelation.User, 0, len(t.IMAdminUserID))
+			for _, v := range t.IMAdminUserID {
+				admins = append(admins, &tablerelation.User{UserID: v, Nickname: v, AppMangerLevel: constant.AppNotificationAdmin})
+			}
+			if err := u.db.InitOnce(tenant.WithTenantID(context.Background(), t.ID), admins); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (s *userServer) GetDesignateUsers(ctx context.Context, req *pbuser.GetDesignateUsersReq) (resp *pbuser.GetDesignateUsersResp, err error) {
@@ -287,6 +312,9 @@ func (s *userServer) UserRegister(ctx context.Context, req *pbuser.UserRegisterR
 	if exist {
 		return nil, servererrs.ErrRegisteredAlready.WrapMsg("userID registered already")
 	}
+	if err := s.checkTenantUserLimit(ctx, len(userIDs)); err != nil {
+		return nil, err
+	}
 	if err := s.webhookBeforeUserRegister(ctx, &s.config.WebhooksConfig.BeforeUserRegister, req); err != nil {
 		return nil, err
 	}
@@ -552,7 +580,7 @@ func (s *userServer) SearchNotificationAccount(ctx context.Context, req *pbuser.
 		}
 
 		// Convert users to response format
-		resp := s.userModelToResp(users, req.Pagination)
+		resp := s.userModelToResp(ctx, users, req.Pagination)
 		if resp.Total != 0 {
 			return resp, nil
 		}
@@ -562,7 +590,7 @@ func (s *userServer) SearchNotificationAccount(ctx context.Context, req *pbuser.
 		if err != nil {
 			return nil, err
 		}
-		resp = s.userModelToResp(users, req.Pagination)
+		resp = s.userModelToResp(ctx, users, req.Pagination)
 		return resp, nil
 	}
 
@@ -572,7 +600,7 @@ func (s *userServer) SearchNotificationAccount(ctx context.Context, req *pbuser.
 		return nil, err
 	}
 
-	resp := s.userModelToResp(users, req.Pagination)
+	resp := s.userModelToResp(ctx, users, req.Pagination)
 	return resp, nil
 }
 
@@ -606,11 +634,28 @@ func (s *userServer) genUserID() string {
 	return string(data)
 }
 
-func (s *userServer) userModelToResp(users []*tablerelation.User, pagination pagination.Pagination) *pbuser.SearchNotificationAccountResp {
+// checkTenantUserLimit rejects registering n users if the tenant of ctx would exceed its maximum number of users.
+func (s *userServer) checkTenantUserLimit(ctx context.Context, n int) error {
+	t := tenant.Current(ctx)
+	if t == nil || t.MaxUserNum <= 0 {
+		return nil
+	}
+	total, err := s.db.CountTotal(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if total+int64(n) > t.MaxUserNum {
+		return servererrs.ErrTenantUserLimit.WrapMsg("tenant user limit reached", "tenantID", t.ID, "total", total, "maxUserNum", t.MaxUserNum)
+	}
+	return nil
+}
+
+func (s *userServer) userModelToResp(ctx context.Context, users []*tablerelation.User, pagination pagination.Pagination) *pbuser.SearchNotificationAccountResp {
 	accounts := make([]*pbuser.NotificationAccountInfo, 0)
 	var total int64
+	imAdminUserID := authverify.AdminUserID(ctx, s.config.Share.IMAdminUserID)
 	for _, v := range users {
-		if v.AppMangerLevel == constant.AppNotificationAdmin && !datautil.Contain(v.UserID, s.config.Share.IMAdminUserID...) {
+		if v.AppMangerLevel == constant.AppNotificationAdmin && !datautil.Contain(v.UserID, imAdminUserID...) {
 			temp := &pbuser.NotificationAccountInfo{
 				UserID:   v.UserID,
 				FaceURL:  v.FaceURL,
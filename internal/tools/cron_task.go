@@ -22,6 +22,8 @@ import (
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	kdisc "github.com/openimsdk/open-im-server/v3/pkg/common/discoveryregister"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 	pbconversation "github.com/openimsdk/protocol/conversation"
 	"github.com/openimsdk/protocol/msg"
 
@@ -46,12 +48,14 @@ func Start(ctx context.Context, config *CronTaskConfig) error {
 	if config.CronTask.RetainChatRecords < 1 {
 		return errs.New("msg destruct time must be greater than 1").Wrap()
 	}
+	tenant.Init(&config.Share)
 	client, err := kdisc.NewDiscoveryRegister(&config.Discovery, &config.Share)
 	if err != nil {
 		return errs.WrapMsg(err, "failed to register discovery service")
 	}
 	client.AddOption(mw.GrpcClient(), grpc.WithTransportCredentials(insecure.NewCredentials()))
 	ctx = mcontext.SetOpUserID(ctx, config.Share.IMAdminUserID[0])
+	tenantCtxs := tenantContexts(ctx, &config.Share)
 
 	msgConn, err := client.GetConn(ctx, config.Share.RpcRegisterName.Msg)
 	if err != nil {
@@ -76,16 +80,18 @@ func Start(ctx context.Context, config *CronTaskConfig) error {
 
 	// scheduled hard delete outdated Msgs in specific time.
 	clearMsgFunc := func() {
-		now := time.Now()
-		deltime := now.Add(-time.Hour * 24 * time.Duration(config.CronTask.RetainChatRecords))
-		ctx := mcontext.SetOperationID(ctx, fmt.Sprintf("cron_%d_%d", os.Getpid(), deltime.UnixMilli()))
-		log.ZDebug(ctx, "clear chat records", "deltime", deltime, "timestamp", deltime.UnixMilli())
-
-		if _, err := msgClient.ClearMsg(ctx, &msg.ClearMsgReq{Timestamp: deltime.UnixMilli()}); err != nil {
-			log.ZError(ctx, "cron clear chat records failed", err, "deltime", deltime, "cont", time.Since(now))
-			return
+		for _, ctx := range tenantCtxs {
+			now := time.Now()
+			deltime := now.Add(-time.Hour * 24 * time.Duration(config.CronTask.RetainChatRecords))
+			ctx := mcontext.SetOperationID(ctx, fmt.Sprintf("cron_%d_%d", os.Getpid(), deltime.UnixMilli()))
+			log.ZDebug(ctx, "clear chat records", "deltime", deltime, "timestamp", deltime.UnixMilli())
+
+			if _, err := msgClient.ClearMsg(ctx, &msg.ClearMsgReq{Timestamp: deltime.UnixMilli()}); err != nil {
+				log.ZError(ctx, "cron clear chat records failed", err, "deltime", deltime, "cont", time.Since(now))
+				continue
+			}
+			log.ZDebug(ctx, "cron clear chat records success", "deltime", deltime, "cont", time.Since(now))
 		}
-		log.ZDebug(ctx, "cron clear chat records success", "deltime", deltime, "cont", time.Since(now))
 	}
 	if _, err := crontab.AddFunc(config.CronTask.CronExecuteTime, clearMsgFunc); err != nil {
 		return errs.Wrap(err)
@@ -93,27 +99,63 @@ func Start(ctx context.Context, config *CronTaskConfig) error {
 
 	// scheduled soft delete outdated Msgs in specific time when user set `is_msg_destruct` feature.
 	msgDestructFunc := func() {
-		now := time.Now()
-		ctx := mcontext.SetOperationID(ctx, fmt.Sprintf("cron_%d_%d", os.Getpid(), now.UnixMilli()))
-		log.ZDebug(ctx, "msg destruct cron start", "now", now)
+		for _, ctx := range tenantCtxs {
+			now := time.Now()
+			ctx := mcontext.SetOperationID(ctx, fmt.Sprintf("cron_%d_%d", os.Getpid(), now.UnixMilli()))
+			log.ZDebug(ctx, "msg destruct cron start", "now", now)
 
-		conversations, err := conversationClient.GetConversationsNeedDestructMsgs(ctx, &pbconversation.GetConversationsNeedDestructMsgsReq{})
-		if err != nil {
-			log.ZError(ctx, "Get conversation need Destruct msgs failed.", err)
-			return
-		} else {
-			_, err := msgClient.DestructMsgs(ctx, &msg.DestructMsgsReq{Conversations: conversations.Conversations})
+			conversations, err := conversationClient.GetConversationsNeedDestructMsgs(ctx, &pbconversation.GetConversationsNeedDestructMsgsReq{})
 			if err != nil {
+				log.ZError(ctx, "Get conversation need Destruct msgs failed.", err)
+				continue
+			}
+			if _, err := msgClient.DestructMsgs(ctx, &msg.DestructMsgsReq{Conversations: conversations.Conversations}); err != nil {
 				log.ZError(ctx, "Destruct Msgs failed.", err)
-				return
+				continue
 			}
+			log.ZDebug(ctx, "msg destruct cron task completed", "cont", time.Since(now))
 		}
-		log.ZDebug(ctx, "msg destruct cron task completed", "cont", time.Since(now))
 	}
 	if _, err := crontab.AddFunc(config.CronTask.CronExecuteTime, msgDestructFunc); err != nil {
 		return errs.Wrap(err)
 	}
 
+	// send the due scheduled messages through SendMsg, so they go through the same verification as any message.
+	if config.CronTask.ScheduledMsgExecuteTime != "" {
+		msgExtClient := msgext.NewMsgExtClient(msgConn)
+		scheduledMsgFunc := func() {
+			for _, ctx := range tenantCtxs {
+				ctx := mcontext.SetOperationID(ctx, fmt.Sprintf("cron_%d_%d", os.Getpid(), time.Now().UnixMilli()))
+				sendScheduledMsgs(ctx, msgClient, msgExtClient)
+			}
+		}
+		job := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(scheduledMsgFunc))
+		if _, err := crontab.AddJob(config.CronTask.ScheduledMsgExecuteTime, job); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+
+	// hand the pending message export jobs to the msg rpc, which writes them in the background.
+	if config.CronTask.MsgExportExecuteTime != "" {
+		msgExtClient := msgext.NewMsgExtClient(msgConn)
+		msgExportFunc := func() {
+			for _, ctx := range tenantCtxs {
+				ctx := mcontext.SetOperationID(ctx, fmt.Sprintf("cron_%d_%d", os.Getpid(), time.Now().UnixMilli()))
+				resp, err := msgExtClient.RunMsgExportJobs(ctx, &msgext.RunMsgExportJobsReq{Limit: msgExportBatch})
+				if err != nil {
+					log.ZError(ctx, "run msg export jobs failed", err)
+					continue
+				}
+				if len(resp.JobIDs) > 0 {
+					log.ZInfo(ctx, "msg export jobs started", "jobIDs", resp.JobIDs)
+				}
+			}
+		}
+		if _, err := crontab.AddFunc(config.CronTask.MsgExportExecuteTime, msgExportFunc); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+
 	// // scheduled delete outdated file Objects and their datas in specific time.
 	// deleteObjectFunc := func() {
 	// 	now := time.Now()
@@ -135,3 +177,48 @@ func Start(ctx context.Context, config *CronTaskConfig) error {
 	<-ctx.Done()
 	return nil
 }
+
+// scheduledMsgBatch is the number of scheduled messages claimed at once.
+const scheduledMsgBatch = 100
+
+// msgExportBatch is the number of export jobs handed to a msg rpc at once, the jobs are heavy so they are spread.
+const msgExportBatch = 1
+
+func sendScheduledMsgs(ctx context.Context, msgClient msg.MsgClient, msgExtClient msgext.MsgExtClient) {
+	for {
+		resp, err := msgExtClient.ClaimDueScheduledMsgs(ctx, &msgext.ClaimDueScheduledMsgsReq{Limit: scheduledMsgBatch})
+		if err != nil {
+			log.ZError(ctx, "claim due scheduled msgs failed", err)
+			return
+		}
+		for _, due := range resp.Msgs {
+			var errMsg string
+			if _, err := msgClient.SendMsg(ctx, due.MsgReq); err != nil {
+				log.ZWarn(ctx, "send scheduled msg failed", err, "scheduleID", due.ScheduleID)
+				errMsg = err.Error()
+			}
+			if _, err := msgExtClient.FinishScheduledMsg(ctx, &msgext.FinishScheduledMsgReq{ScheduleID: due.ScheduleID, ErrMsg: errMsg}); err != nil {
+				log.ZError(ctx, "finish scheduled msg failed", err, "scheduleID", due.ScheduleID)
+			}
+		}
+		if len(resp.Msgs) < scheduledMsgBatch {
+			return
+		}
+	}
+}
+
+// tenantContexts returns the context of the default app followed by one per tenant,
+// the jobs run once for each with an admin of the tenant as op user.
+func tenantContexts(ctx context.Context, share *config.Share) []context.Context {
+	ctxs := []context.Context{ctx}
+	if !share.MultiTenant.Enable {
+		return ctxs
+	}
+	for _, t := range share.MultiTenant.Tenants {
+		if len(t.IMAdminUserID) == 0 {
+			continue
+		}
+		ctxs = append(ctxs, mcontext.SetOpUserID(tenant.WithTenantID(ctx, t.ID), t.IMAdminUserID[0]))
+	}
+	return ctxs
+}
@@ -67,6 +67,14 @@ type SendMsgReq struct {
 	SendMsg
 }
 
+// ScheduleMsgReq is a SendMsgReq which is sent at ScheduleTime instead of now.
+type ScheduleMsgReq struct {
+	SendMsgReq
+
+	// ScheduleTime is the time in milliseconds the message is sent at.
+	ScheduleTime int64 `json:"scheduleTime" binding:"required"`
+}
+
 type GetConversationListReq struct {
 	// userID uniquely identifies the user.
 	UserID string `protobuf:"bytes,1,opt,name=userID,proto3" json:"userID,omitempty" binding:"required"`
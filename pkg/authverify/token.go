@@ -16,11 +16,15 @@ package authverify
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/mcontext"
+	"github.com/openimsdk/tools/tokenverify"
 	"github.com/openimsdk/tools/utils/datautil"
 )
 
@@ -30,7 +34,61 @@ func Secret(secret string) jwt.Keyfunc {
 	}
 }
 
+// Claims are the token claims with the tenant of the user, TenantID is empty for the default app.
+type Claims struct {
+	tokenverify.Claims
+	TenantID string `json:",omitempty"`
+}
+
+func BuildClaims(userID string, platformID int, tenantID string, ttl int64) Claims {
+	return Claims{
+		Claims:   tokenverify.BuildClaims(userID, platformID, ttl),
+		TenantID: tenantID,
+	}
+}
+
+func GetClaimFromToken(tokensString string, secretFunc jwt.Keyfunc) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokensString, &Claims{}, secretFunc)
+	if err == nil {
+		if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+			return claims, nil
+		}
+		return nil, errs.ErrTokenUnknown
+	}
+	var ve *jwt.ValidationError
+	if errors.As(err, &ve) {
+		switch {
+		case ve.Errors&jwt.ValidationErrorMalformed != 0:
+			return nil, errs.ErrTokenMalformed
+		case ve.Errors&jwt.ValidationErrorExpired != 0:
+			return nil, errs.ErrTokenExpired
+		case ve.Errors&jwt.ValidationErrorNotValidYet != 0:
+			return nil, errs.ErrTokenNotValidYet
+		}
+	}
+	return nil, errs.ErrTokenUnknown
+}
+
+// GetTenantIDFromToken reads the tenant of a token without verifying it.
+// It only routes the request to the tenant, the auth rpc verifies the token against the same tenant.
+func GetTenantIDFromToken(tokensString string) string {
+	var claims Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokensString, &claims); err != nil {
+		return ""
+	}
+	return claims.TenantID
+}
+
+// AdminUserID returns the admins of the tenant of ctx, the admins of the default app without a tenant.
+func AdminUserID(ctx context.Context, imAdminUserID []string) []string {
+	if t := tenant.Current(ctx); t != nil {
+		return t.IMAdminUserID
+	}
+	return imAdminUserID
+}
+
 func CheckAccessV3(ctx context.Context, ownerUserID string, imAdminUserID []string) (err error) {
+	imAdminUserID = AdminUserID(ctx, imAdminUserID)
 	opUserID := mcontext.GetOpUserID(ctx)
 	if datautil.Contain(opUserID, imAdminUserID...) {
 		return nil
@@ -42,10 +100,12 @@ func CheckAccessV3(ctx context.Context, ownerUserID string, imAdminUserID []stri
 }
 
 func IsAppManagerUid(ctx context.Context, imAdminUserID []string) bool {
+	imAdminUserID = AdminUserID(ctx, imAdminUserID)
 	return datautil.Contain(mcontext.GetOpUserID(ctx), imAdminUserID...)
 }
 
 func CheckAdmin(ctx context.Context, imAdminUserID []string) error {
+	imAdminUserID = AdminUserID(ctx, imAdminUserID)
 	if datautil.Contain(mcontext.GetOpUserID(ctx), imAdminUserID...) {
 		return nil
 	}
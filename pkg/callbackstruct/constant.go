@@ -22,6 +22,8 @@ const (
 	CallbackBeforeSetGroupInfoCommand       = "callbackBeforeSetGroupInfoCommand"
 	CallbackBeforeSetGroupInfoExCommand     = "callbackBeforeSetGroupInfoExCommand"
 	CallbackAfterRevokeMsgCommand           = "callbackBeforeAfterMsgCommand"
+	CallbackBeforeMsgEditCommand            = "callbackBeforeMsgEditCommand"
+	CallbackTranslateMsgCommand             = "callbackTranslateMsgCommand"
 	CallbackBeforeAddBlackCommand           = "callbackBeforeAddBlackCommand"
 	CallbackAfterAddFriendCommand           = "callbackAfterAddFriendCommand"
 	CallbackBeforeAddFriendAgreeCommand     = "callbackBeforeAddFriendAgreeCommand"
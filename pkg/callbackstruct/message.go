@@ -103,3 +103,20 @@ type CallbackSingleMsgReadReq struct {
 type CallbackSingleMsgReadResp struct {
 	CommonCallbackResp
 }
+
+type CallbackTranslateMsgReq struct {
+	CallbackCommand `json:"callbackCommand"`
+	OperationID     string   `json:"operationID"`
+	ConversationID  string   `json:"conversationID"`
+	Seq             int64    `json:"seq"`
+	SendID          string   `json:"sendID"`
+	ContentType     int32    `json:"contentType"`
+	Text            string   `json:"text"`
+	Languages       []string `json:"languages"`
+}
+
+type CallbackTranslateMsgResp struct {
+	CommonCallbackResp
+	// Translations maps a language to the translated text, the languages left out are not translated.
+	Translations map[string]string `json:"translations"`
+}
@@ -24,3 +24,20 @@ type CallbackAfterRevokeMsgReq struct {
 type CallbackAfterRevokeMsgResp struct {
 	CommonCallbackResp
 }
+
+type CallbackBeforeMsgEditReq struct {
+	CallbackCommand `json:"callbackCommand"`
+	ConversationID  string `json:"conversationID"`
+	Seq             int64  `json:"seq"`
+	UserID          string `json:"userID"`
+	SendID          string `json:"sendID"`
+	SessionType     int32  `json:"sessionType"`
+	ContentType     int32  `json:"contentType"`
+	OldContent      string `json:"oldContent"`
+	Content         string `json:"content"`
+}
+
+type CallbackBeforeMsgEditResp struct {
+	CommonCallbackResp
+	Content *string `json:"content"`
+}
@@ -36,9 +36,11 @@ func NewMsgGatewayCmd() *MsgGatewayCmd {
 	ret := &MsgGatewayCmd{msgGatewayConfig: &msgGatewayConfig}
 	ret.configMap = map[string]any{
 		OpenIMMsgGatewayCfgFileName: &msgGatewayConfig.MsgGateway,
+		OpenIMRPCMsgCfgFileName:     &msgGatewayConfig.MsgConfig,
 		ShareFileName:               &msgGatewayConfig.Share,
 		RedisConfigFileName:         &msgGatewayConfig.RedisConfig,
 		WebhooksConfigFileName:      &msgGatewayConfig.WebhooksConfig,
+		LocalCacheConfigFileName:    &msgGatewayConfig.LocalCacheConfig,
 		DiscoveryConfigFilename:     &msgGatewayConfig.Discovery,
 	}
 	ret.RootCmd = NewRootCmd(program.GetProcessName(), WithConfigMap(ret.configMap))
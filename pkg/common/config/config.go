@@ -112,9 +112,11 @@ type API struct {
 }
 
 type CronTask struct {
-	CronExecuteTime   string `mapstructure:"cronExecuteTime"`
-	RetainChatRecords int    `mapstructure:"retainChatRecords"`
-	FileExpireTime    int    `mapstructure:"fileExpireTime"`
+	CronExecuteTime         string `mapstructure:"cronExecuteTime"`
+	RetainChatRecords       int    `mapstructure:"retainChatRecords"`
+	FileExpireTime          int    `mapstructure:"fileExpireTime"`
+	ScheduledMsgExecuteTime string `mapstructure:"scheduledMsgExecuteTime"`
+	MsgExportExecuteTime    string `mapstructure:"msgExportExecuteTime"`
 }
 
 type OfflinePushConfig struct {
@@ -184,7 +186,28 @@ type MsgGateway struct {
 		WebsocketMaxConnNum int   `mapstructure:"websocketMaxConnNum"`
 		WebsocketMaxMsgLen  int   `mapstructure:"websocketMaxMsgLen"`
 		WebsocketTimeout    int   `mapstructure:"websocketTimeout"`
+		ResumeGraceWindow   int   `mapstructure:"resumeGraceWindow"`
+		ResumeBufferSize    int   `mapstructure:"resumeBufferSize"`
 	} `mapstructure:"longConnSvr"`
+	RateLimit struct {
+		Enable bool                  `mapstructure:"enable"`
+		Limits []MsgGatewayRateLimit `mapstructure:"limits"`
+	} `mapstructure:"rateLimit"`
+	Drain struct {
+		Window          int  `mapstructure:"window"`
+		ReconnectJitter int  `mapstructure:"reconnectJitter"`
+		OnShutdown      bool `mapstructure:"onShutdown"`
+	} `mapstructure:"drain"`
+}
+
+// MsgGatewayRateLimit configures the token buckets of one uplink request identifier.
+// Rates are requests per second, a rate of 0 disables that bucket.
+type MsgGatewayRateLimit struct {
+	ReqIdentifier int32   `mapstructure:"reqIdentifier"`
+	ConnRate      float64 `mapstructure:"connRate"`
+	ConnBurst     int     `mapstructure:"connBurst"`
+	UserRate      float64 `mapstructure:"userRate"`
+	UserBurst     int     `mapstructure:"userBurst"`
 }
 
 type MsgTransfer struct {
@@ -218,6 +241,51 @@ type Push struct {
 		PushURL      string `mapstructure:"pushURL"`
 		PushIntent   string `mapstructure:"pushIntent"`
 	} `mapstructure:"jpns"`
+	APNs struct {
+		KeyFilePath string `mapstructure:"keyFilePath"`
+		KeyID       string `mapstructure:"keyID"`
+		TeamID      string `mapstructure:"teamID"`
+		BundleID    string `mapstructure:"bundleID"`
+	} `mapstructure:"apns"`
+	Webhook struct {
+		URL     string `mapstructure:"url"`
+		Timeout int    `mapstructure:"timeout"`
+	} `mapstructure:"webhook"`
+	Huawei struct {
+		AppID        string `mapstructure:"appID"`
+		ClientSecret string `mapstructure:"clientSecret"`
+	} `mapstructure:"huawei"`
+	Xiaomi struct {
+		AppSecret   string `mapstructure:"appSecret"`
+		PackageName string `mapstructure:"packageName"`
+	} `mapstructure:"xiaomi"`
+	OPPO struct {
+		AppKey       string `mapstructure:"appKey"`
+		MasterSecret string `mapstructure:"masterSecret"`
+	} `mapstructure:"oppo"`
+	Vivo struct {
+		AppID     string `mapstructure:"appID"`
+		AppKey    string `mapstructure:"appKey"`
+		AppSecret string `mapstructure:"appSecret"`
+	} `mapstructure:"vivo"`
+	// Platforms maps the name of a device platform to the provider pushing to it, Enable is not used when it is set.
+	Platforms map[string]string `mapstructure:"platforms"`
+	// Devices configures the providers of the devices registered by the users when Enable is device.
+	Devices struct {
+		Providers []string `mapstructure:"providers"`
+		Default   string   `mapstructure:"default"`
+	} `mapstructure:"devices"`
+	// Retry pushes the failed offline pushes again with exponential backoff.
+	Retry struct {
+		Enable bool `mapstructure:"enable"`
+		// MaxAttempts is the number of failed attempts after which a push is dead-lettered.
+		MaxAttempts int `mapstructure:"maxAttempts"`
+		// InitialInterval and MaxInterval are in seconds, the interval doubles after every failed attempt.
+		InitialInterval int `mapstructure:"initialInterval"`
+		MaxInterval     int `mapstructure:"maxInterval"`
+		// MaxDeadLetters is the number of dead letters kept, the oldest are dropped beyond it.
+		MaxDeadLetters int `mapstructure:"maxDeadLetters"`
+	} `mapstructure:"retry"`
 	IOSPush struct {
 		PushSound  string `mapstructure:"pushSound"`
 		BadgeCount bool   `mapstructure:"badgeCount"`
@@ -245,6 +313,10 @@ type Conversation struct {
 		Ports      []int  `mapstructure:"ports"`
 	} `mapstructure:"rpc"`
 	Prometheus Prometheus `mapstructure:"prometheus"`
+	PinMsg     struct {
+		MaxNum         int  `mapstructure:"maxNum"`
+		GroupAdminOnly bool `mapstructure:"groupAdminOnly"`
+	} `mapstructure:"pinMsg"`
 }
 
 type Friend struct {
@@ -274,6 +346,49 @@ type Msg struct {
 	} `mapstructure:"rpc"`
 	Prometheus   Prometheus `mapstructure:"prometheus"`
 	FriendVerify bool       `mapstructure:"friendVerify"`
+	EditMsg      struct {
+		TimeLimit int64 `mapstructure:"timeLimit"`
+	} `mapstructure:"editMsg"`
+	ScheduledMsg struct {
+		MaxDelay int64 `mapstructure:"maxDelay"`
+	} `mapstructure:"scheduledMsg"`
+	GroupReadReceipt struct {
+		CacheExpire   int64 `mapstructure:"cacheExpire"`
+		PushReadCount bool  `mapstructure:"pushReadCount"`
+		MaxPushMsgNum int   `mapstructure:"maxPushMsgNum"`
+	} `mapstructure:"groupReadReceipt"`
+	MsgExport struct {
+		TempDir       string `mapstructure:"tempDir"`
+		MaxObjectSize int64  `mapstructure:"maxObjectSize"`
+	} `mapstructure:"msgExport"`
+	Moderation Moderation `mapstructure:"moderation"`
+}
+
+// Moderation configures the rules the text of the messages sent by users is checked against before they are stored.
+// The action of a rule is block, mask or flag.
+type Moderation struct {
+	Enable         bool               `mapstructure:"enable"`
+	ReloadInterval int                `mapstructure:"reloadInterval"`
+	Words          []ModerationWords  `mapstructure:"words"`
+	Regexps        []ModerationRegexp `mapstructure:"regexps"`
+	Spam           struct {
+		Window     int    `mapstructure:"window"`
+		MaxMsgs    int    `mapstructure:"maxMsgs"`
+		MaxRepeats int    `mapstructure:"maxRepeats"`
+		Action     string `mapstructure:"action"`
+	} `mapstructure:"spam"`
+}
+
+type ModerationWords struct {
+	Name   string   `mapstructure:"name"`
+	Files  []string `mapstructure:"files"`
+	Action string   `mapstructure:"action"`
+}
+
+type ModerationRegexp struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+	Action  string `mapstructure:"action"`
 }
 
 type Third struct {
@@ -361,6 +476,46 @@ type Share struct {
 	RpcRegisterName  RpcRegisterName `mapstructure:"rpcRegisterName"`
 	IMAdminUserID    []string        `mapstructure:"imAdminUserID"`
 	MultiLoginPolicy int             `mapstructure:"multiLoginPolicy"`
+	MultiTenant      struct {
+		Enable  bool     `mapstructure:"enable"`
+		Tenants []Tenant `mapstructure:"tenants"`
+	} `mapstructure:"multiTenant"`
+	MsgSearch    MsgSearch    `mapstructure:"msgSearch"`
+	MsgTranslate MsgTranslate `mapstructure:"msgTranslate"`
+}
+
+// MsgTranslate configures the translation of text messages, it is done by the translateMsg webhook when it is enabled.
+type MsgTranslate struct {
+	// Languages are translated by msgtransfer when a message is stored,
+	// the other languages are translated the first time a reader pulls the message in them.
+	Languages     []string `mapstructure:"languages"`
+	MaxTextLength int      `mapstructure:"maxTextLength"`
+}
+
+// MsgSearch configures the full text index of messages, msgtransfer indexes the new messages and the msg rpc
+// searches them and removes the deleted ones.
+type MsgSearch struct {
+	Enable   bool   `mapstructure:"enable"`
+	Engine   string `mapstructure:"engine"`
+	Embedded struct {
+		Dir           string `mapstructure:"dir"`
+		FlushInterval int    `mapstructure:"flushInterval"`
+	} `mapstructure:"embedded"`
+	External struct {
+		Address  []string `mapstructure:"address"`
+		Username string   `mapstructure:"username"`
+		Password string   `mapstructure:"password"`
+		Index    string   `mapstructure:"index"`
+	} `mapstructure:"external"`
+}
+
+// Tenant is an app isolated from the others hosted by the same deployment.
+type Tenant struct {
+	ID            string   `mapstructure:"id"`
+	Secret        string   `mapstructure:"secret"`
+	IMAdminUserID []string `mapstructure:"imAdminUserID"`
+	WebhookURL    string   `mapstructure:"webhookURL"`
+	MaxUserNum    int64    `mapstructure:"maxUserNum"`
 }
 type RpcRegisterName struct {
 	User           string `mapstructure:"user"`
@@ -388,6 +543,12 @@ func (r *RpcRegisterName) GetServiceNames() []string {
 	}
 }
 
+// DrainingMessageGateway is the service a draining gateway node moves to in discovery, it takes no new
+// lookups from other gateways or the auth service, but the push service still reaches its open connections.
+func (r *RpcRegisterName) DrainingMessageGateway() string {
+	return r.MessageGateway + "-draining"
+}
+
 // FullConfig stores all configurations for before and after events
 type Webhooks struct {
 	URL                      string       `mapstructure:"url"`
@@ -431,6 +592,8 @@ type Webhooks struct {
 	AfterSetGroupInfoEx      AfterConfig  `mapstructure:"afterSetGroupInfoEx"`
 	BeforeSetGroupInfoEx     BeforeConfig `mapstructure:"beforeSetGroupInfoEx"`
 	AfterRevokeMsg           AfterConfig  `mapstructure:"afterRevokeMsg"`
+	BeforeMsgEdit            BeforeConfig `mapstructure:"beforeMsgEdit"`
+	TranslateMsg             BeforeConfig `mapstructure:"translateMsg"`
 	BeforeAddBlack           BeforeConfig `mapstructure:"beforeAddBlack"`
 	AfterAddFriend           AfterConfig  `mapstructure:"afterAddFriend"`
 	BeforeAddFriendAgree     BeforeConfig `mapstructure:"beforeAddFriendAgree"`
@@ -0,0 +1,51 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/userext"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+func NotificationSettingDB2Ext(setting *model.NotificationSetting) *userext.NotificationSetting {
+	return &userext.NotificationSetting{
+		UserID:   setting.UserID,
+		TimeZone: setting.TimeZone,
+		DNDWindows: datautil.Slice(setting.DNDWindows, func(window model.DNDWindow) *userext.DNDWindow {
+			return &userext.DNDWindow{Start: window.Start, End: window.End, Weekdays: window.Weekdays}
+		}),
+		Keywords:               setting.Keywords,
+		GroupMentionOnly:       setting.GroupMentionOnly,
+		HidePreviewPlatformIDs: setting.HidePreviewPlatformIDs,
+		UpdateTime:             setting.UpdateTime.UnixMilli(),
+	}
+}
+
+func NotificationSettingExt2DB(setting *userext.NotificationSetting) *model.NotificationSetting {
+	return &model.NotificationSetting{
+		UserID:   setting.UserID,
+		TimeZone: setting.TimeZone,
+		DNDWindows: datautil.Slice(setting.DNDWindows, func(window *userext.DNDWindow) model.DNDWindow {
+			return model.DNDWindow{Start: window.Start, End: window.End, Weekdays: window.Weekdays}
+		}),
+		Keywords:               setting.Keywords,
+		GroupMentionOnly:       setting.GroupMentionOnly,
+		HidePreviewPlatformIDs: setting.HidePreviewPlatformIDs,
+		UpdateTime:             time.UnixMilli(setting.UpdateTime),
+	}
+}
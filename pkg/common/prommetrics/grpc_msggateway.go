@@ -23,4 +23,12 @@ var (
 		Name: "online_user_num",
 		Help: "The number of online user num",
 	})
+	MsgGatewayRateLimitedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "msg_gateway_rate_limited_total",
+		Help: "The number of uplink requests rejected by the rate limit",
+	}, []string{"req_identifier", "scope"})
+	MsgGatewayDrainRemainingGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "msg_gateway_drain_remaining_conns",
+		Help: "The number of connections left to close while the gateway drains",
+	})
 )
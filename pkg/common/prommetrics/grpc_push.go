@@ -27,4 +27,16 @@ var (
 		Name: "msg_long_time_push_total",
 		Help: "The number of messages with a push time exceeding 10 seconds",
 	})
+	OfflinePushProviderFailedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "offline_push_provider_failed_total",
+		Help: "The number of failed offline push attempts by provider",
+	}, []string{"provider"})
+	OfflinePushRetryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "offline_push_retry_total",
+		Help: "The number of failed offline pushes queued to be retried by provider",
+	}, []string{"provider"})
+	OfflinePushDeadLetterCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "offline_push_dead_letter_total",
+		Help: "The number of offline pushes dead-lettered after their last attempt failed by provider",
+	}, []string{"provider"})
 )
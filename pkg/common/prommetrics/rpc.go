@@ -45,7 +45,7 @@ func GetGrpcServerMetrics() *gp.ServerMetrics {
 func GetGrpcCusMetrics(registerName string, share *config.Share) []prometheus.Collector {
 	switch registerName {
 	case share.RpcRegisterName.MessageGateway:
-		return []prometheus.Collector{OnlineUserGauge}
+		return []prometheus.Collector{OnlineUserGauge, MsgGatewayRateLimitedCounter, MsgGatewayDrainRemainingGauge}
 	case share.RpcRegisterName.Msg:
 		return []prometheus.Collector{
 			SingleChatMsgProcessSuccessCounter,
@@ -57,6 +57,9 @@ func GetGrpcCusMetrics(registerName string, share *config.Share) []prometheus.Co
 		return []prometheus.Collector{
 			MsgOfflinePushFailedCounter,
 			MsgLoneTimePushCounter,
+			OfflinePushProviderFailedCounter,
+			OfflinePushRetryCounter,
+			OfflinePushDeadLetterCounter,
 		}
 	case share.RpcRegisterName.Auth:
 		return []prometheus.Collector{UserLoginCounter}
@@ -74,6 +74,10 @@ const (
 	MutedInGroup          = 1402 // Member muted in the group
 	MutedGroup            = 1403 // Group is muted
 	MsgAlreadyRevoke      = 1404 // Message already revoked
+	MsgEditExpired        = 1405 // Message edit time window has passed
+	MsgEditConflict       = 1406 // Message was edited concurrently
+	MsgBlocked            = 1407 // Message blocked by the content moderation
+	MsgReactionLimit      = 1408 // Message or user reached the limit of distinct reactions
 
 	// Token error codes.
 	TokenExpiredError     = 1501
@@ -89,7 +93,13 @@ const (
 	ConnArgsErr          = 1602
 	PushMsgErr           = 1603
 	IOSBackgroundPushErr = 1604
+	ReqRateLimitErr      = 1605 // Uplink request rejected by the gateway rate limit
+	ConnDrainingErr      = 1606 // Gateway node is draining and accepts no new connections
 
 	// S3 error codes.
 	FileUploadedExpiredError = 1701 // Upload expired
+
+	// Tenant error codes.
+	TenantNotFoundError  = 1801 // Tenant is not configured
+	TenantUserLimitError = 1802 // Tenant reached its maximum number of users
 )
@@ -57,12 +57,21 @@ var (
 	ErrMutedInGroup     = errs.NewCodeError(MutedInGroup, "MutedInGroup")
 	ErrMutedGroup       = errs.NewCodeError(MutedGroup, "MutedGroup")
 	ErrMsgAlreadyRevoke = errs.NewCodeError(MsgAlreadyRevoke, "MsgAlreadyRevoke")
+	ErrMsgEditExpired   = errs.NewCodeError(MsgEditExpired, "MsgEditExpired")
+	ErrMsgEditConflict  = errs.NewCodeError(MsgEditConflict, "MsgEditConflict")
+	ErrMsgBlocked       = errs.NewCodeError(MsgBlocked, "MsgBlocked")
+	ErrMsgReactionLimit = errs.NewCodeError(MsgReactionLimit, "MsgReactionLimit")
 
 	ErrConnOverMaxNumLimit = errs.NewCodeError(ConnOverMaxNumLimit, "ConnOverMaxNumLimit")
 
 	ErrConnArgsErr          = errs.NewCodeError(ConnArgsErr, "args err, need token, sendID, platformID")
 	ErrPushMsgErr           = errs.NewCodeError(PushMsgErr, "push msg err")
 	ErrIOSBackgroundPushErr = errs.NewCodeError(IOSBackgroundPushErr, "ios background push err")
+	ErrReqRateLimit         = errs.NewCodeError(ReqRateLimitErr, "request rate limited")
+	ErrConnDraining         = errs.NewCodeError(ConnDrainingErr, "gateway is draining")
 
 	ErrFileUploadedExpired = errs.NewCodeError(FileUploadedExpiredError, "FileUploadedExpiredError")
+
+	ErrTenantNotFound  = errs.NewCodeError(TenantNotFoundError, "TenantNotFoundError")
+	ErrTenantUserLimit = errs.NewCodeError(TenantUserLimitError, "TenantUserLimitError")
 )
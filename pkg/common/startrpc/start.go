@@ -30,6 +30,7 @@ import (
 
 	kdisc "github.com/openimsdk/open-im-server/v3/pkg/common/discoveryregister"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/prommetrics"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/tools/discovery"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
@@ -43,6 +44,14 @@ import (
 func Start[T any](ctx context.Context, discovery *config.Discovery, prometheusConfig *config.Prometheus, listenIP,
 	registerIP string, rpcPorts []int, index int, rpcRegisterName string, share *config.Share, config T, rpcFn func(ctx context.Context,
 	config T, client discovery.SvcDiscoveryRegistry, server *grpc.Server) error, options ...grpc.ServerOption) error {
+	return StartWithBeforeStop(ctx, discovery, prometheusConfig, listenIP, registerIP, rpcPorts, index, rpcRegisterName, share, config, rpcFn, nil, options...)
+}
+
+// StartWithBeforeStop starts the rpc server like Start and, on SIGTERM, calls beforeStop
+// while the server is still registered and serving, then stops the server.
+func StartWithBeforeStop[T any](ctx context.Context, discovery *config.Discovery, prometheusConfig *config.Prometheus, listenIP,
+	registerIP string, rpcPorts []int, index int, rpcRegisterName string, share *config.Share, config T, rpcFn func(ctx context.Context,
+	config T, client discovery.SvcDiscoveryRegistry, server *grpc.Server) error, beforeStop func(), options ...grpc.ServerOption) error {
 
 	rpcPort, err := datautil.GetElemByIndex(rpcPorts, index)
 	if err != nil {
@@ -51,6 +60,7 @@ func Start[T any](ctx context.Context, discovery *config.Discovery, prometheusCo
 
 	log.CInfo(ctx, "RPC server is initializing", "rpcRegisterName", rpcRegisterName, "rpcPort", rpcPort,
 		"prometheusPorts", prometheusConfig.Ports)
+	tenant.Init(share)
 	rpcTcpAddr := net.JoinHostPort(network.GetListenIP(listenIP), strconv.Itoa(rpcPort))
 
 	listener, err := net.Listen(
@@ -145,6 +155,9 @@ func Start[T any](ctx context.Context, discovery *config.Discovery, prometheusCo
 	signal.Notify(sigs, syscall.SIGTERM)
 	select {
 	case <-sigs:
+		if beforeStop != nil {
+			beforeStop()
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := gracefulStopWithCtx(ctx, srv.GracefulStop); err != nil {
@@ -29,6 +29,7 @@ const (
 	reactionWriteGroup   = "EX_GROUP_"
 	reactionReadGroup    = "EX_SUPER_GROUP_"
 	reactionNotification = "EX_NOTIFICATION_"
+	groupReadReceipt     = "GROUP_READ_RECEIPT:"
 )
 
 func GetMessageCacheKey(conversationID string, seq int64) string {
@@ -64,3 +65,7 @@ func GetLockMessageTypeKey(clientMsgID string, TypeKey string) string {
 func GetSendMsgKey(id string) string {
 	return sendMsgFailedFlag + id
 }
+
+func GetGroupReadReceiptKey(conversationID string, seq int64) string {
+	return groupReadReceipt + conversationID + ":" + strconv.Itoa(int(seq))
+}
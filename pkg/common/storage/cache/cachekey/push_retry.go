@@ -0,0 +1,44 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cachekey
+
+// The keys of the push retry queue share a hash tag, so its scripts run on one redis cluster node.
+const (
+	pushRetryQueue      = "{PUSH_RETRY}:QUEUE"
+	pushRetryItem       = "{PUSH_RETRY}:ITEM"
+	pushRetryLease      = "{PUSH_RETRY}:LEASE"
+	pushDeadLetterQueue = "{PUSH_RETRY}:DEAD_LETTER"
+	pushDeadLetterItem  = "{PUSH_RETRY}:DEAD_LETTER_ITEM"
+)
+
+func GetPushRetryQueueKey() string {
+	return pushRetryQueue
+}
+
+func GetPushRetryItemKey() string {
+	return pushRetryItem
+}
+
+func GetPushRetryLeaseKey() string {
+	return pushRetryLease
+}
+
+func GetPushDeadLetterQueueKey() string {
+	return pushDeadLetterQueue
+}
+
+func GetPushDeadLetterItemKey() string {
+	return pushDeadLetterItem
+}
@@ -21,10 +21,20 @@ import (
 const (
 	getuiToken              = "GETUI_TOKEN"
 	getuiTaskID             = "GETUI_TASK_ID"
+	device                  = "DEVICE:"
 	fmcToken                = "FCM_TOKEN:"
 	userBadgeUnreadCountSum = "USER_BADGE_UNREAD_COUNT_SUM:"
 )
 
+func GetDeviceKey(userID string) string {
+	return device + userID
+}
+
+func GetDeviceField(platformID int) string {
+	return strconv.Itoa(platformID)
+}
+
+// GetFcmAccountTokenKey is the key the token of a platform was kept in before the devices, it is only read to move it into the device hash.
 func GetFcmAccountTokenKey(account string, platformID int) string {
 	return fmcToken + account + ":" + strconv.Itoa(platformID)
 }
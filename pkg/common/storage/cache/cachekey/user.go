@@ -17,6 +17,7 @@ package cachekey
 const (
 	UserInfoKey             = "USER_INFO:"
 	UserGlobalRecvMsgOptKey = "USER_GLOBAL_RECV_MSG_OPT_KEY:"
+	NotificationSettingKey  = "NOTIFICATION_SETTING:"
 )
 
 func GetUserInfoKey(userID string) string {
@@ -26,3 +27,7 @@ func GetUserInfoKey(userID string) string {
 func GetUserGlobalRecvMsgOptKey(userID string) string {
 	return UserGlobalRecvMsgOptKey + userID
 }
+
+func GetNotificationSettingKey(userID string) string {
+	return NotificationSettingKey + userID
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
 	"github.com/openimsdk/protocol/sdkws"
 )
 
@@ -35,4 +36,7 @@ type MsgCache interface {
 	SetMessageTypeKeyValue(ctx context.Context, clientMsgID string, sessionType int32, typeKey, value string) error
 	LockMessageTypeKey(ctx context.Context, clientMsgID string, TypeKey string) error
 	UnLockMessageTypeKey(ctx context.Context, clientMsgID string, TypeKey string) error
+	GetGroupReadReceipt(ctx context.Context, conversationID string, seq int64) (*model.GroupReadReceipt, error)
+	SetGroupReadReceipt(ctx context.Context, conversationID string, seq int64, receipt *model.GroupReadReceipt, expire time.Duration) error
+	DelGroupReadReceipts(ctx context.Context, conversationID string, seqs []int64) error
 }
@@ -0,0 +1,40 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+// PushRetryCache is the delayed queue of the failed offline pushes and their dead letters.
+type PushRetryCache interface {
+	// AddPushRetry queues retry to be pushed again at dueTime.
+	AddPushRetry(ctx context.Context, retry *model.PushRetry, dueTime time.Time) error
+	// LeaseDuePushRetries leases and returns at most limit retries due at now until now+leaseTime.
+	// A leased retry is due again once its lease expires, unless DonePushRetry was called for it.
+	LeaseDuePushRetries(ctx context.Context, now time.Time, leaseTime time.Duration, limit int) ([]*model.PushRetry, error)
+	// DonePushRetry ends the lease of retryID and removes the retry, unless it was queued again in the meantime.
+	DonePushRetry(ctx context.Context, retryID string) error
+	// AddPushDeadLetter keeps retry as a dead letter, the oldest dead letters beyond maxNum are removed.
+	AddPushDeadLetter(ctx context.Context, retry *model.PushRetry, maxNum int) error
+	// GetPushDeadLetters returns the dead letters, the latest first.
+	GetPushDeadLetters(ctx context.Context, pagination pagination.Pagination) (int64, []*model.PushRetry, error)
+	// TakePushDeadLetters removes and returns the dead letters of retryIDs which exist.
+	TakePushDeadLetters(ctx context.Context, retryIDs []string) ([]*model.PushRetry, error)
+}
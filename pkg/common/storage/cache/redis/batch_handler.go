@@ -77,7 +77,8 @@ func (c *BatchDeleterRedis) execDel(ctx context.Context, keys []string) error {
 			keysByTopic := localcache.GetPublishKeysByTopic(c.redisPubTopics, keys)
 			for topic, keys := range keysByTopic {
 				if len(keys) > 0 {
-					data, err := json.Marshal(keys)
+					// Local caches scope their keys by tenant, the subscribers delete the scoped keys.
+					data, err := json.Marshal(tenant.PrefixKeys(ctx, keys))
 					if err != nil {
 						log.ZWarn(ctx, "keys json marshal failed", err, "topic", topic, "keys", keys)
 					} else {
@@ -16,8 +16,10 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/cachekey"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
 	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
 	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/tools/errs"
@@ -106,6 +108,35 @@ func (c *msgCache) GetSendMsgStatus(ctx context.Context, id string) (int32, erro
 	return int32(result), errs.Wrap(err)
 }
 
+func (c *msgCache) GetGroupReadReceipt(ctx context.Context, conversationID string, seq int64) (*model.GroupReadReceipt, error) {
+	data, err := c.rdb.Get(ctx, cachekey.GetGroupReadReceiptKey(conversationID, seq)).Bytes()
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var receipt model.GroupReadReceipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, errs.WrapMsg(err, "unmarshal group read receipt failed")
+	}
+	return &receipt, nil
+}
+
+func (c *msgCache) SetGroupReadReceipt(ctx context.Context, conversationID string, seq int64, receipt *model.GroupReadReceipt, expire time.Duration) error {
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		return errs.WrapMsg(err, "marshal group read receipt failed")
+	}
+	return errs.Wrap(c.rdb.Set(ctx, cachekey.GetGroupReadReceiptKey(conversationID, seq), data, expire).Err())
+}
+
+func (c *msgCache) DelGroupReadReceipts(ctx context.Context, conversationID string, seqs []int64) error {
+	keys := datautil.Slice(seqs, func(seq int64) string {
+		return cachekey.GetGroupReadReceiptKey(conversationID, seq)
+	})
+	return ProcessKeysBySlot(ctx, c.rdb, keys, func(ctx context.Context, slot int64, keys []string) error {
+		return LuaDeleteBatch(ctx, c.rdb, keys)
+	})
+}
+
 func (c *msgCache) LockMessageTypeKey(ctx context.Context, clientMsgID string, TypeKey string) error {
 	key := c.getLockMessageTypeKey(clientMsgID, TypeKey)
 	return errs.Wrap(c.rdb.SetNX(ctx, key, 1, time.Minute).Err())
@@ -0,0 +1,74 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/dtm-labs/rockscache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/cachekey"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const notificationSettingExpireTime = time.Second * 60 * 60 * 12
+
+type NotificationSettingCacheRedis struct {
+	cache.BatchDeleter
+	expireTime time.Duration
+	rcClient   *rockscache.Client
+	settingDB  database.NotificationSetting
+}
+
+func NewNotificationSettingCacheRedis(rdb redis.UniversalClient, settingDB database.NotificationSetting, options *rockscache.Options) cache.NotificationSettingCache {
+	return &NotificationSettingCacheRedis{
+		BatchDeleter: NewBatchDeleterRedis(rdb, options, nil),
+		expireTime:   notificationSettingExpireTime,
+		rcClient:     rockscache.NewClient(rdb, *options),
+		settingDB:    settingDB,
+	}
+}
+
+func (n *NotificationSettingCacheRedis) CloneNotificationSettingCache() cache.NotificationSettingCache {
+	return &NotificationSettingCacheRedis{
+		BatchDeleter: n.BatchDeleter.Clone(),
+		expireTime:   n.expireTime,
+		rcClient:     n.rcClient,
+		settingDB:    n.settingDB,
+	}
+}
+
+func (n *NotificationSettingCacheRedis) getNotificationSettingKey(userID string) string {
+	return cachekey.GetNotificationSettingKey(userID)
+}
+
+func (n *NotificationSettingCacheRedis) GetNotificationSettings(ctx context.Context, userIDs []string) ([]*model.NotificationSetting, error) {
+	return batchGetCache2(ctx, n.rcClient, n.expireTime, userIDs, n.getNotificationSettingKey, func(setting *model.NotificationSetting) string {
+		return setting.UserID
+	}, n.settingDB.Find)
+}
+
+func (n *NotificationSettingCacheRedis) DelNotificationSettings(userIDs ...string) cache.NotificationSettingCache {
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, n.getNotificationSettingKey(userID))
+	}
+	cache := n.CloneNotificationSettingCache()
+	cache.AddKeys(keys...)
+	return cache
+}
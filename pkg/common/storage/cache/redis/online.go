@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/cachekey"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/log"
@@ -125,7 +126,8 @@ func (s *userOnline) SetUserOnline(ctx context.Context, userID string, online, o
 	}
 	if platformIDs[len(platformIDs)-1] != "0" {
 		log.ZDebug(ctx, "redis SetUserOnline push", "userID", userID, "online", online, "offline", offline, "platformIDs", platformIDs[:len(platformIDs)-1])
-		platformIDs[len(platformIDs)-1] = userID
+		// The subscribers restore the tenant of the change with tenant.FromMQKey.
+		platformIDs[len(platformIDs)-1] = tenant.MQKey(ctx, userID)
 		msg := strings.Join(platformIDs, ":")
 		if err := s.rdb.Publish(ctx, s.channelName, msg).Err(); err != nil {
 			return errs.Wrap(err)
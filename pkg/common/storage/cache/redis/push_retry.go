@@ -0,0 +1,199 @@
+// Copyright © 2023 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/cachekey"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+	"github.com/openimsdk/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// leaseDuePushRetriesScript queues again the retries of the leases KEYS[3] expired at ARGV[1], then moves the retries
+	// due at ARGV[1] from the queue KEYS[1] to the leases scored ARGV[2] and returns their items of KEYS[2].
+	leaseDuePushRetriesScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+if #expired > 0 then
+    for _, id in ipairs(expired) do
+        redis.call('ZADD', KEYS[1], 'NX', ARGV[1], id)
+    end
+    redis.call('ZREM', KEYS[3], unpack(expired))
+end
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+if #ids == 0 then
+    return {}
+end
+redis.call('ZREM', KEYS[1], unpack(ids))
+local items = redis.call('HMGET', KEYS[2], unpack(ids))
+for i, item in ipairs(items) do
+    if item then
+        redis.call('ZADD', KEYS[3], ARGV[2], ids[i])
+    end
+end
+return items
+`)
+
+	// donePushRetryScript removes the lease of ARGV[1] from KEYS[3], and its item of KEYS[2] unless it is back in the queue KEYS[1].
+	donePushRetryScript = redis.NewScript(`
+redis.call('ZREM', KEYS[3], ARGV[1])
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+    redis.call('HDEL', KEYS[2], ARGV[1])
+end
+return 0
+`)
+
+	// addPushDeadLetterScript adds the dead letter ARGV[1] with the item ARGV[2] scored ARGV[3] to the queue KEYS[1] and its items KEYS[2],
+	// then removes the oldest dead letters beyond ARGV[4].
+	addPushDeadLetterScript = redis.NewScript(`
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+local n = redis.call('ZCARD', KEYS[1]) - tonumber(ARGV[4])
+if n <= 0 then
+    return 0
+end
+local ids = redis.call('ZRANGE', KEYS[1], 0, n - 1)
+redis.call('ZREM', KEYS[1], unpack(ids))
+redis.call('HDEL', KEYS[2], unpack(ids))
+return n
+`)
+
+	// takePushDeadLettersScript removes the dead letters ARGV from the queue KEYS[1] and returns their items of KEYS[2].
+	takePushDeadLettersScript = redis.NewScript(`
+local items = redis.call('HMGET', KEYS[2], unpack(ARGV))
+redis.call('ZREM', KEYS[1], unpack(ARGV))
+redis.call('HDEL', KEYS[2], unpack(ARGV))
+return items
+`)
+)
+
+func NewPushRetryCache(rdb redis.UniversalClient) cache.PushRetryCache {
+	return &pushRetryCache{rdb: rdb}
+}
+
+// pushRetryCache keeps the retries in a sorted set scored by the unix millisecond they are due at
+// next to a hash of their items, the leased retries in a sorted set scored by the end of their lease,
+// and the dead letters the same way as the retries scored by the time they died.
+type pushRetryCache struct {
+	rdb redis.UniversalClient
+}
+
+func (c *pushRetryCache) add(ctx context.Context, queueKey, itemKey string, retry *model.PushRetry, score int64) error {
+	data, err := json.Marshal(retry)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, itemKey, retry.RetryID, data)
+		pipe.ZAdd(ctx, queueKey, redis.Z{Score: float64(score), Member: retry.RetryID})
+		return nil
+	})
+	return errs.Wrap(err)
+}
+
+func (c *pushRetryCache) AddPushRetry(ctx context.Context, retry *model.PushRetry, dueTime time.Time) error {
+	return c.add(ctx, cachekey.GetPushRetryQueueKey(), cachekey.GetPushRetryItemKey(), retry, dueTime.UnixMilli())
+}
+
+func (c *pushRetryCache) LeaseDuePushRetries(ctx context.Context, now time.Time, leaseTime time.Duration, limit int) ([]*model.PushRetry, error) {
+	res, err := callLua(ctx, c.rdb, leaseDuePushRetriesScript,
+		[]string{cachekey.GetPushRetryQueueKey(), cachekey.GetPushRetryItemKey(), cachekey.GetPushRetryLeaseKey()},
+		[]any{now.UnixMilli(), now.Add(leaseTime).UnixMilli(), limit})
+	if err != nil {
+		return nil, err
+	}
+	return decodePushRetries(res)
+}
+
+func (c *pushRetryCache) DonePushRetry(ctx context.Context, retryID string) error {
+	_, err := callLua(ctx, c.rdb, donePushRetryScript,
+		[]string{cachekey.GetPushRetryQueueKey(), cachekey.GetPushRetryItemKey(), cachekey.GetPushRetryLeaseKey()}, []any{retryID})
+	return err
+}
+
+func (c *pushRetryCache) AddPushDeadLetter(ctx context.Context, retry *model.PushRetry, maxNum int) error {
+	data, err := json.Marshal(retry)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	_, err = callLua(ctx, c.rdb, addPushDeadLetterScript, []string{cachekey.GetPushDeadLetterQueueKey(), cachekey.GetPushDeadLetterItemKey()},
+		[]any{retry.RetryID, data, time.Now().UnixMilli(), maxNum})
+	return err
+}
+
+func (c *pushRetryCache) GetPushDeadLetters(ctx context.Context, pagination pagination.Pagination) (int64, []*model.PushRetry, error) {
+	total, err := c.rdb.ZCard(ctx, cachekey.GetPushDeadLetterQueueKey()).Result()
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	start := int64(pagination.GetPageNumber()-1) * int64(pagination.GetShowNumber())
+	stop := start + int64(pagination.GetShowNumber()) - 1
+	ids, err := c.rdb.ZRevRange(ctx, cachekey.GetPushDeadLetterQueueKey(), start, stop).Result()
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	if len(ids) == 0 {
+		return total, nil, nil
+	}
+	items, err := c.rdb.HMGet(ctx, cachekey.GetPushDeadLetterItemKey(), ids...).Result()
+	if err != nil {
+		return 0, nil, errs.Wrap(err)
+	}
+	retries, err := decodePushRetries(items)
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, retries, nil
+}
+
+func (c *pushRetryCache) TakePushDeadLetters(ctx context.Context, retryIDs []string) ([]*model.PushRetry, error) {
+	if len(retryIDs) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, len(retryIDs))
+	for _, retryID := range retryIDs {
+		args = append(args, retryID)
+	}
+	res, err := callLua(ctx, c.rdb, takePushDeadLettersScript,
+		[]string{cachekey.GetPushDeadLetterQueueKey(), cachekey.GetPushDeadLetterItemKey()}, args)
+	if err != nil {
+		return nil, err
+	}
+	return decodePushRetries(res)
+}
+
+// decodePushRetries decodes the items returned by redis, the missing ones are skipped.
+func decodePushRetries(res any) ([]*model.PushRetry, error) {
+	items, _ := res.([]any)
+	retries := make([]*model.PushRetry, 0, len(items))
+	for _, item := range items {
+		data, ok := item.(string)
+		if !ok {
+			continue
+		}
+		var retry model.PushRetry
+		if err := json.Unmarshal([]byte(data), &retry); err != nil {
+			return nil, errs.WrapMsg(err, "unmarshal push retry", "data", data)
+		}
+		retries = append(retries, &retry)
+	}
+	return retries, nil
+}
@@ -162,6 +162,32 @@ func (s *seqUserCacheRedis) GetUserReadSeqs(ctx context.Context, userID string,
 	return data, nil
 }
 
+func (s *seqUserCacheRedis) GetUsersReadSeq(ctx context.Context, conversationID string, userIDs []string) (map[string]int64, error) {
+	res, err := batchGetCache2(ctx, s.rocks, s.readExpireTime, userIDs, func(userID string) string {
+		return s.getSeqUserReadSeqKey(conversationID, userID)
+	}, func(v *userReadSeqModel) string {
+		return v.UserID
+	}, func(ctx context.Context, userIDs []string) ([]*userReadSeqModel, error) {
+		seqs, err := s.mgo.GetUsersReadSeq(ctx, conversationID, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]*userReadSeqModel, 0, len(seqs))
+		for userID, seq := range seqs {
+			res = append(res, &userReadSeqModel{UserID: userID, Seq: seq})
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]int64)
+	for _, v := range res {
+		data[v.UserID] = v.Seq
+	}
+	return data, nil
+}
+
 var _ BatchCacheCallback[string] = (*readSeqModel)(nil)
 
 type readSeqModel struct {
@@ -181,3 +207,24 @@ func (r *readSeqModel) UnmarshalJSON(bytes []byte) (err error) {
 func (r *readSeqModel) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.FormatInt(r.Seq, 10)), nil
 }
+
+var _ BatchCacheCallback[string] = (*userReadSeqModel)(nil)
+
+// userReadSeqModel shares the cache value format of readSeqModel, but is keyed by user.
+type userReadSeqModel struct {
+	UserID string
+	Seq    int64
+}
+
+func (r *userReadSeqModel) BatchCache(userID string) {
+	r.UserID = userID
+}
+
+func (r *userReadSeqModel) UnmarshalJSON(bytes []byte) (err error) {
+	r.Seq, err = strconv.ParseInt(string(bytes), 10, 64)
+	return
+}
+
+func (r *userReadSeqModel) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(r.Seq, 10)), nil
+}
@@ -0,0 +1,163 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+// AddTenantHook scopes the keys of every command sent through rdb to the tenant of the command context,
+// so the caches need no change to keep the data of the tenants apart.
+func AddTenantHook(rdb redis.UniversalClient) {
+	rdb.AddHook(tenantHook{})
+}
+
+type tenantHook struct{}
+
+func (tenantHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (tenantHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		tenantID := tenant.GetTenantID(ctx)
+		if tenantID == "" {
+			return next(ctx, cmd)
+		}
+		if err := prefixCmdKeys(tenantID, cmd.Args()); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		if err := next(ctx, cmd); err != nil {
+			return err
+		}
+		trimCmdKeys(tenantID, cmd)
+		return nil
+	}
+}
+
+func (tenantHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		tenantID := tenant.GetTenantID(ctx)
+		if tenantID == "" {
+			return next(ctx, cmds)
+		}
+		for _, cmd := range cmds {
+			if err := prefixCmdKeys(tenantID, cmd.Args()); err != nil {
+				cmd.SetErr(err)
+				return err
+			}
+		}
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			if cmd.Err() == nil {
+				trimCmdKeys(tenantID, cmd)
+			}
+		}
+		return err
+	}
+}
+
+// prefixCmdKeys rewrites the key arguments of a command in place. The patterns of scan and keys are
+// scoped too, a scan without a MATCH pattern would walk the keys of every tenant and is rejected.
+func prefixCmdKeys(tenantID string, args []any) error {
+	if len(args) < 2 {
+		return nil
+	}
+	name, _ := args[0].(string)
+	prefix := func(i int) {
+		if key, ok := args[i].(string); ok {
+			args[i] = tenant.PrefixTenant(tenantID, key)
+		}
+	}
+	switch strings.ToLower(name) {
+	case "ping", "echo", "publish", "spublish", "info", "select", "auth", "hello", "client", "script", "function",
+		"dbsize", "flushdb", "flushall", "time", "config", "command", "multi", "exec", "discard", "unwatch",
+		"readonly", "readwrite", "quit", "wait", "memory", "slowlog", "role":
+		// No key.
+	case "scan":
+		for i := 2; i < len(args)-1; i++ {
+			if option, _ := args[i].(string); strings.EqualFold(option, "match") {
+				prefix(i + 1)
+				return nil
+			}
+		}
+		return errs.ErrInternalServer.WrapMsg("scan without MATCH pattern is not allowed for a tenant", "tenantID", tenantID)
+	case "keys":
+		prefix(1)
+	case "cluster":
+		if sub, _ := args[1].(string); strings.EqualFold(sub, "keyslot") && len(args) > 2 {
+			prefix(2)
+		}
+	case "del", "exists", "unlink", "touch", "watch", "mget", "sinter", "sunion", "sdiff",
+		"sinterstore", "sunionstore", "sdiffstore", "pfcount", "pfmerge", "rename", "renamenx":
+		for i := 1; i < len(args); i++ {
+			prefix(i)
+		}
+	case "mset", "msetnx":
+		for i := 1; i < len(args); i += 2 {
+			prefix(i)
+		}
+	case "blpop", "brpop":
+		for i := 1; i < len(args)-1; i++ {
+			prefix(i)
+		}
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		if len(args) < 3 {
+			return nil
+		}
+		var n int
+		switch v := args[2].(type) {
+		case int:
+			n = v
+		case int64:
+			n = int(v)
+		}
+		for i := 3; i < 3+n && i < len(args); i++ {
+			prefix(i)
+		}
+	default:
+		prefix(1)
+	}
+	return nil
+}
+
+// trimCmdKeys strips the tenant prefix from the keys returned by scan and keys,
+// so the callers can use them with the tenant context again.
+func trimCmdKeys(tenantID string, cmd redis.Cmder) {
+	trim := func(keys []string) []string {
+		res := make([]string, len(keys))
+		for i, key := range keys {
+			res[i] = strings.TrimPrefix(key, tenantID+":")
+		}
+		return res
+	}
+	switch c := cmd.(type) {
+	case *redis.ScanCmd:
+		if strings.EqualFold(c.Name(), "scan") {
+			keys, cursor := c.Val()
+			c.SetVal(trim(keys), cursor)
+		}
+	case *redis.StringSliceCmd:
+		if strings.EqualFold(c.Name(), "keys") {
+			c.SetVal(trim(c.Val()))
+		}
+	}
+}
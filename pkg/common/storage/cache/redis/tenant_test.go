@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixCmdKeys(t *testing.T) {
+	cases := []struct {
+		args   []any
+		expect []any
+	}{
+		{[]any{"get", "k"}, []any{"get", "app1:k"}},
+		{[]any{"hset", "k", "f", "v"}, []any{"hset", "app1:k", "f", "v"}},
+		{[]any{"del", "k1", "k2"}, []any{"del", "app1:k1", "app1:k2"}},
+		{[]any{"mset", "k1", "v1", "k2", "v2"}, []any{"mset", "app1:k1", "v1", "app1:k2", "v2"}},
+		{[]any{"evalsha", "sha", 2, "k1", "k2", "a1"}, []any{"evalsha", "sha", 2, "app1:k1", "app1:k2", "a1"}},
+		{[]any{"cluster", "keyslot", "k"}, []any{"cluster", "keyslot", "app1:k"}},
+		{[]any{"publish", "topic", "msg"}, []any{"publish", "topic", "msg"}},
+		{[]any{"ping"}, []any{"ping"}},
+		{[]any{"keys", "ONLINE:*"}, []any{"keys", "app1:ONLINE:*"}},
+		{[]any{"scan", 0, "match", "ONLINE:*", "count", 100}, []any{"scan", 0, "match", "app1:ONLINE:*", "count", 100}},
+		{[]any{"hscan", "k", 0, "match", "f*"}, []any{"hscan", "app1:k", 0, "match", "f*"}},
+	}
+	for _, c := range cases {
+		assert.NoError(t, prefixCmdKeys("app1", c.args))
+		assert.Equal(t, c.expect, c.args)
+	}
+	assert.Error(t, prefixCmdKeys("app1", []any{"scan", 0, "count", 100}), "scan of every tenant")
+}
+
+func TestTrimCmdKeys(t *testing.T) {
+	ctx := context.Background()
+	scan := redis.NewScanCmd(ctx, nil, "scan", 0, "match", "app1:ONLINE:*")
+	scan.SetVal([]string{"app1:ONLINE:u1", "app1:ONLINE:u2"}, 7)
+	trimCmdKeys("app1", scan)
+	keys, cursor := scan.Val()
+	assert.Equal(t, []string{"ONLINE:u1", "ONLINE:u2"}, keys)
+	assert.Equal(t, uint64(7), cursor)
+
+	hscan := redis.NewScanCmd(ctx, nil, "hscan", "app1:k", 0)
+	hscan.SetVal([]string{"app1:f", "v"}, 0)
+	trimCmdKeys("app1", hscan)
+	keys, _ = hscan.Val()
+	assert.Equal(t, []string{"app1:f", "v"}, keys, "fields are not keys")
+
+	get := redis.NewStringSliceCmd(ctx, "keys", "app1:*")
+	get.SetVal([]string{"app1:a"})
+	trimCmdKeys("app1", get)
+	assert.Equal(t, []string{"a"}, get.Val())
+}
@@ -16,11 +16,17 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache/cachekey"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
 	"github.com/redis/go-redis/v9"
-	"time"
 )
 
 func NewThirdCache(rdb redis.UniversalClient) cache.ThirdCache {
@@ -43,24 +49,179 @@ func (c *thirdCache) getUserBadgeUnreadCountSumKey(userID string) string {
 	return cachekey.GetUserBadgeUnreadCountSumKey(userID)
 }
 
-func (c *thirdCache) getFcmAccountTokenKey(account string, platformID int) string {
-	return cachekey.GetFcmAccountTokenKey(account, platformID)
+func (c *thirdCache) getDeviceKey(userID string) string {
+	return cachekey.GetDeviceKey(userID)
+}
+
+// legacyTokenPlatformIDs are the platforms whose tokens may still be in the FCM_TOKEN keys written before the devices,
+// they are read along with the devices and moved into the device hash of the user when one of its devices is set.
+var legacyTokenPlatformIDs = []int{constant.IOSPlatformID, constant.AndroidPlatformID, constant.IPadPlatformID, constant.AndroidPadPlatformID}
+
+// getDevices reads the device hash of userID together with its FCM_TOKEN keys in one round trip. It returns the unexpired
+// devices by field, the legacy tokens of the platforms without a device as devices, and the fields of the expired devices.
+func (c *thirdCache) getDevices(ctx context.Context, userID string) (map[string]*model.Device, []*model.Device, []string, error) {
+	type legacyToken struct {
+		platformID int
+		token      *redis.StringCmd
+		ttl        *redis.DurationCmd
+	}
+	pipe := c.rdb.Pipeline()
+	hash := pipe.HGetAll(ctx, c.getDeviceKey(userID))
+	tokens := make([]legacyToken, 0, len(legacyTokenPlatformIDs))
+	for _, platformID := range legacyTokenPlatformIDs {
+		key := cachekey.GetFcmAccountTokenKey(userID, platformID)
+		tokens = append(tokens, legacyToken{platformID: platformID, token: pipe.Get(ctx, key), ttl: pipe.TTL(ctx, key)})
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, nil, nil, errs.Wrap(err)
+	}
+	values, err := hash.Result()
+	if err != nil {
+		return nil, nil, nil, errs.Wrap(err)
+	}
+	now := time.Now().Unix()
+	devices := make(map[string]*model.Device, len(values))
+	var expired []string
+	for field, value := range values {
+		var device model.Device
+		if err := json.Unmarshal([]byte(value), &device); err != nil {
+			return nil, nil, nil, errs.WrapMsg(err, "unmarshal device", "userID", userID, "field", field)
+		}
+		if device.ExpireTime > 0 && device.ExpireTime <= now {
+			expired = append(expired, field)
+			continue
+		}
+		devices[field] = &device
+	}
+	var legacy []*model.Device
+	for _, token := range tokens {
+		if _, ok := devices[cachekey.GetDeviceField(token.platformID)]; ok {
+			continue
+		}
+		val, err := token.token.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		} else if err != nil {
+			return nil, nil, nil, errs.Wrap(err)
+		}
+		device := &model.Device{UserID: userID, PlatformID: token.platformID, Token: val, UpdateTime: now}
+		if ttl := token.ttl.Val(); ttl > 0 {
+			device.ExpireTime = now + int64(ttl/time.Second)
+		}
+		legacy = append(legacy, device)
+	}
+	return devices, legacy, expired, nil
+}
+
+// setDevices writes the devices of set into the device hash of userID and removes the expired fields, devices are all the
+// devices of the user once written. The hash expires with the last of them, it is kept while one of them never expires.
+func (c *thirdCache) setDevices(ctx context.Context, userID string, devices map[string]*model.Device, set []*model.Device, expired []string) error {
+	values := make([]any, 0, len(set)*2)
+	for _, device := range set {
+		data, err := json.Marshal(device)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		values = append(values, cachekey.GetDeviceField(device.PlatformID), data)
+	}
+	var expireTime int64
+	for _, device := range devices {
+		if device.ExpireTime == 0 {
+			expireTime = 0
+			break
+		}
+		expireTime = max(expireTime, device.ExpireTime)
+	}
+	key := c.getDeviceKey(userID)
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(expired) > 0 {
+			pipe.HDel(ctx, key, expired...)
+		}
+		if len(values) > 0 {
+			pipe.HSet(ctx, key, values...)
+		}
+		if expireTime > 0 {
+			pipe.ExpireAt(ctx, key, time.Unix(expireTime, 0))
+		} else {
+			pipe.Persist(ctx, key)
+		}
+		return nil
+	})
+	return errs.Wrap(err)
+}
+
+// SetDevice also moves the legacy tokens of userID into its device hash, the one of the platform of device is replaced.
+func (c *thirdCache) SetDevice(ctx context.Context, device *model.Device) error {
+	devices, legacy, expired, err := c.getDevices(ctx, device.UserID)
+	if err != nil {
+		return err
+	}
+	set := []*model.Device{device}
+	for _, legacyDevice := range legacy {
+		if legacyDevice.PlatformID != device.PlatformID {
+			devices[cachekey.GetDeviceField(legacyDevice.PlatformID)] = legacyDevice
+			set = append(set, legacyDevice)
+		}
+	}
+	field := cachekey.GetDeviceField(device.PlatformID)
+	devices[field] = device
+	expired = datautil.Filter(expired, func(e string) (string, bool) { return e, e != field })
+	if err := c.setDevices(ctx, device.UserID, devices, set, expired); err != nil {
+		return err
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+	pipe := c.rdb.Pipeline()
+	for _, legacyDevice := range legacy {
+		pipe.Del(ctx, cachekey.GetFcmAccountTokenKey(device.UserID, legacyDevice.PlatformID))
+	}
+	_, err = pipe.Exec(ctx)
+	return errs.Wrap(err)
+}
+
+func (c *thirdCache) GetDevices(ctx context.Context, userID string) ([]*model.Device, error) {
+	devices, legacy, _, err := c.getDevices(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(datautil.Values(devices), legacy...), nil
+}
+
+// DelDevice also deletes the FCM_TOKEN key of the platform so that it is not read back into the hash.
+func (c *thirdCache) DelDevice(ctx context.Context, userID string, platformID int) error {
+	pipe := c.rdb.Pipeline()
+	pipe.HDel(ctx, c.getDeviceKey(userID), cachekey.GetDeviceField(platformID))
+	pipe.Del(ctx, cachekey.GetFcmAccountTokenKey(userID, platformID))
+	_, err := pipe.Exec(ctx)
+	return errs.Wrap(err)
 }
 
 func (c *thirdCache) SetFcmToken(ctx context.Context, account string, platformID int, fcmToken string, expireTime int64) (err error) {
-	return errs.Wrap(c.rdb.Set(ctx, c.getFcmAccountTokenKey(account, platformID), fcmToken, time.Duration(expireTime)*time.Second).Err())
+	now := time.Now().Unix()
+	device := &model.Device{UserID: account, PlatformID: platformID, Token: fcmToken, UpdateTime: now}
+	if expireTime > 0 {
+		device.ExpireTime = now + expireTime
+	}
+	return c.SetDevice(ctx, device)
 }
 
+// GetFcmToken returns the token of the device of platformID, the error is redis.Nil when there is none.
 func (c *thirdCache) GetFcmToken(ctx context.Context, account string, platformID int) (string, error) {
-	val, err := c.rdb.Get(ctx, c.getFcmAccountTokenKey(account, platformID)).Result()
+	devices, err := c.GetDevices(ctx, account)
 	if err != nil {
-		return "", errs.Wrap(err)
+		return "", err
 	}
-	return val, nil
+	for _, device := range devices {
+		if device.PlatformID == platformID {
+			return device.Token, nil
+		}
+	}
+	return "", errs.Wrap(redis.Nil)
 }
 
 func (c *thirdCache) DelFcmToken(ctx context.Context, account string, platformID int) error {
-	return errs.Wrap(c.rdb.Del(ctx, c.getFcmAccountTokenKey(account, platformID)).Err())
+	return c.DelDevice(ctx, account, platformID)
 }
 
 func (c *thirdCache) IncrUserBadgeUnreadCountSum(ctx context.Context, userID string) (int, error) {
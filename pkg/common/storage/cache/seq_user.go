@@ -13,4 +13,6 @@ type SeqUser interface {
 	SetUserMinSeqs(ctx context.Context, userID string, seqs map[string]int64) error
 	SetUserReadSeqs(ctx context.Context, userID string, seqs map[string]int64) error
 	GetUserReadSeqs(ctx context.Context, userID string, conversationIDs []string) (map[string]int64, error)
+	// GetUsersReadSeq returns the has read seq of each user in the conversation.
+	GetUsersReadSeq(ctx context.Context, conversationID string, userIDs []string) (map[string]int64, error)
 }
@@ -2,9 +2,19 @@ package cache
 
 import (
 	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
 )
 
 type ThirdCache interface {
+	// SetDevice registers the device of device.UserID for offline push, replacing the one of the same platform.
+	SetDevice(ctx context.Context, device *model.Device) error
+	// GetDevices returns the unexpired devices of userID, with the tokens still in the legacy FCM_TOKEN keys as devices.
+	// It only reads, SetDevice moves the legacy tokens into the devices of the user.
+	GetDevices(ctx context.Context, userID string) ([]*model.Device, error)
+	DelDevice(ctx context.Context, userID string, platformID int) error
+	// SetFcmToken, GetFcmToken and DelFcmToken access the token of the device of a platform,
+	// SetFcmToken registers it for the default provider.
 	SetFcmToken(ctx context.Context, account string, platformID int, fcmToken string, expireTime int64) (err error)
 	GetFcmToken(ctx context.Context, account string, platformID int) (string, error)
 	DelFcmToken(ctx context.Context, account string, platformID int) error
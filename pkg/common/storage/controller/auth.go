@@ -20,6 +20,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/tools/errs"
 	"github.com/openimsdk/tools/tokenverify"
@@ -64,10 +65,10 @@ func (a *authDatabase) CreateToken(ctx context.Context, userID string, platformI
 	var deleteTokenKey []string
 	var kickedTokenKey []string
 	for k, v := range tokens {
-		t, err := tokenverify.GetClaimFromToken(k, authverify.Secret(a.accessSecret))
+		t, err := authverify.GetClaimFromToken(k, authverify.Secret(a.accessSecret))
 		if err != nil || v != constant.NormalToken {
 			deleteTokenKey = append(deleteTokenKey, k)
-		} else if a.checkKickToken(ctx, platformID, t) {
+		} else if a.checkKickToken(ctx, platformID, &t.Claims) {
 			kickedTokenKey = append(kickedTokenKey, k)
 		}
 	}
@@ -86,7 +87,7 @@ func (a *authDatabase) CreateToken(ctx context.Context, userID string, platformI
 		}
 	}
 
-	claims := tokenverify.BuildClaims(userID, platformID, a.accessExpire)
+	claims := authverify.BuildClaims(userID, platformID, tenant.GetTenantID(ctx), a.accessExpire)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(a.accessSecret))
 	if err != nil {
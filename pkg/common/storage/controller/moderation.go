@@ -0,0 +1,56 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type ModerationDatabase interface {
+	CreateModerationReview(ctx context.Context, review *model.ModerationReview) error
+	TakeModerationReview(ctx context.Context, reviewID string) (*model.ModerationReview, error)
+	PageModerationReviews(ctx context.Context, status int32, pagination pagination.Pagination) (int64, []*model.ModerationReview, error)
+	// ReviewModerationMsg settles a review still having status from, so that two admins cannot settle it both.
+	ReviewModerationMsg(ctx context.Context, reviewID string, from int32, to int32, reviewerUserID string, reviewTime time.Time) error
+}
+
+func NewModerationDatabase(review database.ModerationReview) ModerationDatabase {
+	return &moderationDatabase{review: review}
+}
+
+type moderationDatabase struct {
+	review database.ModerationReview
+}
+
+func (m *moderationDatabase) CreateModerationReview(ctx context.Context, review *model.ModerationReview) error {
+	return m.review.Create(ctx, review)
+}
+
+func (m *moderationDatabase) TakeModerationReview(ctx context.Context, reviewID string) (*model.ModerationReview, error) {
+	return m.review.Take(ctx, reviewID)
+}
+
+func (m *moderationDatabase) PageModerationReviews(ctx context.Context, status int32, pagination pagination.Pagination) (int64, []*model.ModerationReview, error) {
+	return m.review.FindPage(ctx, status, pagination)
+}
+
+func (m *moderationDatabase) ReviewModerationMsg(ctx context.Context, reviewID string, from int32, to int32, reviewerUserID string, reviewTime time.Time) error {
+	return m.review.Review(ctx, reviewID, from, to, reviewerUserID, reviewTime)
+}
@@ -18,7 +18,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
@@ -26,7 +25,10 @@ import (
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/convert"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 	"github.com/openimsdk/protocol/constant"
 	pbmsg "github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/protocol/sdkws"
@@ -48,6 +50,15 @@ const (
 type CommonMsgDatabase interface {
 	// RevokeMsg revokes a message in a conversation.
 	RevokeMsg(ctx context.Context, conversationID string, seq int64, revoke *model.RevokeModel) error
+	// EditMsg replaces the content of a message in a conversation and keeps its prior version in the edit history.
+	EditMsg(ctx context.Context, conversationID string, seq int64, content string, prior *model.MsgEditModel) error
+	// SetMsgReaction adds or removes the reaction of a user to a message, it reports whether the reactions changed.
+	SetMsgReaction(ctx context.Context, conversationID string, seq int64, reaction string, userID string, add bool) (bool, error)
+	// SetMsgTranslations stores the translations of the content of a message by language,
+	// they are dropped if the message was edited since content was read.
+	SetMsgTranslations(ctx context.Context, conversationID string, seq int64, content string, translations map[string]string) error
+	// GetMsgSeqByServerMsgID returns the seq of a stored message, it is not cached so it is only used by the admin operations.
+	GetMsgSeqByServerMsgID(ctx context.Context, conversationID string, serverMsgID string) (int64, error)
 	// MarkSingleChatMsgsAsRead marks messages as read for a single chat by sequence numbers.
 	MarkSingleChatMsgsAsRead(ctx context.Context, userID string, conversationID string, seqs []int64) error
 	// GetMsgBySeqsRange retrieves messages from MongoDB by a range of sequence numbers.
@@ -66,12 +77,23 @@ type CommonMsgDatabase interface {
 	//SetMaxSeq(ctx context.Context, conversationID string, maxSeq int64) error
 	GetMaxSeqs(ctx context.Context, conversationIDs []string) (map[string]int64, error)
 	GetMaxSeq(ctx context.Context, conversationID string) (int64, error)
+	// GetMinSeq returns the first seq of the conversation which is not deleted.
+	GetMinSeq(ctx context.Context, conversationID string) (int64, error)
+	// GetUserMinSeq returns the first seq of the conversation the user can read.
+	GetUserMinSeq(ctx context.Context, conversationID string, userID string) (int64, error)
 	SetMinSeqs(ctx context.Context, seqs map[string]int64) error
 
 	SetUserConversationsMinSeqs(ctx context.Context, userID string, seqs map[string]int64) (err error)
 	SetHasReadSeq(ctx context.Context, userID string, conversationID string, hasReadSeq int64) error
 	GetHasReadSeqs(ctx context.Context, userID string, conversationIDs []string) (map[string]int64, error)
 	GetHasReadSeq(ctx context.Context, userID string, conversationID string) (int64, error)
+	// GetUsersHasReadSeq returns the has read seq of each user in the conversation.
+	GetUsersHasReadSeq(ctx context.Context, conversationID string, userIDs []string) (map[string]int64, error)
+	// GetGroupReadReceipt returns the cached read receipt of a group message, redis.Nil is returned when it is not cached.
+	GetGroupReadReceipt(ctx context.Context, conversationID string, seq int64) (*model.GroupReadReceipt, error)
+	SetGroupReadReceipt(ctx context.Context, conversationID string, seq int64, receipt *model.GroupReadReceipt, expire time.Duration) error
+	// DelGroupReadReceipts deletes the cached read receipts of the group messages.
+	DelGroupReadReceipts(ctx context.Context, conversationID string, seqs []int64) error
 	UserSetHasReadSeqs(ctx context.Context, userID string, hasReadSeqs map[string]int64) error
 
 	GetMaxSeqsWithTime(ctx context.Context, conversationIDs []string) (map[string]database.SeqTime, error)
@@ -84,6 +106,9 @@ type CommonMsgDatabase interface {
 	GetSendMsgStatus(ctx context.Context, id string) (int32, error)
 	SearchMessage(ctx context.Context, req *pbmsg.SearchMessageReq) (total int64, msgData []*pbmsg.SearchedMsgData, err error)
 	FindOneByDocIDs(ctx context.Context, docIDs []string, seqs map[string]int64) (map[string]*sdkws.MsgData, error)
+	// RangeMsgs calls fn with the stored messages of a conversation from its min seq to its max seq,
+	// one doc at a time in seq order. Physically deleted messages are skipped, those deleted by some users are not.
+	RangeMsgs(ctx context.Context, conversationID string, fn func(msgs []*model.MsgInfoModel) error) error
 
 	// to mq
 	MsgToMQ(ctx context.Context, key string, msg2mq *sdkws.MsgData) error
@@ -127,7 +152,7 @@ type commonMsgDatabase struct {
 }
 
 func (db *commonMsgDatabase) MsgToMQ(ctx context.Context, key string, msg2mq *sdkws.MsgData) error {
-	_, _, err := db.producer.SendMessage(ctx, key, msg2mq)
+	_, _, err := db.producer.SendMessage(ctx, tenant.MQKey(ctx, key), msg2mq)
 	return err
 }
 
@@ -236,6 +261,74 @@ func (db *commonMsgDatabase) RevokeMsg(ctx context.Context, conversationID strin
 	return db.BatchInsertBlock(ctx, conversationID, []any{revoke}, updateKeyRevoke, seq)
 }
 
+func (db *commonMsgDatabase) EditMsg(ctx context.Context, conversationID string, seq int64, content string, prior *model.MsgEditModel) error {
+	res, err := db.msgDocDatabase.EditMsg(ctx, db.msgTable.GetDocID(conversationID, seq), db.msgTable.GetMsgIndex(seq), content, prior)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return servererrs.ErrMsgEditConflict.WrapMsg("msg content changed", "conversationID", conversationID, "seq", seq)
+	}
+	return db.msg.DeleteMessagesFromCache(ctx, conversationID, []int64{seq})
+}
+
+func (db *commonMsgDatabase) SetMsgReaction(ctx context.Context, conversationID string, seq int64, reaction string, userID string, add bool) (bool, error) {
+	var (
+		res *mongo.UpdateResult
+		err error
+	)
+	docID := db.msgTable.GetDocID(conversationID, seq)
+	index := db.msgTable.GetMsgIndex(seq)
+	if add {
+		res, err = db.msgDocDatabase.AddReaction(ctx, docID, index, reaction, userID, msgext.MaxMsgReactions, msgext.MaxUserReactions)
+	} else {
+		res, err = db.msgDocDatabase.RemoveReaction(ctx, docID, index, reaction, userID)
+	}
+	if err != nil {
+		return false, err
+	}
+	if res.MatchedCount == 0 {
+		if add {
+			// Tell a message at its reaction limits apart from a missing one.
+			doc, err := db.msgDocDatabase.FindOneByDocID(ctx, docID)
+			if err != nil && errs.Unwrap(err) != mongo.ErrNoDocuments {
+				return false, err
+			}
+			if doc != nil && index < int64(len(doc.Msg)) && doc.Msg[index] != nil && doc.Msg[index].Msg != nil {
+				return false, servererrs.ErrMsgReactionLimit.WrapMsg("reaction limit reached", "maxReactions", msgext.MaxMsgReactions,
+					"maxUserReactions", msgext.MaxUserReactions)
+			}
+		}
+		return false, errs.ErrRecordNotFound.WrapMsg("msg not found", "conversationID", conversationID, "seq", seq)
+	}
+	if res.ModifiedCount == 0 {
+		return false, nil
+	}
+	if err := db.msg.DeleteMessagesFromCache(ctx, conversationID, []int64{seq}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (db *commonMsgDatabase) SetMsgTranslations(ctx context.Context, conversationID string, seq int64, content string, translations map[string]string) error {
+	if len(translations) == 0 {
+		return nil
+	}
+	res, err := db.msgDocDatabase.SetTranslations(ctx, db.msgTable.GetDocID(conversationID, seq), db.msgTable.GetMsgIndex(seq), content, translations)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		log.ZDebug(ctx, "msg changed before it was translated", "conversationID", conversationID, "seq", seq)
+		return nil
+	}
+	return db.msg.DeleteMessagesFromCache(ctx, conversationID, []int64{seq})
+}
+
+func (db *commonMsgDatabase) GetMsgSeqByServerMsgID(ctx context.Context, conversationID string, serverMsgID string) (int64, error) {
+	return db.msgDocDatabase.FindSeqByServerMsgID(ctx, conversationID, serverMsgID)
+}
+
 func (db *commonMsgDatabase) MarkSingleChatMsgsAsRead(ctx context.Context, userID string, conversationID string, totalSeqs []int64) error {
 	for docID, seqs := range db.msgTable.GetDocIDSeqsMap(conversationID, totalSeqs) {
 		var indexes []int64
@@ -329,10 +422,50 @@ func (db *commonMsgDatabase) findMsgInfoBySeq(ctx context.Context, userID, docID
 	tempCache := make(map[int64][]*model.MsgInfoModel)
 	for _, msg := range msgs {
 		db.handlerDBMsg(ctx, tempCache, userID, conversationID, msg)
+		if err := setAttachedInfo(msg); err != nil {
+			return nil, errs.WrapMsg(err, "set attached info", "docID", docID, "seq", msg.Msg.Seq)
+		}
 	}
 	return msgs, err
 }
 
+// setAttachedInfo adds the reactions, the thread and the translations stored beside a message to its attached info,
+// a revoked message shows none of them.
+func setAttachedInfo(msg *model.MsgInfoModel) (err error) {
+	if msg.Msg == nil || msg.Revoke != nil {
+		return nil
+	}
+	if len(msg.Reactions) > 0 {
+		if msg.Msg.AttachedInfo, err = msgext.SetReactions(msg.Msg.AttachedInfo, msg.Reactions); err != nil {
+			return err
+		}
+	}
+	if msg.Thread != nil {
+		if msg.Msg.AttachedInfo, err = msgext.SetThread(msg.Msg.AttachedInfo, threadDB2Ext(msg.Thread)); err != nil {
+			return err
+		}
+	}
+	if len(msg.Translations) > 0 {
+		if msg.Msg.AttachedInfo, err = msgext.SetTranslations(msg.Msg.AttachedInfo, msg.Translations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func threadDB2Ext(thread *model.ThreadModel) *msgext.Thread {
+	res := &msgext.Thread{ReplyCount: thread.ReplyCount}
+	if thread.LastReply != nil {
+		res.LastReply = &msgext.ThreadReply{
+			Seq:         thread.LastReply.Seq,
+			SendID:      thread.LastReply.SendID,
+			ClientMsgID: thread.LastReply.ClientMsgID,
+			SendTime:    thread.LastReply.SendTime,
+		}
+	}
+	return res
+}
+
 func (db *commonMsgDatabase) getMsgBySeqsRange(ctx context.Context, userID string, conversationID string, allSeqs []int64, begin, end int64) (seqMsgs []*sdkws.MsgData, err error) {
 	log.ZDebug(ctx, "getMsgBySeqsRange", "conversationID", conversationID, "allSeqs", allSeqs, "begin", begin, "end", end)
 	for docID, seqs := range db.msgTable.GetDocIDSeqsMap(conversationID, allSeqs) {
@@ -671,6 +804,49 @@ func (db *commonMsgDatabase) GetMaxSeq(ctx context.Context, conversationID strin
 	return db.seqConversation.GetMaxSeq(ctx, conversationID)
 }
 
+func (db *commonMsgDatabase) GetMinSeq(ctx context.Context, conversationID string) (int64, error) {
+	return db.seqConversation.GetMinSeq(ctx, conversationID)
+}
+
+func (db *commonMsgDatabase) GetUserMinSeq(ctx context.Context, conversationID string, userID string) (int64, error) {
+	return db.seqUser.GetUserMinSeq(ctx, conversationID, userID)
+}
+
+func (db *commonMsgDatabase) RangeMsgs(ctx context.Context, conversationID string, fn func(msgs []*model.MsgInfoModel) error) error {
+	minSeq, err := db.seqConversation.GetMinSeq(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	maxSeq, err := db.seqConversation.GetMaxSeq(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	docMsgNum := db.msgTable.GetSingleGocMsgNum()
+	for seq := max(minSeq, 1); seq <= maxSeq; seq = (seq-1)/docMsgNum*docMsgNum + docMsgNum + 1 {
+		doc, err := db.msgDocDatabase.FindOneByDocID(ctx, db.msgTable.GetDocID(conversationID, seq))
+		if err != nil {
+			if errs.Unwrap(err) == mongo.ErrNoDocuments {
+				continue
+			}
+			return err
+		}
+		msgs := make([]*model.MsgInfoModel, 0, len(doc.Msg))
+		for _, msg := range doc.Msg {
+			if msg == nil || msg.Msg == nil || msg.Msg.Seq < minSeq || msg.Msg.Seq > maxSeq {
+				continue
+			}
+			msgs = append(msgs, msg)
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		if err := fn(msgs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (db *commonMsgDatabase) SetMinSeq(ctx context.Context, conversationID string, minSeq int64) error {
 	return db.seqConversation.SetMinSeq(ctx, conversationID, minSeq)
 }
@@ -699,6 +875,22 @@ func (db *commonMsgDatabase) GetHasReadSeq(ctx context.Context, userID string, c
 	return db.seqUser.GetUserReadSeq(ctx, conversationID, userID)
 }
 
+func (db *commonMsgDatabase) GetUsersHasReadSeq(ctx context.Context, conversationID string, userIDs []string) (map[string]int64, error) {
+	return db.seqUser.GetUsersReadSeq(ctx, conversationID, userIDs)
+}
+
+func (db *commonMsgDatabase) GetGroupReadReceipt(ctx context.Context, conversationID string, seq int64) (*model.GroupReadReceipt, error) {
+	return db.msg.GetGroupReadReceipt(ctx, conversationID, seq)
+}
+
+func (db *commonMsgDatabase) SetGroupReadReceipt(ctx context.Context, conversationID string, seq int64, receipt *model.GroupReadReceipt, expire time.Duration) error {
+	return db.msg.SetGroupReadReceipt(ctx, conversationID, seq, receipt, expire)
+}
+
+func (db *commonMsgDatabase) DelGroupReadReceipts(ctx context.Context, conversationID string, seqs []int64) error {
+	return db.msg.DelGroupReadReceipts(ctx, conversationID, seqs)
+}
+
 func (db *commonMsgDatabase) SetSendMsgStatus(ctx context.Context, id string, status int32) error {
 	return db.msg.SetSendMsgStatus(ctx, id, status)
 }
@@ -840,7 +1032,7 @@ func (db *commonMsgDatabase) DeleteDocMsgBefore(ctx context.Context, ts int64, d
 		return index, nil
 	}
 	maxSeq := doc.Msg[index[len(index)-1]].Msg.Seq
-	conversationID := doc.DocID[:strings.LastIndex(doc.DocID, ":")]
+	conversationID := doc.GetConversationID()
 	if err := db.setMinSeq(ctx, conversationID, maxSeq+1); err != nil {
 		return index, err
 	}
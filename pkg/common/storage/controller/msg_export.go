@@ -0,0 +1,85 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type MsgExportDatabase interface {
+	CreateMsgExportJob(ctx context.Context, job *model.MsgExportJob) error
+	TakeMsgExportJob(ctx context.Context, jobID string) (*model.MsgExportJob, error)
+	FindMsgExportJobs(ctx context.Context, pagination pagination.Pagination) (int64, []*model.MsgExportJob, error)
+	// ClaimMsgExportJobs marks up to limit pending jobs as running, a running job which did not report progress
+	// within claimTimeout is taken over.
+	ClaimMsgExportJobs(ctx context.Context, claimTimeout time.Duration, limit int) ([]*model.MsgExportJob, error)
+	ProgressMsgExportJob(ctx context.Context, jobID string, msgCount int64) error
+	DoneMsgExportJob(ctx context.Context, jobID string, msgCount int64, objectName string, size int64) error
+	FailMsgExportJob(ctx context.Context, jobID string, msgCount int64, errMsg string) error
+}
+
+func NewMsgExportDatabase(job database.MsgExportJob) MsgExportDatabase {
+	return &msgExportDatabase{job: job}
+}
+
+type msgExportDatabase struct {
+	job database.MsgExportJob
+}
+
+func (m *msgExportDatabase) CreateMsgExportJob(ctx context.Context, job *model.MsgExportJob) error {
+	return m.job.Create(ctx, job)
+}
+
+func (m *msgExportDatabase) TakeMsgExportJob(ctx context.Context, jobID string) (*model.MsgExportJob, error) {
+	return m.job.Take(ctx, jobID)
+}
+
+func (m *msgExportDatabase) FindMsgExportJobs(ctx context.Context, pagination pagination.Pagination) (int64, []*model.MsgExportJob, error) {
+	return m.job.Find(ctx, pagination)
+}
+
+func (m *msgExportDatabase) ClaimMsgExportJobs(ctx context.Context, claimTimeout time.Duration, limit int) ([]*model.MsgExportJob, error) {
+	now := time.Now()
+	return m.job.Claim(ctx, now, now.Add(-claimTimeout), limit)
+}
+
+func (m *msgExportDatabase) ProgressMsgExportJob(ctx context.Context, jobID string, msgCount int64) error {
+	return m.job.Progress(ctx, jobID, msgCount, time.Now())
+}
+
+func (m *msgExportDatabase) DoneMsgExportJob(ctx context.Context, jobID string, msgCount int64, objectName string, size int64) error {
+	return m.job.Finish(ctx, jobID, map[string]any{
+		"status":      msgext.MsgExportDone,
+		"msg_count":   msgCount,
+		"object_name": objectName,
+		"size":        size,
+		"finish_time": time.Now(),
+	})
+}
+
+func (m *msgExportDatabase) FailMsgExportJob(ctx context.Context, jobID string, msgCount int64, errMsg string) error {
+	return m.job.Finish(ctx, jobID, map[string]any{
+		"status":      msgext.MsgExportFailed,
+		"msg_count":   msgCount,
+		"err_msg":     errMsg,
+		"finish_time": time.Now(),
+	})
+}
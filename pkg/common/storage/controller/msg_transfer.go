@@ -8,6 +8,8 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
 	pbmsg "github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/tools/errs"
@@ -21,6 +23,11 @@ type MsgTransferDatabase interface {
 	BatchInsertChat2DB(ctx context.Context, conversationID string, msgs []*sdkws.MsgData, currentMaxSeq int64) error
 	// DeleteMessagesFromCache deletes message caches from Redis by sequence numbers.
 	DeleteMessagesFromCache(ctx context.Context, conversationID string, seqs []int64) error
+	// AddThreadReplies updates the reply count and last reply of the parent message of a thread with the replies stored in it.
+	AddThreadReplies(ctx context.Context, threadID string, msgs []*sdkws.MsgData) error
+	// SetMsgTranslations stores the translations of the content of a message by language,
+	// they are dropped if the message was edited since content was read.
+	SetMsgTranslations(ctx context.Context, conversationID string, seq int64, content string, translations map[string]string) error
 
 	// BatchInsertChat2Cache increments the sequence number and then batch inserts messages into the cache.
 	BatchInsertChat2Cache(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) (seq int64, isNewConversation bool, err error)
@@ -215,6 +222,50 @@ func (db *msgTransferDatabase) DeleteMessagesFromCache(ctx context.Context, conv
 	return db.msg.DeleteMessagesFromCache(ctx, conversationID, seqs)
 }
 
+func (db *msgTransferDatabase) AddThreadReplies(ctx context.Context, threadID string, msgs []*sdkws.MsgData) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	conversationID, parentSeq, ok := msgprocessor.ParseThreadConversationID(threadID)
+	if !ok {
+		return errs.ErrArgs.WrapMsg("invalid thread conversationID", "threadID", threadID)
+	}
+	last := msgs[len(msgs)-1]
+	lastReply := &model.ThreadReplyModel{
+		Seq:         last.Seq,
+		SendID:      last.SendID,
+		ClientMsgID: last.ClientMsgID,
+		SendTime:    last.SendTime,
+	}
+	docID := db.msgTable.GetDocID(conversationID, parentSeq)
+	index := db.msgTable.GetMsgIndex(parentSeq)
+	// The seqs of a thread start at 1, so the seq of the newest reply is the reply count, which keeps
+	// the update idempotent when the msgs are consumed again.
+	res, err := db.msgDocDatabase.AddThreadReplies(ctx, docID, index, lastReply)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errs.ErrRecordNotFound.WrapMsg("thread parent msg not found", "conversationID", conversationID, "seq", parentSeq)
+	}
+	return db.msg.DeleteMessagesFromCache(ctx, conversationID, []int64{parentSeq})
+}
+
+func (db *msgTransferDatabase) SetMsgTranslations(ctx context.Context, conversationID string, seq int64, content string, translations map[string]string) error {
+	if len(translations) == 0 {
+		return nil
+	}
+	res, err := db.msgDocDatabase.SetTranslations(ctx, db.msgTable.GetDocID(conversationID, seq), db.msgTable.GetMsgIndex(seq), content, translations)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		log.ZDebug(ctx, "msg changed before it was translated", "conversationID", conversationID, "seq", seq)
+		return nil
+	}
+	return db.msg.DeleteMessagesFromCache(ctx, conversationID, []int64{seq})
+}
+
 func (db *msgTransferDatabase) BatchInsertChat2Cache(ctx context.Context, conversationID string, msgs []*sdkws.MsgData) (seq int64, isNew bool, err error) {
 	lenList := len(msgs)
 	if int64(lenList) > db.msgTable.GetSingleGocMsgNum() {
@@ -266,7 +317,7 @@ func (db *msgTransferDatabase) SetHasReadSeqToDB(ctx context.Context, userID str
 }
 
 func (db *msgTransferDatabase) MsgToPushMQ(ctx context.Context, key, conversationID string, msg2mq *sdkws.MsgData) (int32, int64, error) {
-	partition, offset, err := db.producerToPush.SendMessage(ctx, key, &pbmsg.PushMsgDataToMQ{MsgData: msg2mq, ConversationID: conversationID})
+	partition, offset, err := db.producerToPush.SendMessage(ctx, tenant.MQKey(ctx, key), &pbmsg.PushMsgDataToMQ{MsgData: msg2mq, ConversationID: conversationID})
 	if err != nil {
 		log.ZError(ctx, "MsgToPushMQ", err, "key", key, "msg2mq", msg2mq)
 		return 0, 0, err
@@ -276,7 +327,7 @@ func (db *msgTransferDatabase) MsgToPushMQ(ctx context.Context, key, conversatio
 
 func (db *msgTransferDatabase) MsgToMongoMQ(ctx context.Context, key, conversationID string, messages []*sdkws.MsgData, lastSeq int64) error {
 	if len(messages) > 0 {
-		_, _, err := db.producerToMongo.SendMessage(ctx, key, &pbmsg.MsgDataToMongoByMQ{LastSeq: lastSeq, ConversationID: conversationID, MsgData: messages})
+		_, _, err := db.producerToMongo.SendMessage(ctx, tenant.MQKey(ctx, key), &pbmsg.MsgDataToMongoByMQ{LastSeq: lastSeq, ConversationID: conversationID, MsgData: messages})
 		if err != nil {
 			log.ZError(ctx, "MsgToMongoMQ", err, "key", key, "conversationID", conversationID, "lastSeq", lastSeq)
 			return err
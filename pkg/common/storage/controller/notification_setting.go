@@ -0,0 +1,58 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+type NotificationSettingDatabase interface {
+	SetNotificationSetting(ctx context.Context, setting *model.NotificationSetting) error
+	DeleteNotificationSetting(ctx context.Context, userID string) error
+	// GetNotificationSettings returns the settings of userIDs, the users without a setting are skipped.
+	GetNotificationSettings(ctx context.Context, userIDs []string) ([]*model.NotificationSetting, error)
+}
+
+func NewNotificationSettingDatabase(settingDB database.NotificationSetting, cache cache.NotificationSettingCache) NotificationSettingDatabase {
+	return &notificationSettingDatabase{settingDB: settingDB, cache: cache}
+}
+
+type notificationSettingDatabase struct {
+	settingDB database.NotificationSetting
+	cache     cache.NotificationSettingCache
+}
+
+func (n *notificationSettingDatabase) SetNotificationSetting(ctx context.Context, setting *model.NotificationSetting) error {
+	if err := n.settingDB.Set(ctx, setting); err != nil {
+		return err
+	}
+	return n.cache.DelNotificationSettings(setting.UserID).ChainExecDel(ctx)
+}
+
+func (n *notificationSettingDatabase) DeleteNotificationSetting(ctx context.Context, userID string) error {
+	if err := n.settingDB.Delete(ctx, userID); err != nil {
+		return err
+	}
+	return n.cache.DelNotificationSettings(userID).ChainExecDel(ctx)
+}
+
+func (n *notificationSettingDatabase) GetNotificationSettings(ctx context.Context, userIDs []string) ([]*model.NotificationSetting, error) {
+	return n.cache.GetNotificationSettings(ctx, datautil.Distinct(userIDs))
+}
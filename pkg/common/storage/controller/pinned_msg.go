@@ -0,0 +1,59 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+)
+
+type PinnedMsgDatabase interface {
+	// PinMsg pins a message of the conversation, the oldest pinned message is unpinned when there are more than maxNum.
+	// It reports whether the message was not pinned yet.
+	PinMsg(ctx context.Context, conversationID string, msg *model.PinnedMsg, maxNum int) (bool, error)
+	// UnpinMsg reports whether the message was pinned.
+	UnpinMsg(ctx context.Context, conversationID string, seq int64) (bool, error)
+	// GetPinnedMsgs returns the pinned messages of the conversation, the latest pinned first.
+	GetPinnedMsgs(ctx context.Context, conversationID string) ([]*model.PinnedMsg, error)
+}
+
+func NewPinnedMsgDatabase(pinnedMsg database.PinnedMsg) PinnedMsgDatabase {
+	return &pinnedMsgDatabase{pinnedMsg: pinnedMsg}
+}
+
+type pinnedMsgDatabase struct {
+	pinnedMsg database.PinnedMsg
+}
+
+func (p *pinnedMsgDatabase) PinMsg(ctx context.Context, conversationID string, msg *model.PinnedMsg, maxNum int) (bool, error) {
+	return p.pinnedMsg.Pin(ctx, conversationID, msg, maxNum)
+}
+
+func (p *pinnedMsgDatabase) UnpinMsg(ctx context.Context, conversationID string, seq int64) (bool, error) {
+	return p.pinnedMsg.Unpin(ctx, conversationID, seq)
+}
+
+func (p *pinnedMsgDatabase) GetPinnedMsgs(ctx context.Context, conversationID string) ([]*model.PinnedMsg, error) {
+	pinned, err := p.pinnedMsg.Find(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(pinned)-1; i < j; i, j = i+1, j-1 {
+		pinned[i], pinned[j] = pinned[j], pinned[i]
+	}
+	return pinned, nil
+}
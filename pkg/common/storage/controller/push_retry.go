@@ -0,0 +1,99 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/cache"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+	"github.com/openimsdk/tools/log"
+)
+
+type PushRetryDatabase interface {
+	// RetryPush queues retry to be pushed again after delay.
+	RetryPush(ctx context.Context, retry *model.PushRetry, delay time.Duration) error
+	// LeaseDuePushRetries returns at most limit retries which are due and leases them for leaseTime,
+	// a retry not done before its lease expires is due again, so a crashed pusher does not lose it.
+	LeaseDuePushRetries(ctx context.Context, leaseTime time.Duration, limit int) ([]*model.PushRetry, error)
+	// DonePushRetry removes a leased retry once it was pushed, queued again or dead-lettered.
+	DonePushRetry(ctx context.Context, retryID string) error
+	// DeadLetterPush keeps retry as a dead letter, it is not pushed again unless it is replayed.
+	// The oldest dead letters are dropped beyond the max number kept.
+	DeadLetterPush(ctx context.Context, retry *model.PushRetry) error
+	// GetPushDeadLetters returns the dead letters, the latest first.
+	GetPushDeadLetters(ctx context.Context, pagination pagination.Pagination) (int64, []*model.PushRetry, error)
+	// ReplayPushDeadLetters queues the dead letters of retryIDs to be pushed again now, their attempts start over.
+	// It returns the replayed dead letters.
+	ReplayPushDeadLetters(ctx context.Context, retryIDs []string) ([]*model.PushRetry, error)
+}
+
+// defaultMaxPushDeadLetters is the number of dead letters kept when it is not configured.
+const defaultMaxPushDeadLetters = 10000
+
+func NewPushRetryDatabase(cache cache.PushRetryCache, maxDeadLetters int) PushRetryDatabase {
+	if maxDeadLetters <= 0 {
+		maxDeadLetters = defaultMaxPushDeadLetters
+	}
+	return &pushRetryDatabase{cache: cache, maxDeadLetters: maxDeadLetters}
+}
+
+type pushRetryDatabase struct {
+	cache          cache.PushRetryCache
+	maxDeadLetters int
+}
+
+func (p *pushRetryDatabase) RetryPush(ctx context.Context, retry *model.PushRetry, delay time.Duration) error {
+	return p.cache.AddPushRetry(ctx, retry, time.Now().Add(delay))
+}
+
+func (p *pushRetryDatabase) LeaseDuePushRetries(ctx context.Context, leaseTime time.Duration, limit int) ([]*model.PushRetry, error) {
+	return p.cache.LeaseDuePushRetries(ctx, time.Now(), leaseTime, limit)
+}
+
+func (p *pushRetryDatabase) DonePushRetry(ctx context.Context, retryID string) error {
+	return p.cache.DonePushRetry(ctx, retryID)
+}
+
+func (p *pushRetryDatabase) DeadLetterPush(ctx context.Context, retry *model.PushRetry) error {
+	return p.cache.AddPushDeadLetter(ctx, retry, p.maxDeadLetters)
+}
+
+func (p *pushRetryDatabase) GetPushDeadLetters(ctx context.Context, pagination pagination.Pagination) (int64, []*model.PushRetry, error) {
+	return p.cache.GetPushDeadLetters(ctx, pagination)
+}
+
+func (p *pushRetryDatabase) ReplayPushDeadLetters(ctx context.Context, retryIDs []string) ([]*model.PushRetry, error) {
+	retries, err := p.cache.TakePushDeadLetters(ctx, retryIDs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i, retry := range retries {
+		retry.Attempts = 0
+		if err := p.cache.AddPushRetry(ctx, retry, now); err != nil {
+			// keep the dead letters which were not queued again
+			for _, retry := range retries[i:] {
+				if err := p.cache.AddPushDeadLetter(ctx, retry, p.maxDeadLetters); err != nil {
+					log.ZError(ctx, "restore push dead letter failed", err, "retryID", retry.RetryID)
+				}
+			}
+			return nil, err
+		}
+	}
+	return retries, nil
+}
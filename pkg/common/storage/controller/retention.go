@@ -0,0 +1,86 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/retention"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type RetentionDatabase interface {
+	SetRetentionPolicy(ctx context.Context, policy *model.RetentionPolicy) error
+	DeleteRetentionPolicy(ctx context.Context, targetType int32, targetID string) error
+	PageRetentionPolicies(ctx context.Context, targetType int32, pagination pagination.Pagination) (int64, []*model.RetentionPolicy, error)
+	// GetAllRetentionPolicies returns every policy, it is used when all the conversations are checked at once.
+	GetAllRetentionPolicies(ctx context.Context) ([]*model.RetentionPolicy, error)
+	// GetRetentionRules resolves the policies which apply to each of conversationIDs.
+	GetRetentionRules(ctx context.Context, conversationIDs []string) (map[string]retention.Rule, error)
+	AddRetentionSkips(ctx context.Context, skips []*model.RetentionSkip) error
+	PageRetentionSkips(ctx context.Context, conversationID string, pagination pagination.Pagination) (int64, []*model.RetentionSkip, error)
+}
+
+func NewRetentionDatabase(policy database.RetentionPolicy, skip database.RetentionSkip) RetentionDatabase {
+	return &retentionDatabase{policy: policy, skip: skip}
+}
+
+type retentionDatabase struct {
+	policy database.RetentionPolicy
+	skip   database.RetentionSkip
+}
+
+func (r *retentionDatabase) SetRetentionPolicy(ctx context.Context, policy *model.RetentionPolicy) error {
+	return r.policy.Set(ctx, policy)
+}
+
+func (r *retentionDatabase) DeleteRetentionPolicy(ctx context.Context, targetType int32, targetID string) error {
+	return r.policy.Delete(ctx, targetType, targetID)
+}
+
+func (r *retentionDatabase) PageRetentionPolicies(ctx context.Context, targetType int32, pagination pagination.Pagination) (int64, []*model.RetentionPolicy, error) {
+	return r.policy.FindPage(ctx, targetType, pagination)
+}
+
+func (r *retentionDatabase) GetAllRetentionPolicies(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	return r.policy.FindAll(ctx)
+}
+
+func (r *retentionDatabase) GetRetentionRules(ctx context.Context, conversationIDs []string) (map[string]retention.Rule, error) {
+	var targets []retention.Target
+	for _, conversationID := range conversationIDs {
+		targets = append(targets, retention.Targets(conversationID)...)
+	}
+	policies, err := r.policy.Find(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+	resolver := retention.NewResolver(policies)
+	rules := make(map[string]retention.Rule, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		rules[conversationID] = resolver.Resolve(conversationID)
+	}
+	return rules, nil
+}
+
+func (r *retentionDatabase) AddRetentionSkips(ctx context.Context, skips []*model.RetentionSkip) error {
+	return r.skip.Create(ctx, skips)
+}
+
+func (r *retentionDatabase) PageRetentionSkips(ctx context.Context, conversationID string, pagination pagination.Pagination) (int64, []*model.RetentionSkip, error) {
+	return r.skip.FindPage(ctx, conversationID, pagination)
+}
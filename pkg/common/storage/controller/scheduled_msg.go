@@ -0,0 +1,74 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type ScheduledMsgDatabase interface {
+	CreateScheduledMsg(ctx context.Context, msg *model.ScheduledMsg) error
+	TakeScheduledMsg(ctx context.Context, scheduleID string) (*model.ScheduledMsg, error)
+	FindScheduledMsgs(ctx context.Context, sendID string, pagination pagination.Pagination) (int64, []*model.ScheduledMsg, error)
+	// UpdateScheduledMsg updates a scheduled message of sendID which is not being sent, it reports whether one was updated.
+	UpdateScheduledMsg(ctx context.Context, scheduleID string, sendID string, data map[string]any) (bool, error)
+	// DeleteScheduledMsg deletes a scheduled message of sendID which is not being sent, it reports whether one was deleted.
+	DeleteScheduledMsg(ctx context.Context, scheduleID string, sendID string) (bool, error)
+	// ClaimDueScheduledMsgs marks up to limit due messages as being sent, a claim older than claimTimeout is taken over.
+	ClaimDueScheduledMsgs(ctx context.Context, claimTimeout time.Duration, limit int) ([]*model.ScheduledMsg, error)
+	FinishScheduledMsg(ctx context.Context, scheduleID string, errMsg string) error
+}
+
+func NewScheduledMsgDatabase(scheduledMsg database.ScheduledMsg) ScheduledMsgDatabase {
+	return &scheduledMsgDatabase{scheduledMsg: scheduledMsg}
+}
+
+type scheduledMsgDatabase struct {
+	scheduledMsg database.ScheduledMsg
+}
+
+func (s *scheduledMsgDatabase) CreateScheduledMsg(ctx context.Context, msg *model.ScheduledMsg) error {
+	return s.scheduledMsg.Create(ctx, msg)
+}
+
+func (s *scheduledMsgDatabase) TakeScheduledMsg(ctx context.Context, scheduleID string) (*model.ScheduledMsg, error) {
+	return s.scheduledMsg.Take(ctx, scheduleID)
+}
+
+func (s *scheduledMsgDatabase) FindScheduledMsgs(ctx context.Context, sendID string, pagination pagination.Pagination) (int64, []*model.ScheduledMsg, error) {
+	return s.scheduledMsg.FindBySendID(ctx, sendID, pagination)
+}
+
+func (s *scheduledMsgDatabase) UpdateScheduledMsg(ctx context.Context, scheduleID string, sendID string, data map[string]any) (bool, error) {
+	return s.scheduledMsg.Update(ctx, scheduleID, sendID, data)
+}
+
+func (s *scheduledMsgDatabase) DeleteScheduledMsg(ctx context.Context, scheduleID string, sendID string) (bool, error) {
+	return s.scheduledMsg.Delete(ctx, scheduleID, sendID)
+}
+
+func (s *scheduledMsgDatabase) ClaimDueScheduledMsgs(ctx context.Context, claimTimeout time.Duration, limit int) ([]*model.ScheduledMsg, error) {
+	now := time.Now()
+	return s.scheduledMsg.ClaimDue(ctx, now, now.Add(-claimTimeout), limit)
+}
+
+func (s *scheduledMsgDatabase) FinishScheduledMsg(ctx context.Context, scheduleID string, errMsg string) error {
+	return s.scheduledMsg.Finish(ctx, scheduleID, errMsg)
+}
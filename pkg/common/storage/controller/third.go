@@ -27,6 +27,10 @@ import (
 
 type ThirdDatabase interface {
 	FcmUpdateToken(ctx context.Context, account string, platformID int, fcmToken string, expireTime int64) error
+	// RegisterDevice registers the offline push device of a user, replacing the one of the same platform.
+	RegisterDevice(ctx context.Context, device *model.Device) error
+	UnregisterDevice(ctx context.Context, userID string, platformID int) error
+	GetDevices(ctx context.Context, userID string) ([]*model.Device, error)
 	SetAppBadge(ctx context.Context, userID string, value int) error
 	// about log for debug
 	UploadLogs(ctx context.Context, logs []*model.Log) error
@@ -68,6 +72,18 @@ func (t *thirdDatabase) FcmUpdateToken(ctx context.Context, account string, plat
 	return t.cache.SetFcmToken(ctx, account, platformID, fcmToken, expireTime)
 }
 
+func (t *thirdDatabase) RegisterDevice(ctx context.Context, device *model.Device) error {
+	return t.cache.SetDevice(ctx, device)
+}
+
+func (t *thirdDatabase) UnregisterDevice(ctx context.Context, userID string, platformID int) error {
+	return t.cache.DelDevice(ctx, userID, platformID)
+}
+
+func (t *thirdDatabase) GetDevices(ctx context.Context, userID string) ([]*model.Device, error) {
+	return t.cache.GetDevices(ctx, userID)
+}
+
 func (t *thirdDatabase) SetAppBadge(ctx context.Context, userID string, value int) error {
 	return t.cache.SetUserBadgeUnreadCountSum(ctx, userID, value)
 }
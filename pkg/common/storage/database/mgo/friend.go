@@ -69,7 +69,7 @@ func (f *FriendMgo) Create(ctx context.Context, friends []*model.Friend) error {
 		}
 	}
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.InsertMany(ctx, f.coll, friends)
+		return mongoutil.InsertMany(ctx, tenantColl(ctx, f.coll), friends)
 	}, func() error {
 		mp := make(map[string][]string)
 		for _, friend := range friends {
@@ -91,7 +91,7 @@ func (f *FriendMgo) Delete(ctx context.Context, ownerUserID string, friendUserID
 		"friend_user_id": bson.M{"$in": friendUserIDs},
 	}
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.DeleteOne(ctx, f.coll, filter)
+		return mongoutil.DeleteOne(ctx, tenantColl(ctx, f.coll), filter)
 	}, func() error {
 		return f.owner.IncrVersion(ctx, ownerUserID, friendUserIDs, model.VersionStateDelete)
 	})
@@ -107,7 +107,7 @@ func (f *FriendMgo) UpdateByMap(ctx context.Context, ownerUserID string, friendU
 		"friend_user_id": friendUserID,
 	}
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.UpdateOne(ctx, f.coll, filter, bson.M{"$set": args}, true)
+		return mongoutil.UpdateOne(ctx, tenantColl(ctx, f.coll), filter, bson.M{"$set": args}, true)
 	}, func() error {
 		var friendUserIDs []string
 		if f.IsUpdateIsPinned(args) {
@@ -133,7 +133,7 @@ func (f *FriendMgo) fillTime(friends ...*model.Friend) {
 }
 
 func (f *FriendMgo) findOne(ctx context.Context, filter any) (*model.Friend, error) {
-	friend, err := mongoutil.FindOne[*model.Friend](ctx, f.coll, filter)
+	friend, err := mongoutil.FindOne[*model.Friend](ctx, tenantColl(ctx, f.coll), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +142,7 @@ func (f *FriendMgo) findOne(ctx context.Context, filter any) (*model.Friend, err
 }
 
 func (f *FriendMgo) find(ctx context.Context, filter any) ([]*model.Friend, error) {
-	friends, err := mongoutil.Find[*model.Friend](ctx, f.coll, filter)
+	friends, err := mongoutil.Find[*model.Friend](ctx, tenantColl(ctx, f.coll), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +151,7 @@ func (f *FriendMgo) find(ctx context.Context, filter any) ([]*model.Friend, erro
 }
 
 func (f *FriendMgo) findPage(ctx context.Context, filter any, pagination pagination.Pagination, opts ...*options.FindOptions) (int64, []*model.Friend, error) {
-	return mongoutil.FindPage[*model.Friend](ctx, f.coll, filter, pagination, opts...)
+	return mongoutil.FindPage[*model.Friend](ctx, tenantColl(ctx, f.coll), filter, pagination, opts...)
 }
 
 // Take retrieves a single friend document. Returns an error if not found.
@@ -202,7 +202,7 @@ func (f *FriendMgo) FindOwnerFriends(ctx context.Context, ownerUserID string, pa
 func (f *FriendMgo) FindOwnerFriendUserIds(ctx context.Context, ownerUserID string, limit int) ([]string, error) {
 	filter := bson.M{"owner_user_id": ownerUserID}
 	opt := options.Find().SetProjection(bson.M{"_id": 0, "friend_user_id": 1}).SetSort(f.friendSort()).SetLimit(int64(limit))
-	return mongoutil.Find[string](ctx, f.coll, filter, opt)
+	return mongoutil.Find[string](ctx, tenantColl(ctx, f.coll), filter, opt)
 }
 
 // FindInWhoseFriends finds users who have added the specified user as a friend, with pagination.
@@ -215,7 +215,7 @@ func (f *FriendMgo) FindInWhoseFriends(ctx context.Context, friendUserID string,
 // FindFriendUserIDs retrieves a list of friend user IDs for a given owner.
 func (f *FriendMgo) FindFriendUserIDs(ctx context.Context, ownerUserID string) ([]string, error) {
 	filter := bson.M{"owner_user_id": ownerUserID}
-	return mongoutil.Find[string](ctx, f.coll, filter, options.Find().SetProjection(bson.M{"_id": 0, "friend_user_id": 1}).SetSort(f.friendSort()))
+	return mongoutil.Find[string](ctx, tenantColl(ctx, f.coll), filter, options.Find().SetProjection(bson.M{"_id": 0, "friend_user_id": 1}).SetSort(f.friendSort()))
 }
 
 func (f *FriendMgo) UpdateFriends(ctx context.Context, ownerUserID string, friendUserIDs []string, val map[string]any) error {
@@ -234,7 +234,7 @@ func (f *FriendMgo) UpdateFriends(ctx context.Context, ownerUserID string, frien
 	update := bson.M{"$set": val}
 
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.Ignore(mongoutil.UpdateMany(ctx, f.coll, filter, update))
+		return mongoutil.Ignore(mongoutil.UpdateMany(ctx, tenantColl(ctx, f.coll), filter, update))
 	}, func() error {
 		var userIDs []string
 		if f.IsUpdateIsPinned(val) {
@@ -254,7 +254,7 @@ func (f *FriendMgo) FindFriendUserID(ctx context.Context, friendUserID string) (
 	filter := bson.M{
 		"friend_user_id": friendUserID,
 	}
-	return mongoutil.Find[string](ctx, f.coll, filter, options.Find().SetProjection(bson.M{"_id": 0, "owner_user_id": 1}).SetSort(f.friendSort()))
+	return mongoutil.Find[string](ctx, tenantColl(ctx, f.coll), filter, options.Find().SetProjection(bson.M{"_id": 0, "owner_user_id": 1}).SetSort(f.friendSort()))
 }
 
 func (f *FriendMgo) IncrVersion(ctx context.Context, ownerUserID string, friendUserIDs []string, state int32) error {
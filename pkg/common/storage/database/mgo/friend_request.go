@@ -46,11 +46,11 @@ type FriendRequestMgo struct {
 }
 
 func (f *FriendRequestMgo) FindToUserID(ctx context.Context, toUserID string, pagination pagination.Pagination) (total int64, friendRequests []*model.FriendRequest, err error) {
-	return mongoutil.FindPage[*model.FriendRequest](ctx, f.coll, bson.M{"to_user_id": toUserID}, pagination)
+	return mongoutil.FindPage[*model.FriendRequest](ctx, tenantColl(ctx, f.coll), bson.M{"to_user_id": toUserID}, pagination)
 }
 
 func (f *FriendRequestMgo) FindFromUserID(ctx context.Context, fromUserID string, pagination pagination.Pagination) (total int64, friendRequests []*model.FriendRequest, err error) {
-	return mongoutil.FindPage[*model.FriendRequest](ctx, f.coll, bson.M{"from_user_id": fromUserID}, pagination)
+	return mongoutil.FindPage[*model.FriendRequest](ctx, tenantColl(ctx, f.coll), bson.M{"from_user_id": fromUserID}, pagination)
 }
 
 func (f *FriendRequestMgo) FindBothFriendRequests(ctx context.Context, fromUserID, toUserID string) (friends []*model.FriendRequest, err error) {
@@ -58,22 +58,22 @@ func (f *FriendRequestMgo) FindBothFriendRequests(ctx context.Context, fromUserI
 		{"from_user_id": fromUserID, "to_user_id": toUserID},
 		{"from_user_id": toUserID, "to_user_id": fromUserID},
 	}}
-	return mongoutil.Find[*model.FriendRequest](ctx, f.coll, filter)
+	return mongoutil.Find[*model.FriendRequest](ctx, tenantColl(ctx, f.coll), filter)
 }
 
 func (f *FriendRequestMgo) Create(ctx context.Context, friendRequests []*model.FriendRequest) error {
-	return mongoutil.InsertMany(ctx, f.coll, friendRequests)
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, f.coll), friendRequests)
 }
 
 func (f *FriendRequestMgo) Delete(ctx context.Context, fromUserID, toUserID string) (err error) {
-	return mongoutil.DeleteOne(ctx, f.coll, bson.M{"from_user_id": fromUserID, "to_user_id": toUserID})
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, f.coll), bson.M{"from_user_id": fromUserID, "to_user_id": toUserID})
 }
 
 func (f *FriendRequestMgo) UpdateByMap(ctx context.Context, formUserID, toUserID string, args map[string]any) (err error) {
 	if len(args) == 0 {
 		return nil
 	}
-	return mongoutil.UpdateOne(ctx, f.coll, bson.M{"from_user_id": formUserID, "to_user_id": toUserID}, bson.M{"$set": args}, true)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, f.coll), bson.M{"from_user_id": formUserID, "to_user_id": toUserID}, bson.M{"$set": args}, true)
 }
 
 func (f *FriendRequestMgo) Update(ctx context.Context, friendRequest *model.FriendRequest) (err error) {
@@ -100,11 +100,11 @@ func (f *FriendRequestMgo) Update(ctx context.Context, friendRequest *model.Frie
 		return nil
 	}
 	filter := bson.M{"from_user_id": friendRequest.FromUserID, "to_user_id": friendRequest.ToUserID}
-	return mongoutil.UpdateOne(ctx, f.coll, filter, bson.M{"$set": updater}, true)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, f.coll), filter, bson.M{"$set": updater}, true)
 }
 
 func (f *FriendRequestMgo) Find(ctx context.Context, fromUserID, toUserID string) (friendRequest *model.FriendRequest, err error) {
-	return mongoutil.FindOne[*model.FriendRequest](ctx, f.coll, bson.M{"from_user_id": fromUserID, "to_user_id": toUserID})
+	return mongoutil.FindOne[*model.FriendRequest](ctx, tenantColl(ctx, f.coll), bson.M{"from_user_id": fromUserID, "to_user_id": toUserID})
 }
 
 func (f *FriendRequestMgo) Take(ctx context.Context, fromUserID, toUserID string) (friendRequest *model.FriendRequest, err error) {
@@ -52,7 +52,7 @@ func (g *GroupMgo) sortGroup() any {
 }
 
 func (g *GroupMgo) Create(ctx context.Context, groups []*model.Group) (err error) {
-	return mongoutil.InsertMany(ctx, g.coll, groups)
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, g.coll), groups)
 }
 
 func (g *GroupMgo) UpdateStatus(ctx context.Context, groupID string, status int32) (err error) {
@@ -63,15 +63,15 @@ func (g *GroupMgo) UpdateMap(ctx context.Context, groupID string, args map[strin
 	if len(args) == 0 {
 		return nil
 	}
-	return mongoutil.UpdateOne(ctx, g.coll, bson.M{"group_id": groupID}, bson.M{"$set": args}, true)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID}, bson.M{"$set": args}, true)
 }
 
 func (g *GroupMgo) Find(ctx context.Context, groupIDs []string) (groups []*model.Group, err error) {
-	return mongoutil.Find[*model.Group](ctx, g.coll, bson.M{"group_id": bson.M{"$in": groupIDs}})
+	return mongoutil.Find[*model.Group](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": bson.M{"$in": groupIDs}})
 }
 
 func (g *GroupMgo) Take(ctx context.Context, groupID string) (group *model.Group, err error) {
-	return mongoutil.FindOne[*model.Group](ctx, g.coll, bson.M{"group_id": groupID})
+	return mongoutil.FindOne[*model.Group](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID})
 }
 
 func (g *GroupMgo) Search(ctx context.Context, keyword string, pagination pagination.Pagination) (total int64, groups []*model.Group, err error) {
@@ -79,7 +79,7 @@ func (g *GroupMgo) Search(ctx context.Context, keyword string, pagination pagina
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	// Perform the search with pagination and sorting
-	return mongoutil.FindPage[*model.Group](ctx, g.coll, bson.M{
+	return mongoutil.FindPage[*model.Group](ctx, tenantColl(ctx, g.coll), bson.M{
 		"group_name": bson.M{"$regex": keyword},
 		"status":     bson.M{"$ne": constant.GroupStatusDismissed},
 	}, pagination, opts)
@@ -87,9 +87,9 @@ func (g *GroupMgo) Search(ctx context.Context, keyword string, pagination pagina
 
 func (g *GroupMgo) CountTotal(ctx context.Context, before *time.Time) (count int64, err error) {
 	if before == nil {
-		return mongoutil.Count(ctx, g.coll, bson.M{})
+		return mongoutil.Count(ctx, tenantColl(ctx, g.coll), bson.M{})
 	}
-	return mongoutil.Count(ctx, g.coll, bson.M{"create_time": bson.M{"$lt": before}})
+	return mongoutil.Count(ctx, tenantColl(ctx, g.coll), bson.M{"create_time": bson.M{"$lt": before}})
 }
 
 func (g *GroupMgo) CountRangeEverydayTotal(ctx context.Context, start time.Time, end time.Time) (map[string]int64, error) {
@@ -120,7 +120,7 @@ func (g *GroupMgo) CountRangeEverydayTotal(ctx context.Context, start time.Time,
 		Date  string `bson:"_id"`
 		Count int64  `bson:"count"`
 	}
-	items, err := mongoutil.Aggregate[Item](ctx, g.coll, pipeline)
+	items, err := mongoutil.Aggregate[Item](ctx, tenantColl(ctx, g.coll), pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +140,7 @@ func (g *GroupMgo) FindJoinSortGroupID(ctx context.Context, groupIDs []string) (
 		"status":   bson.M{"$ne": constant.GroupStatusDismissed},
 	}
 	opt := options.Find().SetSort(g.sortGroup()).SetProjection(bson.M{"_id": 0, "group_id": 1})
-	return mongoutil.Find[string](ctx, g.coll, filter, opt)
+	return mongoutil.Find[string](ctx, tenantColl(ctx, g.coll), filter, opt)
 }
 
 func (g *GroupMgo) SearchJoin(ctx context.Context, groupIDs []string, keyword string, pagination pagination.Pagination) (int64, []*model.Group, error) {
@@ -157,5 +157,5 @@ func (g *GroupMgo) SearchJoin(ctx context.Context, groupIDs []string, keyword st
 	// Define the sorting options
 	opts := options.Find().SetSort(g.sortGroup())
 	// Perform the search with pagination and sorting
-	return mongoutil.FindPage[*model.Group](ctx, g.coll, filter, pagination, opts)
+	return mongoutil.FindPage[*model.Group](ctx, tenantColl(ctx, g.coll), filter, pagination, opts)
 }
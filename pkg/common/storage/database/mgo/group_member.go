@@ -65,7 +65,7 @@ func (g *GroupMemberMgo) memberSort() any {
 
 func (g *GroupMemberMgo) Create(ctx context.Context, groupMembers []*model.GroupMember) (err error) {
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.InsertMany(ctx, g.coll, groupMembers)
+		return mongoutil.InsertMany(ctx, tenantColl(ctx, g.coll), groupMembers)
 	}, func() error {
 		gms := make(map[string][]string)
 		for _, member := range groupMembers {
@@ -97,7 +97,7 @@ func (g *GroupMemberMgo) Delete(ctx context.Context, groupID string, userIDs []s
 		filter["user_id"] = bson.M{"$in": userIDs}
 	}
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.DeleteMany(ctx, g.coll, filter)
+		return mongoutil.DeleteMany(ctx, tenantColl(ctx, g.coll), filter)
 	}, func() error {
 		if len(userIDs) == 0 {
 			return g.member.Delete(ctx, groupID)
@@ -116,7 +116,7 @@ func (g *GroupMemberMgo) Delete(ctx context.Context, groupID string, userIDs []s
 
 func (g *GroupMemberMgo) UpdateRoleLevel(ctx context.Context, groupID string, userID string, roleLevel int32) error {
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.UpdateOne(ctx, g.coll, bson.M{"group_id": groupID, "user_id": userID},
+		return mongoutil.UpdateOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": userID},
 			bson.M{"$set": bson.M{"role_level": roleLevel}}, true)
 	}, func() error {
 		return g.member.IncrVersion(ctx, groupID, []string{model.VersionSortChangeID, userID}, model.VersionStateUpdate)
@@ -124,11 +124,11 @@ func (g *GroupMemberMgo) UpdateRoleLevel(ctx context.Context, groupID string, us
 }
 func (g *GroupMemberMgo) UpdateUserRoleLevels(ctx context.Context, groupID string, firstUserID string, firstUserRoleLevel int32, secondUserID string, secondUserRoleLevel int32) error {
 	return mongoutil.IncrVersion(func() error {
-		if err := mongoutil.UpdateOne(ctx, g.coll, bson.M{"group_id": groupID, "user_id": firstUserID},
+		if err := mongoutil.UpdateOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": firstUserID},
 			bson.M{"$set": bson.M{"role_level": firstUserRoleLevel}}, true); err != nil {
 			return err
 		}
-		if err := mongoutil.UpdateOne(ctx, g.coll, bson.M{"group_id": groupID, "user_id": secondUserID},
+		if err := mongoutil.UpdateOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": secondUserID},
 			bson.M{"$set": bson.M{"role_level": secondUserRoleLevel}}, true); err != nil {
 			return err
 		}
@@ -143,7 +143,7 @@ func (g *GroupMemberMgo) Update(ctx context.Context, groupID string, userID stri
 		return nil
 	}
 	return mongoutil.IncrVersion(func() error {
-		return mongoutil.UpdateOne(ctx, g.coll, bson.M{"group_id": groupID, "user_id": userID}, bson.M{"$set": data}, true)
+		return mongoutil.UpdateOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": userID}, bson.M{"$set": data}, true)
 	}, func() error {
 		var userIDs []string
 		if g.IsUpdateRoleLevel(data) {
@@ -156,7 +156,7 @@ func (g *GroupMemberMgo) Update(ctx context.Context, groupID string, userID stri
 }
 
 func (g *GroupMemberMgo) FindMemberUserID(ctx context.Context, groupID string) (userIDs []string, err error) {
-	return mongoutil.Find[string](ctx, g.coll, bson.M{"group_id": groupID}, options.Find().SetProjection(bson.M{"_id": 0, "user_id": 1}).SetSort(g.memberSort()))
+	return mongoutil.Find[string](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID}, options.Find().SetProjection(bson.M{"_id": 0, "user_id": 1}).SetSort(g.memberSort()))
 }
 
 func (g *GroupMemberMgo) Find(ctx context.Context, groupID string, userIDs []string) ([]*model.GroupMember, error) {
@@ -164,7 +164,7 @@ func (g *GroupMemberMgo) Find(ctx context.Context, groupID string, userIDs []str
 	if len(userIDs) > 0 {
 		filter["user_id"] = bson.M{"$in": userIDs}
 	}
-	return mongoutil.Find[*model.GroupMember](ctx, g.coll, filter)
+	return mongoutil.Find[*model.GroupMember](ctx, tenantColl(ctx, g.coll), filter)
 }
 
 func (g *GroupMemberMgo) FindInGroup(ctx context.Context, userID string, groupIDs []string) ([]*model.GroupMember, error) {
@@ -172,32 +172,32 @@ func (g *GroupMemberMgo) FindInGroup(ctx context.Context, userID string, groupID
 	if len(groupIDs) > 0 {
 		filter["group_id"] = bson.M{"$in": groupIDs}
 	}
-	return mongoutil.Find[*model.GroupMember](ctx, g.coll, filter)
+	return mongoutil.Find[*model.GroupMember](ctx, tenantColl(ctx, g.coll), filter)
 }
 
 func (g *GroupMemberMgo) Take(ctx context.Context, groupID string, userID string) (groupMember *model.GroupMember, err error) {
-	return mongoutil.FindOne[*model.GroupMember](ctx, g.coll, bson.M{"group_id": groupID, "user_id": userID})
+	return mongoutil.FindOne[*model.GroupMember](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": userID})
 }
 
 func (g *GroupMemberMgo) TakeOwner(ctx context.Context, groupID string) (groupMember *model.GroupMember, err error) {
-	return mongoutil.FindOne[*model.GroupMember](ctx, g.coll, bson.M{"group_id": groupID, "role_level": constant.GroupOwner})
+	return mongoutil.FindOne[*model.GroupMember](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "role_level": constant.GroupOwner})
 }
 
 func (g *GroupMemberMgo) FindRoleLevelUserIDs(ctx context.Context, groupID string, roleLevel int32) ([]string, error) {
-	return mongoutil.Find[string](ctx, g.coll, bson.M{"group_id": groupID, "role_level": roleLevel}, options.Find().SetProjection(bson.M{"_id": 0, "user_id": 1}))
+	return mongoutil.Find[string](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "role_level": roleLevel}, options.Find().SetProjection(bson.M{"_id": 0, "user_id": 1}))
 }
 
 func (g *GroupMemberMgo) SearchMember(ctx context.Context, keyword string, groupID string, pagination pagination.Pagination) (int64, []*model.GroupMember, error) {
 	filter := bson.M{"group_id": groupID, "nickname": bson.M{"$regex": keyword}}
-	return mongoutil.FindPage[*model.GroupMember](ctx, g.coll, filter, pagination, options.Find().SetSort(g.memberSort()))
+	return mongoutil.FindPage[*model.GroupMember](ctx, tenantColl(ctx, g.coll), filter, pagination, options.Find().SetSort(g.memberSort()))
 }
 
 func (g *GroupMemberMgo) FindUserJoinedGroupID(ctx context.Context, userID string) (groupIDs []string, err error) {
-	return mongoutil.Find[string](ctx, g.coll, bson.M{"user_id": userID}, options.Find().SetProjection(bson.M{"_id": 0, "group_id": 1}).SetSort(g.memberSort()))
+	return mongoutil.Find[string](ctx, tenantColl(ctx, g.coll), bson.M{"user_id": userID}, options.Find().SetProjection(bson.M{"_id": 0, "group_id": 1}).SetSort(g.memberSort()))
 }
 
 func (g *GroupMemberMgo) TakeGroupMemberNum(ctx context.Context, groupID string) (count int64, err error) {
-	return mongoutil.Count(ctx, g.coll, bson.M{"group_id": groupID})
+	return mongoutil.Count(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID})
 }
 
 func (g *GroupMemberMgo) FindUserManagedGroupID(ctx context.Context, userID string) (groupIDs []string, err error) {
@@ -207,7 +207,7 @@ func (g *GroupMemberMgo) FindUserManagedGroupID(ctx context.Context, userID stri
 			"$in": []int{constant.GroupOwner, constant.GroupAdmin},
 		},
 	}
-	return mongoutil.Find[string](ctx, g.coll, filter, options.Find().SetProjection(bson.M{"_id": 0, "group_id": 1}))
+	return mongoutil.Find[string](ctx, tenantColl(ctx, g.coll), filter, options.Find().SetProjection(bson.M{"_id": 0, "group_id": 1}))
 }
 
 func (g *GroupMemberMgo) IsUpdateRoleLevel(data map[string]any) bool {
@@ -47,29 +47,29 @@ type GroupRequestMgo struct {
 }
 
 func (g *GroupRequestMgo) Create(ctx context.Context, groupRequests []*model.GroupRequest) (err error) {
-	return mongoutil.InsertMany(ctx, g.coll, groupRequests)
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, g.coll), groupRequests)
 }
 
 func (g *GroupRequestMgo) Delete(ctx context.Context, groupID string, userID string) (err error) {
-	return mongoutil.DeleteOne(ctx, g.coll, bson.M{"group_id": groupID, "user_id": userID})
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": userID})
 }
 
 func (g *GroupRequestMgo) UpdateHandler(ctx context.Context, groupID string, userID string, handledMsg string, handleResult int32) (err error) {
-	return mongoutil.UpdateOne(ctx, g.coll, bson.M{"group_id": groupID, "user_id": userID}, bson.M{"$set": bson.M{"handle_msg": handledMsg, "handle_result": handleResult}}, true)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": userID}, bson.M{"$set": bson.M{"handle_msg": handledMsg, "handle_result": handleResult}}, true)
 }
 
 func (g *GroupRequestMgo) Take(ctx context.Context, groupID string, userID string) (groupRequest *model.GroupRequest, err error) {
-	return mongoutil.FindOne[*model.GroupRequest](ctx, g.coll, bson.M{"group_id": groupID, "user_id": userID})
+	return mongoutil.FindOne[*model.GroupRequest](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": userID})
 }
 
 func (g *GroupRequestMgo) FindGroupRequests(ctx context.Context, groupID string, userIDs []string) ([]*model.GroupRequest, error) {
-	return mongoutil.Find[*model.GroupRequest](ctx, g.coll, bson.M{"group_id": groupID, "user_id": bson.M{"$in": userIDs}})
+	return mongoutil.Find[*model.GroupRequest](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": groupID, "user_id": bson.M{"$in": userIDs}})
 }
 
 func (g *GroupRequestMgo) Page(ctx context.Context, userID string, pagination pagination.Pagination) (total int64, groups []*model.GroupRequest, err error) {
-	return mongoutil.FindPage[*model.GroupRequest](ctx, g.coll, bson.M{"user_id": userID}, pagination)
+	return mongoutil.FindPage[*model.GroupRequest](ctx, tenantColl(ctx, g.coll), bson.M{"user_id": userID}, pagination)
 }
 
 func (g *GroupRequestMgo) PageGroup(ctx context.Context, groupIDs []string, pagination pagination.Pagination) (total int64, groups []*model.GroupRequest, err error) {
-	return mongoutil.FindPage[*model.GroupRequest](ctx, g.coll, bson.M{"group_id": bson.M{"$in": groupIDs}}, pagination)
+	return mongoutil.FindPage[*model.GroupRequest](ctx, tenantColl(ctx, g.coll), bson.M{"group_id": bson.M{"$in": groupIDs}}, pagination)
 }
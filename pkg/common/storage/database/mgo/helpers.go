@@ -15,10 +15,68 @@
 package mgo
 
 import (
+	"context"
+	"sync"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func IsNotFound(err error) bool {
 	return errs.Unwrap(err) == mongo.ErrNoDocuments
 }
+
+// tenantIndexed holds the tenant collections whose indexes are created.
+var tenantIndexed sync.Map
+
+// tenantColl returns the collection holding the data of the tenant of ctx, coll itself for the default app.
+// A tenant collection gets the indexes of coll the first time it is used.
+func tenantColl(ctx context.Context, coll *mongo.Collection) *mongo.Collection {
+	name := tenant.CollName(ctx, coll.Name())
+	if name == coll.Name() {
+		return coll
+	}
+	tc := coll.Database().Collection(name)
+	if _, ok := tenantIndexed.Load(name); !ok {
+		if err := copyIndexes(ctx, coll, tc); err != nil {
+			log.ZWarn(ctx, "create tenant collection indexes failed", err, "collection", name)
+		} else {
+			tenantIndexed.Store(name, struct{}{})
+		}
+	}
+	return tc
+}
+
+func copyIndexes(ctx context.Context, src *mongo.Collection, dst *mongo.Collection) error {
+	specs, err := src.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return errs.WrapMsg(err, "list indexes failed", "collection", src.Name())
+	}
+	models := make([]mongo.IndexModel, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Name == "_id_" {
+			continue
+		}
+		opts := options.Index().SetName(spec.Name)
+		if spec.Unique != nil {
+			opts.SetUnique(*spec.Unique)
+		}
+		if spec.Sparse != nil {
+			opts.SetSparse(*spec.Sparse)
+		}
+		if spec.ExpireAfterSeconds != nil {
+			opts.SetExpireAfterSeconds(*spec.ExpireAfterSeconds)
+		}
+		models = append(models, mongo.IndexModel{Keys: spec.KeysDocument, Options: opts})
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if _, err := dst.Indexes().CreateMany(ctx, models); err != nil {
+		return errs.WrapMsg(err, "create indexes failed", "collection", dst.Name())
+	}
+	return nil
+}
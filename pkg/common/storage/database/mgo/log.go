@@ -58,7 +58,7 @@ type LogMgo struct {
 }
 
 func (l *LogMgo) Create(ctx context.Context, log []*model.Log) error {
-	return mongoutil.InsertMany(ctx, l.coll, log)
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, l.coll), log)
 }
 
 func (l *LogMgo) Search(ctx context.Context, keyword string, start time.Time, end time.Time, pagination pagination.Pagination) (int64, []*model.Log, error) {
@@ -66,19 +66,19 @@ func (l *LogMgo) Search(ctx context.Context, keyword string, start time.Time, en
 	if keyword != "" {
 		filter["user_id"] = bson.M{"$regex": keyword}
 	}
-	return mongoutil.FindPage[*model.Log](ctx, l.coll, filter, pagination, options.Find().SetSort(bson.M{"create_time": -1}))
+	return mongoutil.FindPage[*model.Log](ctx, tenantColl(ctx, l.coll), filter, pagination, options.Find().SetSort(bson.M{"create_time": -1}))
 }
 
 func (l *LogMgo) Delete(ctx context.Context, logID []string, userID string) error {
 	if userID == "" {
-		return mongoutil.DeleteMany(ctx, l.coll, bson.M{"log_id": bson.M{"$in": logID}})
+		return mongoutil.DeleteMany(ctx, tenantColl(ctx, l.coll), bson.M{"log_id": bson.M{"$in": logID}})
 	}
-	return mongoutil.DeleteMany(ctx, l.coll, bson.M{"log_id": bson.M{"$in": logID}, "user_id": userID})
+	return mongoutil.DeleteMany(ctx, tenantColl(ctx, l.coll), bson.M{"log_id": bson.M{"$in": logID}, "user_id": userID})
 }
 
 func (l *LogMgo) Get(ctx context.Context, logIDs []string, userID string) ([]*model.Log, error) {
 	if userID == "" {
-		return mongoutil.Find[*model.Log](ctx, l.coll, bson.M{"log_id": bson.M{"$in": logIDs}})
+		return mongoutil.Find[*model.Log](ctx, tenantColl(ctx, l.coll), bson.M{"log_id": bson.M{"$in": logIDs}})
 	}
-	return mongoutil.Find[*model.Log](ctx, l.coll, bson.M{"log_id": bson.M{"$in": logIDs}, "user_id": userID})
+	return mongoutil.Find[*model.Log](ctx, tenantColl(ctx, l.coll), bson.M{"log_id": bson.M{"$in": logIDs}, "user_id": userID})
 }
@@ -0,0 +1,77 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/mongoutil"
+	"github.com/openimsdk/tools/db/pagination"
+	"github.com/openimsdk/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewModerationReviewMongo(db *mongo.Database) (database.ModerationReview, error) {
+	coll := db.Collection(database.ModerationReviewName)
+	_, err := coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "review_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "create_time", Value: -1},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &ModerationReviewMgo{coll: coll}, nil
+}
+
+type ModerationReviewMgo struct {
+	coll *mongo.Collection
+}
+
+func (m *ModerationReviewMgo) Create(ctx context.Context, review *model.ModerationReview) error {
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, m.coll), []*model.ModerationReview{review})
+}
+
+func (m *ModerationReviewMgo) Take(ctx context.Context, reviewID string) (*model.ModerationReview, error) {
+	return mongoutil.FindOne[*model.ModerationReview](ctx, tenantColl(ctx, m.coll), bson.M{"review_id": reviewID})
+}
+
+func (m *ModerationReviewMgo) FindPage(ctx context.Context, status int32, pagination pagination.Pagination) (int64, []*model.ModerationReview, error) {
+	filter := bson.M{}
+	if status >= 0 {
+		filter["status"] = status
+	}
+	return mongoutil.FindPage[*model.ModerationReview](ctx, tenantColl(ctx, m.coll), filter, pagination, options.Find().SetSort(bson.M{"create_time": -1}))
+}
+
+func (m *ModerationReviewMgo) Review(ctx context.Context, reviewID string, from int32, to int32, reviewerUserID string, reviewTime time.Time) error {
+	filter := bson.M{"review_id": reviewID, "status": from}
+	update := bson.M{"$set": bson.M{"status": to, "reviewer_user_id": reviewerUserID, "review_time": reviewTime}}
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, m.coll), filter, update, true)
+}
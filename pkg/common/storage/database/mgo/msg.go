@@ -45,11 +45,11 @@ type MsgMgo struct {
 func (m *MsgMgo) PushMsgsToDoc(ctx context.Context, docID string, msgsToMongo []model.MsgInfoModel) error {
 	filter := bson.M{"doc_id": docID}
 	update := bson.M{"$push": bson.M{"msgs": bson.M{"$each": msgsToMongo}}}
-	return mongoutil.UpdateOne(ctx, m.coll, filter, update, false)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, m.coll), filter, update, false)
 }
 
 func (m *MsgMgo) Create(ctx context.Context, msg *model.MsgDocModel) error {
-	return mongoutil.InsertMany(ctx, m.coll, []*model.MsgDocModel{msg})
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, m.coll), []*model.MsgDocModel{msg})
 }
 
 func (m *MsgMgo) UpdateMsg(ctx context.Context, docID string, index int64, key string, value any) (*mongo.UpdateResult, error) {
@@ -61,7 +61,7 @@ func (m *MsgMgo) UpdateMsg(ctx context.Context, docID string, index int64, key s
 	}
 	filter := bson.M{"doc_id": docID}
 	update := bson.M{"$set": bson.M{field: value}}
-	return mongoutil.UpdateOneResult(ctx, m.coll, filter, update)
+	return mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, update)
 }
 
 func (m *MsgMgo) PushUnique(ctx context.Context, docID string, index int64, key string, value any) (*mongo.UpdateResult, error) {
@@ -77,21 +77,131 @@ func (m *MsgMgo) PushUnique(ctx context.Context, docID string, index int64, key
 			field: bson.M{"$each": value},
 		},
 	}
-	return mongoutil.UpdateOneResult(ctx, m.coll, filter, update)
+	return mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, update)
 }
 
 func (m *MsgMgo) UpdateMsgContent(ctx context.Context, docID string, index int64, msg []byte) error {
 	filter := bson.M{"doc_id": docID}
 	update := bson.M{"$set": bson.M{fmt.Sprintf("msgs.%d.msg", index): msg}}
-	return mongoutil.UpdateOne(ctx, m.coll, filter, update, false)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, m.coll), filter, update, false)
+}
+
+// EditMsg replaces the content of a message and appends its prior version to the edit history.
+// The message is only updated if its content is still prior.Content, so concurrent edits cannot lose a version.
+func (m *MsgMgo) EditMsg(ctx context.Context, docID string, index int64, content string, prior *model.MsgEditModel) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"doc_id": docID,
+		fmt.Sprintf("msgs.%d.msg.content", index): prior.Content,
+	}
+	update := bson.M{
+		"$set":   bson.M{fmt.Sprintf("msgs.%d.msg.content", index): content},
+		"$push":  bson.M{fmt.Sprintf("msgs.%d.edit_history", index): prior},
+		"$unset": bson.M{fmt.Sprintf("msgs.%d.translations", index): ""},
+	}
+	return mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, update)
+}
+
+// AddReaction adds userID to the reactors of a message, ModifiedCount is 0 if the user already reacted with reaction.
+// MatchedCount is 0 if the reaction is new to the message and it already has maxReactions,
+// or userID already reacted to it with maxUserReactions others.
+func (m *MsgMgo) AddReaction(ctx context.Context, docID string, index int64, reaction string, userID string, maxReactions int, maxUserReactions int) (*mongo.UpdateResult, error) {
+	reactions := reactionsExpr(index)
+	countOf := func(cond bson.M) bson.M {
+		return bson.M{"$size": bson.M{"$filter": bson.M{"input": reactions, "as": "r", "cond": cond}}}
+	}
+	filter := bson.M{
+		"doc_id":                          docID,
+		fmt.Sprintf("msgs.%d.msg", index): bson.M{"$ne": nil},
+		"$expr": bson.M{"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"$gt": bson.A{countOf(bson.M{"$eq": bson.A{"$$r.k", reaction}}), 0}},
+				bson.M{"$lt": bson.A{bson.M{"$size": reactions}, maxReactions}},
+			}},
+			bson.M{"$or": bson.A{
+				bson.M{"$gt": bson.A{countOf(bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$$r.k", reaction}},
+					bson.M{"$in": bson.A{userID, "$$r.v"}},
+				}}), 0}},
+				bson.M{"$lt": bson.A{countOf(bson.M{"$in": bson.A{userID, "$$r.v"}}), maxUserReactions}},
+			}},
+		}},
+	}
+	update := bson.M{"$addToSet": bson.M{fmt.Sprintf("msgs.%d.reactions.%s", index, reaction): userID}}
+	return mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, update)
+}
+
+// reactionsExpr evaluates to the reactions of the message at index as [{k, v}], the ones nobody is left in excluded.
+func reactionsExpr(index int64) bson.M {
+	return bson.M{"$filter": bson.M{
+		"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{
+			bson.M{"$let": bson.M{
+				"vars": bson.M{"msg": bson.M{"$arrayElemAt": bson.A{"$msgs", index}}},
+				"in":   "$$msg.reactions",
+			}},
+			bson.M{},
+		}}},
+		"as":   "reaction",
+		"cond": bson.M{"$gt": bson.A{bson.M{"$size": "$$reaction.v"}, 0}},
+	}}
+}
+
+// RemoveReaction removes userID from the reactors of a message, ModifiedCount is 0 if the user did not react with reaction.
+func (m *MsgMgo) RemoveReaction(ctx context.Context, docID string, index int64, reaction string, userID string) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"doc_id":                          docID,
+		fmt.Sprintf("msgs.%d.msg", index): bson.M{"$ne": nil},
+	}
+	key := fmt.Sprintf("msgs.%d.reactions.%s", index, reaction)
+	res, err := mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, bson.M{"$pull": bson.M{key: userID}})
+	if err != nil || res.ModifiedCount == 0 {
+		return res, err
+	}
+	// Drop the reaction once nobody is left in it, so removed reactions do not pile up in the document.
+	if _, err := mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": docID, key: bson.M{"$size": 0}}, bson.M{"$unset": bson.M{key: ""}}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AddThreadReplies records the replies of the thread anchored on a message up to lastReply, the reply count is
+// the seq of lastReply. Replies older than the recorded ones are ignored, so the update can be applied again.
+func (m *MsgMgo) AddThreadReplies(ctx context.Context, docID string, index int64, lastReply *model.ThreadReplyModel) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"doc_id":                          docID,
+		fmt.Sprintf("msgs.%d.msg", index): bson.M{"$ne": nil},
+	}
+	update := bson.M{"$max": bson.M{fmt.Sprintf("msgs.%d.thread.reply_count", index): lastReply.Seq}}
+	res, err := mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, update)
+	if err != nil || res.MatchedCount == 0 {
+		return res, err
+	}
+	key := fmt.Sprintf("msgs.%d.thread.last_reply", index)
+	filter[key+".seq"] = bson.M{"$not": bson.M{"$gte": lastReply.Seq}}
+	if _, err := mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, bson.M{"$set": bson.M{key: lastReply}}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SetTranslations stores the translations of a message by language, MatchedCount is 0 if its content is no longer content.
+func (m *MsgMgo) SetTranslations(ctx context.Context, docID string, index int64, content string, translations map[string]string) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"doc_id": docID,
+		fmt.Sprintf("msgs.%d.msg.content", index): content,
+	}
+	set := make(bson.M, len(translations))
+	for language, text := range translations {
+		set[fmt.Sprintf("msgs.%d.translations.%s", index, language)] = text
+	}
+	return mongoutil.UpdateOneResult(ctx, tenantColl(ctx, m.coll), filter, bson.M{"$set": set})
 }
 
 func (m *MsgMgo) IsExistDocID(ctx context.Context, docID string) (bool, error) {
-	return mongoutil.Exist(ctx, m.coll, bson.M{"doc_id": docID})
+	return mongoutil.Exist(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": docID})
 }
 
 func (m *MsgMgo) FindOneByDocID(ctx context.Context, docID string) (*model.MsgDocModel, error) {
-	return mongoutil.FindOne[*model.MsgDocModel](ctx, m.coll, bson.M{"doc_id": docID})
+	return mongoutil.FindOne[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": docID})
 }
 
 func (m *MsgMgo) GetMsgBySeqIndexIn1Doc(ctx context.Context, userID, docID string, seqs []int64) ([]*model.MsgInfoModel, error) {
@@ -140,7 +250,7 @@ func (m *MsgMgo) getMsgBySeqIndexIn1Doc(ctx context.Context, userID, docID strin
 			}},
 		}}},
 	}
-	msgDocModel, err := mongoutil.Aggregate[*model.MsgDocModel](ctx, m.coll, pipeline)
+	msgDocModel, err := mongoutil.Aggregate[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -218,11 +328,36 @@ func (m *MsgMgo) GetOldestMsg(ctx context.Context, conversationID string) (*mode
 	}
 }
 
+func (m *MsgMgo) FindSeqByServerMsgID(ctx context.Context, conversationID string, serverMsgID string) (int64, error) {
+	filter := bson.M{
+		"doc_id":                 primitive.Regex{Pattern: fmt.Sprintf("^%s:", conversationID)},
+		"msgs.msg.server_msg_id": serverMsgID,
+	}
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$unwind": "$msgs"},
+		{"$match": bson.M{"msgs.msg.server_msg_id": serverMsgID}},
+		{"$project": bson.M{"_id": 0, "seq": "$msgs.msg.seq"}},
+		{"$limit": 1},
+	}
+	type result struct {
+		Seq int64 `bson:"seq"`
+	}
+	res, err := mongoutil.Aggregate[result](ctx, tenantColl(ctx, m.coll), pipeline)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, errs.ErrRecordNotFound.WrapMsg("msg not found", "conversationID", conversationID, "serverMsgID", serverMsgID)
+	}
+	return res[0].Seq, nil
+}
+
 func (m *MsgMgo) DeleteDocs(ctx context.Context, docIDs []string) error {
 	if len(docIDs) == 0 {
 		return nil
 	}
-	return mongoutil.DeleteMany(ctx, m.coll, bson.M{"doc_id": bson.M{"$in": docIDs}})
+	return mongoutil.DeleteMany(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": bson.M{"$in": docIDs}})
 }
 
 func (m *MsgMgo) GetMsgDocModelByIndex(ctx context.Context, conversationID string, index, sort int64) (*model.MsgDocModel, error) {
@@ -231,7 +366,7 @@ func (m *MsgMgo) GetMsgDocModelByIndex(ctx context.Context, conversationID strin
 	}
 	opt := options.Find().SetSkip(index).SetSort(bson.M{"_id": sort}).SetLimit(1)
 	filter := bson.M{"doc_id": primitive.Regex{Pattern: fmt.Sprintf("^%s:", conversationID)}}
-	msgs, err := mongoutil.Find[*model.MsgDocModel](ctx, m.coll, filter, opt)
+	msgs, err := mongoutil.Find[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), filter, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +385,7 @@ func (m *MsgMgo) DeleteMsgsInOneDocByIndex(ctx context.Context, docID string, in
 			"msg": nil,
 		}
 	}
-	_, err := mongoutil.UpdateMany(ctx, m.coll, bson.M{"doc_id": docID}, update)
+	_, err := mongoutil.UpdateMany(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": docID}, update)
 	return err
 }
 
@@ -273,7 +408,7 @@ func (m *MsgMgo) MarkSingleChatMsgsAsRead(ctx context.Context, userID string, do
 			SetUpdate(update)
 		updates = append(updates, updateModel)
 	}
-	if _, err := m.coll.BulkWrite(ctx, updates); err != nil {
+	if _, err := tenantColl(ctx, m.coll).BulkWrite(ctx, updates); err != nil {
 		return errs.WrapMsg(err, fmt.Sprintf("docID is %s, indexes is %v", docID, indexes))
 	}
 	return nil
@@ -432,7 +567,7 @@ func (m *MsgMgo) searchMessageIndex(ctx context.Context, filter any, nextID prim
 		},
 		bson.M{"$sort": bson.M{"_id": 1}},
 	)
-	return mongoutil.Aggregate[searchMessageIndex](ctx, m.coll, pipeline)
+	return mongoutil.Aggregate[searchMessageIndex](ctx, tenantColl(ctx, m.coll), pipeline)
 }
 
 func (m *MsgMgo) searchMessage(ctx context.Context, req *msg.SearchMessageReq) (int64, []searchMessageIndex, error) {
@@ -521,7 +656,7 @@ func (m *MsgMgo) getDocRange(ctx context.Context, id primitive.ObjectID, index [
 		bson.M{"$match": bson.M{"_id": id}},
 		bson.M{"$project": "$msgs"},
 	}
-	msgs, err := mongoutil.Aggregate[*model.MsgInfoModel](ctx, m.coll, pipeline)
+	msgs, err := mongoutil.Aggregate[*model.MsgInfoModel](ctx, tenantColl(ctx, m.coll), pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -542,7 +677,7 @@ func (m *MsgMgo) SearchMessage(ctx context.Context, req *msg.SearchMessageReq) (
 		msgs = make([]*model.MsgInfoModel, 0, n)
 	}
 	for _, val := range data {
-		res, err := mongoutil.FindOne[*model.MsgDocModel](ctx, m.coll, bson.M{"_id": val.ID})
+		res, err := mongoutil.FindOne[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), bson.M{"_id": val.ID})
 		if err != nil {
 			return 0, nil, err
 		}
@@ -920,7 +1055,7 @@ func (m *MsgMgo) RangeUserSendCount(ctx context.Context, start time.Time, end ti
 			},
 		},
 	}
-	result, err := mongoutil.Aggregate[*Result](ctx, m.coll, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	result, err := mongoutil.Aggregate[*Result](ctx, tenantColl(ctx, m.coll), pipeline, options.Aggregate().SetAllowDiskUse(true))
 	if err != nil {
 		return 0, 0, nil, nil, err
 	}
@@ -1157,7 +1292,7 @@ func (m *MsgMgo) RangeGroupSendCount(ctx context.Context, start time.Time, end t
 			},
 		},
 	}
-	result, err := mongoutil.Aggregate[*Result](ctx, m.coll, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	result, err := mongoutil.Aggregate[*Result](ctx, tenantColl(ctx, m.coll), pipeline, options.Aggregate().SetAllowDiskUse(true))
 	if err != nil {
 		return 0, 0, nil, nil, err
 	}
@@ -1181,7 +1316,7 @@ func (m *MsgMgo) RangeGroupSendCount(ctx context.Context, start time.Time, end t
 func (m *MsgMgo) ConvertMsgsDocLen(ctx context.Context, conversationIDs []string) {
 	for _, conversationID := range conversationIDs {
 		regex := primitive.Regex{Pattern: fmt.Sprintf("^%s:", conversationID)}
-		msgDocs, err := mongoutil.Find[*model.MsgDocModel](ctx, m.coll, bson.M{"doc_id": regex})
+		msgDocs, err := mongoutil.Find[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": regex})
 		if err != nil {
 			log.ZError(ctx, "convertAll find msg doc failed", err, "conversationID", conversationID)
 			continue
@@ -1191,7 +1326,7 @@ func (m *MsgMgo) ConvertMsgsDocLen(ctx context.Context, conversationIDs []string
 		}
 		log.ZDebug(ctx, "msg doc convert", "conversationID", conversationID, "len(msgDocs)", len(msgDocs))
 		if len(msgDocs[0].Msg) == int(m.model.GetSingleGocMsgNum5000()) {
-			if err := mongoutil.DeleteMany(ctx, m.coll, bson.M{"doc_id": regex}); err != nil {
+			if err := mongoutil.DeleteMany(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": regex}); err != nil {
 				log.ZError(ctx, "convertAll delete many failed", err, "conversationID", conversationID)
 				continue
 			}
@@ -1218,7 +1353,7 @@ func (m *MsgMgo) ConvertMsgsDocLen(ctx context.Context, conversationIDs []string
 					}
 				}
 			}
-			if err = mongoutil.InsertMany(ctx, m.coll, newMsgDocs); err != nil {
+			if err = mongoutil.InsertMany(ctx, tenantColl(ctx, m.coll), newMsgDocs); err != nil {
 				log.ZError(ctx, "convertAll insert many failed", err, "conversationID", conversationID, "len(newMsgDocs)", len(newMsgDocs))
 			} else {
 				log.ZDebug(ctx, "msg doc convert", "conversationID", conversationID, "len(newMsgDocs)", len(newMsgDocs))
@@ -1233,7 +1368,7 @@ func (m *MsgMgo) GetDocIDs(ctx context.Context) ([]string, error) {
 	var docIDs []string
 	var offset int
 
-	count, err := m.coll.CountDocuments(ctx, bson.M{})
+	count, err := tenantColl(ctx, m.coll).CountDocuments(ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
@@ -1246,7 +1381,7 @@ func (m *MsgMgo) GetDocIDs(ctx context.Context) ([]string, error) {
 		offset = skip * limit
 	}
 	log.ZDebug(ctx, "offset", "skip", skip, "offset", offset)
-	res, err := mongoutil.Aggregate[*model.MsgDocModel](ctx, m.coll, []bson.M{
+	res, err := mongoutil.Aggregate[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), []bson.M{
 		{
 			"$project": bson.M{
 				"doc_id": 1,
@@ -1268,7 +1403,7 @@ func (m *MsgMgo) GetDocIDs(ctx context.Context) ([]string, error) {
 }
 
 func (m *MsgMgo) GetBeforeMsg(ctx context.Context, ts int64, docIDs []string, limit int) ([]*model.MsgDocModel, error) {
-	return mongoutil.Aggregate[*model.MsgDocModel](ctx, m.coll, []bson.M{
+	return mongoutil.Aggregate[*model.MsgDocModel](ctx, tenantColl(ctx, m.coll), []bson.M{
 		{
 			"$match": bson.M{
 				"doc_id": bson.M{
@@ -1302,7 +1437,7 @@ func (m *MsgMgo) DeleteMsgByIndex(ctx context.Context, docID string, index []int
 	for i := range index {
 		set[fmt.Sprintf("msgs.%d", i)] = model
 	}
-	return mongoutil.UpdateOne(ctx, m.coll, bson.M{"doc_id": docID}, bson.M{"$set": set}, true)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": docID}, bson.M{"$set": set}, true)
 }
 
 //func (m *MsgMgo) ClearMsg(ctx context.Context, t time.Time) (int64, error) {
@@ -1327,7 +1462,7 @@ func (m *MsgMgo) DeleteMsgByIndex(ctx context.Context, docID string, index []int
 //}
 
 func (m *MsgMgo) DeleteDoc(ctx context.Context, docID string) error {
-	return mongoutil.DeleteOne(ctx, m.coll, bson.M{"doc_id": docID})
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, m.coll), bson.M{"doc_id": docID})
 }
 
 //func (m *MsgMgo) DeleteDocMsg(ctx context.Context, ts int64, doc *relation.MsgDocModel) (int64, error) {
@@ -0,0 +1,106 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/tools/db/mongoutil"
+	"github.com/openimsdk/tools/db/pagination"
+	"github.com/openimsdk/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewMsgExportJobMongo(db *mongo.Database) (database.MsgExportJob, error) {
+	coll := db.Collection(database.MsgExportJobName)
+	_, err := coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "job_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "create_time", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "create_time", Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &MsgExportJobMgo{coll: coll}, nil
+}
+
+type MsgExportJobMgo struct {
+	coll *mongo.Collection
+}
+
+func (m *MsgExportJobMgo) Create(ctx context.Context, job *model.MsgExportJob) error {
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, m.coll), []*model.MsgExportJob{job})
+}
+
+func (m *MsgExportJobMgo) Take(ctx context.Context, jobID string) (*model.MsgExportJob, error) {
+	return mongoutil.FindOne[*model.MsgExportJob](ctx, tenantColl(ctx, m.coll), bson.M{"job_id": jobID})
+}
+
+func (m *MsgExportJobMgo) Find(ctx context.Context, pagination pagination.Pagination) (int64, []*model.MsgExportJob, error) {
+	return mongoutil.FindPage[*model.MsgExportJob](ctx, tenantColl(ctx, m.coll), bson.M{}, pagination, options.Find().SetSort(bson.M{"create_time": -1}))
+}
+
+func (m *MsgExportJobMgo) Claim(ctx context.Context, now time.Time, expire time.Time, limit int) ([]*model.MsgExportJob, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": msgext.MsgExportPending},
+			bson.M{"status": msgext.MsgExportRunning, "claim_time": bson.M{"$lt": expire}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"status": msgext.MsgExportRunning, "claim_time": now, "msg_count": 0}}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"create_time": 1}).SetReturnDocument(options.After)
+	var res []*model.MsgExportJob
+	for len(res) < limit {
+		job, err := mongoutil.FindOneAndUpdate[*model.MsgExportJob](ctx, tenantColl(ctx, m.coll), filter, update, opts)
+		if err != nil {
+			if IsNotFound(err) {
+				break
+			}
+			return nil, err
+		}
+		res = append(res, job)
+	}
+	return res, nil
+}
+
+func (m *MsgExportJobMgo) Progress(ctx context.Context, jobID string, msgCount int64, now time.Time) error {
+	filter := bson.M{"job_id": jobID, "status": msgext.MsgExportRunning}
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, m.coll), filter, bson.M{"$set": bson.M{"msg_count": msgCount, "claim_time": now}}, false)
+}
+
+func (m *MsgExportJobMgo) Finish(ctx context.Context, jobID string, data map[string]any) error {
+	filter := bson.M{"job_id": jobID, "status": msgext.MsgExportRunning}
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, m.coll), filter, bson.M{"$set": data}, false)
+}
@@ -0,0 +1,61 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgo
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/mongoutil"
+	"github.com/openimsdk/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewNotificationSettingMongo(db *mongo.Database) (database.NotificationSetting, error) {
+	coll := db.Collection(database.NotificationSettingName)
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "user_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &NotificationSettingMgo{coll: coll}, nil
+}
+
+type NotificationSettingMgo struct {
+	coll *mongo.Collection
+}
+
+func (n *NotificationSettingMgo) Set(ctx context.Context, setting *model.NotificationSetting) error {
+	filter := bson.M{"user_id": setting.UserID}
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, n.coll), filter, bson.M{"$set": setting}, false, options.Update().SetUpsert(true))
+}
+
+func (n *NotificationSettingMgo) Delete(ctx context.Context, userID string) error {
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, n.coll), bson.M{"user_id": userID})
+}
+
+func (n *NotificationSettingMgo) Find(ctx context.Context, userIDs []string) ([]*model.NotificationSetting, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return mongoutil.Find[*model.NotificationSetting](ctx, tenantColl(ctx, n.coll), bson.M{"user_id": bson.M{"$in": userIDs}})
+}
@@ -58,26 +58,26 @@ func (o *S3Mongo) SetObject(ctx context.Context, obj *model.Object) error {
 		"group":        obj.Group,
 		"create_time":  obj.CreateTime,
 	}
-	return mongoutil.UpdateOne(ctx, o.coll, filter, bson.M{"$set": update}, false, options.Update().SetUpsert(true))
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, o.coll), filter, bson.M{"$set": update}, false, options.Update().SetUpsert(true))
 }
 
 func (o *S3Mongo) Take(ctx context.Context, engine string, name string) (*model.Object, error) {
 	if engine == "" {
-		return mongoutil.FindOne[*model.Object](ctx, o.coll, bson.M{"name": name})
+		return mongoutil.FindOne[*model.Object](ctx, tenantColl(ctx, o.coll), bson.M{"name": name})
 	}
-	return mongoutil.FindOne[*model.Object](ctx, o.coll, bson.M{"name": name, "engine": engine})
+	return mongoutil.FindOne[*model.Object](ctx, tenantColl(ctx, o.coll), bson.M{"name": name, "engine": engine})
 }
 
 func (o *S3Mongo) Delete(ctx context.Context, engine string, name string) error {
-	return mongoutil.DeleteOne(ctx, o.coll, bson.M{"name": name, "engine": engine})
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, o.coll), bson.M{"name": name, "engine": engine})
 }
 func (o *S3Mongo) FindByExpires(ctx context.Context, duration time.Time, pagination pagination.Pagination) (total int64, objects []*model.Object, err error) {
-	return mongoutil.FindPage[*model.Object](ctx, o.coll, bson.M{
+	return mongoutil.FindPage[*model.Object](ctx, tenantColl(ctx, o.coll), bson.M{
 		"create_time": bson.M{"$lt": duration},
 	}, pagination)
 }
 func (o *S3Mongo) FindNotDelByS3(ctx context.Context, key string, duration time.Time) (int64, error) {
-	return mongoutil.Count(ctx, o.coll, bson.M{
+	return mongoutil.Count(ctx, tenantColl(ctx, o.coll), bson.M{
 		"key":         key,
 		"create_time": bson.M{"$gt": duration},
 	})
@@ -0,0 +1,83 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgo
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/mongoutil"
+	"github.com/openimsdk/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewPinnedMsgMongo(db *mongo.Database) (database.PinnedMsg, error) {
+	coll := db.Collection(database.PinnedMsgName)
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "conversation_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &PinnedMsgMgo{coll: coll}, nil
+}
+
+type PinnedMsgMgo struct {
+	coll *mongo.Collection
+}
+
+func (p *PinnedMsgMgo) Pin(ctx context.Context, conversationID string, msg *model.PinnedMsg, maxNum int) (bool, error) {
+	coll := tenantColl(ctx, p.coll)
+	// Create the document first so the push below never upserts, a failed "pinned.seq" match means already pinned.
+	err := mongoutil.UpdateOne(ctx, coll, bson.M{"conversation_id": conversationID},
+		bson.M{"$setOnInsert": bson.M{"pinned": bson.A{}}}, false, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	filter := bson.M{"conversation_id": conversationID, "pinned.seq": bson.M{"$ne": msg.Seq}}
+	update := bson.M{"$push": bson.M{"pinned": bson.M{"$each": bson.A{msg}, "$slice": -maxNum}}}
+	res, err := mongoutil.UpdateOneResult(ctx, coll, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (p *PinnedMsgMgo) Unpin(ctx context.Context, conversationID string, seq int64) (bool, error) {
+	filter := bson.M{"conversation_id": conversationID}
+	update := bson.M{"$pull": bson.M{"pinned": bson.M{"seq": seq}}}
+	res, err := mongoutil.UpdateOneResult(ctx, tenantColl(ctx, p.coll), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (p *PinnedMsgMgo) Find(ctx context.Context, conversationID string) ([]*model.PinnedMsg, error) {
+	res, err := mongoutil.FindOne[*model.ConversationPinnedMsgs](ctx, tenantColl(ctx, p.coll), bson.M{"conversation_id": conversationID})
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return res.Pinned, nil
+}
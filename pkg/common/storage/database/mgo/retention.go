@@ -0,0 +1,125 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/retention"
+	"github.com/openimsdk/tools/db/mongoutil"
+	"github.com/openimsdk/tools/db/pagination"
+	"github.com/openimsdk/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// retentionSkipExpire is how long the skipped deletions are recorded.
+const retentionSkipExpire = time.Hour * 24 * 90
+
+func NewRetentionPolicyMongo(db *mongo.Database) (database.RetentionPolicy, error) {
+	coll := db.Collection(database.RetentionPolicyName)
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "target_type", Value: 1},
+			{Key: "target_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &RetentionPolicyMgo{coll: coll}, nil
+}
+
+type RetentionPolicyMgo struct {
+	coll *mongo.Collection
+}
+
+func (r *RetentionPolicyMgo) Set(ctx context.Context, policy *model.RetentionPolicy) error {
+	filter := bson.M{"target_type": policy.TargetType, "target_id": policy.TargetID}
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, r.coll), filter, bson.M{"$set": policy}, false, options.Update().SetUpsert(true))
+}
+
+func (r *RetentionPolicyMgo) Delete(ctx context.Context, targetType int32, targetID string) error {
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, r.coll), bson.M{"target_type": targetType, "target_id": targetID})
+}
+
+func (r *RetentionPolicyMgo) Find(ctx context.Context, targets []retention.Target) ([]*model.RetentionPolicy, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	or := make(bson.A, 0, len(targets))
+	for _, target := range targets {
+		or = append(or, bson.M{"target_type": target.Type, "target_id": target.ID})
+	}
+	return mongoutil.Find[*model.RetentionPolicy](ctx, tenantColl(ctx, r.coll), bson.M{"$or": or})
+}
+
+func (r *RetentionPolicyMgo) FindAll(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	return mongoutil.Find[*model.RetentionPolicy](ctx, tenantColl(ctx, r.coll), bson.M{})
+}
+
+func (r *RetentionPolicyMgo) FindPage(ctx context.Context, targetType int32, pagination pagination.Pagination) (int64, []*model.RetentionPolicy, error) {
+	filter := bson.M{}
+	if targetType != 0 {
+		filter["target_type"] = targetType
+	}
+	return mongoutil.FindPage[*model.RetentionPolicy](ctx, tenantColl(ctx, r.coll), filter, pagination, options.Find().SetSort(bson.M{"update_time": -1}))
+}
+
+func NewRetentionSkipMongo(db *mongo.Database) (database.RetentionSkip, error) {
+	coll := db.Collection(database.RetentionSkipName)
+	_, err := coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "conversation_id", Value: 1},
+				{Key: "create_time", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "create_time", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(int32(retentionSkipExpire / time.Second)),
+		},
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &RetentionSkipMgo{coll: coll}, nil
+}
+
+type RetentionSkipMgo struct {
+	coll *mongo.Collection
+}
+
+func (r *RetentionSkipMgo) Create(ctx context.Context, skips []*model.RetentionSkip) error {
+	if len(skips) == 0 {
+		return nil
+	}
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, r.coll), skips)
+}
+
+func (r *RetentionSkipMgo) FindPage(ctx context.Context, conversationID string, pagination pagination.Pagination) (int64, []*model.RetentionSkip, error) {
+	filter := bson.M{}
+	if conversationID != "" {
+		filter["conversation_id"] = conversationID
+	}
+	return mongoutil.FindPage[*model.RetentionSkip](ctx, tenantColl(ctx, r.coll), filter, pagination, options.Find().SetSort(bson.M{"create_time": -1}))
+}
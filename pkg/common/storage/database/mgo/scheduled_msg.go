@@ -0,0 +1,131 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/database"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
+	"github.com/openimsdk/tools/db/mongoutil"
+	"github.com/openimsdk/tools/db/pagination"
+	"github.com/openimsdk/tools/errs"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func NewScheduledMsgMongo(db *mongo.Database) (database.ScheduledMsg, error) {
+	coll := db.Collection(database.ScheduledMsgName)
+	_, err := coll.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "schedule_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "send_id", Value: 1},
+				{Key: "send_time", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "send_time", Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &ScheduledMsgMgo{coll: coll}, nil
+}
+
+type ScheduledMsgMgo struct {
+	coll *mongo.Collection
+}
+
+func (s *ScheduledMsgMgo) Create(ctx context.Context, msg *model.ScheduledMsg) error {
+	return mongoutil.InsertMany(ctx, tenantColl(ctx, s.coll), []*model.ScheduledMsg{msg})
+}
+
+func (s *ScheduledMsgMgo) Take(ctx context.Context, scheduleID string) (*model.ScheduledMsg, error) {
+	return mongoutil.FindOne[*model.ScheduledMsg](ctx, tenantColl(ctx, s.coll), bson.M{"schedule_id": scheduleID})
+}
+
+func (s *ScheduledMsgMgo) FindBySendID(ctx context.Context, sendID string, pagination pagination.Pagination) (int64, []*model.ScheduledMsg, error) {
+	return mongoutil.FindPage[*model.ScheduledMsg](ctx, tenantColl(ctx, s.coll), bson.M{"send_id": sendID}, pagination, options.Find().SetSort(bson.M{"send_time": 1}))
+}
+
+// notClaimed matches the scheduled messages the cron task is not sending.
+func notClaimed(scheduleID string, sendID string) bson.M {
+	return bson.M{
+		"schedule_id": scheduleID,
+		"send_id":     sendID,
+		"status":      bson.M{"$ne": msgext.ScheduledMsgSending},
+	}
+}
+
+func (s *ScheduledMsgMgo) Update(ctx context.Context, scheduleID string, sendID string, data map[string]any) (bool, error) {
+	res, err := mongoutil.UpdateOneResult(ctx, tenantColl(ctx, s.coll), notClaimed(scheduleID, sendID), bson.M{"$set": data})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (s *ScheduledMsgMgo) Delete(ctx context.Context, scheduleID string, sendID string) (bool, error) {
+	res, err := mongoutil.DeleteOneResult(ctx, tenantColl(ctx, s.coll), notClaimed(scheduleID, sendID))
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (s *ScheduledMsgMgo) ClaimDue(ctx context.Context, now time.Time, expire time.Time, limit int) ([]*model.ScheduledMsg, error) {
+	filter := bson.M{
+		"send_time": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"status": msgext.ScheduledMsgPending},
+			bson.M{"status": msgext.ScheduledMsgSending, "claim_time": bson.M{"$lt": expire}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"status": msgext.ScheduledMsgSending, "claim_time": now}}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"send_time": 1}).SetReturnDocument(options.After)
+	var res []*model.ScheduledMsg
+	for len(res) < limit {
+		msg, err := mongoutil.FindOneAndUpdate[*model.ScheduledMsg](ctx, tenantColl(ctx, s.coll), filter, update, opts)
+		if err != nil {
+			if IsNotFound(err) {
+				break
+			}
+			return nil, err
+		}
+		res = append(res, msg)
+	}
+	return res, nil
+}
+
+func (s *ScheduledMsgMgo) Finish(ctx context.Context, scheduleID string, errMsg string) error {
+	filter := bson.M{"schedule_id": scheduleID, "status": msgext.ScheduledMsgSending}
+	if errMsg == "" {
+		return mongoutil.DeleteOne(ctx, tenantColl(ctx, s.coll), filter)
+	}
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, s.coll), filter, bson.M{"$set": bson.M{"status": msgext.ScheduledMsgFailed, "err_msg": errMsg}}, false)
+}
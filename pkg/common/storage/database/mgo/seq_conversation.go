@@ -45,7 +45,7 @@ func (s *seqConversationMongo) setSeq(ctx context.Context, conversationID string
 		"$setOnInsert": insert,
 	}
 	opt := options.Update().SetUpsert(true)
-	return mongoutil.UpdateOne(ctx, s.coll, filter, update, false, opt)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, s.coll), filter, update, false, opt)
 }
 
 func (s *seqConversationMongo) Malloc(ctx context.Context, conversationID string, size int64) (int64, error) {
@@ -61,7 +61,7 @@ func (s *seqConversationMongo) Malloc(ctx context.Context, conversationID string
 		"$set": map[string]any{"min_seq": int64(0)},
 	}
 	opt := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After).SetProjection(map[string]any{"_id": 0, "max_seq": 1})
-	lastSeq, err := mongoutil.FindOneAndUpdate[int64](ctx, s.coll, filter, update, opt)
+	lastSeq, err := mongoutil.FindOneAndUpdate[int64](ctx, tenantColl(ctx, s.coll), filter, update, opt)
 	if err != nil {
 		return 0, err
 	}
@@ -73,7 +73,7 @@ func (s *seqConversationMongo) SetMaxSeq(ctx context.Context, conversationID str
 }
 
 func (s *seqConversationMongo) GetMaxSeq(ctx context.Context, conversationID string) (int64, error) {
-	seq, err := mongoutil.FindOne[int64](ctx, s.coll, bson.M{"conversation_id": conversationID}, options.FindOne().SetProjection(map[string]any{"_id": 0, "max_seq": 1}))
+	seq, err := mongoutil.FindOne[int64](ctx, tenantColl(ctx, s.coll), bson.M{"conversation_id": conversationID}, options.FindOne().SetProjection(map[string]any{"_id": 0, "max_seq": 1}))
 	if err == nil {
 		return seq, nil
 	} else if IsNotFound(err) {
@@ -84,7 +84,7 @@ func (s *seqConversationMongo) GetMaxSeq(ctx context.Context, conversationID str
 }
 
 func (s *seqConversationMongo) GetMinSeq(ctx context.Context, conversationID string) (int64, error) {
-	seq, err := mongoutil.FindOne[int64](ctx, s.coll, bson.M{"conversation_id": conversationID}, options.FindOne().SetProjection(map[string]any{"_id": 0, "min_seq": 1}))
+	seq, err := mongoutil.FindOne[int64](ctx, tenantColl(ctx, s.coll), bson.M{"conversation_id": conversationID}, options.FindOne().SetProjection(map[string]any{"_id": 0, "min_seq": 1}))
 	if err == nil {
 		return seq, nil
 	} else if IsNotFound(err) {
@@ -99,5 +99,5 @@ func (s *seqConversationMongo) SetMinSeq(ctx context.Context, conversationID str
 }
 
 func (s *seqConversationMongo) GetConversation(ctx context.Context, conversationID string) (*model.SeqConversation, error) {
-	return mongoutil.FindOne[*model.SeqConversation](ctx, s.coll, bson.M{"conversation_id": conversationID})
+	return mongoutil.FindOne[*model.SeqConversation](ctx, tenantColl(ctx, s.coll), bson.M{"conversation_id": conversationID})
 }
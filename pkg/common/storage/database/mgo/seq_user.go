@@ -49,7 +49,7 @@ func (s *seqUserMongo) setSeq(ctx context.Context, conversationID string, userID
 		"$setOnInsert": insert,
 	}
 	opt := options.Update().SetUpsert(true)
-	return mongoutil.UpdateOne(ctx, s.coll, filter, update, false, opt)
+	return mongoutil.UpdateOne(ctx, tenantColl(ctx, s.coll), filter, update, false, opt)
 }
 
 func (s *seqUserMongo) getSeq(ctx context.Context, conversationID string, userID string, failed string) (int64, error) {
@@ -58,7 +58,7 @@ func (s *seqUserMongo) getSeq(ctx context.Context, conversationID string, userID
 		"conversation_id": conversationID,
 	}
 	opt := options.FindOne().SetProjection(bson.M{"_id": 0, failed: 1})
-	seq, err := mongoutil.FindOne[int64](ctx, s.coll, filter, opt)
+	seq, err := mongoutil.FindOne[int64](ctx, tenantColl(ctx, s.coll), filter, opt)
 	if err == nil {
 		return seq, nil
 	} else if errors.Is(err, mongo.ErrNoDocuments) {
@@ -102,7 +102,7 @@ func (s *seqUserMongo) GetUserReadSeqs(ctx context.Context, userID string, conve
 	}
 	filter := bson.M{"user_id": userID, "conversation_id": bson.M{"$in": conversationID}}
 	opt := options.Find().SetProjection(bson.M{"_id": 0, "conversation_id": 1, "read_seq": 1})
-	seqs, err := mongoutil.Find[*model.SeqUser](ctx, s.coll, filter, opt)
+	seqs, err := mongoutil.Find[*model.SeqUser](ctx, tenantColl(ctx, s.coll), filter, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +114,24 @@ func (s *seqUserMongo) GetUserReadSeqs(ctx context.Context, userID string, conve
 	return res, nil
 }
 
+func (s *seqUserMongo) GetUsersReadSeq(ctx context.Context, conversationID string, userIDs []string) (map[string]int64, error) {
+	if len(userIDs) == 0 {
+		return map[string]int64{}, nil
+	}
+	filter := bson.M{"conversation_id": conversationID, "user_id": bson.M{"$in": userIDs}}
+	opt := options.Find().SetProjection(bson.M{"_id": 0, "user_id": 1, "read_seq": 1})
+	seqs, err := mongoutil.Find[*model.SeqUser](ctx, tenantColl(ctx, s.coll), filter, opt)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]int64)
+	for _, seq := range seqs {
+		res[seq.UserID] = seq.ReadSeq
+	}
+	s.notFoundSet0(res, userIDs)
+	return res, nil
+}
+
 func (s *seqUserMongo) SetUserReadSeq(ctx context.Context, conversationID string, userID string, seq int64) error {
 	dbSeq, err := s.GetUserReadSeq(ctx, conversationID, userID)
 	if err != nil {
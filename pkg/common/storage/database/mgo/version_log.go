@@ -92,7 +92,7 @@ func (l *VersionLogMgo) initDoc(ctx context.Context, dId string, eIds []string,
 			LastUpdate: now,
 		})
 	}
-	if _, err := l.coll.InsertOne(ctx, &wl); err != nil {
+	if _, err := tenantColl(ctx, l.coll).InsertOne(ctx, &wl); err != nil {
 		return nil, err
 	}
 	return wl.VersionLog(), nil
@@ -159,7 +159,7 @@ func (l *VersionLogMgo) writeLogBatch2(ctx context.Context, dId string, eIds []s
 		"logs": 0,
 	}
 	opt := options.FindOneAndUpdate().SetUpsert(false).SetReturnDocument(options.After).SetProjection(projection)
-	res, err := mongoutil.FindOneAndUpdate[*model.VersionLog](ctx, l.coll, filter, pipeline, opt)
+	res, err := mongoutil.FindOneAndUpdate[*model.VersionLog](ctx, tenantColl(ctx, l.coll), filter, pipeline, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +176,7 @@ func (l *VersionLogMgo) writeLogBatch2(ctx context.Context, dId string, eIds []s
 }
 
 func (l *VersionLogMgo) findDoc(ctx context.Context, dId string) (*model.VersionLog, error) {
-	vl, err := mongoutil.FindOne[*model.VersionLogTable](ctx, l.coll, bson.M{"d_id": dId}, options.FindOne().SetProjection(bson.M{"logs": 0}))
+	vl, err := mongoutil.FindOne[*model.VersionLogTable](ctx, tenantColl(ctx, l.coll), bson.M{"d_id": dId}, options.FindOne().SetProjection(bson.M{"logs": 0}))
 	if err != nil {
 		return nil, err
 	}
@@ -281,7 +281,7 @@ func (l *VersionLogMgo) findChangeLog(ctx context.Context, dId string, version u
 	if limit <= 0 {
 		pipeline = pipeline[:len(pipeline)-1]
 	}
-	vl, err := mongoutil.Aggregate[*model.VersionLog](ctx, l.coll, pipeline)
+	vl, err := mongoutil.Aggregate[*model.VersionLog](ctx, tenantColl(ctx, l.coll), pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +292,7 @@ func (l *VersionLogMgo) findChangeLog(ctx context.Context, dId string, version u
 }
 
 func (l *VersionLogMgo) DeleteAfterUnchangedLog(ctx context.Context, deadline time.Time) error {
-	return mongoutil.DeleteMany(ctx, l.coll, bson.M{
+	return mongoutil.DeleteMany(ctx, tenantColl(ctx, l.coll), bson.M{
 		"last_update": bson.M{
 			"$lt": deadline,
 		},
@@ -300,5 +300,5 @@ func (l *VersionLogMgo) DeleteAfterUnchangedLog(ctx context.Context, deadline ti
 }
 
 func (l *VersionLogMgo) Delete(ctx context.Context, dId string) error {
-	return mongoutil.DeleteOne(ctx, l.coll, bson.M{"d_id": dId})
+	return mongoutil.DeleteOne(ctx, tenantColl(ctx, l.coll), bson.M{"d_id": dId})
 }
@@ -0,0 +1,32 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type ModerationReview interface {
+	Create(ctx context.Context, review *model.ModerationReview) error
+	Take(ctx context.Context, reviewID string) (*model.ModerationReview, error)
+	// FindPage returns the latest reviews with status, or of every status when it is negative.
+	FindPage(ctx context.Context, status int32, pagination pagination.Pagination) (int64, []*model.ModerationReview, error)
+	// Review changes the status of a review which still has status from, it returns ErrNoDocuments when it has not.
+	Review(ctx context.Context, reviewID string, from int32, to int32, reviewerUserID string, reviewTime time.Time) error
+}
@@ -29,11 +29,19 @@ type Msg interface {
 	UpdateMsg(ctx context.Context, docID string, index int64, key string, value any) (*mongo.UpdateResult, error)
 	PushUnique(ctx context.Context, docID string, index int64, key string, value any) (*mongo.UpdateResult, error)
 	UpdateMsgContent(ctx context.Context, docID string, index int64, msg []byte) error
+	EditMsg(ctx context.Context, docID string, index int64, content string, prior *model.MsgEditModel) (*mongo.UpdateResult, error)
+	// AddReaction matches nothing when the message has maxReactions other reactions or userID has maxUserReactions on it.
+	AddReaction(ctx context.Context, docID string, index int64, reaction string, userID string, maxReactions int, maxUserReactions int) (*mongo.UpdateResult, error)
+	RemoveReaction(ctx context.Context, docID string, index int64, reaction string, userID string) (*mongo.UpdateResult, error)
+	AddThreadReplies(ctx context.Context, docID string, index int64, lastReply *model.ThreadReplyModel) (*mongo.UpdateResult, error)
+	SetTranslations(ctx context.Context, docID string, index int64, content string, translations map[string]string) (*mongo.UpdateResult, error)
 	IsExistDocID(ctx context.Context, docID string) (bool, error)
 	FindOneByDocID(ctx context.Context, docID string) (*model.MsgDocModel, error)
 	GetMsgBySeqIndexIn1Doc(ctx context.Context, userID, docID string, seqs []int64) ([]*model.MsgInfoModel, error)
 	GetNewestMsg(ctx context.Context, conversationID string) (*model.MsgInfoModel, error)
 	GetOldestMsg(ctx context.Context, conversationID string) (*model.MsgInfoModel, error)
+	// FindSeqByServerMsgID returns the seq of the message of conversationID with serverMsgID.
+	FindSeqByServerMsgID(ctx context.Context, conversationID string, serverMsgID string) (int64, error)
 	DeleteDocs(ctx context.Context, docIDs []string) error
 	GetMsgDocModelByIndex(ctx context.Context, conversationID string, index, sort int64) (*model.MsgDocModel, error)
 	DeleteMsgsInOneDocByIndex(ctx context.Context, docID string, indexes []int) error
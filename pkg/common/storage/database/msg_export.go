@@ -0,0 +1,35 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type MsgExportJob interface {
+	Create(ctx context.Context, job *model.MsgExportJob) error
+	Take(ctx context.Context, jobID string) (*model.MsgExportJob, error)
+	Find(ctx context.Context, pagination pagination.Pagination) (int64, []*model.MsgExportJob, error)
+	// Claim marks up to limit pending jobs as running at now. Running jobs claimed before expire are claimed again.
+	Claim(ctx context.Context, now time.Time, expire time.Time, limit int) ([]*model.MsgExportJob, error)
+	// Progress renews the claim of a running job and records the number of messages written.
+	Progress(ctx context.Context, jobID string, msgCount int64, now time.Time) error
+	// Finish sets the result of a running job.
+	Finish(ctx context.Context, jobID string, data map[string]any) error
+}
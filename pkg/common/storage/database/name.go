@@ -17,4 +17,11 @@ const (
 	UserName                = "user"
 	SeqConversationName     = "seq"
 	SeqUserName             = "seq_user"
+	ScheduledMsgName        = "scheduled_msg"
+	PinnedMsgName           = "pinned_msg"
+	MsgExportJobName        = "msg_export_job"
+	RetentionPolicyName     = "retention_policy"
+	RetentionSkipName       = "retention_skip"
+	ModerationReviewName    = "moderation_review"
+	NotificationSettingName = "notification_setting"
 )
@@ -0,0 +1,31 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+)
+
+type PinnedMsg interface {
+	// Pin appends msg to the pinned messages of the conversation, keeping the latest maxNum.
+	// It reports whether msg was not pinned yet.
+	Pin(ctx context.Context, conversationID string, msg *model.PinnedMsg, maxNum int) (bool, error)
+	// Unpin reports whether the message seq was pinned.
+	Unpin(ctx context.Context, conversationID string, seq int64) (bool, error)
+	// Find returns the pinned messages of the conversation, the latest pinned last.
+	Find(ctx context.Context, conversationID string) ([]*model.PinnedMsg, error)
+}
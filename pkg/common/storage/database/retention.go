@@ -0,0 +1,40 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/retention"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type RetentionPolicy interface {
+	// Set creates or replaces the policy of its target.
+	Set(ctx context.Context, policy *model.RetentionPolicy) error
+	Delete(ctx context.Context, targetType int32, targetID string) error
+	// Find returns the policies of targets.
+	Find(ctx context.Context, targets []retention.Target) ([]*model.RetentionPolicy, error)
+	FindAll(ctx context.Context) ([]*model.RetentionPolicy, error)
+	// FindPage returns the policies of targetType, or all of them when it is 0.
+	FindPage(ctx context.Context, targetType int32, pagination pagination.Pagination) (int64, []*model.RetentionPolicy, error)
+}
+
+type RetentionSkip interface {
+	Create(ctx context.Context, skips []*model.RetentionSkip) error
+	// FindPage returns the latest skips of conversationID, or of every conversation when it is empty.
+	FindPage(ctx context.Context, conversationID string, pagination pagination.Pagination) (int64, []*model.RetentionSkip, error)
+}
@@ -0,0 +1,37 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/tools/db/pagination"
+)
+
+type ScheduledMsg interface {
+	Create(ctx context.Context, msg *model.ScheduledMsg) error
+	Take(ctx context.Context, scheduleID string) (*model.ScheduledMsg, error)
+	FindBySendID(ctx context.Context, sendID string, pagination pagination.Pagination) (int64, []*model.ScheduledMsg, error)
+	// Update updates a scheduled message of sendID which is not claimed, it reports whether one was updated.
+	Update(ctx context.Context, scheduleID string, sendID string, data map[string]any) (bool, error)
+	// Delete deletes a scheduled message of sendID which is not claimed, it reports whether one was deleted.
+	Delete(ctx context.Context, scheduleID string, sendID string) (bool, error)
+	// ClaimDue marks up to limit messages due at now as sending. Messages claimed before expire are claimed again.
+	ClaimDue(ctx context.Context, now time.Time, expire time.Time, limit int) ([]*model.ScheduledMsg, error)
+	// Finish removes a sent message, or marks it as failed with errMsg.
+	Finish(ctx context.Context, scheduleID string, errMsg string) error
+}
@@ -0,0 +1,27 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// Device is a device of a user registered for offline push, a user has at most one device per platform.
+type Device struct {
+	UserID     string `json:"userID"`
+	PlatformID int    `json:"platformID"`
+	// Provider is the offline push provider the Token belongs to, empty for the provider configured as default.
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+	// ExpireTime is the unix second the device expires at, 0 for never.
+	ExpireTime int64 `json:"expireTime"`
+	UpdateTime int64 `json:"updateTime"`
+}
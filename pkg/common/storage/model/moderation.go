@@ -0,0 +1,43 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// ModerationReview is a message flagged by a moderation rule, waiting for an admin to approve or remove it.
+type ModerationReview struct {
+	ReviewID       string          `bson:"review_id"`
+	ConversationID string          `bson:"conversation_id"`
+	ClientMsgID    string          `bson:"client_msg_id"`
+	ServerMsgID    string          `bson:"server_msg_id"`
+	SendID         string          `bson:"send_id"`
+	SessionType    int32           `bson:"session_type"`
+	ContentType    int32           `bson:"content_type"`
+	Content        string          `bson:"content"`
+	Hits           []ModerationHit `bson:"hits"`
+	// Status is one of the review statuses of the msgext package.
+	Status         int32     `bson:"status"`
+	ReviewerUserID string    `bson:"reviewer_user_id"`
+	ReviewTime     time.Time `bson:"review_time"`
+	CreateTime     time.Time `bson:"create_time"`
+}
+
+type ModerationHit struct {
+	Rule   string `bson:"rule"`
+	Action string `bson:"action"`
+	Reason string `bson:"reason"`
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/tools/errs"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -42,6 +43,32 @@ type RevokeModel struct {
 	Time     int64  `bson:"time"`
 }
 
+// MsgEditModel is a prior content of an edited message, EditorUserID and EditTime record the edit that replaced it.
+type MsgEditModel struct {
+	Content      string `bson:"content"`
+	EditorUserID string `bson:"editor_user_id"`
+	EditTime     int64  `bson:"edit_time"`
+}
+
+// ThreadModel is the summary of the thread anchored on a message.
+type ThreadModel struct {
+	ReplyCount int64             `bson:"reply_count"`
+	LastReply  *ThreadReplyModel `bson:"last_reply"`
+}
+
+type ThreadReplyModel struct {
+	Seq         int64  `bson:"seq"`
+	SendID      string `bson:"send_id"`
+	ClientMsgID string `bson:"client_msg_id"`
+	SendTime    int64  `bson:"send_time"`
+}
+
+// GroupReadReceipt is the cached split of the group members by whether they have read a message.
+type GroupReadReceipt struct {
+	ReadUserIDs   []string `json:"readUserIDs"`
+	UnreadUserIDs []string `json:"unreadUserIDs"`
+}
+
 type OfflinePushModel struct {
 	Title         string `bson:"title"`
 	Desc          string `bson:"desc"`
@@ -76,10 +103,16 @@ type MsgDataModel struct {
 }
 
 type MsgInfoModel struct {
-	Msg     *MsgDataModel `bson:"msg"`
-	Revoke  *RevokeModel  `bson:"revoke"`
-	DelList []string      `bson:"del_list"`
-	IsRead  bool          `bson:"is_read"`
+	Msg         *MsgDataModel   `bson:"msg"`
+	Revoke      *RevokeModel    `bson:"revoke"`
+	DelList     []string        `bson:"del_list"`
+	IsRead      bool            `bson:"is_read"`
+	EditHistory []*MsgEditModel `bson:"edit_history,omitempty"`
+	// Reactions maps a reaction to the users who reacted with it.
+	Reactions map[string][]string `bson:"reactions,omitempty"`
+	Thread    *ThreadModel        `bson:"thread,omitempty"`
+	// Translations maps a language to the translated text of the message.
+	Translations map[string]string `bson:"translations,omitempty"`
 }
 
 type UserCount struct {
@@ -127,6 +160,11 @@ func (m *MsgDocModel) GetDocIDSeqsMap(conversationID string, seqs []int64) map[s
 	return t
 }
 
+// GetConversationID returns the conversation the doc holds the messages of.
+func (m *MsgDocModel) GetConversationID() string {
+	return m.DocID[:strings.LastIndex(m.DocID, ":")]
+}
+
 func (*MsgDocModel) GetMsgIndex(seq int64) int64 {
 	return (seq - 1) % singleGocMsgNum
 }
@@ -0,0 +1,38 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// MsgExportJob is an export of the messages of conversations to an archive in the object storage.
+type MsgExportJob struct {
+	JobID           string   `bson:"job_id"`
+	CreatorUserID   string   `bson:"creator_user_id"`
+	ConversationIDs []string `bson:"conversation_ids"`
+	Format          string   `bson:"format"`
+	StartTime       int64    `bson:"start_time"`
+	EndTime         int64    `bson:"end_time"`
+	Status          int32    `bson:"status"`
+	MsgCount        int64    `bson:"msg_count"`
+	ObjectName      string   `bson:"object_name"`
+	Size            int64    `bson:"size"`
+	ErrMsg          string   `bson:"err_msg"`
+	// ClaimTime is renewed while the job is written, a running job whose claim time is too old is claimed again.
+	ClaimTime  time.Time `bson:"claim_time"`
+	CreateTime time.Time `bson:"create_time"`
+	FinishTime time.Time `bson:"finish_time"`
+}
@@ -0,0 +1,43 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// NotificationSetting changes when and how the offline pushes notify a user.
+type NotificationSetting struct {
+	UserID string `bson:"user_id"`
+	// TimeZone is the IANA name of the time zone of the DNDWindows, UTC when empty.
+	TimeZone   string      `bson:"time_zone"`
+	DNDWindows []DNDWindow `bson:"dnd_windows"`
+	// Keywords notify the messages containing one of them even when the user would not be notified otherwise.
+	Keywords []string `bson:"keywords"`
+	// GroupMentionOnly only notifies the group messages which mention the user or everyone.
+	GroupMentionOnly bool `bson:"group_mention_only"`
+	// HidePreviewPlatformIDs are the platforms whose notifications do not show the content of the messages.
+	HidePreviewPlatformIDs []int32   `bson:"hide_preview_platform_ids"`
+	UpdateTime             time.Time `bson:"update_time"`
+}
+
+// DNDWindow is a time of the day during which the user is not notified.
+type DNDWindow struct {
+	// Start and End are "15:04" times of the day, a window which does not end after it starts ends the next day.
+	Start string `bson:"start"`
+	End   string `bson:"end"`
+	// Weekdays are the days the window starts on, 0 for Sunday, every day when empty.
+	Weekdays []int32 `bson:"weekdays"`
+}
@@ -0,0 +1,31 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// ConversationPinnedMsgs holds the messages pinned in a conversation, the latest pinned last.
+type ConversationPinnedMsgs struct {
+	ConversationID string       `bson:"conversation_id"`
+	Pinned         []*PinnedMsg `bson:"pinned"`
+}
+
+type PinnedMsg struct {
+	Seq       int64     `bson:"seq"`
+	PinUserID string    `bson:"pin_user_id"`
+	PinTime   time.Time `bson:"pin_time"`
+}
@@ -0,0 +1,37 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// PushRetry is an offline push which failed, it is pushed again until it succeeds or is dead-lettered.
+type PushRetry struct {
+	RetryID string `json:"retryID"`
+	// Provider is the provider which failed when the push went through several, empty for the whole push.
+	Provider string   `json:"provider"`
+	UserIDs  []string `json:"userIDs"`
+	// PlatformIDs are the platforms the push was limited to, empty for every platform.
+	PlatformIDs   []int  `json:"platformIDs,omitempty"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	Ex            string `json:"ex"`
+	IOSPushSound  string `json:"iosPushSound"`
+	IOSBadgeCount bool   `json:"iosBadgeCount"`
+	ClientMsgID   string `json:"clientMsgID"`
+	// Attempts is the number of times the push failed.
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError"`
+	// CreateTime and UpdateTime are the unix milliseconds of the first and the last attempt.
+	CreateTime int64 `json:"createTime"`
+	UpdateTime int64 `json:"updateTime"`
+}
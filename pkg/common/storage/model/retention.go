@@ -0,0 +1,42 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// RetentionPolicy changes how long the messages of a conversation, of the conversation of a group or of the
+// conversations of a user are kept, and can put them under a legal hold which blocks every deletion.
+type RetentionPolicy struct {
+	// TargetType is one of the target types of the retention package.
+	TargetType int32  `bson:"target_type"`
+	TargetID   string `bson:"target_id"`
+	// RetainDays is the number of days the messages are kept, 0 keeps the global retainChatRecords.
+	RetainDays     int64     `bson:"retain_days"`
+	LegalHold      bool      `bson:"legal_hold"`
+	HoldReason     string    `bson:"hold_reason"`
+	OperatorUserID string    `bson:"operator_user_id"`
+	UpdateTime     time.Time `bson:"update_time"`
+}
+
+// RetentionSkip records a deletion of the messages of a conversation which a retention policy blocked.
+type RetentionSkip struct {
+	ConversationID string    `bson:"conversation_id"`
+	Operation      string    `bson:"operation"`
+	Reason         string    `bson:"reason"`
+	OperatorUserID string    `bson:"operator_user_id"`
+	CreateTime     time.Time `bson:"create_time"`
+}
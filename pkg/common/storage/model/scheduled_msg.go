@@ -0,0 +1,32 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// ScheduledMsg is a message waiting to be sent at SendTime.
+type ScheduledMsg struct {
+	ScheduleID string `bson:"schedule_id"`
+	SendID     string `bson:"send_id"`
+	// MsgReq is the marshaled msg.SendMsgReq passed to SendMsg at SendTime.
+	MsgReq     []byte    `bson:"msg_req"`
+	SendTime   time.Time `bson:"send_time"`
+	Status     int32     `bson:"status"`
+	ErrMsg     string    `bson:"err_msg"`
+	ClaimTime  time.Time `bson:"claim_time"`
+	CreateTime time.Time `bson:"create_time"`
+}
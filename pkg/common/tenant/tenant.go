@@ -0,0 +1,174 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tenant isolates the apps hosted by one deployment.
+// The tenant of a request is kept in its context as a rpc custom header, so it follows the request through every rpc call.
+// An empty tenant is the default app, whose data is stored exactly as in a single tenant deployment.
+package tenant
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/protocol/constant"
+)
+
+// Key is the context key and rpc metadata key of the tenant ID.
+const Key = "tenantID"
+
+// mqKeySep separates the tenant ID from the original key of a kafka message.
+const mqKeySep = "\x1f"
+
+var tenants atomic.Pointer[registry]
+
+type registry struct {
+	byID     map[string]*config.Tenant
+	bySecret map[string]*config.Tenant
+}
+
+// Init loads the tenants of conf, it is called once at startup by every service.
+func Init(conf *config.Share) {
+	r := &registry{
+		byID:     make(map[string]*config.Tenant),
+		bySecret: make(map[string]*config.Tenant),
+	}
+	if conf.MultiTenant.Enable {
+		for i := range conf.MultiTenant.Tenants {
+			t := &conf.MultiTenant.Tenants[i]
+			r.byID[t.ID] = t
+			r.bySecret[t.Secret] = t
+		}
+	}
+	tenants.Store(r)
+}
+
+// Enabled reports whether any tenant is configured.
+func Enabled() bool {
+	r := tenants.Load()
+	return r != nil && len(r.byID) > 0
+}
+
+// Get returns the configuration of the tenant with id.
+func Get(id string) (*config.Tenant, bool) {
+	r := tenants.Load()
+	if r == nil {
+		return nil, false
+	}
+	t, ok := r.byID[id]
+	return t, ok
+}
+
+// GetBySecret returns the tenant whose app server uses secret.
+func GetBySecret(secret string) (*config.Tenant, bool) {
+	r := tenants.Load()
+	if r == nil {
+		return nil, false
+	}
+	t, ok := r.bySecret[secret]
+	return t, ok
+}
+
+// Current returns the configuration of the tenant of ctx, nil for the default app.
+func Current(ctx context.Context) *config.Tenant {
+	id := GetTenantID(ctx)
+	if id == "" {
+		return nil
+	}
+	t, _ := Get(id)
+	return t
+}
+
+// WithTenantID sets the tenant of ctx and registers it as a rpc custom header.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	keys, _ := ctx.Value(constant.RpcCustomHeader).([]string)
+	found := false
+	for _, key := range keys {
+		if key == Key {
+			found = true
+			break
+		}
+	}
+	if !found {
+		keys = append(keys[:len(keys):len(keys)], Key)
+		ctx = context.WithValue(ctx, constant.RpcCustomHeader, keys)
+	}
+	return context.WithValue(ctx, Key, []string{tenantID})
+}
+
+// GetTenantID returns the tenant of ctx, empty for the default app.
+func GetTenantID(ctx context.Context) string {
+	if val, ok := ctx.Value(Key).([]string); ok && len(val) > 0 {
+		return val[0]
+	}
+	return ""
+}
+
+// Prefix returns key scoped to the tenant of ctx, it is used for cache keys.
+func Prefix(ctx context.Context, key string) string {
+	return PrefixTenant(GetTenantID(ctx), key)
+}
+
+// PrefixTenant returns key scoped to tenantID.
+func PrefixTenant(tenantID string, key string) string {
+	if tenantID == "" {
+		return key
+	}
+	return tenantID + ":" + key
+}
+
+// PrefixKeys returns keys scoped to the tenant of ctx, keys itself is left unchanged.
+func PrefixKeys(ctx context.Context, keys []string) []string {
+	tenantID := GetTenantID(ctx)
+	if tenantID == "" {
+		return keys
+	}
+	res := make([]string, len(keys))
+	for i, key := range keys {
+		res[i] = PrefixTenant(tenantID, key)
+	}
+	return res
+}
+
+// CollName returns the name of the mongo collection holding the data of the tenant of ctx.
+func CollName(ctx context.Context, name string) string {
+	tenantID := GetTenantID(ctx)
+	if tenantID == "" {
+		return name
+	}
+	return tenantID + "_" + name
+}
+
+// MQKey carries the tenant of ctx in the key of a kafka message, the consumer restores it with FromMQKey.
+// Messages with the same key keep going to the same partition.
+func MQKey(ctx context.Context, key string) string {
+	tenantID := GetTenantID(ctx)
+	if tenantID == "" {
+		return key
+	}
+	return tenantID + mqKeySep + key
+}
+
+// FromMQKey restores the tenant carried by a kafka message key and returns the original key.
+func FromMQKey(ctx context.Context, key string) (context.Context, string) {
+	tenantID, rest, ok := strings.Cut(key, mqKeySep)
+	if !ok {
+		return ctx, key
+	}
+	return WithTenantID(ctx, tenantID), rest
+}
@@ -0,0 +1,83 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTenantID(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constant.RpcCustomHeader, []string{"other"})
+	assert.Equal(t, "", GetTenantID(ctx))
+	assert.Equal(t, ctx, WithTenantID(ctx, ""))
+
+	ctx = WithTenantID(ctx, "app1")
+	assert.Equal(t, "app1", GetTenantID(ctx))
+	assert.Equal(t, []string{"other", Key}, ctx.Value(constant.RpcCustomHeader))
+
+	ctx = WithTenantID(ctx, "app2")
+	assert.Equal(t, "app2", GetTenantID(ctx))
+	assert.Equal(t, []string{"other", Key}, ctx.Value(constant.RpcCustomHeader))
+
+	assert.Equal(t, "app2:token", Prefix(ctx, "token"))
+	assert.Equal(t, "app2_msg", CollName(ctx, "msg"))
+	assert.Equal(t, "token", Prefix(context.Background(), "token"))
+	assert.Equal(t, "msg", CollName(context.Background(), "msg"))
+}
+
+func TestPrefixKeys(t *testing.T) {
+	keys := []string{"k1", "k2"}
+	assert.Equal(t, keys, PrefixKeys(context.Background(), keys))
+	ctx := WithTenantID(context.Background(), "app1")
+	assert.Equal(t, []string{"app1:k1", "app1:k2"}, PrefixKeys(ctx, keys))
+	assert.Equal(t, []string{"k1", "k2"}, keys)
+}
+
+func TestMQKey(t *testing.T) {
+	ctx := WithTenantID(context.Background(), "app1")
+	key := MQKey(ctx, "si_u1_u2")
+	assert.NotEqual(t, "si_u1_u2", key)
+
+	restored, rest := FromMQKey(context.Background(), key)
+	assert.Equal(t, "si_u1_u2", rest)
+	assert.Equal(t, "app1", GetTenantID(restored))
+
+	restored, rest = FromMQKey(context.Background(), MQKey(context.Background(), "si_u1_u2"))
+	assert.Equal(t, "si_u1_u2", rest)
+	assert.Equal(t, "", GetTenantID(restored))
+}
+
+func TestRegistry(t *testing.T) {
+	var conf config.Share
+	conf.MultiTenant.Tenants = []config.Tenant{{ID: "app1", Secret: "s1"}}
+	Init(&conf)
+	assert.False(t, Enabled())
+
+	conf.MultiTenant.Enable = true
+	Init(&conf)
+	assert.True(t, Enabled())
+	app, ok := GetBySecret("s1")
+	assert.True(t, ok)
+	assert.Equal(t, "app1", app.ID)
+	_, ok = Get("app2")
+	assert.False(t, ok)
+	assert.Equal(t, app, Current(WithTenantID(context.Background(), "app1")))
+	assert.Nil(t, Current(context.Background()))
+}
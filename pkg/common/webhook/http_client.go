@@ -20,6 +20,7 @@ import (
 	"github.com/openimsdk/open-im-server/v3/pkg/callbackstruct"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/tools/log"
 	"github.com/openimsdk/tools/mcontext"
@@ -67,11 +68,21 @@ func (c *Client) AsyncPost(ctx context.Context, command string, req callbackstru
 }
 
 func (c *Client) post(ctx context.Context, command string, input interface{}, output callbackstruct.CallbackResp, timeout int) error {
+	url := c.url
+	header := make(map[string]string)
+	if t := tenant.Current(ctx); t != nil {
+		// The tenant's own app server gets its webhooks, a shared one tells the tenants apart by the header.
+		if t.WebhookURL != "" {
+			url = t.WebhookURL
+		}
+		header[tenant.Key] = t.ID
+	}
 	ctx = mcontext.WithMustInfoCtx([]string{mcontext.GetOperationID(ctx), mcontext.GetOpUserID(ctx), mcontext.GetOpUserPlatform(ctx), mcontext.GetConnID(ctx)})
-	fullURL := c.url + "/" + command
+	fullURL := url + "/" + command
 	log.ZInfo(ctx, "webhook", "url", fullURL, "input", input, "config", timeout)
 	operationID, _ := ctx.Value(constant.OperationID).(string)
-	b, err := c.client.Post(ctx, fullURL, map[string]string{constant.OperationID: operationID}, input, timeout)
+	header[constant.OperationID] = operationID
+	b, err := c.client.Post(ctx, fullURL, header, input, timeout)
 	if err != nil {
 		return servererrs.ErrNetwork.WrapMsg(err.Error(), "post url", fullURL)
 	}
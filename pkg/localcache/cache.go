@@ -19,6 +19,7 @@ import (
 	"hash/fnv"
 	"unsafe"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/common/tenant"
 	"github.com/openimsdk/open-im-server/v3/pkg/localcache/link"
 	"github.com/openimsdk/open-im-server/v3/pkg/localcache/lru"
 )
@@ -101,6 +102,8 @@ func (c *cache[V]) Get(ctx context.Context, key string, fetch func(ctx context.C
 }
 
 func (c *cache[V]) GetLink(ctx context.Context, key string, fetch func(ctx context.Context) (V, error), link ...string) (V, error) {
+	key = tenant.Prefix(ctx, key)
+	link = tenant.PrefixKeys(ctx, link)
 	if c.local != nil {
 		return c.local.Get(key, func() (V, error) {
 			if len(link) > 0 {
@@ -114,6 +117,7 @@ func (c *cache[V]) GetLink(ctx context.Context, key string, fetch func(ctx conte
 }
 
 func (c *cache[V]) Del(ctx context.Context, key ...string) {
+	key = tenant.PrefixKeys(ctx, key)
 	for _, fn := range c.opt.delFn {
 		fn(ctx, key...)
 	}
@@ -121,7 +125,7 @@ func (c *cache[V]) Del(ctx context.Context, key ...string) {
 }
 
 func (c *cache[V]) DelLocal(ctx context.Context, key ...string) {
-	c.del(key...)
+	c.del(tenant.PrefixKeys(ctx, key)...)
 }
 
 func (c *cache[V]) Stop() {
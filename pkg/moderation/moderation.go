@@ -0,0 +1,262 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package moderation checks the text of messages against pluggable rules before they are sent.
+// A rule lets a message pass, flags it for review, masks the text it matched or blocks the message,
+// a Pipeline runs the rules and keeps the most severe action.
+package moderation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+)
+
+// Action is what a rule does with a message, the greater the more severe.
+type Action int32
+
+const (
+	ActionPass Action = iota
+	ActionFlag
+	ActionMask
+	ActionBlock
+)
+
+// ParseAction returns the action named s in the configuration.
+func ParseAction(s string) (Action, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "flag":
+		return ActionFlag, nil
+	case "mask":
+		return ActionMask, nil
+	case "block":
+		return ActionBlock, nil
+	default:
+		return ActionPass, errs.ErrArgs.WrapMsg("unknown moderation action", "action", s)
+	}
+}
+
+func (a Action) String() string {
+	switch a {
+	case ActionFlag:
+		return "flag"
+	case ActionMask:
+		return "mask"
+	case ActionBlock:
+		return "block"
+	default:
+		return "pass"
+	}
+}
+
+// Msg is the part of a message the rules check.
+type Msg struct {
+	// SenderKey identifies the sender across the tenants.
+	SenderKey   string
+	ContentType int32
+	// Text is empty for the messages without text, they are only checked by the rules which do not need it.
+	Text string
+}
+
+// Span is the byte range of the text matched by a rule.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Hit is a rule matched by a message.
+type Hit struct {
+	Rule   string
+	Action Action
+	Reason string
+	// Spans are masked when Action is ActionMask.
+	Spans []Span
+}
+
+// Rule is a check of messages.
+type Rule interface {
+	Name() string
+	// Check returns the hit of msg, nil when the rule lets it pass.
+	Check(ctx context.Context, msg *Msg) *Hit
+}
+
+// Reloader is a Rule whose configuration is loaded from files, Reload reports whether it changed.
+type Reloader interface {
+	Reload() (bool, error)
+}
+
+// Result is the outcome of the checks of a message.
+type Result struct {
+	// Action is the most severe action of the hits.
+	Action Action
+	// Text is the text of the message with the spans of the mask hits masked.
+	Text string
+	Hits []*Hit
+}
+
+// Flagged reports whether a hit flagged the message for review.
+func (r *Result) Flagged() bool {
+	for _, hit := range r.Hits {
+		if hit.Action == ActionFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// Masked reports whether the text of the message was masked.
+func (r *Result) Masked() bool {
+	for _, hit := range r.Hits {
+		if hit.Action == ActionMask {
+			return true
+		}
+	}
+	return false
+}
+
+// Reasons returns the reasons of the hits with action, all of them when action is ActionPass.
+func (r *Result) Reasons(action Action) []string {
+	var reasons []string
+	for _, hit := range r.Hits {
+		if action == ActionPass || hit.Action == action {
+			reasons = append(reasons, hit.Rule+": "+hit.Reason)
+		}
+	}
+	return reasons
+}
+
+type Pipeline struct {
+	rules []Rule
+}
+
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// New returns the pipeline of the rules configured by conf, nil when the moderation is not enabled.
+func New(conf *config.Moderation) (*Pipeline, error) {
+	if !conf.Enable {
+		return nil, nil
+	}
+	var rules []Rule
+	if conf.Spam.Window > 0 && (conf.Spam.MaxMsgs > 0 || conf.Spam.MaxRepeats > 0) {
+		action, err := ParseAction(conf.Spam.Action)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, NewSpamRule("spam", action, time.Duration(conf.Spam.Window)*time.Second, conf.Spam.MaxMsgs, conf.Spam.MaxRepeats))
+	}
+	for _, words := range conf.Words {
+		if len(words.Files) == 0 {
+			continue
+		}
+		action, err := ParseAction(words.Action)
+		if err != nil {
+			return nil, err
+		}
+		rule, err := NewWordRule(words.Name, action, words.Files)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	for _, regexp := range conf.Regexps {
+		action, err := ParseAction(regexp.Action)
+		if err != nil {
+			return nil, err
+		}
+		rule, err := NewRegexRule(regexp.Name, regexp.Pattern, action)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return NewPipeline(rules...), nil
+}
+
+// Check runs every rule on msg.
+func (p *Pipeline) Check(ctx context.Context, msg *Msg) *Result {
+	res := &Result{Text: msg.Text}
+	var spans []Span
+	for _, rule := range p.rules {
+		hit := rule.Check(ctx, msg)
+		if hit == nil {
+			continue
+		}
+		res.Hits = append(res.Hits, hit)
+		if hit.Action > res.Action {
+			res.Action = hit.Action
+		}
+		if hit.Action == ActionMask {
+			spans = append(spans, hit.Spans...)
+		}
+	}
+	if len(spans) > 0 {
+		res.Text = Mask(msg.Text, spans)
+	}
+	return res
+}
+
+// Watch reloads the rules loaded from files every interval until ctx is done.
+func (p *Pipeline) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, rule := range p.rules {
+			reloader, ok := rule.(Reloader)
+			if !ok {
+				continue
+			}
+			changed, err := reloader.Reload()
+			if err != nil {
+				log.ZWarn(ctx, "reload moderation rule failed", err, "rule", rule.Name())
+			} else if changed {
+				log.ZInfo(ctx, "moderation rule reloaded", "rule", rule.Name())
+			}
+		}
+	}
+}
+
+// Mask replaces every character of text within spans by '*'.
+func Mask(text string, spans []Span) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, r := range text {
+		masked := false
+		for _, span := range spans {
+			if i >= span.Start && i < span.End {
+				masked = true
+				break
+			}
+		}
+		if masked {
+			b.WriteByte('*')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrieFind(t *testing.T) {
+	trie := NewTrie([]string{"bad", "badword", "坏蛋", ""})
+	assert.Equal(t, 3, trie.Len())
+	text := "a BadWord, bad 坏蛋!"
+	var words []string
+	for _, span := range trie.Find(text) {
+		words = append(words, text[span.Start:span.End])
+	}
+	assert.Equal(t, []string{"BadWord", "bad", "坏蛋"}, words)
+	assert.Empty(t, trie.Find("ba d"))
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "a ***, ** b", Mask("a bad, 坏蛋 b", []Span{{Start: 2, End: 5}, {Start: 7, End: 13}}))
+}
+
+func TestPipeline(t *testing.T) {
+	regex, err := NewRegexRule("phone", `\d{11}`, ActionFlag)
+	assert.NoError(t, err)
+	p := NewPipeline(NewWordRuleFromWords("words", ActionMask, []string{"bad"}), regex)
+	res := p.Check(context.Background(), &Msg{SenderKey: "u1", Text: "bad, call 13800000000"})
+	assert.Equal(t, ActionMask, res.Action)
+	assert.Equal(t, "***, call 13800000000", res.Text)
+	assert.True(t, res.Flagged())
+	assert.True(t, res.Masked())
+	assert.Len(t, res.Reasons(ActionFlag), 1)
+
+	p = NewPipeline(NewWordRuleFromWords("words", ActionBlock, []string{"bad"}))
+	assert.Equal(t, ActionBlock, p.Check(context.Background(), &Msg{Text: "so BAD"}).Action)
+	assert.Equal(t, ActionPass, p.Check(context.Background(), &Msg{Text: "fine"}).Action)
+}
+
+func TestSpamRule(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := NewSpamRule("spam", ActionBlock, 10*time.Second, 3, 2)
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+	assert.Nil(t, r.Check(ctx, &Msg{SenderKey: "u1", Text: "hi"}))
+	assert.Nil(t, r.Check(ctx, &Msg{SenderKey: "u1", Text: "hi"}))
+	assert.NotNil(t, r.Check(ctx, &Msg{SenderKey: "u1", Text: "hi"}))
+	assert.Nil(t, r.Check(ctx, &Msg{SenderKey: "u2", Text: "hi"}))
+	assert.NotNil(t, r.Check(ctx, &Msg{SenderKey: "u1", Text: "other"}))
+	now = now.Add(11 * time.Second)
+	assert.Nil(t, r.Check(ctx, &Msg{SenderKey: "u1", Text: "hi"}))
+	assert.Len(t, r.senders, 1)
+}
+
+func TestWordRuleReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.txt")
+	assert.NoError(t, os.WriteFile(file, []byte("# comment\nbad\n"), 0o644))
+	r, err := NewWordRule("words", ActionMask, []string{file})
+	assert.NoError(t, err)
+	assert.NotNil(t, r.Check(context.Background(), &Msg{Text: "bad"}))
+	changed, err := r.Reload()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	assert.NoError(t, os.WriteFile(file, []byte("worse\nevil\n"), 0o644))
+	assert.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	changed, err = r.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, r.Check(context.Background(), &Msg{Text: "bad"}))
+	assert.NotNil(t, r.Check(context.Background(), &Msg{Text: "evil"}))
+}
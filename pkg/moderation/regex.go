@@ -0,0 +1,58 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+// RegexRule matches a regular expression.
+type RegexRule struct {
+	name   string
+	action Action
+	re     *regexp.Regexp
+}
+
+func NewRegexRule(name string, pattern string, action Action) (*RegexRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errs.ErrArgs.WrapMsg("invalid moderation pattern", "name", name, "pattern", pattern, "err", err.Error())
+	}
+	return &RegexRule{name: name, action: action, re: re}, nil
+}
+
+func (r *RegexRule) Name() string {
+	return r.name
+}
+
+func (r *RegexRule) Check(_ context.Context, msg *Msg) *Hit {
+	if msg.Text == "" {
+		return nil
+	}
+	matches := r.re.FindAllStringIndex(msg.Text, -1)
+	spans := make([]Span, 0, len(matches))
+	for _, match := range matches {
+		if match[1] > match[0] {
+			spans = append(spans, Span{Start: match[0], End: match[1]})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	return &Hit{Rule: r.name, Action: r.action, Reason: "matched pattern " + r.re.String(), Spans: spans}
+}
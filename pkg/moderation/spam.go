@@ -0,0 +1,121 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SpamRule matches the senders sending more than maxMsgs messages, or the same text more than maxRepeats times, within window.
+// The counts are kept in memory, so they are per process.
+type SpamRule struct {
+	name       string
+	action     Action
+	window     time.Duration
+	maxMsgs    int
+	maxRepeats int
+	now        func() time.Time
+
+	mu        sync.Mutex
+	senders   map[string]*senderWindow
+	lastSweep time.Time
+}
+
+type senderWindow struct {
+	times  []time.Time
+	hashes []uint64
+}
+
+func NewSpamRule(name string, action Action, window time.Duration, maxMsgs int, maxRepeats int) *SpamRule {
+	return &SpamRule{
+		name:       name,
+		action:     action,
+		window:     window,
+		maxMsgs:    maxMsgs,
+		maxRepeats: maxRepeats,
+		now:        time.Now,
+		senders:    make(map[string]*senderWindow),
+	}
+}
+
+func (r *SpamRule) Name() string {
+	return r.name
+}
+
+func (r *SpamRule) Check(_ context.Context, msg *Msg) *Hit {
+	now := r.now()
+	since := now.Add(-r.window)
+	var hash uint64
+	if msg.Text != "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(msg.Text))
+		hash = h.Sum64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(now, since)
+	w, ok := r.senders[msg.SenderKey]
+	if !ok {
+		w = &senderWindow{}
+		r.senders[msg.SenderKey] = w
+	}
+	w.expire(since)
+	w.times = append(w.times, now)
+	w.hashes = append(w.hashes, hash)
+	if r.maxMsgs > 0 && len(w.times) > r.maxMsgs {
+		return &Hit{Rule: r.name, Action: r.action, Reason: "sent " + strconv.Itoa(len(w.times)) + " messages in " + r.window.String()}
+	}
+	if r.maxRepeats > 0 && hash != 0 {
+		var repeats int
+		for _, h := range w.hashes {
+			if h == hash {
+				repeats++
+			}
+		}
+		if repeats > r.maxRepeats {
+			return &Hit{Rule: r.name, Action: r.action, Reason: "repeated the same text " + strconv.Itoa(repeats) + " times in " + r.window.String()}
+		}
+	}
+	return nil
+}
+
+// sweep drops the senders without messages within the window, at most once a window.
+func (r *SpamRule) sweep(now time.Time, since time.Time) {
+	if now.Sub(r.lastSweep) < r.window {
+		return
+	}
+	r.lastSweep = now
+	for key, w := range r.senders {
+		w.expire(since)
+		if len(w.times) == 0 {
+			delete(r.senders, key)
+		}
+	}
+}
+
+func (w *senderWindow) expire(since time.Time) {
+	var i int
+	for i < len(w.times) && !w.times[i].After(since) {
+		i++
+	}
+	if i > 0 {
+		w.times = append(w.times[:0], w.times[i:]...)
+		w.hashes = append(w.hashes[:0], w.hashes[i:]...)
+	}
+}
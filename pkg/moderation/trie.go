@@ -0,0 +1,96 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Trie matches the words of a word list in a text, ignoring the case.
+type Trie struct {
+	root *trieNode
+	size int
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	end      bool
+}
+
+func NewTrie(words []string) *Trie {
+	t := &Trie{root: &trieNode{}}
+	for _, word := range words {
+		t.insert(word)
+	}
+	return t
+}
+
+func (t *Trie) insert(word string) {
+	if word == "" {
+		return
+	}
+	node := t.root
+	for _, r := range word {
+		r = unicode.ToLower(r)
+		child, ok := node.children[r]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	if !node.end {
+		node.end = true
+		t.size++
+	}
+}
+
+// Len returns the number of words.
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Find returns the spans of the words found in text from left to right,
+// the longest word is taken where several start at the same place and the spans do not overlap.
+func (t *Trie) Find(text string) []Span {
+	var spans []Span
+	for start := 0; start < len(text); {
+		end := -1
+		node := t.root
+		for i := start; i < len(text); {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			node = node.children[unicode.ToLower(r)]
+			if node == nil {
+				break
+			}
+			i += size
+			if node.end {
+				end = i
+			}
+		}
+		if end > 0 {
+			spans = append(spans, Span{Start: start, End: end})
+			start = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		start += size
+	}
+	return spans
+}
@@ -0,0 +1,137 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+// WordRule matches the words of word list files, one word per line, the lines starting with # are comments.
+// The files are loaded again by Reload when they changed.
+type WordRule struct {
+	name   string
+	action Action
+	files  []string
+
+	mu    sync.Mutex
+	stats map[string]fileStat
+	trie  atomic.Pointer[Trie]
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func NewWordRule(name string, action Action, files []string) (*WordRule, error) {
+	if len(files) == 0 {
+		return nil, errs.ErrArgs.WrapMsg("word rule without files", "name", name)
+	}
+	r := &WordRule{name: name, action: action, files: files}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewWordRuleFromWords returns the rule matching words, it is not reloaded.
+func NewWordRuleFromWords(name string, action Action, words []string) *WordRule {
+	r := &WordRule{name: name, action: action}
+	r.trie.Store(NewTrie(words))
+	return r
+}
+
+func (r *WordRule) Name() string {
+	return r.name
+}
+
+func (r *WordRule) Check(_ context.Context, msg *Msg) *Hit {
+	if msg.Text == "" {
+		return nil
+	}
+	spans := r.trie.Load().Find(msg.Text)
+	if len(spans) == 0 {
+		return nil
+	}
+	words := make([]string, 0, len(spans))
+	for _, span := range spans {
+		words = append(words, msg.Text[span.Start:span.End])
+	}
+	return &Hit{Rule: r.name, Action: r.action, Reason: "matched words " + strings.Join(words, ","), Spans: spans}
+}
+
+func (r *WordRule) Reload() (bool, error) {
+	if len(r.files) == 0 {
+		return false, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make(map[string]fileStat, len(r.files))
+	changed := r.stats == nil
+	for _, file := range r.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, errs.WrapMsg(err, "stat word file failed", "file", file)
+		}
+		stat := fileStat{modTime: info.ModTime(), size: info.Size()}
+		if old, ok := r.stats[file]; !ok || old != stat {
+			changed = true
+		}
+		stats[file] = stat
+	}
+	if !changed {
+		return false, nil
+	}
+	var words []string
+	for _, file := range r.files {
+		fileWords, err := readWords(file)
+		if err != nil {
+			return false, err
+		}
+		words = append(words, fileWords...)
+	}
+	r.trie.Store(NewTrie(words))
+	r.stats = stats
+	return true, nil
+}
+
+func readWords(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "open word file failed", "file", file)
+	}
+	defer f.Close()
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errs.WrapMsg(err, "read word file failed", "file", file)
+	}
+	return words, nil
+}
@@ -0,0 +1,98 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgexport
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+var htmlTemplate = template.Must(template.New("").Funcs(template.FuncMap{"time": formatTime}).Parse(`
+{{- define "head" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body{font-family:sans-serif;margin:2em}
+.msg{margin:.6em 0;padding:.4em .6em;border-left:3px solid #ccc}
+.meta{color:#666;font-size:.85em}
+.revoked{color:#999;font-style:italic}
+pre{white-space:pre-wrap;margin:.3em 0}
+</style>
+</head>
+<body>
+<h1>{{.}}</h1>
+{{end -}}
+{{define "conversation"}}<h2>{{.}}</h2>
+{{end -}}
+{{define "msg"}}<div class="msg" id="{{.ConversationID}}-{{.Seq}}">
+<div class="meta">#{{.Seq}} {{time .SendTime}} <b>{{if .SenderNickname}}{{.SenderNickname}}{{else}}{{.SendID}}{{end}}</b> ({{.SendID}}) type {{.ContentType}}</div>
+{{- if .Revoked}}
+<div class="revoked">revoked</div>
+{{- end}}
+{{- if .Text}}
+<pre>{{.Text}}</pre>
+{{- else}}
+<pre>{{.Content}}</pre>
+{{- end}}
+{{- range .Objects}}
+<div class="meta">object: {{.}}</div>
+{{- end}}
+</div>
+{{end -}}
+{{define "tail"}}</body>
+</html>
+{{end}}`))
+
+func formatTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+type htmlWriter struct {
+	w              io.Writer
+	conversationID string
+}
+
+func newHTMLWriter(w io.Writer, title string) (*htmlWriter, error) {
+	if err := htmlTemplate.ExecuteTemplate(w, "head", title); err != nil {
+		return nil, errs.WrapMsg(err, "write html head failed")
+	}
+	return &htmlWriter{w: w}, nil
+}
+
+func (h *htmlWriter) WriteMsg(msg *Msg, _ []*Attachment) error {
+	if msg.ConversationID != h.conversationID {
+		h.conversationID = msg.ConversationID
+		if err := htmlTemplate.ExecuteTemplate(h.w, "conversation", msg.ConversationID); err != nil {
+			return errs.WrapMsg(err, "write html conversation failed", "conversationID", msg.ConversationID)
+		}
+	}
+	if err := htmlTemplate.ExecuteTemplate(h.w, "msg", msg); err != nil {
+		return errs.WrapMsg(err, "write html msg failed", "conversationID", msg.ConversationID, "seq", msg.Seq)
+	}
+	return nil
+}
+
+func (h *htmlWriter) Close() error {
+	if err := htmlTemplate.ExecuteTemplate(h.w, "tail", nil); err != nil {
+		return errs.WrapMsg(err, "write html tail failed")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgexport
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{enc: enc}
+}
+
+func (j *jsonlWriter) WriteMsg(msg *Msg, _ []*Attachment) error {
+	if err := j.enc.Encode(msg); err != nil {
+		return errs.WrapMsg(err, "write jsonl msg failed", "conversationID", msg.ConversationID, "seq", msg.Seq)
+	}
+	return nil
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgexport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+// mailDomain is the domain of the mail addresses made of user ids and group ids.
+const mailDomain = "openim"
+
+// base64LineLen is the length of the lines of base64 encoded attachments.
+const base64LineLen = 76
+
+// fromLine matches the lines mboxrd quotes by prepending '>', so they are not read as the start of a message.
+var fromLine = regexp.MustCompile(`(?m)^(>*From )`)
+
+type mboxWriter struct {
+	w io.Writer
+}
+
+func newMboxWriter(w io.Writer) *mboxWriter {
+	return &mboxWriter{w: w}
+}
+
+func mailAddress(id string) string {
+	return id + "@" + mailDomain
+}
+
+func (m *mboxWriter) WriteMsg(msg *Msg, attachments []*Attachment) error {
+	if err := m.writeMsg(msg, attachments); err != nil {
+		return errs.WrapMsg(err, "write mbox msg failed", "conversationID", msg.ConversationID, "seq", msg.Seq)
+	}
+	return nil
+}
+
+func (m *mboxWriter) writeMsg(msg *Msg, attachments []*Attachment) error {
+	sendTime := time.UnixMilli(msg.SendTime).UTC()
+	to := msg.GroupID
+	if to == "" {
+		to = msg.RecvID
+	}
+	msgID := msg.ServerMsgID
+	if msgID == "" {
+		msgID = msg.ClientMsgID
+	}
+	mw := multipart.NewWriter(m.w)
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "From %s %s\r\n", mailAddress(msg.SendID), sendTime.Format(time.ANSIC))
+	headers := [][2]string{
+		{"From", (&mail.Address{Name: msg.SenderNickname, Address: mailAddress(msg.SendID)}).String()},
+		{"To", mailAddress(to)},
+		{"Date", sendTime.Format(time.RFC1123Z)},
+		{"Subject", mime.QEncoding.Encode("utf-8", fmt.Sprintf("%s #%d", msg.ConversationID, msg.Seq))},
+		{"Message-ID", "<" + msgID + "@" + mailDomain + ">"},
+		{"X-OpenIM-Conversation-ID", msg.ConversationID},
+		{"X-OpenIM-Seq", strconv.FormatInt(msg.Seq, 10)},
+		{"X-OpenIM-Content-Type", strconv.Itoa(int(msg.ContentType))},
+	}
+	if msg.Revoked {
+		headers = append(headers, [2]string{"X-OpenIM-Revoked", "true"})
+	}
+	// The objects are listed even when they are not attached.
+	for _, name := range msg.Objects {
+		headers = append(headers, [2]string{"X-OpenIM-Object", name})
+	}
+	headers = append(headers,
+		[2]string{"MIME-Version", "1.0"},
+		[2]string{"Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()})},
+	)
+	for _, h := range headers {
+		fmt.Fprintf(&head, "%s: %s\r\n", h[0], h[1])
+	}
+	head.WriteString("\r\n")
+	if _, err := m.w.Write(head.Bytes()); err != nil {
+		return err
+	}
+	if err := writeTextPart(mw, msg); err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if err := writeAttachmentPart(mw, attachment); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	// A blank line separates the message from the From line of the next one.
+	_, err := io.WriteString(m.w, "\r\n")
+	return err
+}
+
+func writeTextPart(mw *multipart.Writer, msg *Msg) error {
+	text := msg.Text
+	if text == "" {
+		text = msg.Content
+	}
+	var body bytes.Buffer
+	qp := quotedprintable.NewWriter(&body)
+	if _, err := io.WriteString(qp, text); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(fromLine.ReplaceAll(body.Bytes(), []byte(">$1")))
+	return err
+}
+
+func writeAttachmentPart(mw *multipart.Writer, attachment *Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(attachment.Name)}))
+	header.Set("X-OpenIM-Object-Name", attachment.Name)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	lw := &lineWriter{w: part}
+	enc := base64.NewEncoder(base64.StdEncoding, lw)
+	if _, err := io.Copy(enc, attachment.Data); err != nil {
+		return errs.WrapMsg(err, "read attachment failed", "name", attachment.Name)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return lw.Close()
+}
+
+// lineWriter breaks what is written into lines of base64LineLen bytes.
+type lineWriter struct {
+	w   io.Writer
+	col int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if l.col == base64LineLen {
+			if _, err := io.WriteString(l.w, "\r\n"); err != nil {
+				return 0, err
+			}
+			l.col = 0
+		}
+		chunk := min(len(p), base64LineLen-l.col)
+		if _, err := l.w.Write(p[:chunk]); err != nil {
+			return 0, err
+		}
+		l.col += chunk
+		p = p[chunk:]
+	}
+	return n, nil
+}
+
+func (l *lineWriter) Close() error {
+	if l.col == 0 {
+		return nil
+	}
+	_, err := io.WriteString(l.w, "\r\n")
+	return err
+}
+
+func (m *mboxWriter) Close() error {
+	return nil
+}
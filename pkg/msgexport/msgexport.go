@@ -0,0 +1,117 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package msgexport writes the messages of conversations to archives which can be read without OpenIM,
+// compliance teams use them to keep or review a chat history.
+package msgexport
+
+import (
+	"io"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	// FormatJSONL writes one json object per line for each message.
+	FormatJSONL = "jsonl"
+	// FormatHTML writes a single page for reading in a browser.
+	FormatHTML = "html"
+	// FormatMbox writes a mboxrd archive, one mail per message with the referenced objects attached.
+	FormatMbox = "mbox"
+)
+
+// Msg is an exported message.
+type Msg struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	ClientMsgID    string `json:"clientMsgID"`
+	ServerMsgID    string `json:"serverMsgID"`
+	SendID         string `json:"sendID"`
+	SenderNickname string `json:"senderNickname"`
+	RecvID         string `json:"recvID,omitempty"`
+	GroupID        string `json:"groupID,omitempty"`
+	SessionType    int32  `json:"sessionType"`
+	ContentType    int32  `json:"contentType"`
+	Content        string `json:"content"`
+	// Text is the readable text of Content, it is empty for content types without text.
+	Text     string `json:"text,omitempty"`
+	SendTime int64  `json:"sendTime"`
+	// Revoked marks a revoked message, its content is still exported.
+	Revoked bool `json:"revoked,omitempty"`
+	// Objects are the names of the objects in the object storage Content refers to.
+	Objects []string `json:"objects,omitempty"`
+}
+
+// Attachment is the content of an object a message refers to.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        io.Reader
+}
+
+type Writer interface {
+	// WriteMsg writes msg, the messages of a conversation are written one after another in seq order.
+	// Only the formats which include objects read attachments.
+	WriteMsg(msg *Msg, attachments []*Attachment) error
+	// Close writes the end of the archive, it does not close the underlying writer.
+	Close() error
+}
+
+// IsFormat reports whether format is supported by NewWriter.
+func IsFormat(format string) bool {
+	switch format {
+	case FormatJSONL, FormatHTML, FormatMbox:
+		return true
+	default:
+		return false
+	}
+}
+
+// IncludesObjects reports whether the archives of format include the content of the objects.
+func IncludesObjects(format string) bool {
+	return format == FormatMbox
+}
+
+// NewWriter returns a Writer writing format to w, title names the archive where the format has a place for it.
+func NewWriter(format string, w io.Writer, title string) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatHTML:
+		return newHTMLWriter(w, title)
+	case FormatMbox:
+		return newMboxWriter(w), nil
+	default:
+		return nil, errs.ErrArgs.WrapMsg("unknown export format", "format", format)
+	}
+}
+
+// FileExt returns the file extension of the archives of format.
+func FileExt(format string) string {
+	return "." + format
+}
+
+// ContentType returns the mime type of the archives of format.
+func ContentType(format string) string {
+	switch format {
+	case FormatJSONL:
+		return "application/jsonl"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMbox:
+		return "application/mbox"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgexport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testMsgs() []*Msg {
+	return []*Msg{
+		{ConversationID: "si_a_b", Seq: 1, ClientMsgID: "c1", SendID: "a", SenderNickname: "Alice", RecvID: "b", ContentType: 101, Content: `{"content":"hi <b>"}`, Text: "hi <b>", SendTime: 1700000000000},
+		{ConversationID: "si_a_b", Seq: 2, ClientMsgID: "c2", SendID: "b", RecvID: "a", ContentType: 101, Content: `{"content":"x"}`, Text: "line\nFrom here", SendTime: 1700000001000, Revoked: true},
+		{ConversationID: "si_a_b", Seq: 3, ClientMsgID: "c3", SendID: "a", RecvID: "b", ContentType: 105, Content: `{"fileName":"r.txt"}`, Text: "r.txt", SendTime: 1700000002000, Objects: []string{"a/r.txt"}},
+	}
+}
+
+func writeAll(t *testing.T, format string, attach bool) string {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, "export <1>")
+	assert.NoError(t, err)
+	for _, msg := range testMsgs() {
+		var attachments []*Attachment
+		if attach {
+			for _, name := range msg.Objects {
+				attachments = append(attachments, &Attachment{Name: name, ContentType: "text/plain", Data: strings.NewReader(strings.Repeat("data ", 40))})
+			}
+		}
+		assert.NoError(t, w.WriteMsg(msg, attachments))
+	}
+	assert.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestJSONL(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeAll(t, FormatJSONL, false)), "\n")
+	assert.Len(t, lines, 3)
+	var msg Msg
+	assert.NoError(t, json.Unmarshal([]byte(lines[2]), &msg))
+	assert.Equal(t, *testMsgs()[2], msg)
+}
+
+func TestHTML(t *testing.T) {
+	page := writeAll(t, FormatHTML, false)
+	assert.Contains(t, page, "<title>export &lt;1&gt;</title>")
+	assert.Contains(t, page, "<pre>hi &lt;b&gt;</pre>")
+	assert.Equal(t, 1, strings.Count(page, "<h2>si_a_b</h2>"))
+	assert.Contains(t, page, "object: a/r.txt")
+	assert.Equal(t, 1, strings.Count(page, `<div class="revoked">`))
+	assert.True(t, strings.HasSuffix(page, "</html>\n"))
+}
+
+// splitMbox splits a mboxrd archive into its messages and unquotes their lines.
+func splitMbox(t *testing.T, archive string) []string {
+	var msgs []string
+	var cur strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(archive))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "From ") {
+			if cur.Len() > 0 {
+				msgs = append(msgs, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = line[1:]
+		}
+		cur.WriteString(line + "\n")
+	}
+	assert.NoError(t, scanner.Err())
+	return append(msgs, cur.String())
+}
+
+func TestMbox(t *testing.T) {
+	archive := writeAll(t, FormatMbox, true)
+	assert.Contains(t, archive, "\n>From here")
+	msgs := splitMbox(t, archive)
+	assert.Len(t, msgs, 3)
+
+	texts := make([]string, 0, len(msgs))
+	var attachment []byte
+	for _, raw := range msgs {
+		m, err := mail.ReadMessage(strings.NewReader(raw))
+		assert.NoError(t, err)
+		assert.Equal(t, "si_a_b", m.Header.Get("X-OpenIM-Conversation-ID"))
+		mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/mixed", mediaType)
+		mr := multipart.NewReader(m.Body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			data, err := io.ReadAll(part)
+			assert.NoError(t, err)
+			if part.FileName() != "" {
+				assert.Equal(t, "a/r.txt", part.Header.Get("X-OpenIM-Object-Name"))
+				attachment, err = base64.StdEncoding.DecodeString(strings.ReplaceAll(string(data), "\r\n", ""))
+				assert.NoError(t, err)
+				continue
+			}
+			text, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(data)))
+			assert.NoError(t, err)
+			texts = append(texts, string(text))
+		}
+	}
+	first, err := mail.ReadMessage(strings.NewReader(msgs[0]))
+	assert.NoError(t, err)
+	from, err := mail.ParseAddress(first.Header.Get("From"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", from.Name)
+	second, err := mail.ReadMessage(strings.NewReader(msgs[1]))
+	assert.NoError(t, err)
+	assert.Equal(t, "true", second.Header.Get("X-OpenIM-Revoked"))
+	assert.Equal(t, []string{"hi <b>", "line\nFrom here", "r.txt"}, texts)
+	assert.Equal(t, strings.Repeat("data ", 40), string(attachment))
+}
+
+func TestIsFormat(t *testing.T) {
+	assert.True(t, IsFormat(FormatMbox))
+	assert.False(t, IsFormat("pdf"))
+	_, err := NewWriter("pdf", io.Discard, "")
+	assert.Error(t, err)
+}
@@ -44,16 +44,17 @@ func GetNotificationConversationIDByMsg(msg *sdkws.MsgData) string {
 	return ""
 }
 
+// GetChatConversationIDByMsg returns the conversation holding msg, the thread conversation for a thread reply.
 func GetChatConversationIDByMsg(msg *sdkws.MsgData) string {
 	switch msg.SessionType {
 	case constant.SingleChatType:
 		l := []string{msg.SendID, msg.RecvID}
 		sort.Strings(l)
-		return "si_" + strings.Join(l, "_")
+		return withThread(msg, "si_"+strings.Join(l, "_"))
 	case constant.WriteGroupChatType:
 		return "g_" + msg.GroupID
 	case constant.ReadGroupChatType:
-		return "sg_" + msg.GroupID
+		return withThread(msg, "sg_"+msg.GroupID)
 	case constant.NotificationChatType:
 		return "sn_" + msg.SendID + "_" + msg.RecvID
 	}
@@ -82,7 +83,7 @@ func GetConversationIDByMsg(msg *sdkws.MsgData) string {
 		if !options.IsNotNotification() {
 			return "n_" + strings.Join(l, "_")
 		}
-		return "si_" + strings.Join(l, "_") // single chat
+		return withThread(msg, "si_"+strings.Join(l, "_")) // single chat
 	case constant.WriteGroupChatType:
 		if !options.IsNotNotification() {
 			return "n_" + msg.GroupID // group chat
@@ -92,7 +93,7 @@ func GetConversationIDByMsg(msg *sdkws.MsgData) string {
 		if !options.IsNotNotification() {
 			return "n_" + msg.GroupID // super group chat
 		}
-		return "sg_" + msg.GroupID // super group chat
+		return withThread(msg, "sg_"+msg.GroupID) // super group chat
 	case constant.NotificationChatType:
 		if !options.IsNotNotification() {
 			return "n_" + msg.SendID + "_" + msg.RecvID // super group chat
@@ -161,12 +162,12 @@ func ParseConversationID(msg *sdkws.MsgData) (isNotification bool, conversationI
 		if !options.IsNotNotification() {
 			return true, "n_" + strings.Join(l, "_")
 		}
-		return false, "si_" + strings.Join(l, "_") // single chat
+		return false, withThread(msg, "si_"+strings.Join(l, "_")) // single chat
 	case constant.ReadGroupChatType:
 		if !options.IsNotNotification() {
 			return true, "n_" + msg.GroupID // super group chat
 		}
-		return false, "sg_" + msg.GroupID // super group chat
+		return false, withThread(msg, "sg_"+msg.GroupID) // super group chat
 	case constant.NotificationChatType:
 		if !options.IsNotNotification() {
 			return true, "n_" + msg.SendID + "_" + msg.RecvID // super group chat
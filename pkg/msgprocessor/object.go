@@ -0,0 +1,86 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// objectURLPath is the path the api serves the objects of the object storage under.
+const objectURLPath = "/object/"
+
+type pictureInfo struct {
+	URL string `json:"url"`
+}
+
+// objectElem holds the urls of the content of the content types which refer to objects.
+type objectElem struct {
+	SourcePicture   *pictureInfo `json:"sourcePicture"`
+	BigPicture      *pictureInfo `json:"bigPicture"`
+	SnapshotPicture *pictureInfo `json:"snapshotPicture"`
+	SourceURL       string       `json:"sourceUrl"`
+	VideoURL        string       `json:"videoUrl"`
+	SnapshotURL     string       `json:"snapshotUrl"`
+}
+
+// GetObjectNames returns the names of the objects in the object storage the content of msg refers to,
+// urls which are not served by the api are ignored.
+func GetObjectNames(msg *sdkws.MsgData) []string {
+	switch msg.ContentType {
+	case constant.Picture, constant.Voice, constant.Video, constant.File:
+	default:
+		return nil
+	}
+	var elem objectElem
+	if err := json.Unmarshal(msg.Content, &elem); err != nil {
+		return nil
+	}
+	var urls []string
+	for _, picture := range []*pictureInfo{elem.SourcePicture, elem.BigPicture, elem.SnapshotPicture} {
+		if picture != nil {
+			urls = append(urls, picture.URL)
+		}
+	}
+	urls = append(urls, elem.SourceURL, elem.VideoURL, elem.SnapshotURL)
+	var names []string
+	for _, rawURL := range urls {
+		if name := ObjectNameFromURL(rawURL); name != "" {
+			names = append(names, name)
+		}
+	}
+	return datautil.Distinct(names)
+}
+
+// ObjectNameFromURL returns the object name of an url of the object api, it is empty for other urls.
+func ObjectNameFromURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	i := strings.Index(u.Path, objectURLPath)
+	if i < 0 {
+		return ""
+	}
+	return u.Path[i+len(objectURLPath):]
+}
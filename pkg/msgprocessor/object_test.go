@@ -0,0 +1,33 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetObjectNames(t *testing.T) {
+	picture := `{"sourcePicture":{"url":"https://im.example.com/api/object/u1/a.png"},"bigPicture":{"url":"https://im.example.com/api/object/u1/a.png"},"snapshotPicture":{"url":"https://im.example.com/api/object/u1/a_thumb.png"}}`
+	assert.ElementsMatch(t, []string{"u1/a.png", "u1/a_thumb.png"}, GetObjectNames(&sdkws.MsgData{ContentType: constant.Picture, Content: []byte(picture)}))
+	video := `{"videoUrl":"http://10.0.0.1:10002/object/u1/v.mp4","snapshotUrl":"https://cdn.example.com/v.jpg"}`
+	assert.Equal(t, []string{"u1/v.mp4"}, GetObjectNames(&sdkws.MsgData{ContentType: constant.Video, Content: []byte(video)}))
+	assert.Empty(t, GetObjectNames(&sdkws.MsgData{ContentType: constant.Text, Content: []byte(`{"content":"/object/x"}`)}))
+	assert.Empty(t, ObjectNameFromURL(""))
+	assert.Equal(t, "u1/a b.txt", ObjectNameFromURL("https://im.example.com/object/u1/a%20b.txt"))
+}
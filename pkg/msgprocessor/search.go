@@ -0,0 +1,53 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"encoding/json"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+)
+
+// searchElem holds the searchable fields of the content of the searchable content types without text.
+type searchElem struct {
+	FileName    string `json:"fileName"`
+	Description string `json:"description"`
+	Nickname    string `json:"nickname"`
+}
+
+// GetSearchText returns the text msg is found by in the full text search, it is empty when the content type
+// of msg is not searchable.
+func GetSearchText(msg *sdkws.MsgData) string {
+	switch msg.ContentType {
+	case constant.Text, constant.AtText, constant.Quote:
+		return GetText(msg)
+	case constant.File, constant.Location, constant.Custom, constant.Card:
+	default:
+		return ""
+	}
+	var elem searchElem
+	if err := json.Unmarshal(msg.Content, &elem); err != nil {
+		return ""
+	}
+	switch msg.ContentType {
+	case constant.File:
+		return elem.FileName
+	case constant.Card:
+		return elem.Nickname
+	default:
+		return elem.Description
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSearchText(t *testing.T) {
+	assert.Equal(t, "hello", GetSearchText(&sdkws.MsgData{ContentType: constant.Text, Content: []byte(`{"content":"hello"}`)}))
+	assert.Equal(t, "hi @bob", GetSearchText(&sdkws.MsgData{ContentType: constant.AtText, Content: []byte(`{"text":"hi @bob","atUserList":["bob"]}`)}))
+	assert.Equal(t, "report.pdf", GetSearchText(&sdkws.MsgData{ContentType: constant.File, Content: []byte(`{"fileName":"report.pdf","fileSize":1}`)}))
+	assert.Empty(t, GetSearchText(&sdkws.MsgData{ContentType: constant.Picture, Content: []byte(`{"sourcePath":"a.png"}`)}))
+	assert.Empty(t, GetSearchText(&sdkws.MsgData{ContentType: constant.Text, Content: []byte(`not json`)}))
+}
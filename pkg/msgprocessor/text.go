@@ -0,0 +1,74 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"encoding/json"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// textField returns the field of the content of contentType holding its text, empty when it has none.
+func textField(contentType int32) string {
+	switch contentType {
+	case constant.Text:
+		return "content"
+	case constant.AtText, constant.Quote:
+		return "text"
+	default:
+		return ""
+	}
+}
+
+// GetText returns the text of a Text, AtText or Quote msg, it is empty for the other content types.
+func GetText(msg *sdkws.MsgData) string {
+	field := textField(msg.ContentType)
+	if field == "" {
+		return ""
+	}
+	var content map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Content, &content); err != nil {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(content[field], &text); err != nil {
+		return ""
+	}
+	return text
+}
+
+// SetText replaces the text of the content of msg, the other fields of the content are kept as they are.
+func SetText(msg *sdkws.MsgData, text string) error {
+	field := textField(msg.ContentType)
+	if field == "" {
+		return errs.ErrArgs.WrapMsg("content type has no text", "contentType", msg.ContentType)
+	}
+	var content map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Content, &content); err != nil {
+		return errs.WrapMsg(err, "content is not a json object")
+	}
+	data, err := json.Marshal(text)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	content[field] = data
+	msg.Content, err = json.Marshal(content)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
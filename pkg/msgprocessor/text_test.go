@@ -0,0 +1,36 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	msg := &sdkws.MsgData{ContentType: constant.Text, Content: []byte(`{"content":"hello bad","extra":1}`)}
+	assert.Equal(t, "hello bad", GetText(msg))
+	assert.NoError(t, SetText(msg, "hello ***"))
+	assert.Equal(t, "hello ***", GetText(msg))
+	assert.JSONEq(t, `{"content":"hello ***","extra":1}`, string(msg.Content))
+
+	assert.Equal(t, "hi @bob", GetText(&sdkws.MsgData{ContentType: constant.AtText, Content: []byte(`{"text":"hi @bob"}`)}))
+	assert.Empty(t, GetText(&sdkws.MsgData{ContentType: constant.Picture, Content: []byte(`{"text":"x"}`)}))
+	assert.Empty(t, GetText(&sdkws.MsgData{ContentType: constant.Text, Content: []byte(`not json`)}))
+	assert.Error(t, SetText(&sdkws.MsgData{ContentType: constant.Picture, Content: []byte(`{}`)}, "x"))
+}
@@ -0,0 +1,82 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/openimsdk/protocol/sdkws"
+)
+
+const (
+	threadConversationPrefix = "th_"
+
+	// AttachedInfoThreadParentSeq is the key of the attached info that makes a message a reply in the thread of
+	// the message with this seq in the same conversation.
+	AttachedInfoThreadParentSeq = "threadParentSeq"
+)
+
+// GetThreadConversationID returns the conversation of the thread anchored on the message parentSeq of conversationID.
+// A thread has its own seq stream, it is pulled and marked as read like any conversation.
+func GetThreadConversationID(conversationID string, parentSeq int64) string {
+	return threadConversationPrefix + conversationID + "_" + strconv.FormatInt(parentSeq, 10)
+}
+
+func IsThreadConversationID(conversationID string) bool {
+	return strings.HasPrefix(conversationID, threadConversationPrefix)
+}
+
+// ParseThreadConversationID returns the conversation and the seq of the parent message of a thread.
+func ParseThreadConversationID(threadID string) (conversationID string, parentSeq int64, ok bool) {
+	if !IsThreadConversationID(threadID) {
+		return "", 0, false
+	}
+	i := strings.LastIndexByte(threadID, '_')
+	if i <= len(threadConversationPrefix) {
+		return "", 0, false
+	}
+	parentSeq, err := strconv.ParseInt(threadID[i+1:], 10, 64)
+	if err != nil || parentSeq <= 0 {
+		return "", 0, false
+	}
+	return threadID[len(threadConversationPrefix):i], parentSeq, true
+}
+
+// GetThreadParentSeq returns the seq of the parent message of a thread reply, 0 if msg is not a thread reply.
+func GetThreadParentSeq(msg *sdkws.MsgData) int64 {
+	if !strings.Contains(msg.AttachedInfo, AttachedInfoThreadParentSeq) {
+		return 0
+	}
+	var info struct {
+		ThreadParentSeq int64 `json:"threadParentSeq"`
+	}
+	if err := json.Unmarshal([]byte(msg.AttachedInfo), &info); err != nil || info.ThreadParentSeq < 0 {
+		return 0
+	}
+	return info.ThreadParentSeq
+}
+
+// withThread returns the thread conversation of msg if it is a thread reply, conversationID otherwise.
+func withThread(msg *sdkws.MsgData, conversationID string) string {
+	if conversationID == "" {
+		return ""
+	}
+	if parentSeq := GetThreadParentSeq(msg); parentSeq > 0 {
+		return GetThreadConversationID(conversationID, parentSeq)
+	}
+	return conversationID
+}
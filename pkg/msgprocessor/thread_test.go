@@ -0,0 +1,62 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgprocessor
+
+import (
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThreadConversationID(t *testing.T) {
+	threadID := GetThreadConversationID("sg_group_1", 42)
+	assert.Equal(t, "th_sg_group_1_42", threadID)
+	assert.True(t, IsThreadConversationID(threadID))
+
+	conversationID, parentSeq, ok := ParseThreadConversationID(threadID)
+	assert.True(t, ok)
+	assert.Equal(t, "sg_group_1", conversationID)
+	assert.Equal(t, int64(42), parentSeq)
+
+	for _, id := range []string{"sg_group_1", "th_", "th_42", "th_sg_g1_x", "th_sg_g1_0"} {
+		_, _, ok = ParseThreadConversationID(id)
+		assert.False(t, ok, id)
+	}
+}
+
+func TestThreadReplyConversationID(t *testing.T) {
+	msg := &sdkws.MsgData{
+		SessionType:  constant.ReadGroupChatType,
+		GroupID:      "g1",
+		AttachedInfo: `{"threadParentSeq":7}`,
+	}
+	assert.Equal(t, int64(7), GetThreadParentSeq(msg))
+	assert.Equal(t, "th_sg_g1_7", GetChatConversationIDByMsg(msg))
+	assert.Equal(t, "n_g1", GetNotificationConversationIDByMsg(msg))
+
+	msg.AttachedInfo = `{"isPrivateChat":false}`
+	assert.Equal(t, int64(0), GetThreadParentSeq(msg))
+	assert.Equal(t, "sg_g1", GetChatConversationIDByMsg(msg))
+
+	msg = &sdkws.MsgData{
+		SessionType:  constant.SingleChatType,
+		SendID:       "u2",
+		RecvID:       "u1",
+		AttachedInfo: `{"threadParentSeq":3}`,
+	}
+	assert.Equal(t, "th_si_u1_u2_3", GetChatConversationIDByMsg(msg))
+}
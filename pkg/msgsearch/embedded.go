@@ -0,0 +1,649 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgsearch
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/log"
+)
+
+const (
+	defaultFlushInterval = time.Second
+	// maxPendingDocs is the number of buffered docs which are flushed without waiting for the flush interval.
+	maxPendingDocs = 10000
+	// mergeFactor is the number of segments of about the same size which are merged into one.
+	mergeFactor = 10
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// embeddedIndex is an inverted index on the local disk. Every writer buffers the docs it indexes and flushes them
+// as a new segment into its own subdirectory of dir, then merges its small segments in the background.
+// Searchers load the segments of every writer and reload them when they change.
+type embeddedIndex struct {
+	dir           string
+	writerID      string
+	flushInterval time.Duration
+
+	pendingLock sync.Mutex
+	pending     []*pendingDoc
+	flushLock   sync.Mutex
+	lastGen     int64
+
+	lock        sync.RWMutex
+	segments    []*segment
+	refreshLock sync.Mutex
+	refreshTime time.Time
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+type pendingDoc struct {
+	doc   *segmentDoc
+	terms []string
+}
+
+func newEmbeddedIndex(conf *config.MsgSearch, writerID string) (MsgSearchIndex, error) {
+	if conf.Embedded.Dir == "" {
+		return nil, errs.New("msgSearch.embedded.dir is empty").Wrap()
+	}
+	flushInterval := time.Duration(conf.Embedded.FlushInterval) * time.Second
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	return openEmbeddedIndex(conf.Embedded.Dir, writerID, flushInterval)
+}
+
+func openEmbeddedIndex(dir string, writerID string, flushInterval time.Duration) (*embeddedIndex, error) {
+	if strings.ContainsAny(writerID, `/\`) || writerID == "." || writerID == ".." {
+		return nil, errs.New("invalid msg search writer id", "writerID", writerID).Wrap()
+	}
+	e := &embeddedIndex{
+		dir:           dir,
+		writerID:      writerID,
+		flushInterval: flushInterval,
+		done:          make(chan struct{}),
+	}
+	if writerID == "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, errs.WrapMsg(err, "create msg search dir failed", "dir", dir)
+		}
+		return e, nil
+	}
+	if err := os.MkdirAll(e.writerDir(), 0o755); err != nil {
+		return nil, errs.WrapMsg(err, "create msg search dir failed", "dir", e.writerDir())
+	}
+	segments, err := e.openWriterSegments()
+	if err != nil {
+		return nil, err
+	}
+	e.segments = segments
+	if len(segments) > 0 {
+		e.lastGen = segments[len(segments)-1].last
+	}
+	e.wg.Add(1)
+	go e.loop()
+	return e, nil
+}
+
+func (e *embeddedIndex) writerDir() string {
+	return filepath.Join(e.dir, e.writerID)
+}
+
+// openWriterSegments opens the segments of the writer, and removes the files left by an interrupted flush or merge.
+func (e *embeddedIndex) openWriterSegments() ([]*segment, error) {
+	entries, err := os.ReadDir(e.writerDir())
+	if err != nil {
+		return nil, errs.WrapMsg(err, "read msg search dir failed", "dir", e.writerDir())
+	}
+	var named []*segment
+	for _, entry := range entries {
+		path := filepath.Join(e.writerDir(), entry.Name())
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			_ = os.Remove(path)
+			continue
+		}
+		if first, last, ok := parseSegmentName(entry.Name()); ok {
+			named = append(named, &segment{path: path, first: first, last: last})
+		}
+	}
+	live, covered := splitCovered(named)
+	for _, seg := range covered {
+		_ = os.Remove(seg.path)
+	}
+	segments := make([]*segment, 0, len(live))
+	for _, seg := range live {
+		opened, err := openSegment(seg.path)
+		if err != nil {
+			closeSegments(segments)
+			return nil, err
+		}
+		segments = append(segments, opened)
+	}
+	return segments, nil
+}
+
+func (e *embeddedIndex) Index(ctx context.Context, docs []*Doc) error {
+	if e.writerID == "" {
+		return errs.New("msg search index is opened for search only").Wrap()
+	}
+	pending := make([]*pendingDoc, 0, len(docs))
+	for _, doc := range docs {
+		terms := Tokenize(doc.Text)
+		if len(terms) == 0 {
+			continue
+		}
+		pending = append(pending, &pendingDoc{
+			doc: &segmentDoc{
+				TenantID:       doc.TenantID,
+				ConversationID: doc.ConversationID,
+				Seq:            doc.Seq,
+				SendID:         doc.SendID,
+				ContentType:    doc.ContentType,
+				SendTime:       doc.SendTime,
+				Len:            int32(len(terms)),
+			},
+			terms: terms,
+		})
+	}
+	return e.addPending(ctx, pending)
+}
+
+func (e *embeddedIndex) Delete(ctx context.Context, tenantID string, conversationID string, seqs []int64) error {
+	if e.writerID == "" {
+		return errs.New("msg search index is opened for search only").Wrap()
+	}
+	pending := make([]*pendingDoc, 0, len(seqs))
+	for _, seq := range seqs {
+		pending = append(pending, &pendingDoc{
+			doc: &segmentDoc{TenantID: tenantID, ConversationID: conversationID, Seq: seq, Deleted: true},
+		})
+	}
+	return e.addPending(ctx, pending)
+}
+
+func (e *embeddedIndex) DeleteBefore(ctx context.Context, tenantID string, conversationID string, minSeq int64) error {
+	if e.writerID == "" {
+		return errs.New("msg search index is opened for search only").Wrap()
+	}
+	if minSeq <= 1 {
+		return nil
+	}
+	return e.addPending(ctx, []*pendingDoc{{
+		doc: &segmentDoc{TenantID: tenantID, ConversationID: conversationID, Deleted: true, MinSeq: minSeq},
+	}})
+}
+
+func (e *embeddedIndex) addPending(ctx context.Context, docs []*pendingDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	e.pendingLock.Lock()
+	e.pending = append(e.pending, docs...)
+	full := len(e.pending) >= maxPendingDocs
+	e.pendingLock.Unlock()
+	if full {
+		return e.flush(ctx)
+	}
+	return nil
+}
+
+func (e *embeddedIndex) loop() {
+	defer e.wg.Done()
+	ctx := context.Background()
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			if err := e.flush(ctx); err != nil {
+				log.ZError(ctx, "flush msg search index failed", err, "dir", e.writerDir())
+			}
+			return
+		case <-ticker.C:
+			if err := e.flush(ctx); err != nil {
+				log.ZError(ctx, "flush msg search index failed", err, "dir", e.writerDir())
+				continue
+			}
+			if err := e.merge(ctx); err != nil {
+				log.ZError(ctx, "merge msg search index failed", err, "dir", e.writerDir())
+			}
+		}
+	}
+}
+
+func (e *embeddedIndex) nextGen() int64 {
+	gen := time.Now().UnixNano()
+	if gen <= e.lastGen {
+		gen = e.lastGen + 1
+	}
+	e.lastGen = gen
+	return gen
+}
+
+// flush writes the buffered docs as a new segment, the docs are buffered again when it fails.
+func (e *embeddedIndex) flush(ctx context.Context) error {
+	e.flushLock.Lock()
+	defer e.flushLock.Unlock()
+	e.pendingLock.Lock()
+	pending := e.pending
+	e.pending = nil
+	e.pendingLock.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	index := make(map[docKey]int, len(pending))
+	docs := make([]*segmentDoc, 0, len(pending))
+	docTerms := make([][]string, 0, len(pending))
+	gen := e.nextGen()
+	for _, p := range pending {
+		p.doc.Gen = gen
+		if i, ok := index[p.doc.key()]; ok {
+			if p.doc.MinSeq > 0 {
+				p.doc.MinSeq = max(p.doc.MinSeq, docs[i].MinSeq)
+			}
+			docs[i], docTerms[i] = p.doc, p.terms
+			continue
+		}
+		index[p.doc.key()] = len(docs)
+		docs = append(docs, p.doc)
+		docTerms = append(docTerms, p.terms)
+	}
+	seg, err := writeSegment(filepath.Join(e.writerDir(), segmentName(gen, gen)), docs, docTerms)
+	if err != nil {
+		e.pendingLock.Lock()
+		e.pending = append(pending, e.pending...)
+		e.pendingLock.Unlock()
+		return err
+	}
+	log.ZDebug(ctx, "msg search segment flushed", "path", seg.path, "docs", len(docs))
+	e.lock.Lock()
+	e.segments = append(e.segments, seg)
+	e.lock.Unlock()
+	return nil
+}
+
+func segmentLevel(seg *segment) int {
+	return int(math.Log(float64(len(seg.docs)+1)) / math.Log(mergeFactor))
+}
+
+// merge merges every mergeFactor adjacent segments of the writer of the same level into one,
+// so the number of segments grows logarithmically with the number of docs.
+func (e *embeddedIndex) merge(ctx context.Context) error {
+	e.flushLock.Lock()
+	defer e.flushLock.Unlock()
+	for {
+		e.lock.RLock()
+		var group []*segment
+		for _, seg := range e.ownSegments() {
+			if len(group) == mergeFactor {
+				break
+			}
+			if len(group) > 0 && segmentLevel(group[0]) != segmentLevel(seg) {
+				group = group[:0]
+			}
+			group = append(group, seg)
+		}
+		e.lock.RUnlock()
+		if len(group) < mergeFactor {
+			return nil
+		}
+		path := filepath.Join(e.writerDir(), segmentName(group[0].first, group[len(group)-1].last))
+		merged, err := mergeSegments(path, group)
+		if err != nil {
+			return err
+		}
+		log.ZDebug(ctx, "msg search segments merged", "path", merged.path, "segments", len(group), "docs", len(merged.docs))
+		e.lock.Lock()
+		segments := make([]*segment, 0, len(e.segments)-len(group)+1)
+		for _, seg := range e.segments {
+			if seg == group[0] {
+				segments = append(segments, merged)
+			}
+			if !merged.covers(seg) {
+				segments = append(segments, seg)
+			}
+		}
+		sortSegments(segments)
+		e.segments = segments
+		e.lock.Unlock()
+		for _, seg := range group {
+			_ = seg.close()
+			if err := os.Remove(seg.path); err != nil {
+				log.ZWarn(ctx, "remove merged msg search segment failed", err, "path", seg.path)
+			}
+		}
+	}
+}
+
+// refresh reloads the segments written by the other writers, at most once every flush interval.
+func (e *embeddedIndex) refresh() error {
+	e.refreshLock.Lock()
+	defer e.refreshLock.Unlock()
+	if time.Since(e.refreshTime) < e.flushInterval {
+		return nil
+	}
+	dirs, err := os.ReadDir(e.dir)
+	if err != nil {
+		return errs.WrapMsg(err, "read msg search dir failed", "dir", e.dir)
+	}
+	e.lock.RLock()
+	loaded := make(map[string]*segment, len(e.segments))
+	for _, seg := range e.segments {
+		loaded[seg.path] = seg
+	}
+	e.lock.RUnlock()
+	var others []*segment
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == e.writerID {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(e.dir, dir.Name()))
+		if err != nil {
+			return errs.WrapMsg(err, "read msg search dir failed", "dir", dir.Name())
+		}
+		var writerSegments []*segment
+		for _, entry := range entries {
+			first, last, ok := parseSegmentName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(e.dir, dir.Name(), entry.Name())
+			if seg, ok := loaded[path]; ok {
+				writerSegments = append(writerSegments, seg)
+			} else {
+				writerSegments = append(writerSegments, &segment{path: path, first: first, last: last})
+			}
+		}
+		live, _ := splitCovered(writerSegments)
+		for _, seg := range live {
+			if seg.file == nil {
+				opened, err := openSegment(seg.path)
+				if err != nil {
+					// the writer may have merged and removed it meanwhile, the merged one is loaded next time
+					log.ZWarn(context.Background(), "open msg search segment failed", err, "path", seg.path)
+					continue
+				}
+				seg = opened
+			}
+			others = append(others, seg)
+		}
+	}
+	keep := make(map[*segment]struct{}, len(others))
+	for _, seg := range others {
+		keep[seg] = struct{}{}
+	}
+	e.lock.Lock()
+	segments := append(others, e.ownSegments()...)
+	for _, seg := range e.segments {
+		if _, ok := keep[seg]; !ok && !e.isOwn(seg) {
+			_ = seg.close()
+		}
+	}
+	sortSegments(segments)
+	e.segments = segments
+	e.lock.Unlock()
+	e.refreshTime = time.Now()
+	return nil
+}
+
+func (e *embeddedIndex) isOwn(seg *segment) bool {
+	return e.writerID != "" && filepath.Dir(seg.path) == e.writerDir()
+}
+
+// ownSegments returns the segments of the writer ordered from old to new, the caller must hold the lock.
+func (e *embeddedIndex) ownSegments() []*segment {
+	var segments []*segment
+	for _, seg := range e.segments {
+		if e.isOwn(seg) {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+func (e *embeddedIndex) Search(ctx context.Context, query *Query) (*Result, error) {
+	terms := QueryTerms(query.Keyword)
+	if len(terms) == 0 {
+		return nil, errs.ErrArgs.WrapMsg("keyword has no searchable words")
+	}
+	if len(query.ConversationIDs) == 0 {
+		return &Result{}, nil
+	}
+	if err := e.refresh(); err != nil {
+		return nil, err
+	}
+	conversations := make(map[string]struct{}, len(query.ConversationIDs))
+	for _, conversationID := range query.ConversationIDs {
+		conversations[conversationID] = struct{}{}
+	}
+	contentTypes := make(map[int32]struct{}, len(query.ContentTypes))
+	for _, contentType := range query.ContentTypes {
+		contentTypes[contentType] = struct{}{}
+	}
+	match := func(doc *segmentDoc) bool {
+		if doc.Deleted || doc.TenantID != query.TenantID {
+			return false
+		}
+		if _, ok := conversations[doc.ConversationID]; !ok {
+			return false
+		}
+		if query.SendID != "" && doc.SendID != query.SendID {
+			return false
+		}
+		if len(contentTypes) > 0 {
+			if _, ok := contentTypes[doc.ContentType]; !ok {
+				return false
+			}
+		}
+		if query.StartTime > 0 && doc.SendTime < query.StartTime {
+			return false
+		}
+		if query.EndTime > 0 && doc.SendTime > query.EndTime {
+			return false
+		}
+		return true
+	}
+
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	var (
+		docNum   int64
+		totalLen int64
+		docFreq  = make([]int64, len(terms))
+	)
+	for _, seg := range e.segments {
+		docNum += int64(len(seg.docs))
+		totalLen += seg.totalLen
+		for i, term := range terms {
+			docFreq[i] += int64(seg.dict[term].DocFreq)
+		}
+	}
+	if docNum == 0 {
+		return &Result{}, nil
+	}
+	avgLen := float64(totalLen) / float64(docNum)
+	idf := make([]float64, len(terms))
+	for i := range terms {
+		idf[i] = math.Log(1 + (float64(docNum)-float64(docFreq[i])+0.5)/(float64(docFreq[i])+0.5))
+	}
+	ranges := make(map[docKey][]rangeDelete)
+	for _, seg := range e.segments {
+		for _, doc := range seg.ranges {
+			ranges[doc.key()] = append(ranges[doc.key()], rangeDelete{minSeq: doc.MinSeq, gen: seg.gen(doc)})
+		}
+	}
+	var hits []*Hit
+	for i := len(e.segments) - 1; i >= 0; i-- {
+		seg := e.segments[i]
+		freqs, err := e.intersect(seg, terms)
+		if err != nil {
+			return nil, err
+		}
+		for doc, freq := range freqs {
+			d := seg.docs[doc]
+			if !match(d) || e.replaced(i, d, ranges) {
+				continue
+			}
+			var score float64
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(d.Len)/avgLen)
+			for j := range terms {
+				tf := float64(freq[j])
+				score += idf[j] * tf * (bm25K1 + 1) / (tf + norm)
+			}
+			hits = append(hits, &Hit{ConversationID: d.ConversationID, Seq: d.Seq, SendTime: d.SendTime, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].SendTime != hits[j].SendTime {
+			return hits[i].SendTime > hits[j].SendTime
+		}
+		if hits[i].ConversationID != hits[j].ConversationID {
+			return hits[i].ConversationID < hits[j].ConversationID
+		}
+		return hits[i].Seq < hits[j].Seq
+	})
+	res := &Result{Total: int64(len(hits))}
+	offset := max(query.Offset, 0)
+	if offset < len(hits) && query.Limit > 0 {
+		res.Hits = hits[offset:min(offset+query.Limit, len(hits))]
+	}
+	return res, nil
+}
+
+// intersect returns the frequency of every term in each doc of seg containing all of terms.
+func (e *embeddedIndex) intersect(seg *segment, terms []string) (map[uint32][]uint32, error) {
+	var freqs map[uint32][]uint32
+	for i, term := range terms {
+		postings, err := seg.postings(term)
+		if err != nil {
+			return nil, err
+		}
+		if len(postings) == 0 {
+			return nil, nil
+		}
+		if i == 0 {
+			freqs = make(map[uint32][]uint32, len(postings))
+			for _, p := range postings {
+				freq := make([]uint32, len(terms))
+				freq[0] = p.freq
+				freqs[p.doc] = freq
+			}
+			continue
+		}
+		next := make(map[uint32][]uint32, len(freqs))
+		for _, p := range postings {
+			if freq, ok := freqs[p.doc]; ok {
+				freq[i] = p.freq
+				next[p.doc] = freq
+			}
+		}
+		if len(next) == 0 {
+			return nil, nil
+		}
+		freqs = next
+	}
+	return freqs, nil
+}
+
+type rangeDelete struct {
+	minSeq int64
+	gen    int64
+}
+
+// replaced reports whether doc of the i-th segment is replaced or deleted by a newer doc. The segments of
+// different writers overlap in time, so the docs are compared by the generation they were written in.
+func (e *embeddedIndex) replaced(i int, doc *segmentDoc, ranges map[docKey][]rangeDelete) bool {
+	gen := e.segments[i].gen(doc)
+	key := doc.key()
+	for j, seg := range e.segments {
+		if j == i {
+			continue
+		}
+		if k, ok := seg.keys[key]; ok && seg.gen(seg.docs[k]) > gen {
+			return true
+		}
+	}
+	key.seq = 0
+	for _, r := range ranges[key] {
+		if r.gen > gen && doc.Seq < r.minSeq {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *embeddedIndex) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+		e.wg.Wait()
+		e.lock.Lock()
+		closeSegments(e.segments)
+		e.segments = nil
+		e.lock.Unlock()
+	})
+	return nil
+}
+
+// splitCovered splits the segments of one writer into the live ones, ordered from old to new,
+// and the ones already merged into another.
+func splitCovered(segments []*segment) (live []*segment, covered []*segment) {
+	for _, seg := range segments {
+		isCovered := false
+		for _, other := range segments {
+			if other.covers(seg) {
+				isCovered = true
+				break
+			}
+		}
+		if isCovered {
+			covered = append(covered, seg)
+		} else {
+			live = append(live, seg)
+		}
+	}
+	sortSegments(live)
+	return live, covered
+}
+
+func sortSegments(segments []*segment) {
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].last < segments[j].last
+	})
+}
+
+func closeSegments(segments []*segment) {
+	for _, seg := range segments {
+		if seg.file != nil {
+			_ = seg.close()
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgsearch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchKeys(t *testing.T, index *embeddedIndex, query *Query) []string {
+	t.Helper()
+	index.refreshTime = time.Time{}
+	res, err := index.Search(context.Background(), query)
+	assert.NoError(t, err)
+	keys := make([]string, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		keys = append(keys, hit.ConversationID+":"+strconv.FormatInt(hit.Seq, 10))
+	}
+	return keys
+}
+
+func TestEmbeddedIndex(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writer, err := openEmbeddedIndex(dir, "w1", time.Hour)
+	assert.NoError(t, err)
+	defer writer.Close()
+	reader, err := openEmbeddedIndex(dir, "", time.Hour)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	assert.NoError(t, writer.Index(ctx, []*Doc{
+		{ConversationID: "c1", Seq: 1, SendID: "u1", SendTime: 1, Text: "the quick brown fox"},
+		{ConversationID: "c1", Seq: 2, SendID: "u2", SendTime: 2, Text: "fox fox fox"},
+		{ConversationID: "c2", Seq: 1, SendID: "u1", SendTime: 3, Text: "a lazy dog and a fox"},
+		{ConversationID: "c3", Seq: 1, SendID: "u1", SendTime: 4, Text: "fox in another conversation"},
+		{TenantID: "app1", ConversationID: "c1", Seq: 3, SendTime: 5, Text: "fox of another tenant"},
+	}))
+	assert.NoError(t, writer.flush(ctx))
+
+	query := &Query{Keyword: "fox", ConversationIDs: []string{"c1", "c2"}, Limit: 10}
+	assert.Equal(t, []string{"c1:2", "c1:1", "c2:1"}, searchKeys(t, reader, query))
+	assert.Equal(t, []string{"c1:1"}, searchKeys(t, reader, &Query{Keyword: "Quick FOX", ConversationIDs: []string{"c1", "c2"}, Limit: 10}))
+	assert.Equal(t, []string{"c1:1", "c2:1"}, searchKeys(t, reader, &Query{Keyword: "fox", ConversationIDs: []string{"c1", "c2"}, SendID: "u1", Limit: 10}))
+	assert.Equal(t, []string{"c2:1"}, searchKeys(t, reader, &Query{Keyword: "fox", ConversationIDs: []string{"c1", "c2"}, StartTime: 3, Limit: 10}))
+	assert.Equal(t, []string{"c1:3"}, searchKeys(t, reader, &Query{TenantID: "app1", Keyword: "fox", ConversationIDs: []string{"c1"}, Limit: 10}))
+	assert.Empty(t, searchKeys(t, reader, &Query{Keyword: "fox", Limit: 10}))
+	assert.Equal(t, []string{"c1:1"}, searchKeys(t, reader, &Query{Keyword: "fox", ConversationIDs: []string{"c1", "c2"}, Offset: 1, Limit: 1}))
+
+	assert.NoError(t, writer.Index(ctx, []*Doc{{ConversationID: "c1", Seq: 2, SendID: "u2", SendTime: 2, Text: "edited to a cat"}}))
+	assert.NoError(t, writer.Delete(ctx, "", "c2", []int64{1}))
+	assert.NoError(t, writer.flush(ctx))
+	assert.Equal(t, []string{"c1:1"}, searchKeys(t, reader, query))
+	assert.Equal(t, []string{"c1:2"}, searchKeys(t, reader, &Query{Keyword: "cat", ConversationIDs: []string{"c1"}, Limit: 10}))
+}
+
+func TestEmbeddedIndexMerge(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writer, err := openEmbeddedIndex(dir, "w1", time.Hour)
+	assert.NoError(t, err)
+	for i := 1; i <= mergeFactor; i++ {
+		assert.NoError(t, writer.Index(ctx, []*Doc{{ConversationID: "c1", Seq: int64(i), Text: "msg " + strconv.Itoa(i)}}))
+		if i == mergeFactor {
+			assert.NoError(t, writer.Delete(ctx, "", "c1", []int64{1}))
+		}
+		assert.NoError(t, writer.flush(ctx))
+	}
+	assert.NoError(t, writer.Index(ctx, []*Doc{{ConversationID: "c1", Seq: 2, Text: "msg replaced"}}))
+	assert.NoError(t, writer.flush(ctx))
+	assert.NoError(t, writer.merge(ctx))
+	assert.Len(t, writer.segments, 2)
+	files, err := filepath.Glob(filepath.Join(dir, "w1", "*"+segmentExt))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	mergedFirst := writer.segments[0].first
+	assert.NoError(t, writer.Close())
+
+	reader, err := openEmbeddedIndex(dir, "", time.Hour)
+	assert.NoError(t, err)
+	defer reader.Close()
+	assert.Equal(t, []string{"c1:3"}, searchKeys(t, reader, &Query{Keyword: "3", ConversationIDs: []string{"c1"}, Limit: 10}))
+	assert.Empty(t, searchKeys(t, reader, &Query{Keyword: "1", ConversationIDs: []string{"c1"}, Limit: 10}))
+	assert.Empty(t, searchKeys(t, reader, &Query{Keyword: "2", ConversationIDs: []string{"c1"}, Limit: 10}))
+	res, err := reader.Search(ctx, &Query{Keyword: "msg", ConversationIDs: []string{"c1"}, Limit: 100})
+	assert.NoError(t, err)
+	assert.EqualValues(t, mergeFactor-1, res.Total)
+
+	// a merge interrupted before removing the merged segments leaves them covered by the merged one
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "w1", segmentName(mergedFirst, mergedFirst)), []byte("stale"), 0o644))
+	reopened, err := openEmbeddedIndex(dir, "w1", time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, reopened.segments, 2)
+	assert.NoError(t, reopened.Close())
+}
+
+func TestEmbeddedIndexWriters(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	w1, err := openEmbeddedIndex(dir, "w1", time.Hour)
+	assert.NoError(t, err)
+	defer w1.Close()
+	w2, err := openEmbeddedIndex(dir, "w2", time.Hour)
+	assert.NoError(t, err)
+	defer w2.Close()
+	reader, err := openEmbeddedIndex(dir, "", time.Hour)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	for i := 1; i < mergeFactor; i++ {
+		assert.NoError(t, w1.Index(ctx, []*Doc{{ConversationID: "c1", Seq: int64(i), Text: "fox " + strconv.Itoa(i)}}))
+		assert.NoError(t, w1.flush(ctx))
+	}
+	assert.NoError(t, w2.Delete(ctx, "", "c1", []int64{5}))
+	assert.NoError(t, w2.DeleteBefore(ctx, "", "c1", 3))
+	assert.NoError(t, w2.flush(ctx))
+	query := &Query{Keyword: "fox", ConversationIDs: []string{"c1"}, Limit: 100}
+	res, err := reader.Search(ctx, query)
+	assert.NoError(t, err)
+	assert.EqualValues(t, mergeFactor-4, res.Total)
+
+	// the merged segment of w1 is newer than the deletes of w2, its docs written before them stay deleted
+	assert.NoError(t, w1.Index(ctx, []*Doc{{ConversationID: "c1", Seq: int64(mergeFactor), Text: "fox 10"}}))
+	assert.NoError(t, w1.flush(ctx))
+	assert.NoError(t, w1.merge(ctx))
+	assert.Len(t, w1.segments, 1)
+	reader.refreshTime = time.Time{}
+	res, err = reader.Search(ctx, query)
+	assert.NoError(t, err)
+	assert.EqualValues(t, mergeFactor-3, res.Total)
+	assert.Empty(t, searchKeys(t, reader, &Query{Keyword: "5", ConversationIDs: []string{"c1"}, Limit: 10}))
+	assert.Empty(t, searchKeys(t, reader, &Query{Keyword: "2", ConversationIDs: []string{"c1"}, Limit: 10}))
+	assert.Equal(t, []string{"c1:3"}, searchKeys(t, reader, &Query{Keyword: "3", ConversationIDs: []string{"c1"}, Limit: 10}))
+
+	// a doc indexed again after the delete is searchable
+	assert.NoError(t, w1.Index(ctx, []*Doc{{ConversationID: "c1", Seq: 5, Text: "fox 5 again"}}))
+	assert.NoError(t, w1.flush(ctx))
+	assert.Equal(t, []string{"c1:5"}, searchKeys(t, reader, &Query{Keyword: "again", ConversationIDs: []string{"c1"}, Limit: 10}))
+}
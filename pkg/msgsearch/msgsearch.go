@@ -0,0 +1,135 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package msgsearch indexes the text of persisted messages so that a user can search them by keywords
+// across all of the conversations, ranked by relevance.
+// The embedded engine keeps an inverted index on the local disk, external engines are plugged in with Register.
+package msgsearch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// EngineEmbedded is the engine used when msgSearch.engine is empty.
+const EngineEmbedded = "embedded"
+
+// Doc is the searchable part of a message.
+type Doc struct {
+	TenantID       string
+	ConversationID string
+	Seq            int64
+	SendID         string
+	ContentType    int32
+	SendTime       int64
+	Text           string
+}
+
+// NewDoc returns the doc of msg of the conversation, ok is false when msg has no searchable text.
+func NewDoc(tenantID string, conversationID string, msg *sdkws.MsgData) (doc *Doc, ok bool) {
+	text := msgprocessor.GetSearchText(msg)
+	if text == "" {
+		return nil, false
+	}
+	return &Doc{
+		TenantID:       tenantID,
+		ConversationID: conversationID,
+		Seq:            msg.Seq,
+		SendID:         msg.SendID,
+		ContentType:    msg.ContentType,
+		SendTime:       msg.SendTime,
+		Text:           text,
+	}, true
+}
+
+type Query struct {
+	TenantID string
+	Keyword  string
+	// ConversationIDs are the conversations the searcher can access, docs of other conversations are never returned.
+	ConversationIDs []string
+	SendID          string
+	ContentTypes    []int32
+	// StartTime and EndTime limit the send time in milliseconds, 0 means no limit.
+	StartTime int64
+	EndTime   int64
+	Offset    int
+	Limit     int
+}
+
+type Hit struct {
+	ConversationID string
+	Seq            int64
+	SendTime       int64
+	Score          float64
+}
+
+type Result struct {
+	Total int64
+	Hits  []*Hit
+}
+
+// MsgSearchIndex is the full text index of messages.
+type MsgSearchIndex interface {
+	// Index adds docs to the index, a doc replaces the indexed one of the same conversation and seq.
+	Index(ctx context.Context, docs []*Doc) error
+	// Delete removes the docs of seqs of the conversation from the index.
+	Delete(ctx context.Context, tenantID string, conversationID string, seqs []int64) error
+	// DeleteBefore removes the docs of the conversation whose seq is below minSeq from the index.
+	DeleteBefore(ctx context.Context, tenantID string, conversationID string, minSeq int64) error
+	// Search returns the hits containing every term of the keyword, the most relevant first.
+	Search(ctx context.Context, query *Query) (*Result, error)
+	Close() error
+}
+
+// Factory creates an index from conf, writerID identifies the instance writing the index,
+// it is empty for the instances which only search it.
+type Factory func(conf *config.MsgSearch, writerID string) (MsgSearchIndex, error)
+
+var (
+	factoryLock sync.RWMutex
+	factories   = map[string]Factory{EngineEmbedded: newEmbeddedIndex}
+)
+
+// Register makes an engine available to New, it is usually called in the init function of the engine package.
+func Register(engine string, factory Factory) {
+	factoryLock.Lock()
+	defer factoryLock.Unlock()
+	if _, ok := factories[engine]; ok {
+		panic("msgsearch: engine registered twice: " + engine)
+	}
+	factories[engine] = factory
+}
+
+// New creates the index of conf.Engine, it returns nil when the search is not enabled.
+func New(conf *config.MsgSearch, writerID string) (MsgSearchIndex, error) {
+	if !conf.Enable {
+		return nil, nil
+	}
+	engine := conf.Engine
+	if engine == "" {
+		engine = EngineEmbedded
+	}
+	factoryLock.RLock()
+	factory, ok := factories[engine]
+	factoryLock.RUnlock()
+	if !ok {
+		return nil, errs.New("unknown msg search engine", "engine", engine).Wrap()
+	}
+	return factory(conf, writerID)
+}
@@ -0,0 +1,421 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgsearch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+// A segment is an immutable file of the embedded index:
+//
+//	magic | postings of every term | gob docs | gob dictionary | docs offset | dictionary offset | magic
+//
+// The postings of a term are uvarint pairs of doc index delta and term frequency. The docs and the dictionary
+// are loaded in memory, the postings are read from the file when a term is searched.
+const (
+	segmentMagic      = "OIMSEG01"
+	segmentExt        = ".seg"
+	segmentFooterSize = 16 + len(segmentMagic)
+)
+
+type segmentDoc struct {
+	TenantID       string
+	ConversationID string
+	Seq            int64
+	SendID         string
+	ContentType    int32
+	SendTime       int64
+	// Len is the number of terms of the doc, the relevance of a term is normalized by it.
+	Len int32
+	// Deleted docs have no terms, they hide the older docs of the same key.
+	Deleted bool
+	// Gen is the generation of the flush which wrote the doc, the newest doc of a key wins across writers.
+	// It is 0 in the segments written before it was recorded, their docs take the last generation of the segment.
+	Gen int64
+	// MinSeq is set on the deleted doc of seq 0 which hides the older docs of the conversation whose seq is below it.
+	MinSeq int64
+}
+
+func (d *segmentDoc) key() docKey {
+	return docKey{tenantID: d.TenantID, conversationID: d.ConversationID, seq: d.Seq}
+}
+
+type docKey struct {
+	tenantID       string
+	conversationID string
+	seq            int64
+}
+
+type termEntry struct {
+	Term    string
+	Offset  int64
+	Size    int32
+	DocFreq int32
+}
+
+type posting struct {
+	doc  uint32
+	freq uint32
+}
+
+type segment struct {
+	path string
+	// first and last are the generations of the flushes the segment holds, a merged segment replaces the
+	// segments of every generation in its range.
+	first int64
+	last  int64
+	file  *os.File
+	docs  []*segmentDoc
+	keys  map[docKey]uint32
+	// ranges are the docs deleting the docs of a conversation below their MinSeq.
+	ranges   []*segmentDoc
+	dict     map[string]termEntry
+	totalLen int64
+}
+
+func segmentName(first, last int64) string {
+	return fmt.Sprintf("%020d-%020d%s", first, last, segmentExt)
+}
+
+func parseSegmentName(name string) (first int64, last int64, ok bool) {
+	if !strings.HasSuffix(name, segmentExt) {
+		return 0, 0, false
+	}
+	firstStr, lastStr, ok := strings.Cut(strings.TrimSuffix(name, segmentExt), "-")
+	if !ok {
+		return 0, 0, false
+	}
+	first, err := strconv.ParseInt(firstStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	last, err = strconv.ParseInt(lastStr, 10, 64)
+	if err != nil || last < first {
+		return 0, 0, false
+	}
+	return first, last, true
+}
+
+// covers reports whether o of the same writer is in the range of s, so it has been merged into s.
+func (s *segment) covers(o *segment) bool {
+	if s == o || filepath.Dir(s.path) != filepath.Dir(o.path) {
+		return false
+	}
+	return s.first <= o.first && o.last <= s.last && (s.first != o.first || s.last != o.last)
+}
+
+// gen returns the generation doc of s was written in.
+func (s *segment) gen(doc *segmentDoc) int64 {
+	if doc.Gen != 0 {
+		return doc.Gen
+	}
+	return s.last
+}
+
+func (s *segment) postings(term string) ([]posting, error) {
+	entry, ok := s.dict[term]
+	if !ok {
+		return nil, nil
+	}
+	buf := make([]byte, entry.Size)
+	if _, err := s.file.ReadAt(buf, entry.Offset); err != nil {
+		return nil, errs.WrapMsg(err, "read postings failed", "path", s.path, "term", term)
+	}
+	res := make([]posting, 0, entry.DocFreq)
+	var doc uint64
+	for len(buf) > 0 {
+		delta, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errs.New("corrupted postings", "path", s.path, "term", term).Wrap()
+		}
+		buf = buf[n:]
+		freq, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errs.New("corrupted postings", "path", s.path, "term", term).Wrap()
+		}
+		buf = buf[n:]
+		doc += delta
+		res = append(res, posting{doc: uint32(doc), freq: uint32(freq)})
+	}
+	return res, nil
+}
+
+func (s *segment) close() error {
+	return s.file.Close()
+}
+
+func openSegment(path string) (*segment, error) {
+	first, last, ok := parseSegmentName(filepath.Base(path))
+	if !ok {
+		return nil, errs.New("invalid segment name", "path", path).Wrap()
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "open segment failed", "path", path)
+	}
+	seg, err := readSegment(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, errs.WrapMsg(err, "read segment failed", "path", path)
+	}
+	seg.path, seg.first, seg.last = path, first, last
+	return seg, nil
+}
+
+func readSegment(file *os.File) (*segment, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < int64(len(segmentMagic)+segmentFooterSize) {
+		return nil, errs.New("segment too small")
+	}
+	footer := make([]byte, segmentFooterSize)
+	if _, err := file.ReadAt(footer, size-int64(segmentFooterSize)); err != nil {
+		return nil, err
+	}
+	if string(footer[16:]) != segmentMagic {
+		return nil, errs.New("invalid segment magic")
+	}
+	docsOffset := int64(binary.BigEndian.Uint64(footer[0:8]))
+	dictOffset := int64(binary.BigEndian.Uint64(footer[8:16]))
+	if docsOffset < int64(len(segmentMagic)) || dictOffset < docsOffset || dictOffset > size-int64(segmentFooterSize) {
+		return nil, errs.New("invalid segment footer")
+	}
+	seg := &segment{file: file}
+	if err := gob.NewDecoder(io.NewSectionReader(file, docsOffset, dictOffset-docsOffset)).Decode(&seg.docs); err != nil {
+		return nil, err
+	}
+	var entries []termEntry
+	if err := gob.NewDecoder(io.NewSectionReader(file, dictOffset, size-int64(segmentFooterSize)-dictOffset)).Decode(&entries); err != nil {
+		return nil, err
+	}
+	seg.dict = make(map[string]termEntry, len(entries))
+	for _, entry := range entries {
+		seg.dict[entry.Term] = entry
+	}
+	seg.keys = make(map[docKey]uint32, len(seg.docs))
+	for i, doc := range seg.docs {
+		seg.keys[doc.key()] = uint32(i)
+		seg.totalLen += int64(doc.Len)
+		if doc.MinSeq > 0 {
+			seg.ranges = append(seg.ranges, doc)
+		}
+	}
+	return seg, nil
+}
+
+// segmentWriter writes a segment to a temporary file, the terms must be added in ascending order.
+type segmentWriter struct {
+	path    string
+	file    *os.File
+	w       *bufio.Writer
+	offset  int64
+	entries []termEntry
+	buf     []byte
+}
+
+func newSegmentWriter(path string) (*segmentWriter, error) {
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, errs.WrapMsg(err, "create segment failed", "path", path)
+	}
+	w := &segmentWriter{path: path, file: file, w: bufio.NewWriter(file)}
+	if _, err := w.w.WriteString(segmentMagic); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "write segment failed", "path", path)
+	}
+	w.offset = int64(len(segmentMagic))
+	return w, nil
+}
+
+func (w *segmentWriter) addTerm(term string, postings []posting) error {
+	if len(postings) == 0 {
+		return nil
+	}
+	w.buf = w.buf[:0]
+	var prev uint32
+	for _, p := range postings {
+		w.buf = binary.AppendUvarint(w.buf, uint64(p.doc-prev))
+		w.buf = binary.AppendUvarint(w.buf, uint64(p.freq))
+		prev = p.doc
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		return errs.WrapMsg(err, "write segment failed", "path", w.path)
+	}
+	w.entries = append(w.entries, termEntry{Term: term, Offset: w.offset, Size: int32(len(w.buf)), DocFreq: int32(len(postings))})
+	w.offset += int64(len(w.buf))
+	return nil
+}
+
+// finish writes the docs and the dictionary, then renames the temporary file to path so the segment
+// appears completely or not at all.
+func (w *segmentWriter) finish(docs []*segmentDoc) (*segment, error) {
+	var section bytes.Buffer
+	if err := gob.NewEncoder(&section).Encode(docs); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "encode segment docs failed", "path", w.path)
+	}
+	docsOffset := w.offset
+	dictOffset := docsOffset + int64(section.Len())
+	if err := gob.NewEncoder(&section).Encode(w.entries); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "encode segment dictionary failed", "path", w.path)
+	}
+	footer := make([]byte, 16, segmentFooterSize)
+	binary.BigEndian.PutUint64(footer[0:8], uint64(docsOffset))
+	binary.BigEndian.PutUint64(footer[8:16], uint64(dictOffset))
+	footer = append(footer, segmentMagic...)
+	if _, err := w.w.Write(section.Bytes()); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "write segment failed", "path", w.path)
+	}
+	if _, err := w.w.Write(footer); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "write segment failed", "path", w.path)
+	}
+	if err := w.w.Flush(); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "write segment failed", "path", w.path)
+	}
+	if err := w.file.Sync(); err != nil {
+		w.abort()
+		return nil, errs.WrapMsg(err, "sync segment failed", "path", w.path)
+	}
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return nil, errs.WrapMsg(err, "close segment failed", "path", w.path)
+	}
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		_ = os.Remove(w.file.Name())
+		return nil, errs.WrapMsg(err, "rename segment failed", "path", w.path)
+	}
+	return openSegment(w.path)
+}
+
+func (w *segmentWriter) abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// writeSegment writes docs whose terms are docTerms as a new segment.
+func writeSegment(path string, docs []*segmentDoc, docTerms [][]string) (*segment, error) {
+	postings := make(map[string][]posting)
+	for i, terms := range docTerms {
+		freqs := make(map[string]uint32, len(terms))
+		for _, term := range terms {
+			freqs[term]++
+		}
+		for term, freq := range freqs {
+			postings[term] = append(postings[term], posting{doc: uint32(i), freq: freq})
+		}
+	}
+	terms := make([]string, 0, len(postings))
+	for term := range postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	w, err := newSegmentWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, term := range terms {
+		if err := w.addTerm(term, postings[term]); err != nil {
+			w.abort()
+			return nil, err
+		}
+	}
+	return w.finish(docs)
+}
+
+// mergeSegments writes the docs of segs, ordered from old to new, into one segment,
+// a doc replaced by a newer doc of the same key is dropped.
+func mergeSegments(path string, segs []*segment) (*segment, error) {
+	latest := make(map[docKey][2]int)
+	minSeqs := make(map[docKey]int64)
+	for i, seg := range segs {
+		for j, doc := range seg.docs {
+			latest[doc.key()] = [2]int{i, j}
+			if doc.MinSeq > 0 {
+				minSeqs[doc.key()] = max(minSeqs[doc.key()], doc.MinSeq)
+			}
+		}
+	}
+	remap := make([]map[uint32]uint32, len(segs))
+	var docs []*segmentDoc
+	for i, seg := range segs {
+		remap[i] = make(map[uint32]uint32)
+		for j, doc := range seg.docs {
+			if latest[doc.key()] != [2]int{i, j} {
+				continue
+			}
+			merged := *doc
+			merged.Gen = seg.gen(doc)
+			if merged.MinSeq > 0 {
+				merged.MinSeq = minSeqs[doc.key()]
+			}
+			remap[i][uint32(j)] = uint32(len(docs))
+			docs = append(docs, &merged)
+		}
+	}
+	termSet := make(map[string]struct{})
+	for _, seg := range segs {
+		for term := range seg.dict {
+			termSet[term] = struct{}{}
+		}
+	}
+	terms := make([]string, 0, len(termSet))
+	for term := range termSet {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	w, err := newSegmentWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, term := range terms {
+		var merged []posting
+		for i, seg := range segs {
+			postings, err := seg.postings(term)
+			if err != nil {
+				w.abort()
+				return nil, err
+			}
+			for _, p := range postings {
+				if doc, ok := remap[i][p.doc]; ok {
+					merged = append(merged, posting{doc: doc, freq: p.freq})
+				}
+			}
+		}
+		if err := w.addTerm(term, merged); err != nil {
+			w.abort()
+			return nil, err
+		}
+	}
+	return w.finish(docs)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgsearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTermLen is the max length in bytes of an indexed word, longer ones like urls are not indexed.
+const maxTermLen = 64
+
+// Tokenize splits text into the terms it is indexed by, a term appears once for every occurrence.
+// Words of letters and digits are lower cased. Runs of CJK characters, which have no spaces between words,
+// are indexed by every character and every pair of adjacent characters.
+func Tokenize(text string) []string {
+	return tokenize(text, false)
+}
+
+// QueryTerms returns the distinct terms a keyword is searched by. A run of CJK characters is searched by
+// its pairs of adjacent characters, a single CJK character by itself.
+func QueryTerms(keyword string) []string {
+	terms := tokenize(keyword, true)
+	res := make([]string, 0, len(terms))
+	seen := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		res = append(res, term)
+	}
+	return res
+}
+
+// Match reports whether text contains every term of keyword,
+// it checks hits against the current text of messages edited after they were indexed.
+func Match(keyword string, text string) bool {
+	terms := QueryTerms(keyword)
+	if len(terms) == 0 {
+		return false
+	}
+	textTerms := make(map[string]struct{})
+	for _, term := range Tokenize(text) {
+		textTerms[term] = struct{}{}
+	}
+	for _, term := range terms {
+		if _, ok := textTerms[term]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func tokenize(text string, query bool) []string {
+	var (
+		terms []string
+		word  strings.Builder
+		run   []rune
+	)
+	flushWord := func() {
+		if word.Len() > 0 && word.Len() <= maxTermLen {
+			terms = append(terms, word.String())
+		}
+		word.Reset()
+	}
+	flushRun := func() {
+		switch {
+		case len(run) == 0:
+		case len(run) == 1:
+			terms = append(terms, string(run))
+		case query:
+			for i := 0; i+1 < len(run); i++ {
+				terms = append(terms, string(run[i:i+2]))
+			}
+		default:
+			for i := range run {
+				terms = append(terms, string(run[i]))
+				if i+1 < len(run) {
+					terms = append(terms, string(run[i:i+2]))
+				}
+			}
+		}
+		run = run[:0]
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushRun()
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flushWord()
+			flushRun()
+		}
+	}
+	flushWord()
+	flushRun()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
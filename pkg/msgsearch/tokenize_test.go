@@ -0,0 +1,42 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgsearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"hello", "world", "42"}, Tokenize("Hello, WORLD! 42"))
+	assert.Equal(t, []string{"你", "你好", "好", "好世", "世", "世界", "界", "go"}, Tokenize("你好世界go"))
+	assert.Empty(t, Tokenize(" ,.!"))
+	assert.Equal(t, []string{"https", "example", "com"}, Tokenize("https://example.com"))
+	assert.Empty(t, Tokenize(strings.Repeat("a", maxTermLen+1)))
+}
+
+func TestQueryTerms(t *testing.T) {
+	assert.Equal(t, []string{"你好", "好世", "世界"}, QueryTerms("你好世界 你好"))
+	assert.Equal(t, []string{"好", "go"}, QueryTerms("好 Go go"))
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, Match("world hello", "Hello world!"))
+	assert.True(t, Match("世界", "你好世界"))
+	assert.False(t, Match("hello moon", "Hello world!"))
+	assert.False(t, Match("", "Hello world!"))
+}
@@ -0,0 +1,193 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notifypref decides from the notification setting of a user whether a message is notified to the user
+// by an offline push, and on which platforms the notification shows the content of the message.
+package notifypref
+
+import (
+	"strings"
+	"sync"
+	"time"
+	// the time zones of the settings are loaded even where the system has no time zone database
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+const (
+	MaxDNDWindows = 10
+	MaxKeywords   = 50
+	// MaxKeywordLength is the maximum number of characters of a keyword.
+	MaxKeywordLength = 64
+)
+
+const minutesPerDay = 24 * 60
+
+// Check validates setting before it is stored.
+func Check(setting *model.NotificationSetting) error {
+	if _, err := loadLocation(setting.TimeZone); err != nil {
+		return errs.ErrArgs.WrapMsg("timeZone is invalid", "timeZone", setting.TimeZone)
+	}
+	if len(setting.DNDWindows) > MaxDNDWindows {
+		return errs.ErrArgs.WrapMsg("too many dndWindows", "max", MaxDNDWindows)
+	}
+	for _, window := range setting.DNDWindows {
+		if _, err := parseClock(window.Start); err != nil {
+			return err
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return err
+		}
+		for _, weekday := range window.Weekdays {
+			if weekday < int32(time.Sunday) || weekday > int32(time.Saturday) {
+				return errs.ErrArgs.WrapMsg("weekday is invalid", "weekday", weekday)
+			}
+		}
+	}
+	if len(setting.Keywords) > MaxKeywords {
+		return errs.ErrArgs.WrapMsg("too many keywords", "max", MaxKeywords)
+	}
+	for _, keyword := range setting.Keywords {
+		if strings.TrimSpace(keyword) == "" {
+			return errs.ErrArgs.WrapMsg("keyword is empty")
+		}
+		if utf8.RuneCountInString(keyword) > MaxKeywordLength {
+			return errs.ErrArgs.WrapMsg("keyword is too long", "keyword", keyword, "max", MaxKeywordLength)
+		}
+	}
+	for _, platformID := range setting.HidePreviewPlatformIDs {
+		if !datautil.Contain(int(platformID), thirdext.DevicePlatformIDs...) {
+			return errs.ErrArgs.WrapMsg("platformID does not support offline push", "platformID", platformID)
+		}
+	}
+	return nil
+}
+
+// parseClock returns the minute of the day of a "15:04" time.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, errs.ErrArgs.WrapMsg("time of the day is not HH:MM", "time", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+var locations sync.Map
+
+// loadLocation returns the time zone named name, UTC when it is empty.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// InDoNotDisturb reports whether now is in one of the do-not-disturb windows of setting, in its time zone.
+func InDoNotDisturb(setting *model.NotificationSetting, now time.Time) bool {
+	if setting == nil || len(setting.DNDWindows) == 0 {
+		return false
+	}
+	loc, err := loadLocation(setting.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	minute := now.Hour()*60 + now.Minute()
+	today := int32(now.Weekday())
+	yesterday := (today + 6) % 7
+	for _, window := range setting.DNDWindows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			continue
+		}
+		if start < end {
+			if minute >= start && minute < end && startsOn(window, today) {
+				return true
+			}
+			continue
+		}
+		// the window ends the next day, a window ending when it starts lasts the whole day
+		if (minute >= start && startsOn(window, today)) || (minute < end && startsOn(window, yesterday)) {
+			return true
+		}
+	}
+	return false
+}
+
+func startsOn(window model.DNDWindow, weekday int32) bool {
+	if len(window.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range window.Weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchKeyword returns the first keyword of setting which text contains, ignoring case.
+func MatchKeyword(setting *model.NotificationSetting, text string) (string, bool) {
+	if setting == nil || text == "" {
+		return "", false
+	}
+	text = strings.ToLower(text)
+	for _, keyword := range setting.Keywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return keyword, true
+		}
+	}
+	return "", false
+}
+
+// Message is what the notification settings look at in a message pushed to a user.
+type Message struct {
+	Text    string
+	IsGroup bool
+	// Mentioned is true when the message mentions the user or everyone.
+	Mentioned bool
+}
+
+// ShouldNotify reports whether msg is notified at now to the user of setting, every message is notified
+// when setting is nil. A message containing a keyword is notified even when it is a group message which
+// does not mention the user of a mention-only setting, or when now is in a do-not-disturb window.
+func ShouldNotify(setting *model.NotificationSetting, msg Message, now time.Time) bool {
+	if setting == nil {
+		return true
+	}
+	if _, ok := MatchKeyword(setting, msg.Text); ok {
+		return true
+	}
+	if msg.IsGroup && setting.GroupMentionOnly && !msg.Mentioned {
+		return false
+	}
+	return !InDoNotDisturb(setting, now)
+}
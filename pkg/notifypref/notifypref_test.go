@@ -0,0 +1,94 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifypref
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	valid := &model.NotificationSetting{
+		TimeZone:               "Asia/Shanghai",
+		DNDWindows:             []model.DNDWindow{{Start: "22:00", End: "07:30", Weekdays: []int32{0, 6}}},
+		Keywords:               []string{"urgent"},
+		HidePreviewPlatformIDs: []int32{1},
+	}
+	assert.NoError(t, Check(valid))
+	assert.NoError(t, Check(&model.NotificationSetting{}))
+	assert.Error(t, Check(&model.NotificationSetting{TimeZone: "Mars/Olympus"}))
+	assert.Error(t, Check(&model.NotificationSetting{DNDWindows: []model.DNDWindow{{Start: "24:00", End: "07:00"}}}))
+	assert.Error(t, Check(&model.NotificationSetting{DNDWindows: []model.DNDWindow{{Start: "22:00", End: "7"}}}))
+	assert.Error(t, Check(&model.NotificationSetting{DNDWindows: []model.DNDWindow{{Start: "22:00", End: "07:00", Weekdays: []int32{7}}}}))
+	assert.Error(t, Check(&model.NotificationSetting{Keywords: []string{" "}}))
+	assert.Error(t, Check(&model.NotificationSetting{HidePreviewPlatformIDs: []int32{100}}))
+}
+
+func TestInDoNotDisturb(t *testing.T) {
+	setting := &model.NotificationSetting{
+		TimeZone: "Asia/Shanghai",
+		DNDWindows: []model.DNDWindow{
+			// Friday and Saturday nights
+			{Start: "23:00", End: "08:00", Weekdays: []int32{5, 6}},
+			{Start: "12:00", End: "13:00"},
+		},
+	}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	at := func(day, hour, minute int) time.Time {
+		// 2024-03-01 is a Friday
+		return time.Date(2024, 3, day, hour, minute, 0, 0, shanghai)
+	}
+	assert.True(t, InDoNotDisturb(setting, at(1, 23, 0)))
+	assert.True(t, InDoNotDisturb(setting, at(2, 7, 59)))
+	assert.False(t, InDoNotDisturb(setting, at(2, 8, 0)))
+	assert.True(t, InDoNotDisturb(setting, at(3, 1, 0)))
+	// the window starting on Sunday night is not set
+	assert.False(t, InDoNotDisturb(setting, at(3, 23, 30)))
+	assert.False(t, InDoNotDisturb(setting, at(4, 1, 0)))
+	assert.True(t, InDoNotDisturb(setting, at(4, 12, 30)))
+	assert.False(t, InDoNotDisturb(setting, at(4, 13, 0)))
+	// the windows are in the time zone of the setting
+	assert.True(t, InDoNotDisturb(setting, at(1, 23, 0).UTC()))
+	assert.False(t, InDoNotDisturb(&model.NotificationSetting{DNDWindows: setting.DNDWindows}, at(1, 23, 0)))
+
+	allDay := &model.NotificationSetting{DNDWindows: []model.DNDWindow{{Start: "00:00", End: "00:00"}}}
+	assert.True(t, InDoNotDisturb(allDay, time.Now()))
+	assert.False(t, InDoNotDisturb(nil, time.Now()))
+}
+
+func TestShouldNotify(t *testing.T) {
+	setting := &model.NotificationSetting{
+		DNDWindows:       []model.DNDWindow{{Start: "22:00", End: "07:00"}},
+		Keywords:         []string{"Deploy"},
+		GroupMentionOnly: true,
+	}
+	day := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	assert.True(t, ShouldNotify(setting, Message{Text: "hello"}, day))
+	assert.False(t, ShouldNotify(setting, Message{Text: "hello"}, night))
+	assert.True(t, ShouldNotify(setting, Message{Text: "the deploy failed"}, night))
+
+	assert.False(t, ShouldNotify(setting, Message{Text: "hello", IsGroup: true}, day))
+	assert.True(t, ShouldNotify(setting, Message{Text: "hello", IsGroup: true, Mentioned: true}, day))
+	assert.False(t, ShouldNotify(setting, Message{Text: "hello", IsGroup: true, Mentioned: true}, night))
+	assert.True(t, ShouldNotify(setting, Message{Text: "DEPLOY now", IsGroup: true}, night))
+
+	assert.True(t, ShouldNotify(nil, Message{IsGroup: true}, night))
+}
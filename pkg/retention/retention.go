@@ -0,0 +1,168 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retention decides how long the messages of a conversation are kept and whether they are under a legal
+// hold, from the policies set on the conversation, on its group and on its users.
+package retention
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+)
+
+const (
+	TargetConversation = 1
+	TargetGroup        = 2
+	// TargetUser applies to every conversation of the user.
+	TargetUser = 3
+)
+
+// IsTargetType reports whether targetType is one of the target types.
+func IsTargetType(targetType int32) bool {
+	return targetType == TargetConversation || targetType == TargetGroup || targetType == TargetUser
+}
+
+type Target struct {
+	Type int32
+	ID   string
+}
+
+const threadConversationPrefix = "th_"
+
+// Targets returns the targets whose policies apply to conversationID, from the most specific one:
+// the conversation, its group and its users. A thread belongs to the conversation it is anchored in.
+// The users of a single chat can not be told apart when user ids contain '_', so every split is returned.
+func Targets(conversationID string) []Target {
+	targets := []Target{{Type: TargetConversation, ID: conversationID}}
+	if strings.HasPrefix(conversationID, threadConversationPrefix) {
+		if i := strings.LastIndexByte(conversationID, '_'); i > len(threadConversationPrefix) {
+			if _, err := strconv.ParseInt(conversationID[i+1:], 10, 64); err == nil {
+				conversationID = conversationID[len(threadConversationPrefix):i]
+				targets = append(targets, Target{Type: TargetConversation, ID: conversationID})
+			}
+		}
+	}
+	prefix, rest, ok := strings.Cut(conversationID, "_")
+	if !ok || rest == "" {
+		return targets
+	}
+	switch prefix {
+	case "sg", "g":
+		return append(targets, Target{Type: TargetGroup, ID: rest})
+	case "n":
+		// A notification conversation is either of a group or of two users.
+		targets = append(targets, Target{Type: TargetGroup, ID: rest})
+		return append(targets, userTargets(rest)...)
+	case "si", "sn":
+		return append(targets, userTargets(rest)...)
+	default:
+		return targets
+	}
+}
+
+// userTargets returns the users of ids, the user ids joined by '_'.
+func userTargets(ids string) []Target {
+	if !strings.Contains(ids, "_") {
+		return []Target{{Type: TargetUser, ID: ids}}
+	}
+	var targets []Target
+	for i := 0; i < len(ids); i++ {
+		if ids[i] != '_' || i == 0 || i == len(ids)-1 {
+			continue
+		}
+		targets = append(targets, Target{Type: TargetUser, ID: ids[:i]}, Target{Type: TargetUser, ID: ids[i+1:]})
+	}
+	return targets
+}
+
+// Rule is what the policies of a conversation resolve to.
+type Rule struct {
+	// RetainDays is the number of days the messages are kept, 0 means the global retention applies.
+	RetainDays int64
+	LegalHold  bool
+	HoldReason string
+}
+
+// Cutoff returns the send time in milliseconds before which the messages can be cleared,
+// globalCutoff is the one of the global retention.
+func (r Rule) Cutoff(now time.Time, globalCutoff int64) int64 {
+	if r.RetainDays <= 0 {
+		return globalCutoff
+	}
+	return now.Add(-time.Duration(r.RetainDays) * time.Hour * 24).UnixMilli()
+}
+
+// Reason describes why the deletion of the messages of a conversation with the rule is skipped.
+func (r Rule) Reason() string {
+	if r.LegalHold {
+		if r.HoldReason == "" {
+			return "legal hold"
+		}
+		return "legal hold: " + r.HoldReason
+	}
+	return "retained for " + strconv.FormatInt(r.RetainDays, 10) + " days"
+}
+
+type Resolver struct {
+	policies map[Target]*model.RetentionPolicy
+}
+
+func NewResolver(policies []*model.RetentionPolicy) *Resolver {
+	r := &Resolver{policies: make(map[Target]*model.RetentionPolicy, len(policies))}
+	for _, policy := range policies {
+		r.policies[Target{Type: policy.TargetType, ID: policy.TargetID}] = policy
+	}
+	return r
+}
+
+// Resolve combines the policies which apply to conversationID. A legal hold on any of them holds the conversation.
+// The retention of the conversation wins over the one of its group, which wins over the longest one of its users.
+func (r *Resolver) Resolve(conversationID string) Rule {
+	var (
+		rule                                  Rule
+		conversationDays, groupDays, userDays int64
+	)
+	for _, target := range Targets(conversationID) {
+		policy, ok := r.policies[target]
+		if !ok {
+			continue
+		}
+		if policy.LegalHold && !rule.LegalHold {
+			rule.LegalHold = true
+			rule.HoldReason = policy.HoldReason
+		}
+		switch target.Type {
+		case TargetConversation:
+			if conversationDays == 0 {
+				conversationDays = policy.RetainDays
+			}
+		case TargetGroup:
+			groupDays = max(groupDays, policy.RetainDays)
+		case TargetUser:
+			userDays = max(userDays, policy.RetainDays)
+		}
+	}
+	switch {
+	case conversationDays > 0:
+		rule.RetainDays = conversationDays
+	case groupDays > 0:
+		rule.RetainDays = groupDays
+	default:
+		rule.RetainDays = userDays
+	}
+	return rule
+}
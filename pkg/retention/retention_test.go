@@ -0,0 +1,55 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/common/storage/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargets(t *testing.T) {
+	assert.Equal(t, []Target{{TargetConversation, "sg_g1"}, {TargetGroup, "g1"}}, Targets("sg_g1"))
+	assert.Equal(t, []Target{{TargetConversation, "si_a_b"}, {TargetUser, "a"}, {TargetUser, "b"}}, Targets("si_a_b"))
+	assert.Equal(t, []Target{
+		{TargetConversation, "th_sg_g1_12"}, {TargetConversation, "sg_g1"}, {TargetGroup, "g1"},
+	}, Targets("th_sg_g1_12"))
+	assert.ElementsMatch(t, []Target{
+		{TargetConversation, "si_a_b_c"}, {TargetUser, "a"}, {TargetUser, "b_c"}, {TargetUser, "a_b"}, {TargetUser, "c"},
+	}, Targets("si_a_b_c"))
+	assert.Equal(t, []Target{{TargetConversation, "x"}}, Targets("x"))
+}
+
+func TestResolve(t *testing.T) {
+	r := NewResolver([]*model.RetentionPolicy{
+		{TargetType: TargetUser, TargetID: "a", RetainDays: 30},
+		{TargetType: TargetUser, TargetID: "b", RetainDays: 90},
+		{TargetType: TargetGroup, TargetID: "g1", RetainDays: 10},
+		{TargetType: TargetConversation, TargetID: "sg_g1", RetainDays: 400},
+		{TargetType: TargetUser, TargetID: "c", LegalHold: true, HoldReason: "case 7"},
+	})
+	assert.Equal(t, Rule{RetainDays: 90}, r.Resolve("si_a_b"))
+	assert.Equal(t, Rule{RetainDays: 400}, r.Resolve("sg_g1"))
+	assert.Equal(t, Rule{RetainDays: 400}, r.Resolve("th_sg_g1_3"))
+	assert.Equal(t, Rule{RetainDays: 30, LegalHold: true, HoldReason: "case 7"}, r.Resolve("si_a_c"))
+	assert.Equal(t, Rule{}, r.Resolve("si_d_e"))
+	assert.Equal(t, "legal hold: case 7", r.Resolve("si_a_c").Reason())
+
+	now := time.Now()
+	assert.Equal(t, int64(5), Rule{}.Cutoff(now, 5))
+	assert.Equal(t, now.Add(-time.Hour*24*2).UnixMilli(), Rule{RetainDays: 2}.Cutoff(now, 5))
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/conversationext"
 	pbconversation "github.com/openimsdk/protocol/conversation"
 	"github.com/openimsdk/tools/discovery"
 	"github.com/openimsdk/tools/errs"
@@ -26,9 +27,10 @@ import (
 )
 
 type Conversation struct {
-	Client pbconversation.ConversationClient
-	conn   grpc.ClientConnInterface
-	discov discovery.SvcDiscoveryRegistry
+	Client    pbconversation.ConversationClient
+	ExtClient conversationext.ConversationExtClient
+	conn      grpc.ClientConnInterface
+	discov    discovery.SvcDiscoveryRegistry
 }
 
 func NewConversation(discov discovery.SvcDiscoveryRegistry, rpcRegisterName string) *Conversation {
@@ -37,7 +39,7 @@ func NewConversation(discov discovery.SvcDiscoveryRegistry, rpcRegisterName stri
 		program.ExitWithError(err)
 	}
 	client := pbconversation.NewConversationClient(conn)
-	return &Conversation{discov: discov, conn: conn, Client: client}
+	return &Conversation{discov: discov, conn: conn, Client: client, ExtClient: conversationext.NewConversationExtClient(conn)}
 }
 
 type ConversationRpcClient Conversation
@@ -20,9 +20,10 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/conversationext"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/msgext"
 	"github.com/openimsdk/protocol/constant"
 	"github.com/openimsdk/protocol/msg"
 	"github.com/openimsdk/protocol/sdkws"
@@ -76,10 +77,14 @@ func newContentTypeConf(conf *config.Notification) map[int32]config.Notification
 		constant.ConversationChangeNotification:      conf.ConversationChanged,
 		constant.ConversationUnreadNotification:      conf.ConversationChanged,
 		constant.ConversationPrivateChatNotification: conf.ConversationSetPrivate,
+		conversationext.PinnedMsgNotification:        {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
 		// msg
-		constant.MsgRevokeNotification:  {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
-		constant.HasReadReceipt:         {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
-		constant.DeleteMsgsNotification: {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
+		constant.MsgRevokeNotification:       {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
+		constant.HasReadReceipt:              {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
+		constant.DeleteMsgsNotification:      {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
+		msgext.MsgEditNotification:           {IsSendMsg: false, ReliabilityLevel: constant.ReliableNotificationNoMsg},
+		msgext.MsgReactionNotification:       {IsSendMsg: false, ReliabilityLevel: constant.UnreliableNotification},
+		msgext.GroupMsgReadCountNotification: {IsSendMsg: false, ReliabilityLevel: constant.UnreliableNotification},
 	}
 }
 
@@ -130,9 +135,10 @@ func newSessionTypeConf() map[int32]int32 {
 }
 
 type Message struct {
-	conn   grpc.ClientConnInterface
-	Client msg.MsgClient
-	discov discovery.SvcDiscoveryRegistry
+	conn      grpc.ClientConnInterface
+	Client    msg.MsgClient
+	ExtClient msgext.MsgExtClient
+	discov    discovery.SvcDiscoveryRegistry
 }
 
 func NewMessage(discov discovery.SvcDiscoveryRegistry, rpcRegisterName string) *Message {
@@ -141,7 +147,7 @@ func NewMessage(discov discovery.SvcDiscoveryRegistry, rpcRegisterName string) *
 		program.ExitWithError(err)
 	}
 	client := msg.NewMsgClient(conn)
-	return &Message{discov: discov, conn: conn, Client: client}
+	return &Message{discov: discov, conn: conn, Client: client, ExtClient: msgext.NewMsgExtClient(conn)}
 }
 
 type MessageRpcClient Message
@@ -311,7 +317,7 @@ func WithRpcGetUserName() NotificationOptions {
 	}
 }
 
-func (s *NotificationSender) send(ctx context.Context, sendID, recvID string, contentType, sessionType int32, m proto.Message, opts ...NotificationOptions) {
+func (s *NotificationSender) send(ctx context.Context, sendID, recvID string, contentType, sessionType int32, m any, opts ...NotificationOptions) {
 	//ctx = mcontext.WithMustInfoCtx([]string{mcontext.GetOperationID(ctx), mcontext.GetOpUserID(ctx), mcontext.GetOpUserPlatform(ctx), mcontext.GetConnID(ctx)})
 	ctx = context.WithoutCancel(ctx)
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(5))
@@ -369,13 +375,13 @@ func (s *NotificationSender) send(ctx context.Context, sendID, recvID string, co
 	}
 }
 
-func (s *NotificationSender) NotificationWithSessionType(ctx context.Context, sendID, recvID string, contentType, sessionType int32, m proto.Message, opts ...NotificationOptions) {
+func (s *NotificationSender) NotificationWithSessionType(ctx context.Context, sendID, recvID string, contentType, sessionType int32, m any, opts ...NotificationOptions) {
 	if err := s.queue.Push(func() { s.send(ctx, sendID, recvID, contentType, sessionType, m, opts...) }); err != nil {
 		log.ZWarn(ctx, "Push to queue failed", err, "sendID", sendID, "recvID", recvID, "msg", jsonutil.StructToJsonString(m))
 	}
 }
 
-func (s *NotificationSender) Notification(ctx context.Context, sendID, recvID string, contentType int32, m proto.Message, opts ...NotificationOptions) {
+func (s *NotificationSender) Notification(ctx context.Context, sendID, recvID string, contentType int32, m any, opts ...NotificationOptions) {
 	s.NotificationWithSessionType(ctx, sendID, recvID, contentType, s.sessionTypeConf[contentType], m, opts...)
 }
 
@@ -17,6 +17,7 @@ package rpcclient
 import (
 	"context"
 
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/thirdext"
 	"github.com/openimsdk/protocol/third"
 	"github.com/openimsdk/tools/discovery"
 	"github.com/openimsdk/tools/system/program"
@@ -26,6 +27,7 @@ import (
 type Third struct {
 	conn       grpc.ClientConnInterface
 	Client     third.ThirdClient
+	ExtClient  thirdext.ThirdExtClient
 	discov     discovery.SvcDiscoveryRegistry
 	GrafanaUrl string
 }
@@ -39,7 +41,7 @@ func NewThird(discov discovery.SvcDiscoveryRegistry, rpcRegisterName, grafanaUrl
 	if err != nil {
 		program.ExitWithError(err)
 	}
-	return &Third{discov: discov, Client: client, conn: conn, GrafanaUrl: grafanaUrl}
+	return &Third{discov: discov, Client: client, ExtClient: thirdext.NewThirdExtClient(conn), conn: conn, GrafanaUrl: grafanaUrl}
 }
 func (t *Third) DeleteOutdatedData(ctx context.Context, expires int64) error {
 	_, err := t.Client.DeleteOutdatedData(ctx, &third.DeleteOutdatedDataReq{ExpireTime: expires})
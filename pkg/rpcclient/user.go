@@ -20,9 +20,11 @@ import (
 
 	"github.com/openimsdk/open-im-server/v3/pkg/authverify"
 	"github.com/openimsdk/open-im-server/v3/pkg/common/servererrs"
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext/userext"
 	"github.com/openimsdk/protocol/sdkws"
 	"github.com/openimsdk/protocol/user"
 	"github.com/openimsdk/tools/discovery"
+	"github.com/openimsdk/tools/mcontext"
 	"github.com/openimsdk/tools/system/program"
 	"github.com/openimsdk/tools/utils/datautil"
 	"google.golang.org/grpc"
@@ -32,6 +34,7 @@ import (
 type User struct {
 	conn                  grpc.ClientConnInterface
 	Client                user.UserClient
+	ExtClient             userext.UserExtClient
 	Discov                discovery.SvcDiscoveryRegistry
 	MessageGateWayRpcName string
 	imAdminUserID         []string
@@ -45,7 +48,7 @@ func NewUser(discov discovery.SvcDiscoveryRegistry, rpcRegisterName, messageGate
 		program.ExitWithError(err)
 	}
 	client := user.NewUserClient(conn)
-	return &User{Discov: discov, Client: client,
+	return &User{Discov: discov, Client: client, ExtClient: userext.NewUserExtClient(conn),
 		conn:                  conn,
 		MessageGateWayRpcName: messageGateWayRpcName,
 		imAdminUserID:         imAdminUserID}
@@ -229,3 +232,27 @@ func (u *UserRpcClient) GetUserOnlinePlatform(ctx context.Context, userID string
 func (u *UserRpcClient) GetAllOnlineUsers(ctx context.Context, cursor uint64) (*user.GetAllOnlineUsersResp, error) {
 	return u.Client.GetAllOnlineUsers(ctx, &user.GetAllOnlineUsersReq{Cursor: cursor})
 }
+
+// GetNotificationSettings retrieves the notification settings of userIDs as an admin, keyed by user ID.
+// The users who never changed their setting have none.
+func (u *UserRpcClient) GetNotificationSettings(ctx context.Context, userIDs []string) (map[string]*userext.NotificationSetting, error) {
+	settings := make(map[string]*userext.NotificationSetting)
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+	if imAdminUserID := authverify.AdminUserID(ctx, u.imAdminUserID); len(imAdminUserID) > 0 {
+		ctx = mcontext.WithOpUserIDContext(ctx, imAdminUserID[0])
+	}
+	for i := 0; i < len(userIDs); i += userext.MaxNotificationSettingUsers {
+		resp, err := u.ExtClient.GetNotificationSettings(ctx, &userext.GetNotificationSettingsReq{
+			UserIDs: userIDs[i:min(i+userext.MaxNotificationSettingUsers, len(userIDs))],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, setting := range resp.Settings {
+			settings[setting.UserID] = setting
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,56 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpcext hosts the rpc services of the server that are not part of the protocol module.
+// Their messages are plain go structs sent with a json codec, next to the protobuf services on the same grpc servers.
+package rpcext
+
+import (
+	"encoding/json"
+
+	"github.com/openimsdk/tools/errs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the content subtype of the extension services, the requests are sent as application/grpc+json.
+const Name = "json"
+
+// CallOption selects the json codec, the clients of the extension services append it to every call.
+var CallOption = grpc.CallContentSubtype(Name)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errs.WrapMsg(err, "rpcext json marshal failed")
+	}
+	return data, nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return errs.WrapMsg(err, "rpcext json unmarshal failed")
+	}
+	return nil
+}
+
+func (codec) Name() string {
+	return Name
+}
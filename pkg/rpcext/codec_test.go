@@ -0,0 +1,40 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestCodec(t *testing.T) {
+	c := encoding.GetCodec(Name)
+	assert.NotNil(t, c)
+
+	type req struct {
+		UserID string `json:"userID"`
+		Seq    int64  `json:"seq"`
+	}
+	data, err := c.Marshal(&req{UserID: "u1", Seq: 3})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"userID":"u1","seq":3}`, string(data))
+
+	var out req
+	assert.NoError(t, c.Unmarshal(data, &out))
+	assert.Equal(t, req{UserID: "u1", Seq: 3}, out)
+	assert.Error(t, c.Unmarshal([]byte("{"), &out))
+}
@@ -0,0 +1,98 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversationext
+
+import (
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// PinnedMsgNotification is the content type of the notification sent when a message is pinned or unpinned,
+// its detail is PinnedMsgTips.
+const PinnedMsgNotification = 2105
+
+type PinMsgReq struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	UserID         string `json:"userID"`
+}
+
+func (x *PinMsgReq) Check() error {
+	return checkPinMsg(x.ConversationID, x.Seq, x.UserID)
+}
+
+type PinMsgResp struct{}
+
+type UnpinMsgReq struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	UserID         string `json:"userID"`
+}
+
+func (x *UnpinMsgReq) Check() error {
+	return checkPinMsg(x.ConversationID, x.Seq, x.UserID)
+}
+
+type UnpinMsgResp struct{}
+
+func checkPinMsg(conversationID string, seq int64, userID string) error {
+	if conversationID == "" {
+		return errs.ErrArgs.WrapMsg("conversationID is empty")
+	}
+	if seq <= 0 {
+		return errs.ErrArgs.WrapMsg("seq is invalid")
+	}
+	if userID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	return nil
+}
+
+type GetPinnedMsgsReq struct {
+	ConversationID string `json:"conversationID"`
+	UserID         string `json:"userID"`
+}
+
+func (x *GetPinnedMsgsReq) Check() error {
+	if x.ConversationID == "" {
+		return errs.ErrArgs.WrapMsg("conversationID is empty")
+	}
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	return nil
+}
+
+type PinnedMsg struct {
+	Seq       int64          `json:"seq"`
+	PinUserID string         `json:"pinUserID"`
+	PinTime   int64          `json:"pinTime"`
+	MsgData   *sdkws.MsgData `json:"msgData"`
+}
+
+type GetPinnedMsgsResp struct {
+	// Msgs are sorted by pin time, the latest first.
+	Msgs []*PinnedMsg `json:"msgs"`
+}
+
+type PinnedMsgTips struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	OpUserID       string `json:"opUserID"`
+	// IsPinned is false when the message is unpinned.
+	IsPinned bool `json:"isPinned"`
+	// PinnedSeqs are the seqs pinned in the conversation after the change, the latest first.
+	PinnedSeqs []int64 `json:"pinnedSeqs"`
+}
@@ -0,0 +1,98 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"encoding/json"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	// MsgEditNotification is the content type of the notification sent when a message is edited, its detail is EditMsgTips.
+	MsgEditNotification = 2103
+
+	editContentMaxLen = 16 * 1024
+)
+
+type EditMsgReq struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	UserID         string `json:"userID"`
+	// Content replaces the content of the message, it has the format of the message content type.
+	Content string `json:"content"`
+}
+
+func (x *EditMsgReq) Check() error {
+	if x.ConversationID == "" {
+		return errs.ErrArgs.WrapMsg("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errs.ErrArgs.WrapMsg("seq is invalid")
+	}
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if x.Content == "" {
+		return errs.ErrArgs.WrapMsg("content is empty")
+	}
+	if len(x.Content) > editContentMaxLen {
+		return errs.ErrArgs.WrapMsg("content is too long", "maxLen", editContentMaxLen)
+	}
+	return nil
+}
+
+// CheckEditContent reports whether a message of contentType can be edited to content. Only text messages
+// can be edited, and content must be their JSON content with a non-empty text within editContentMaxLen,
+// so an edit can not turn a message into another kind or swap e.g. the URL of a picture.
+func CheckEditContent(contentType int32, content string) error {
+	var field string
+	switch contentType {
+	case constant.Text:
+		field = "content"
+	case constant.AtText, constant.Quote:
+		field = "text"
+	default:
+		return errs.ErrArgs.WrapMsg("msg content type can not be edited", "contentType", contentType)
+	}
+	if len(content) > editContentMaxLen {
+		return errs.ErrArgs.WrapMsg("content is too long", "maxLen", editContentMaxLen)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &fields); err != nil {
+		return errs.ErrArgs.WrapMsg("content is not a json object", "contentType", contentType)
+	}
+	var text string
+	if err := json.Unmarshal(fields[field], &text); err != nil || text == "" {
+		return errs.ErrArgs.WrapMsg("content has no text", "contentType", contentType, "field", field)
+	}
+	return nil
+}
+
+type EditMsgResp struct {
+	EditTime int64 `json:"editTime"`
+}
+
+type EditMsgTips struct {
+	EditorUserID   string `json:"editorUserID"`
+	ClientMsgID    string `json:"clientMsgID"`
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	SessionType    int32  `json:"sessionType"`
+	Content        string `json:"content"`
+	EditTime       int64  `json:"editTime"`
+	IsAdminEdit    bool   `json:"isAdminEdit"`
+}
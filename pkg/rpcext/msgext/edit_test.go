@@ -0,0 +1,38 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckEditContent(t *testing.T) {
+	assert.NoError(t, CheckEditContent(constant.Text, `{"content":"hello"}`))
+	assert.NoError(t, CheckEditContent(constant.AtText, `{"text":"@u1 hi","atUserList":["u1"]}`))
+	assert.NoError(t, CheckEditContent(constant.Quote, `{"text":"yes","quoteMessage":{}}`))
+
+	assert.Error(t, CheckEditContent(constant.Picture, `{"sourcePicture":{"url":"https://evil"}}`), "not text")
+	assert.Error(t, CheckEditContent(constant.Text, `hello`), "not json")
+	assert.Error(t, CheckEditContent(constant.Text, `{"text":"hello"}`), "field of another type")
+	assert.Error(t, CheckEditContent(constant.AtText, `{"text":""}`), "empty text")
+	assert.Error(t, CheckEditContent(constant.Text, `{"content":"`+strings.Repeat("a", editContentMaxLen)+`"}`), "too long")
+
+	req := EditMsgReq{ConversationID: "si_u1_u2", Seq: 1, UserID: "u1", Content: strings.Repeat("a", editContentMaxLen+1)}
+	assert.Error(t, req.Check(), "too long")
+}
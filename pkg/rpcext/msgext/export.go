@@ -0,0 +1,138 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"github.com/openimsdk/open-im-server/v3/pkg/msgexport"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	// MsgExportPending is the status of an export job waiting to be claimed by the cron task.
+	MsgExportPending = 0
+	// MsgExportRunning is the status of an export job being written by a msg rpc.
+	MsgExportRunning = 1
+	// MsgExportDone is the status of an export job whose archive is uploaded.
+	MsgExportDone = 2
+	// MsgExportFailed is the status of an export job which could not be written, ErrMsg tells why.
+	MsgExportFailed = 3
+)
+
+// maxExportConversationNum is the number of conversations one export job can hold.
+const maxExportConversationNum = 100
+
+type CreateMsgExportJobReq struct {
+	ConversationIDs []string `json:"conversationIDs"`
+	// Format is one of the formats of msgexport.
+	Format string `json:"format"`
+	// StartTime and EndTime limit the send time in milliseconds of the exported messages when they are not 0.
+	StartTime int64 `json:"startTime"`
+	EndTime   int64 `json:"endTime"`
+}
+
+func (x *CreateMsgExportJobReq) Check() error {
+	if len(x.ConversationIDs) == 0 {
+		return errs.ErrArgs.WrapMsg("conversationIDs is empty")
+	}
+	if len(x.ConversationIDs) > maxExportConversationNum {
+		return errs.ErrArgs.WrapMsg("too many conversationIDs", "max", maxExportConversationNum)
+	}
+	for _, conversationID := range x.ConversationIDs {
+		if conversationID == "" {
+			return errs.ErrArgs.WrapMsg("conversationID is empty")
+		}
+	}
+	if !msgexport.IsFormat(x.Format) {
+		return errs.ErrArgs.WrapMsg("format is invalid", "format", x.Format)
+	}
+	if x.StartTime < 0 || x.EndTime < 0 || (x.EndTime != 0 && x.EndTime < x.StartTime) {
+		return errs.ErrArgs.WrapMsg("time range is invalid")
+	}
+	return nil
+}
+
+type CreateMsgExportJobResp struct {
+	JobID string `json:"jobID"`
+}
+
+type MsgExportJob struct {
+	JobID           string   `json:"jobID"`
+	CreatorUserID   string   `json:"creatorUserID"`
+	ConversationIDs []string `json:"conversationIDs"`
+	Format          string   `json:"format"`
+	StartTime       int64    `json:"startTime"`
+	EndTime         int64    `json:"endTime"`
+	Status          int32    `json:"status"`
+	// MsgCount is the number of messages written so far.
+	MsgCount int64 `json:"msgCount"`
+	// ObjectName is the name of the uploaded archive in the object storage.
+	ObjectName string `json:"objectName"`
+	Size       int64  `json:"size"`
+	ErrMsg     string `json:"errMsg"`
+	CreateTime int64  `json:"createTime"`
+	FinishTime int64  `json:"finishTime"`
+	// URL is the access url of the archive, it is only set by GetMsgExportJob for a done job.
+	URL           string `json:"url,omitempty"`
+	URLExpireTime int64  `json:"urlExpireTime,omitempty"`
+}
+
+type GetMsgExportJobReq struct {
+	JobID string `json:"jobID"`
+}
+
+func (x *GetMsgExportJobReq) Check() error {
+	if x.JobID == "" {
+		return errs.ErrArgs.WrapMsg("jobID is empty")
+	}
+	return nil
+}
+
+type GetMsgExportJobResp struct {
+	Job *MsgExportJob `json:"job"`
+}
+
+type GetMsgExportJobsReq struct {
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetMsgExportJobsReq) Check() error {
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetMsgExportJobsResp struct {
+	Total int64           `json:"total"`
+	Jobs  []*MsgExportJob `json:"jobs"`
+}
+
+// RunMsgExportJobsReq is sent by the cron task, the msg rpc claims up to Limit pending jobs and writes them
+// in the background. A job whose runner stopped reporting progress is claimed again.
+type RunMsgExportJobsReq struct {
+	Limit int `json:"limit"`
+}
+
+func (x *RunMsgExportJobsReq) Check() error {
+	if x.Limit <= 0 {
+		return errs.ErrArgs.WrapMsg("limit is invalid")
+	}
+	return nil
+}
+
+type RunMsgExportJobsResp struct {
+	JobIDs []string `json:"jobIDs"`
+}
@@ -0,0 +1,142 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	// AttachedInfoForward is the key of the original message in the attached info of a forwarded copy.
+	AttachedInfoForward = "forward"
+
+	// MaxForwardMsgs is the number of messages a forward can take.
+	MaxForwardMsgs = 100
+)
+
+// ForwardFrom is the original message of a forwarded copy.
+type ForwardFrom struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	ServerMsgID    string `json:"serverMsgID"`
+	ClientMsgID    string `json:"clientMsgID"`
+	SendID         string `json:"sendID"`
+	SendTime       int64  `json:"sendTime"`
+}
+
+// SetForwardFrom returns attachedInfo with the original message set under AttachedInfoForward.
+func SetForwardFrom(attachedInfo string, from *ForwardFrom) (string, error) {
+	return setAttachedInfo(attachedInfo, AttachedInfoForward, from)
+}
+
+// MergeElem is the content of a merged history message, in the layout the sdk reads.
+type MergeElem struct {
+	Title        string       `json:"title"`
+	AbstractList []string     `json:"abstractList"`
+	MultiMessage []*MergedMsg `json:"multiMessage"`
+}
+
+// MergedMsg is a message of a merged history, Content is the content of the original message as it was stored.
+type MergedMsg struct {
+	ClientMsgID      string `json:"clientMsgID"`
+	ServerMsgID      string `json:"serverMsgID"`
+	CreateTime       int64  `json:"createTime"`
+	SendTime         int64  `json:"sendTime"`
+	SessionType      int32  `json:"sessionType"`
+	SendID           string `json:"sendID"`
+	RecvID           string `json:"recvID"`
+	GroupID          string `json:"groupID"`
+	MsgFrom          int32  `json:"msgFrom"`
+	ContentType      int32  `json:"contentType"`
+	SenderPlatformID int32  `json:"senderPlatformID"`
+	SenderNickname   string `json:"senderNickname"`
+	SenderFaceURL    string `json:"senderFaceUrl"`
+	Content          string `json:"content"`
+	Seq              int64  `json:"seq"`
+	Ex               string `json:"ex"`
+	// ConversationID is the conversation the message was forwarded from.
+	ConversationID string `json:"conversationID"`
+}
+
+type ForwardSource struct {
+	ConversationID string  `json:"conversationID"`
+	Seqs           []int64 `json:"seqs"`
+}
+
+type ForwardMsgsReq struct {
+	// UserID forwards the messages and sends the result.
+	UserID string `json:"userID"`
+	// Sources are read by UserID, the messages are forwarded in the order of the sources and of their seqs.
+	Sources     []*ForwardSource `json:"sources"`
+	SessionType int32            `json:"sessionType"`
+	RecvID      string           `json:"recvID"`
+	GroupID     string           `json:"groupID"`
+	// Merge sends a single merged history message instead of a copy of each message.
+	Merge bool `json:"merge"`
+	// Title is the title of the merged history.
+	Title string `json:"title"`
+}
+
+func (x *ForwardMsgsReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if len(x.Sources) == 0 {
+		return errs.ErrArgs.WrapMsg("sources is empty")
+	}
+	var count int
+	for _, source := range x.Sources {
+		if source == nil || source.ConversationID == "" {
+			return errs.ErrArgs.WrapMsg("source conversationID is empty")
+		}
+		if len(source.Seqs) == 0 {
+			return errs.ErrArgs.WrapMsg("source seqs is empty", "conversationID", source.ConversationID)
+		}
+		for _, seq := range source.Seqs {
+			if seq <= 0 {
+				return errs.ErrArgs.WrapMsg("seq is invalid", "conversationID", source.ConversationID, "seq", seq)
+			}
+		}
+		count += len(source.Seqs)
+	}
+	if count > MaxForwardMsgs {
+		return errs.ErrArgs.WrapMsg("too many msgs to forward", "count", count, "max", MaxForwardMsgs)
+	}
+	switch x.SessionType {
+	case constant.SingleChatType:
+		if x.RecvID == "" {
+			return errs.ErrArgs.WrapMsg("recvID is empty")
+		}
+	case constant.ReadGroupChatType:
+		if x.GroupID == "" {
+			return errs.ErrArgs.WrapMsg("groupID is empty")
+		}
+	default:
+		return errs.ErrArgs.WrapMsg("sessionType can not be forwarded to", "sessionType", x.SessionType)
+	}
+	return nil
+}
+
+// ForwardedMsg is a message sent by a forward.
+type ForwardedMsg struct {
+	ServerMsgID string `json:"serverMsgID"`
+	ClientMsgID string `json:"clientMsgID"`
+	SendTime    int64  `json:"sendTime"`
+}
+
+type ForwardMsgsResp struct {
+	Msgs []*ForwardedMsg `json:"msgs"`
+}
@@ -0,0 +1,63 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckForwardMsgs(t *testing.T) {
+	sources := []*ForwardSource{{ConversationID: "si_u1_u2", Seqs: []int64{1, 2}}}
+	tooMany := make([]int64, MaxForwardMsgs+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+	for _, c := range []struct {
+		req   ForwardMsgsReq
+		valid bool
+	}{
+		{ForwardMsgsReq{UserID: "u1", Sources: sources, SessionType: constant.SingleChatType, RecvID: "u3"}, true},
+		{ForwardMsgsReq{UserID: "u1", Sources: sources, SessionType: constant.ReadGroupChatType, GroupID: "g1", Merge: true}, true},
+		{ForwardMsgsReq{Sources: sources, SessionType: constant.SingleChatType, RecvID: "u3"}, false},
+		{ForwardMsgsReq{UserID: "u1", SessionType: constant.SingleChatType, RecvID: "u3"}, false},
+		{ForwardMsgsReq{UserID: "u1", Sources: sources, SessionType: constant.SingleChatType}, false},
+		{ForwardMsgsReq{UserID: "u1", Sources: sources, SessionType: constant.NotificationChatType, RecvID: "u3"}, false},
+		{ForwardMsgsReq{UserID: "u1", Sources: []*ForwardSource{{ConversationID: "si_u1_u2", Seqs: []int64{0}}}, SessionType: constant.SingleChatType, RecvID: "u3"}, false},
+		{ForwardMsgsReq{UserID: "u1", Sources: []*ForwardSource{{ConversationID: "si_u1_u2", Seqs: tooMany}}, SessionType: constant.SingleChatType, RecvID: "u3"}, false},
+	} {
+		if c.valid {
+			assert.NoError(t, c.req.Check(), c.req)
+		} else {
+			assert.Error(t, c.req.Check(), c.req)
+		}
+	}
+}
+
+func TestSetForwardFrom(t *testing.T) {
+	attachedInfo, err := SetForwardFrom(`{"other":1}`, &ForwardFrom{ConversationID: "si_u1_u2", Seq: 3, ServerMsgID: "m1"})
+	assert.NoError(t, err)
+	var info struct {
+		Other   int          `json:"other"`
+		Forward *ForwardFrom `json:"forward"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(attachedInfo), &info))
+	assert.Equal(t, 1, info.Other)
+	assert.Equal(t, int64(3), info.Forward.Seq)
+	assert.Equal(t, "m1", info.Forward.ServerMsgID)
+}
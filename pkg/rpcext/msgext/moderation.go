@@ -0,0 +1,90 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// The statuses of the messages flagged by the moderation.
+const (
+	ModerationReviewPending  int32 = 0
+	ModerationReviewApproved int32 = 1
+	ModerationReviewRemoved  int32 = 2
+)
+
+type ModerationHit struct {
+	Rule string `json:"rule"`
+	// Action is the action of the rule, one of pass, flag, mask and block.
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// ModerationReview is a message flagged by the moderation.
+type ModerationReview struct {
+	ReviewID       string           `json:"reviewID"`
+	ConversationID string           `json:"conversationID"`
+	ClientMsgID    string           `json:"clientMsgID"`
+	ServerMsgID    string           `json:"serverMsgID"`
+	SendID         string           `json:"sendID"`
+	SessionType    int32            `json:"sessionType"`
+	ContentType    int32            `json:"contentType"`
+	Content        string           `json:"content"`
+	Hits           []*ModerationHit `json:"hits"`
+	Status         int32            `json:"status"`
+	ReviewerUserID string           `json:"reviewerUserID"`
+	ReviewTime     int64            `json:"reviewTime"`
+	CreateTime     int64            `json:"createTime"`
+}
+
+type GetModerationReviewsReq struct {
+	// Status filters the reviews, -1 returns them all.
+	Status     int32                    `json:"status"`
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetModerationReviewsReq) Check() error {
+	if x.Status < -1 || x.Status > ModerationReviewRemoved {
+		return errs.ErrArgs.WrapMsg("status is invalid", "status", x.Status)
+	}
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetModerationReviewsResp struct {
+	Total   int64               `json:"total"`
+	Reviews []*ModerationReview `json:"reviews"`
+}
+
+type ReviewModerationMsgReq struct {
+	ReviewID string `json:"reviewID"`
+	// Status is ModerationReviewApproved to keep the message or ModerationReviewRemoved to revoke it.
+	Status int32 `json:"status"`
+}
+
+func (x *ReviewModerationMsgReq) Check() error {
+	if x.ReviewID == "" {
+		return errs.ErrArgs.WrapMsg("reviewID is empty")
+	}
+	if x.Status != ModerationReviewApproved && x.Status != ModerationReviewRemoved {
+		return errs.ErrArgs.WrapMsg("status is invalid", "status", x.Status)
+	}
+	return nil
+}
+
+type ReviewModerationMsgResp struct{}
@@ -0,0 +1,898 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package msgext is the extension service of the msg rpc, it is served by the msg rpc next to msg.Msg.
+// It follows the layout of the generated grpc code so it can be moved to the protocol module unchanged.
+package msgext
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	MsgExt_EditMsg_FullMethodName                = "/openim.msgext.MsgExt/EditMsg"
+	MsgExt_AddReaction_FullMethodName            = "/openim.msgext.MsgExt/AddReaction"
+	MsgExt_RemoveReaction_FullMethodName         = "/openim.msgext.MsgExt/RemoveReaction"
+	MsgExt_ScheduleMsg_FullMethodName            = "/openim.msgext.MsgExt/ScheduleMsg"
+	MsgExt_GetScheduledMsgs_FullMethodName       = "/openim.msgext.MsgExt/GetScheduledMsgs"
+	MsgExt_UpdateScheduledMsg_FullMethodName     = "/openim.msgext.MsgExt/UpdateScheduledMsg"
+	MsgExt_CancelScheduledMsg_FullMethodName     = "/openim.msgext.MsgExt/CancelScheduledMsg"
+	MsgExt_ClaimDueScheduledMsgs_FullMethodName  = "/openim.msgext.MsgExt/ClaimDueScheduledMsgs"
+	MsgExt_FinishScheduledMsg_FullMethodName     = "/openim.msgext.MsgExt/FinishScheduledMsg"
+	MsgExt_GetGroupMsgReadMembers_FullMethodName = "/openim.msgext.MsgExt/GetGroupMsgReadMembers"
+	MsgExt_FullTextSearchMsg_FullMethodName      = "/openim.msgext.MsgExt/FullTextSearchMsg"
+	MsgExt_CreateMsgExportJob_FullMethodName     = "/openim.msgext.MsgExt/CreateMsgExportJob"
+	MsgExt_GetMsgExportJob_FullMethodName        = "/openim.msgext.MsgExt/GetMsgExportJob"
+	MsgExt_GetMsgExportJobs_FullMethodName       = "/openim.msgext.MsgExt/GetMsgExportJobs"
+	MsgExt_RunMsgExportJobs_FullMethodName       = "/openim.msgext.MsgExt/RunMsgExportJobs"
+	MsgExt_SetRetentionPolicy_FullMethodName     = "/openim.msgext.MsgExt/SetRetentionPolicy"
+	MsgExt_DeleteRetentionPolicy_FullMethodName  = "/openim.msgext.MsgExt/DeleteRetentionPolicy"
+	MsgExt_GetRetentionPolicies_FullMethodName   = "/openim.msgext.MsgExt/GetRetentionPolicies"
+	MsgExt_GetRetentionSkips_FullMethodName      = "/openim.msgext.MsgExt/GetRetentionSkips"
+	MsgExt_GetModerationReviews_FullMethodName   = "/openim.msgext.MsgExt/GetModerationReviews"
+	MsgExt_ReviewModerationMsg_FullMethodName    = "/openim.msgext.MsgExt/ReviewModerationMsg"
+	MsgExt_ForwardMsgs_FullMethodName            = "/openim.msgext.MsgExt/ForwardMsgs"
+)
+
+// MsgExtClient is the client API for MsgExt service.
+type MsgExtClient interface {
+	EditMsg(ctx context.Context, in *EditMsgReq, opts ...grpc.CallOption) (*EditMsgResp, error)
+	AddReaction(ctx context.Context, in *AddReactionReq, opts ...grpc.CallOption) (*AddReactionResp, error)
+	RemoveReaction(ctx context.Context, in *RemoveReactionReq, opts ...grpc.CallOption) (*RemoveReactionResp, error)
+	ScheduleMsg(ctx context.Context, in *ScheduleMsgReq, opts ...grpc.CallOption) (*ScheduleMsgResp, error)
+	GetScheduledMsgs(ctx context.Context, in *GetScheduledMsgsReq, opts ...grpc.CallOption) (*GetScheduledMsgsResp, error)
+	UpdateScheduledMsg(ctx context.Context, in *UpdateScheduledMsgReq, opts ...grpc.CallOption) (*UpdateScheduledMsgResp, error)
+	CancelScheduledMsg(ctx context.Context, in *CancelScheduledMsgReq, opts ...grpc.CallOption) (*CancelScheduledMsgResp, error)
+	ClaimDueScheduledMsgs(ctx context.Context, in *ClaimDueScheduledMsgsReq, opts ...grpc.CallOption) (*ClaimDueScheduledMsgsResp, error)
+	FinishScheduledMsg(ctx context.Context, in *FinishScheduledMsgReq, opts ...grpc.CallOption) (*FinishScheduledMsgResp, error)
+	GetGroupMsgReadMembers(ctx context.Context, in *GetGroupMsgReadMembersReq, opts ...grpc.CallOption) (*GetGroupMsgReadMembersResp, error)
+	FullTextSearchMsg(ctx context.Context, in *FullTextSearchMsgReq, opts ...grpc.CallOption) (*FullTextSearchMsgResp, error)
+	CreateMsgExportJob(ctx context.Context, in *CreateMsgExportJobReq, opts ...grpc.CallOption) (*CreateMsgExportJobResp, error)
+	GetMsgExportJob(ctx context.Context, in *GetMsgExportJobReq, opts ...grpc.CallOption) (*GetMsgExportJobResp, error)
+	GetMsgExportJobs(ctx context.Context, in *GetMsgExportJobsReq, opts ...grpc.CallOption) (*GetMsgExportJobsResp, error)
+	RunMsgExportJobs(ctx context.Context, in *RunMsgExportJobsReq, opts ...grpc.CallOption) (*RunMsgExportJobsResp, error)
+	SetRetentionPolicy(ctx context.Context, in *SetRetentionPolicyReq, opts ...grpc.CallOption) (*SetRetentionPolicyResp, error)
+	DeleteRetentionPolicy(ctx context.Context, in *DeleteRetentionPolicyReq, opts ...grpc.CallOption) (*DeleteRetentionPolicyResp, error)
+	GetRetentionPolicies(ctx context.Context, in *GetRetentionPoliciesReq, opts ...grpc.CallOption) (*GetRetentionPoliciesResp, error)
+	GetRetentionSkips(ctx context.Context, in *GetRetentionSkipsReq, opts ...grpc.CallOption) (*GetRetentionSkipsResp, error)
+	GetModerationReviews(ctx context.Context, in *GetModerationReviewsReq, opts ...grpc.CallOption) (*GetModerationReviewsResp, error)
+	ReviewModerationMsg(ctx context.Context, in *ReviewModerationMsgReq, opts ...grpc.CallOption) (*ReviewModerationMsgResp, error)
+	ForwardMsgs(ctx context.Context, in *ForwardMsgsReq, opts ...grpc.CallOption) (*ForwardMsgsResp, error)
+}
+
+type msgExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMsgExtClient(cc grpc.ClientConnInterface) MsgExtClient {
+	return &msgExtClient{cc}
+}
+
+func (c *msgExtClient) EditMsg(ctx context.Context, in *EditMsgReq, opts ...grpc.CallOption) (*EditMsgResp, error) {
+	out := new(EditMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_EditMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) AddReaction(ctx context.Context, in *AddReactionReq, opts ...grpc.CallOption) (*AddReactionResp, error) {
+	out := new(AddReactionResp)
+	err := c.cc.Invoke(ctx, MsgExt_AddReaction_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) RemoveReaction(ctx context.Context, in *RemoveReactionReq, opts ...grpc.CallOption) (*RemoveReactionResp, error) {
+	out := new(RemoveReactionResp)
+	err := c.cc.Invoke(ctx, MsgExt_RemoveReaction_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) ScheduleMsg(ctx context.Context, in *ScheduleMsgReq, opts ...grpc.CallOption) (*ScheduleMsgResp, error) {
+	out := new(ScheduleMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_ScheduleMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetScheduledMsgs(ctx context.Context, in *GetScheduledMsgsReq, opts ...grpc.CallOption) (*GetScheduledMsgsResp, error) {
+	out := new(GetScheduledMsgsResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetScheduledMsgs_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) UpdateScheduledMsg(ctx context.Context, in *UpdateScheduledMsgReq, opts ...grpc.CallOption) (*UpdateScheduledMsgResp, error) {
+	out := new(UpdateScheduledMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_UpdateScheduledMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) CancelScheduledMsg(ctx context.Context, in *CancelScheduledMsgReq, opts ...grpc.CallOption) (*CancelScheduledMsgResp, error) {
+	out := new(CancelScheduledMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_CancelScheduledMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) ClaimDueScheduledMsgs(ctx context.Context, in *ClaimDueScheduledMsgsReq, opts ...grpc.CallOption) (*ClaimDueScheduledMsgsResp, error) {
+	out := new(ClaimDueScheduledMsgsResp)
+	err := c.cc.Invoke(ctx, MsgExt_ClaimDueScheduledMsgs_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) FinishScheduledMsg(ctx context.Context, in *FinishScheduledMsgReq, opts ...grpc.CallOption) (*FinishScheduledMsgResp, error) {
+	out := new(FinishScheduledMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_FinishScheduledMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetGroupMsgReadMembers(ctx context.Context, in *GetGroupMsgReadMembersReq, opts ...grpc.CallOption) (*GetGroupMsgReadMembersResp, error) {
+	out := new(GetGroupMsgReadMembersResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetGroupMsgReadMembers_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) FullTextSearchMsg(ctx context.Context, in *FullTextSearchMsgReq, opts ...grpc.CallOption) (*FullTextSearchMsgResp, error) {
+	out := new(FullTextSearchMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_FullTextSearchMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) CreateMsgExportJob(ctx context.Context, in *CreateMsgExportJobReq, opts ...grpc.CallOption) (*CreateMsgExportJobResp, error) {
+	out := new(CreateMsgExportJobResp)
+	err := c.cc.Invoke(ctx, MsgExt_CreateMsgExportJob_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetMsgExportJob(ctx context.Context, in *GetMsgExportJobReq, opts ...grpc.CallOption) (*GetMsgExportJobResp, error) {
+	out := new(GetMsgExportJobResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetMsgExportJob_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetMsgExportJobs(ctx context.Context, in *GetMsgExportJobsReq, opts ...grpc.CallOption) (*GetMsgExportJobsResp, error) {
+	out := new(GetMsgExportJobsResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetMsgExportJobs_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) RunMsgExportJobs(ctx context.Context, in *RunMsgExportJobsReq, opts ...grpc.CallOption) (*RunMsgExportJobsResp, error) {
+	out := new(RunMsgExportJobsResp)
+	err := c.cc.Invoke(ctx, MsgExt_RunMsgExportJobs_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) SetRetentionPolicy(ctx context.Context, in *SetRetentionPolicyReq, opts ...grpc.CallOption) (*SetRetentionPolicyResp, error) {
+	out := new(SetRetentionPolicyResp)
+	err := c.cc.Invoke(ctx, MsgExt_SetRetentionPolicy_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) DeleteRetentionPolicy(ctx context.Context, in *DeleteRetentionPolicyReq, opts ...grpc.CallOption) (*DeleteRetentionPolicyResp, error) {
+	out := new(DeleteRetentionPolicyResp)
+	err := c.cc.Invoke(ctx, MsgExt_DeleteRetentionPolicy_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetRetentionPolicies(ctx context.Context, in *GetRetentionPoliciesReq, opts ...grpc.CallOption) (*GetRetentionPoliciesResp, error) {
+	out := new(GetRetentionPoliciesResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetRetentionPolicies_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetRetentionSkips(ctx context.Context, in *GetRetentionSkipsReq, opts ...grpc.CallOption) (*GetRetentionSkipsResp, error) {
+	out := new(GetRetentionSkipsResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetRetentionSkips_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) GetModerationReviews(ctx context.Context, in *GetModerationReviewsReq, opts ...grpc.CallOption) (*GetModerationReviewsResp, error) {
+	out := new(GetModerationReviewsResp)
+	err := c.cc.Invoke(ctx, MsgExt_GetModerationReviews_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) ReviewModerationMsg(ctx context.Context, in *ReviewModerationMsgReq, opts ...grpc.CallOption) (*ReviewModerationMsgResp, error) {
+	out := new(ReviewModerationMsgResp)
+	err := c.cc.Invoke(ctx, MsgExt_ReviewModerationMsg_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msgExtClient) ForwardMsgs(ctx context.Context, in *ForwardMsgsReq, opts ...grpc.CallOption) (*ForwardMsgsResp, error) {
+	out := new(ForwardMsgsResp)
+	err := c.cc.Invoke(ctx, MsgExt_ForwardMsgs_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MsgExtServer is the server API for MsgExt service.
+type MsgExtServer interface {
+	EditMsg(context.Context, *EditMsgReq) (*EditMsgResp, error)
+	AddReaction(context.Context, *AddReactionReq) (*AddReactionResp, error)
+	RemoveReaction(context.Context, *RemoveReactionReq) (*RemoveReactionResp, error)
+	ScheduleMsg(context.Context, *ScheduleMsgReq) (*ScheduleMsgResp, error)
+	GetScheduledMsgs(context.Context, *GetScheduledMsgsReq) (*GetScheduledMsgsResp, error)
+	UpdateScheduledMsg(context.Context, *UpdateScheduledMsgReq) (*UpdateScheduledMsgResp, error)
+	CancelScheduledMsg(context.Context, *CancelScheduledMsgReq) (*CancelScheduledMsgResp, error)
+	ClaimDueScheduledMsgs(context.Context, *ClaimDueScheduledMsgsReq) (*ClaimDueScheduledMsgsResp, error)
+	FinishScheduledMsg(context.Context, *FinishScheduledMsgReq) (*FinishScheduledMsgResp, error)
+	GetGroupMsgReadMembers(context.Context, *GetGroupMsgReadMembersReq) (*GetGroupMsgReadMembersResp, error)
+	FullTextSearchMsg(context.Context, *FullTextSearchMsgReq) (*FullTextSearchMsgResp, error)
+	CreateMsgExportJob(context.Context, *CreateMsgExportJobReq) (*CreateMsgExportJobResp, error)
+	GetMsgExportJob(context.Context, *GetMsgExportJobReq) (*GetMsgExportJobResp, error)
+	GetMsgExportJobs(context.Context, *GetMsgExportJobsReq) (*GetMsgExportJobsResp, error)
+	RunMsgExportJobs(context.Context, *RunMsgExportJobsReq) (*RunMsgExportJobsResp, error)
+	SetRetentionPolicy(context.Context, *SetRetentionPolicyReq) (*SetRetentionPolicyResp, error)
+	DeleteRetentionPolicy(context.Context, *DeleteRetentionPolicyReq) (*DeleteRetentionPolicyResp, error)
+	GetRetentionPolicies(context.Context, *GetRetentionPoliciesReq) (*GetRetentionPoliciesResp, error)
+	GetRetentionSkips(context.Context, *GetRetentionSkipsReq) (*GetRetentionSkipsResp, error)
+	GetModerationReviews(context.Context, *GetModerationReviewsReq) (*GetModerationReviewsResp, error)
+	ReviewModerationMsg(context.Context, *ReviewModerationMsgReq) (*ReviewModerationMsgResp, error)
+	ForwardMsgs(context.Context, *ForwardMsgsReq) (*ForwardMsgsResp, error)
+}
+
+// UnimplementedMsgExtServer can be embedded to have forward compatible implementations.
+type UnimplementedMsgExtServer struct{}
+
+func (UnimplementedMsgExtServer) EditMsg(context.Context, *EditMsgReq) (*EditMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) AddReaction(context.Context, *AddReactionReq) (*AddReactionResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReaction not implemented")
+}
+
+func (UnimplementedMsgExtServer) RemoveReaction(context.Context, *RemoveReactionReq) (*RemoveReactionResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveReaction not implemented")
+}
+
+func (UnimplementedMsgExtServer) ScheduleMsg(context.Context, *ScheduleMsgReq) (*ScheduleMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScheduleMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetScheduledMsgs(context.Context, *GetScheduledMsgsReq) (*GetScheduledMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScheduledMsgs not implemented")
+}
+
+func (UnimplementedMsgExtServer) UpdateScheduledMsg(context.Context, *UpdateScheduledMsgReq) (*UpdateScheduledMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateScheduledMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) CancelScheduledMsg(context.Context, *CancelScheduledMsgReq) (*CancelScheduledMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelScheduledMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) ClaimDueScheduledMsgs(context.Context, *ClaimDueScheduledMsgsReq) (*ClaimDueScheduledMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimDueScheduledMsgs not implemented")
+}
+
+func (UnimplementedMsgExtServer) FinishScheduledMsg(context.Context, *FinishScheduledMsgReq) (*FinishScheduledMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishScheduledMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetGroupMsgReadMembers(context.Context, *GetGroupMsgReadMembersReq) (*GetGroupMsgReadMembersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupMsgReadMembers not implemented")
+}
+
+func (UnimplementedMsgExtServer) FullTextSearchMsg(context.Context, *FullTextSearchMsgReq) (*FullTextSearchMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FullTextSearchMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) CreateMsgExportJob(context.Context, *CreateMsgExportJobReq) (*CreateMsgExportJobResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMsgExportJob not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetMsgExportJob(context.Context, *GetMsgExportJobReq) (*GetMsgExportJobResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMsgExportJob not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetMsgExportJobs(context.Context, *GetMsgExportJobsReq) (*GetMsgExportJobsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMsgExportJobs not implemented")
+}
+
+func (UnimplementedMsgExtServer) RunMsgExportJobs(context.Context, *RunMsgExportJobsReq) (*RunMsgExportJobsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunMsgExportJobs not implemented")
+}
+
+func (UnimplementedMsgExtServer) SetRetentionPolicy(context.Context, *SetRetentionPolicyReq) (*SetRetentionPolicyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRetentionPolicy not implemented")
+}
+
+func (UnimplementedMsgExtServer) DeleteRetentionPolicy(context.Context, *DeleteRetentionPolicyReq) (*DeleteRetentionPolicyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRetentionPolicy not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetRetentionPolicies(context.Context, *GetRetentionPoliciesReq) (*GetRetentionPoliciesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRetentionPolicies not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetRetentionSkips(context.Context, *GetRetentionSkipsReq) (*GetRetentionSkipsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRetentionSkips not implemented")
+}
+
+func (UnimplementedMsgExtServer) GetModerationReviews(context.Context, *GetModerationReviewsReq) (*GetModerationReviewsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModerationReviews not implemented")
+}
+
+func (UnimplementedMsgExtServer) ReviewModerationMsg(context.Context, *ReviewModerationMsgReq) (*ReviewModerationMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewModerationMsg not implemented")
+}
+
+func (UnimplementedMsgExtServer) ForwardMsgs(context.Context, *ForwardMsgsReq) (*ForwardMsgsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForwardMsgs not implemented")
+}
+
+func RegisterMsgExtServer(s grpc.ServiceRegistrar, srv MsgExtServer) {
+	s.RegisterService(&MsgExt_ServiceDesc, srv)
+}
+
+func _MsgExt_EditMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(EditMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).EditMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_EditMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).EditMsg(ctx, req.(*EditMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_AddReaction_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(AddReactionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).AddReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_AddReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).AddReaction(ctx, req.(*AddReactionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_RemoveReaction_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(RemoveReactionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).RemoveReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_RemoveReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).RemoveReaction(ctx, req.(*RemoveReactionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_ScheduleMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ScheduleMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).ScheduleMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_ScheduleMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).ScheduleMsg(ctx, req.(*ScheduleMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetScheduledMsgs_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetScheduledMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetScheduledMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetScheduledMsgs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetScheduledMsgs(ctx, req.(*GetScheduledMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_UpdateScheduledMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(UpdateScheduledMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).UpdateScheduledMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_UpdateScheduledMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).UpdateScheduledMsg(ctx, req.(*UpdateScheduledMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_CancelScheduledMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(CancelScheduledMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).CancelScheduledMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_CancelScheduledMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).CancelScheduledMsg(ctx, req.(*CancelScheduledMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_ClaimDueScheduledMsgs_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ClaimDueScheduledMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).ClaimDueScheduledMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_ClaimDueScheduledMsgs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).ClaimDueScheduledMsgs(ctx, req.(*ClaimDueScheduledMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_FinishScheduledMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(FinishScheduledMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).FinishScheduledMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_FinishScheduledMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).FinishScheduledMsg(ctx, req.(*FinishScheduledMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetGroupMsgReadMembers_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetGroupMsgReadMembersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetGroupMsgReadMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetGroupMsgReadMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetGroupMsgReadMembers(ctx, req.(*GetGroupMsgReadMembersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_FullTextSearchMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(FullTextSearchMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).FullTextSearchMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_FullTextSearchMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).FullTextSearchMsg(ctx, req.(*FullTextSearchMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_CreateMsgExportJob_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(CreateMsgExportJobReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).CreateMsgExportJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_CreateMsgExportJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).CreateMsgExportJob(ctx, req.(*CreateMsgExportJobReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetMsgExportJob_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetMsgExportJobReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetMsgExportJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetMsgExportJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetMsgExportJob(ctx, req.(*GetMsgExportJobReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetMsgExportJobs_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetMsgExportJobsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetMsgExportJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetMsgExportJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetMsgExportJobs(ctx, req.(*GetMsgExportJobsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_RunMsgExportJobs_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(RunMsgExportJobsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).RunMsgExportJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_RunMsgExportJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).RunMsgExportJobs(ctx, req.(*RunMsgExportJobsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_SetRetentionPolicy_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(SetRetentionPolicyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).SetRetentionPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_SetRetentionPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).SetRetentionPolicy(ctx, req.(*SetRetentionPolicyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_DeleteRetentionPolicy_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DeleteRetentionPolicyReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).DeleteRetentionPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_DeleteRetentionPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).DeleteRetentionPolicy(ctx, req.(*DeleteRetentionPolicyReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetRetentionPolicies_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetRetentionPoliciesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetRetentionPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetRetentionPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetRetentionPolicies(ctx, req.(*GetRetentionPoliciesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetRetentionSkips_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetRetentionSkipsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetRetentionSkips(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetRetentionSkips_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetRetentionSkips(ctx, req.(*GetRetentionSkipsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_GetModerationReviews_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetModerationReviewsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).GetModerationReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_GetModerationReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).GetModerationReviews(ctx, req.(*GetModerationReviewsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_ReviewModerationMsg_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ReviewModerationMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).ReviewModerationMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_ReviewModerationMsg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).ReviewModerationMsg(ctx, req.(*ReviewModerationMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsgExt_ForwardMsgs_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ForwardMsgsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsgExtServer).ForwardMsgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MsgExt_ForwardMsgs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(MsgExtServer).ForwardMsgs(ctx, req.(*ForwardMsgsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MsgExt_ServiceDesc is the grpc.ServiceDesc for MsgExt service.
+var MsgExt_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "openim.msgext.MsgExt",
+	HandlerType: (*MsgExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EditMsg",
+			Handler:    _MsgExt_EditMsg_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _MsgExt_AddReaction_Handler,
+		},
+		{
+			MethodName: "RemoveReaction",
+			Handler:    _MsgExt_RemoveReaction_Handler,
+		},
+		{
+			MethodName: "ScheduleMsg",
+			Handler:    _MsgExt_ScheduleMsg_Handler,
+		},
+		{
+			MethodName: "GetScheduledMsgs",
+			Handler:    _MsgExt_GetScheduledMsgs_Handler,
+		},
+		{
+			MethodName: "UpdateScheduledMsg",
+			Handler:    _MsgExt_UpdateScheduledMsg_Handler,
+		},
+		{
+			MethodName: "CancelScheduledMsg",
+			Handler:    _MsgExt_CancelScheduledMsg_Handler,
+		},
+		{
+			MethodName: "ClaimDueScheduledMsgs",
+			Handler:    _MsgExt_ClaimDueScheduledMsgs_Handler,
+		},
+		{
+			MethodName: "FinishScheduledMsg",
+			Handler:    _MsgExt_FinishScheduledMsg_Handler,
+		},
+		{
+			MethodName: "GetGroupMsgReadMembers",
+			Handler:    _MsgExt_GetGroupMsgReadMembers_Handler,
+		},
+		{
+			MethodName: "FullTextSearchMsg",
+			Handler:    _MsgExt_FullTextSearchMsg_Handler,
+		},
+		{
+			MethodName: "CreateMsgExportJob",
+			Handler:    _MsgExt_CreateMsgExportJob_Handler,
+		},
+		{
+			MethodName: "GetMsgExportJob",
+			Handler:    _MsgExt_GetMsgExportJob_Handler,
+		},
+		{
+			MethodName: "GetMsgExportJobs",
+			Handler:    _MsgExt_GetMsgExportJobs_Handler,
+		},
+		{
+			MethodName: "RunMsgExportJobs",
+			Handler:    _MsgExt_RunMsgExportJobs_Handler,
+		},
+		{
+			MethodName: "SetRetentionPolicy",
+			Handler:    _MsgExt_SetRetentionPolicy_Handler,
+		},
+		{
+			MethodName: "DeleteRetentionPolicy",
+			Handler:    _MsgExt_DeleteRetentionPolicy_Handler,
+		},
+		{
+			MethodName: "GetRetentionPolicies",
+			Handler:    _MsgExt_GetRetentionPolicies_Handler,
+		},
+		{
+			MethodName: "GetRetentionSkips",
+			Handler:    _MsgExt_GetRetentionSkips_Handler,
+		},
+		{
+			MethodName: "GetModerationReviews",
+			Handler:    _MsgExt_GetModerationReviews_Handler,
+		},
+		{
+			MethodName: "ReviewModerationMsg",
+			Handler:    _MsgExt_ReviewModerationMsg_Handler,
+		},
+		{
+			MethodName: "ForwardMsgs",
+			Handler:    _MsgExt_ForwardMsgs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "msgext.go",
+}
@@ -0,0 +1,145 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	// MsgReactionNotification is the content type of the notification sent when a reaction is added or removed,
+	// its detail is ReactionTips.
+	MsgReactionNotification = 2104
+
+	// AttachedInfoReactions is the key of the reactions in the attached info of a pulled message.
+	AttachedInfoReactions = "reactions"
+
+	// MaxMsgReactions bounds the distinct reactions of a message, they are stored in the shared message document.
+	MaxMsgReactions = 50
+	// MaxUserReactions bounds the distinct reactions one user adds to a message.
+	MaxUserReactions = 10
+
+	reactionMaxLen = 64
+)
+
+type AddReactionReq struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	UserID         string `json:"userID"`
+	// Reaction is usually an emoji, it is stored as a mongo field name so it can not contain '.' or start with '$'.
+	Reaction string `json:"reaction"`
+}
+
+func (x *AddReactionReq) Check() error {
+	return checkReaction(x.ConversationID, x.Seq, x.UserID, x.Reaction)
+}
+
+type AddReactionResp struct{}
+
+type RemoveReactionReq struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	UserID         string `json:"userID"`
+	Reaction       string `json:"reaction"`
+}
+
+func (x *RemoveReactionReq) Check() error {
+	return checkReaction(x.ConversationID, x.Seq, x.UserID, x.Reaction)
+}
+
+type RemoveReactionResp struct{}
+
+func checkReaction(conversationID string, seq int64, userID string, reaction string) error {
+	if conversationID == "" {
+		return errs.ErrArgs.WrapMsg("conversationID is empty")
+	}
+	if seq <= 0 {
+		return errs.ErrArgs.WrapMsg("seq is invalid")
+	}
+	if userID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if reaction == "" || len(reaction) > reactionMaxLen {
+		return errs.ErrArgs.WrapMsg("reaction length is invalid", "maxLen", reactionMaxLen)
+	}
+	if strings.ContainsAny(reaction, ".\x00") || strings.HasPrefix(reaction, "$") {
+		return errs.ErrArgs.WrapMsg("reaction contains invalid characters")
+	}
+	return nil
+}
+
+type ReactionTips struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	ClientMsgID    string `json:"clientMsgID"`
+	SessionType    int32  `json:"sessionType"`
+	UserID         string `json:"userID"`
+	Reaction       string `json:"reaction"`
+	// IsAdd is false when the reaction is removed.
+	IsAdd bool `json:"isAdd"`
+}
+
+// Reaction is the aggregated reaction of a message.
+type Reaction struct {
+	Reaction string   `json:"reaction"`
+	Count    int      `json:"count"`
+	UserIDs  []string `json:"userIDs"`
+}
+
+// SetReactions returns attachedInfo with the aggregated reactions set under AttachedInfoReactions.
+// The reactions are sorted by count, reactions without reactor are dropped.
+func SetReactions(attachedInfo string, reactions map[string][]string) (string, error) {
+	list := make([]*Reaction, 0, len(reactions))
+	for reaction, userIDs := range reactions {
+		if len(userIDs) == 0 {
+			continue
+		}
+		list = append(list, &Reaction{Reaction: reaction, Count: len(userIDs), UserIDs: userIDs})
+	}
+	if len(list) == 0 {
+		return attachedInfo, nil
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Reaction < list[j].Reaction
+	})
+	return setAttachedInfo(attachedInfo, AttachedInfoReactions, list)
+}
+
+// setAttachedInfo returns attachedInfo with value set under key, the other keys are kept as they are.
+func setAttachedInfo(attachedInfo string, key string, value any) (string, error) {
+	info := make(map[string]json.RawMessage)
+	if attachedInfo != "" {
+		if err := json.Unmarshal([]byte(attachedInfo), &info); err != nil {
+			return "", errs.WrapMsg(err, "attached info is not a json object")
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	info[key] = data
+	res, err := json.Marshal(info)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	return string(res), nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetReactions(t *testing.T) {
+	info, err := SetReactions("", map[string][]string{"😂": {}})
+	assert.NoError(t, err)
+	assert.Equal(t, "", info)
+
+	info, err = SetReactions(`{"isPrivateChat":true}`, map[string][]string{
+		"👍": {"u1"},
+		"❤": {"u1", "u2"},
+		"😂": {},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"isPrivateChat": true,
+		"reactions": [
+			{"reaction": "❤", "count": 2, "userIDs": ["u1", "u2"]},
+			{"reaction": "👍", "count": 1, "userIDs": ["u1"]}
+		]
+	}`, info)
+
+	_, err = SetReactions("not json", map[string][]string{"👍": {"u1"}})
+	assert.Error(t, err)
+}
+
+func TestSetThread(t *testing.T) {
+	info, err := SetThread(`{"reactions":[]}`, &Thread{
+		ReplyCount: 3,
+		LastReply:  &ThreadReply{Seq: 3, SendID: "u1", ClientMsgID: "c3", SendTime: 100},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"reactions": [],
+		"thread": {"replyCount": 3, "lastReply": {"seq": 3, "sendID": "u1", "clientMsgID": "c3", "sendTime": 100}}
+	}`, info)
+}
+
+func TestCheckReaction(t *testing.T) {
+	req := &AddReactionReq{ConversationID: "si_u1_u2", Seq: 1, UserID: "u1", Reaction: "👍"}
+	assert.NoError(t, req.Check())
+	for _, reaction := range []string{"", "a.b", "$set", "a\x00"} {
+		req.Reaction = reaction
+		assert.Error(t, req.Check(), reaction)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"sort"
+
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// GroupMsgReadCountNotification is the content type of the notification pushed to the senders of group messages
+// when members read them, its detail is GroupMsgReadCountTips.
+const GroupMsgReadCountNotification = 2106
+
+type GetGroupMsgReadMembersReq struct {
+	ConversationID string `json:"conversationID"`
+	Seq            int64  `json:"seq"`
+	UserID         string `json:"userID"`
+	// Pagination is applied to both the read and the unread member lists.
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetGroupMsgReadMembersReq) Check() error {
+	if x.ConversationID == "" {
+		return errs.ErrArgs.WrapMsg("conversationID is empty")
+	}
+	if x.Seq <= 0 {
+		return errs.ErrArgs.WrapMsg("seq is invalid")
+	}
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetGroupMsgReadMembersResp struct {
+	ReadCount     int64    `json:"readCount"`
+	UnreadCount   int64    `json:"unreadCount"`
+	ReadUserIDs   []string `json:"readUserIDs"`
+	UnreadUserIDs []string `json:"unreadUserIDs"`
+}
+
+type GroupMsgReadCountTips struct {
+	ConversationID string               `json:"conversationID"`
+	GroupID        string               `json:"groupID"`
+	Msgs           []*GroupMsgReadCount `json:"msgs"`
+}
+
+type GroupMsgReadCount struct {
+	Seq         int64  `json:"seq"`
+	ClientMsgID string `json:"clientMsgID"`
+	ReadCount   int64  `json:"readCount"`
+	UnreadCount int64  `json:"unreadCount"`
+}
+
+// SplitReadMembers splits the members except the sender by whether their has read seq reached seq,
+// both lists are sorted so that they can be paginated stably.
+func SplitReadMembers(memberIDs []string, hasReadSeqs map[string]int64, seq int64, sendID string) (readUserIDs []string, unreadUserIDs []string) {
+	readUserIDs = make([]string, 0, len(memberIDs))
+	unreadUserIDs = make([]string, 0, len(memberIDs))
+	for _, userID := range memberIDs {
+		if userID == sendID {
+			continue
+		}
+		if hasReadSeqs[userID] >= seq {
+			readUserIDs = append(readUserIDs, userID)
+		} else {
+			unreadUserIDs = append(unreadUserIDs, userID)
+		}
+	}
+	sort.Strings(readUserIDs)
+	sort.Strings(unreadUserIDs)
+	return readUserIDs, unreadUserIDs
+}
@@ -0,0 +1,34 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitReadMembers(t *testing.T) {
+	memberIDs := []string{"u3", "sender", "u1", "u2", "u4"}
+	hasReadSeqs := map[string]int64{"u1": 10, "u2": 9, "u3": 12, "sender": 10}
+
+	read, unread := SplitReadMembers(memberIDs, hasReadSeqs, 10, "sender")
+	assert.Equal(t, []string{"u1", "u3"}, read)
+	assert.Equal(t, []string{"u2", "u4"}, unread)
+
+	read, unread = SplitReadMembers(nil, hasReadSeqs, 10, "sender")
+	assert.Empty(t, read)
+	assert.Empty(t, unread)
+}
@@ -0,0 +1,125 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"github.com/openimsdk/open-im-server/v3/pkg/retention"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+type RetentionPolicy struct {
+	// TargetType is one of the target types of the retention package.
+	TargetType int32  `json:"targetType"`
+	TargetID   string `json:"targetID"`
+	// RetainDays is the number of days the messages are kept, 0 keeps the global retention.
+	RetainDays     int64  `json:"retainDays"`
+	LegalHold      bool   `json:"legalHold"`
+	HoldReason     string `json:"holdReason"`
+	OperatorUserID string `json:"operatorUserID"`
+	UpdateTime     int64  `json:"updateTime"`
+}
+
+type SetRetentionPolicyReq struct {
+	TargetType int32  `json:"targetType"`
+	TargetID   string `json:"targetID"`
+	RetainDays int64  `json:"retainDays"`
+	LegalHold  bool   `json:"legalHold"`
+	HoldReason string `json:"holdReason"`
+}
+
+func (x *SetRetentionPolicyReq) Check() error {
+	if !retention.IsTargetType(x.TargetType) {
+		return errs.ErrArgs.WrapMsg("targetType is invalid", "targetType", x.TargetType)
+	}
+	if x.TargetID == "" {
+		return errs.ErrArgs.WrapMsg("targetID is empty")
+	}
+	if x.RetainDays < 0 {
+		return errs.ErrArgs.WrapMsg("retainDays is invalid")
+	}
+	if x.RetainDays == 0 && !x.LegalHold {
+		return errs.ErrArgs.WrapMsg("policy changes nothing, delete it instead")
+	}
+	return nil
+}
+
+type SetRetentionPolicyResp struct{}
+
+type DeleteRetentionPolicyReq struct {
+	TargetType int32  `json:"targetType"`
+	TargetID   string `json:"targetID"`
+}
+
+func (x *DeleteRetentionPolicyReq) Check() error {
+	if !retention.IsTargetType(x.TargetType) {
+		return errs.ErrArgs.WrapMsg("targetType is invalid", "targetType", x.TargetType)
+	}
+	if x.TargetID == "" {
+		return errs.ErrArgs.WrapMsg("targetID is empty")
+	}
+	return nil
+}
+
+type DeleteRetentionPolicyResp struct{}
+
+type GetRetentionPoliciesReq struct {
+	// TargetType filters the policies when it is not 0.
+	TargetType int32                    `json:"targetType"`
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetRetentionPoliciesReq) Check() error {
+	if x.TargetType != 0 && !retention.IsTargetType(x.TargetType) {
+		return errs.ErrArgs.WrapMsg("targetType is invalid", "targetType", x.TargetType)
+	}
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetRetentionPoliciesResp struct {
+	Total    int64              `json:"total"`
+	Policies []*RetentionPolicy `json:"policies"`
+}
+
+// RetentionSkip is a deletion of the messages of a conversation which a retention policy blocked.
+type RetentionSkip struct {
+	ConversationID string `json:"conversationID"`
+	// Operation is the rpc which skipped the deletion.
+	Operation      string `json:"operation"`
+	Reason         string `json:"reason"`
+	OperatorUserID string `json:"operatorUserID"`
+	CreateTime     int64  `json:"createTime"`
+}
+
+type GetRetentionSkipsReq struct {
+	// ConversationID filters the skips when it is not empty.
+	ConversationID string                   `json:"conversationID"`
+	Pagination     *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetRetentionSkipsReq) Check() error {
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetRetentionSkipsResp struct {
+	Total int64            `json:"total"`
+	Skips []*RetentionSkip `json:"skips"`
+}
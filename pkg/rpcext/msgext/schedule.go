@@ -0,0 +1,166 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"github.com/openimsdk/protocol/msg"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+const (
+	// ScheduledMsgPending is the status of a scheduled message waiting for its send time.
+	ScheduledMsgPending = 0
+	// ScheduledMsgSending is the status of a scheduled message claimed by the cron task.
+	ScheduledMsgSending = 1
+	// ScheduledMsgFailed is the status of a scheduled message rejected by SendMsg, it is kept so the sender can see why.
+	ScheduledMsgFailed = 2
+)
+
+type ScheduleMsgReq struct {
+	// SendTime is the time in milliseconds the message is sent at.
+	SendTime int64           `json:"sendTime"`
+	MsgReq   *msg.SendMsgReq `json:"msgReq"`
+}
+
+func (x *ScheduleMsgReq) Check() error {
+	if x.SendTime <= 0 {
+		return errs.ErrArgs.WrapMsg("sendTime is invalid")
+	}
+	if x.MsgReq == nil || x.MsgReq.MsgData == nil {
+		return errs.ErrArgs.WrapMsg("msgData is nil")
+	}
+	if x.MsgReq.MsgData.SendID == "" {
+		return errs.ErrArgs.WrapMsg("sendID is empty")
+	}
+	return nil
+}
+
+type ScheduleMsgResp struct {
+	ScheduleID string `json:"scheduleID"`
+}
+
+type ScheduledMsg struct {
+	ScheduleID string         `json:"scheduleID"`
+	SendTime   int64          `json:"sendTime"`
+	Status     int32          `json:"status"`
+	ErrMsg     string         `json:"errMsg"`
+	CreateTime int64          `json:"createTime"`
+	MsgData    *sdkws.MsgData `json:"msgData"`
+}
+
+type GetScheduledMsgsReq struct {
+	UserID     string                   `json:"userID"`
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetScheduledMsgsReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetScheduledMsgsResp struct {
+	Total int64           `json:"total"`
+	Msgs  []*ScheduledMsg `json:"msgs"`
+}
+
+type UpdateScheduledMsgReq struct {
+	UserID     string `json:"userID"`
+	ScheduleID string `json:"scheduleID"`
+	// SendTime replaces the send time when it is not 0.
+	SendTime int64 `json:"sendTime"`
+	// Content replaces the content of the message when it is not nil.
+	Content *string `json:"content"`
+}
+
+func (x *UpdateScheduledMsgReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if x.ScheduleID == "" {
+		return errs.ErrArgs.WrapMsg("scheduleID is empty")
+	}
+	if x.SendTime < 0 {
+		return errs.ErrArgs.WrapMsg("sendTime is invalid")
+	}
+	if x.SendTime == 0 && x.Content == nil {
+		return errs.ErrArgs.WrapMsg("nothing to update")
+	}
+	if x.Content != nil && *x.Content == "" {
+		return errs.ErrArgs.WrapMsg("content is empty")
+	}
+	return nil
+}
+
+type UpdateScheduledMsgResp struct{}
+
+type CancelScheduledMsgReq struct {
+	UserID     string `json:"userID"`
+	ScheduleID string `json:"scheduleID"`
+}
+
+func (x *CancelScheduledMsgReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if x.ScheduleID == "" {
+		return errs.ErrArgs.WrapMsg("scheduleID is empty")
+	}
+	return nil
+}
+
+type CancelScheduledMsgResp struct{}
+
+// ClaimDueScheduledMsgsReq is sent by the cron task, the claimed messages are not claimed again
+// until FinishScheduledMsg is called or the claim expires.
+type ClaimDueScheduledMsgsReq struct {
+	Limit int `json:"limit"`
+}
+
+func (x *ClaimDueScheduledMsgsReq) Check() error {
+	if x.Limit <= 0 {
+		return errs.ErrArgs.WrapMsg("limit is invalid")
+	}
+	return nil
+}
+
+type DueScheduledMsg struct {
+	ScheduleID string          `json:"scheduleID"`
+	MsgReq     *msg.SendMsgReq `json:"msgReq"`
+}
+
+type ClaimDueScheduledMsgsResp struct {
+	Msgs []*DueScheduledMsg `json:"msgs"`
+}
+
+type FinishScheduledMsgReq struct {
+	ScheduleID string `json:"scheduleID"`
+	// ErrMsg is the error returned by SendMsg, the scheduled message is removed when it is empty.
+	ErrMsg string `json:"errMsg"`
+}
+
+func (x *FinishScheduledMsgReq) Check() error {
+	if x.ScheduleID == "" {
+		return errs.ErrArgs.WrapMsg("scheduleID is empty")
+	}
+	return nil
+}
+
+type FinishScheduledMsgResp struct{}
@@ -0,0 +1,43 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUpdateScheduledMsg(t *testing.T) {
+	content := `{"content":"hi"}`
+	empty := ""
+	for _, c := range []struct {
+		req   UpdateScheduledMsgReq
+		valid bool
+	}{
+		{UpdateScheduledMsgReq{UserID: "u1", ScheduleID: "s1", SendTime: 1}, true},
+		{UpdateScheduledMsgReq{UserID: "u1", ScheduleID: "s1", Content: &content}, true},
+		{UpdateScheduledMsgReq{UserID: "u1", ScheduleID: "s1"}, false},
+		{UpdateScheduledMsgReq{UserID: "u1", ScheduleID: "s1", Content: &empty}, false},
+		{UpdateScheduledMsgReq{UserID: "u1", ScheduleID: "s1", SendTime: -1}, false},
+		{UpdateScheduledMsgReq{ScheduleID: "s1", SendTime: 1}, false},
+	} {
+		if c.valid {
+			assert.NoError(t, c.req.Check(), c.req)
+		} else {
+			assert.Error(t, c.req.Check(), c.req)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// FullTextSearchMsgReq searches the messages of all the conversations of the user by keywords.
+type FullTextSearchMsgReq struct {
+	UserID  string `json:"userID"`
+	Keyword string `json:"keyword"`
+	// ConversationIDs limits the search to these conversations of the user when it is not empty.
+	ConversationIDs []string `json:"conversationIDs"`
+	SendID          string   `json:"sendID"`
+	ContentTypes    []int32  `json:"contentTypes"`
+	// StartTime and EndTime limit the send time in milliseconds, 0 means no limit.
+	StartTime  int64                    `json:"startTime"`
+	EndTime    int64                    `json:"endTime"`
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *FullTextSearchMsgReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if x.Keyword == "" {
+		return errs.ErrArgs.WrapMsg("keyword is empty")
+	}
+	if x.StartTime < 0 || x.EndTime < 0 || (x.EndTime > 0 && x.StartTime > x.EndTime) {
+		return errs.ErrArgs.WrapMsg("time range is invalid")
+	}
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type FullTextSearchMsgResp struct {
+	// Total is the number of indexed hits, the messages the user can no longer see are dropped from the pages.
+	Total int64          `json:"total"`
+	Msgs  []*SearchedMsg `json:"msgs"`
+}
+
+type SearchedMsg struct {
+	ConversationID string         `json:"conversationID"`
+	Score          float64        `json:"score"`
+	MsgData        *sdkws.MsgData `json:"msgData"`
+}
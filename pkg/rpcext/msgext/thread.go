@@ -0,0 +1,36 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+// AttachedInfoThread is the key of the thread summary in the attached info of a pulled parent message.
+const AttachedInfoThread = "thread"
+
+// Thread is the summary of the thread anchored on a message.
+type Thread struct {
+	ReplyCount int64        `json:"replyCount"`
+	LastReply  *ThreadReply `json:"lastReply"`
+}
+
+type ThreadReply struct {
+	Seq         int64  `json:"seq"`
+	SendID      string `json:"sendID"`
+	ClientMsgID string `json:"clientMsgID"`
+	SendTime    int64  `json:"sendTime"`
+}
+
+// SetThread returns attachedInfo with the thread summary set under AttachedInfoThread.
+func SetThread(attachedInfo string, thread *Thread) (string, error) {
+	return setAttachedInfo(attachedInfo, AttachedInfoThread, thread)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"encoding/json"
+
+	"github.com/openimsdk/tools/errs"
+)
+
+// AttachedInfoTranslations is the key of the translations in the attached info of a pulled message,
+// it maps the language asked by the reader to the translated text.
+const AttachedInfoTranslations = "translations"
+
+// SetTranslations returns attachedInfo with translations set under AttachedInfoTranslations,
+// the key is removed when translations is empty.
+func SetTranslations(attachedInfo string, translations map[string]string) (string, error) {
+	if len(translations) == 0 {
+		return deleteAttachedInfo(attachedInfo, AttachedInfoTranslations)
+	}
+	return setAttachedInfo(attachedInfo, AttachedInfoTranslations, translations)
+}
+
+// GetTranslations returns the translations set in attachedInfo by SetTranslations.
+func GetTranslations(attachedInfo string) (map[string]string, error) {
+	if attachedInfo == "" {
+		return nil, nil
+	}
+	var info struct {
+		Translations map[string]string `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(attachedInfo), &info); err != nil {
+		return nil, errs.WrapMsg(err, "attached info is not a json object")
+	}
+	return info.Translations, nil
+}
+
+// deleteAttachedInfo returns attachedInfo without key, the other keys are kept as they are.
+func deleteAttachedInfo(attachedInfo string, key string) (string, error) {
+	if attachedInfo == "" {
+		return attachedInfo, nil
+	}
+	info := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(attachedInfo), &info); err != nil {
+		return "", errs.WrapMsg(err, "attached info is not a json object")
+	}
+	if _, ok := info[key]; !ok {
+		return attachedInfo, nil
+	}
+	delete(info, key)
+	res, err := json.Marshal(info)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	return string(res), nil
+}
@@ -0,0 +1,46 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslations(t *testing.T) {
+	info, err := SetTranslations(`{"isPrivateChat":true}`, map[string]string{"en": "hello"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"isPrivateChat": true, "translations": {"en": "hello"}}`, info)
+
+	translations, err := GetTranslations(info)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"en": "hello"}, translations)
+
+	info, err = SetTranslations(info, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"isPrivateChat": true}`, info)
+
+	info, err = SetTranslations("", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", info)
+
+	translations, err = GetTranslations("")
+	assert.NoError(t, err)
+	assert.Nil(t, translations)
+
+	_, err = GetTranslations("not json")
+	assert.Error(t, err)
+}
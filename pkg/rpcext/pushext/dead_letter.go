@@ -0,0 +1,67 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pushext
+
+import (
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/openimsdk/tools/errs"
+)
+
+// DeadLetter is an offline push which failed every attempt.
+type DeadLetter struct {
+	RetryID string `json:"retryID"`
+	// Provider is the provider which failed when the push went through several, empty for the whole push.
+	Provider    string   `json:"provider"`
+	UserIDs     []string `json:"userIDs"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	ClientMsgID string   `json:"clientMsgID"`
+	Attempts    int32    `json:"attempts"`
+	LastError   string   `json:"lastError"`
+	CreateTime  int64    `json:"createTime"`
+	UpdateTime  int64    `json:"updateTime"`
+}
+
+type GetDeadLettersReq struct {
+	Pagination *sdkws.RequestPagination `json:"pagination"`
+}
+
+func (x *GetDeadLettersReq) Check() error {
+	if x.Pagination == nil {
+		return errs.ErrArgs.WrapMsg("pagination is nil")
+	}
+	return nil
+}
+
+type GetDeadLettersResp struct {
+	Total       int64         `json:"total"`
+	DeadLetters []*DeadLetter `json:"deadLetters"`
+}
+
+type ReplayDeadLettersReq struct {
+	RetryIDs []string `json:"retryIDs"`
+}
+
+func (x *ReplayDeadLettersReq) Check() error {
+	if len(x.RetryIDs) == 0 {
+		return errs.ErrArgs.WrapMsg("retryIDs is empty")
+	}
+	return nil
+}
+
+type ReplayDeadLettersResp struct {
+	// RetryIDs are the dead letters queued again, the others did not exist.
+	RetryIDs []string `json:"retryIDs"`
+}
@@ -0,0 +1,138 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pushext is the extension service of the push rpc, it is served by the push rpc
+// next to push.PushMsgService. It follows the layout of the generated grpc code.
+package pushext
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	PushExt_GetDeadLetters_FullMethodName    = "/openim.pushext.PushExt/GetDeadLetters"
+	PushExt_ReplayDeadLetters_FullMethodName = "/openim.pushext.PushExt/ReplayDeadLetters"
+)
+
+// PushExtClient is the client API for PushExt service.
+type PushExtClient interface {
+	GetDeadLetters(ctx context.Context, in *GetDeadLettersReq, opts ...grpc.CallOption) (*GetDeadLettersResp, error)
+	ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersReq, opts ...grpc.CallOption) (*ReplayDeadLettersResp, error)
+}
+
+type pushExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPushExtClient(cc grpc.ClientConnInterface) PushExtClient {
+	return &pushExtClient{cc}
+}
+
+func (c *pushExtClient) GetDeadLetters(ctx context.Context, in *GetDeadLettersReq, opts ...grpc.CallOption) (*GetDeadLettersResp, error) {
+	out := new(GetDeadLettersResp)
+	err := c.cc.Invoke(ctx, PushExt_GetDeadLetters_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushExtClient) ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersReq, opts ...grpc.CallOption) (*ReplayDeadLettersResp, error) {
+	out := new(ReplayDeadLettersResp)
+	err := c.cc.Invoke(ctx, PushExt_ReplayDeadLetters_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PushExtServer is the server API for PushExt service.
+type PushExtServer interface {
+	GetDeadLetters(context.Context, *GetDeadLettersReq) (*GetDeadLettersResp, error)
+	ReplayDeadLetters(context.Context, *ReplayDeadLettersReq) (*ReplayDeadLettersResp, error)
+}
+
+// UnimplementedPushExtServer can be embedded to have forward compatible implementations.
+type UnimplementedPushExtServer struct{}
+
+func (UnimplementedPushExtServer) GetDeadLetters(context.Context, *GetDeadLettersReq) (*GetDeadLettersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeadLetters not implemented")
+}
+
+func (UnimplementedPushExtServer) ReplayDeadLetters(context.Context, *ReplayDeadLettersReq) (*ReplayDeadLettersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayDeadLetters not implemented")
+}
+
+func RegisterPushExtServer(s grpc.ServiceRegistrar, srv PushExtServer) {
+	s.RegisterService(&PushExt_ServiceDesc, srv)
+}
+
+func _PushExt_GetDeadLetters_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetDeadLettersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).GetDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushExt_GetDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(PushExtServer).GetDeadLetters(ctx, req.(*GetDeadLettersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushExt_ReplayDeadLetters_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ReplayDeadLettersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushExtServer).ReplayDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushExt_ReplayDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(PushExtServer).ReplayDeadLetters(ctx, req.(*ReplayDeadLettersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PushExt_ServiceDesc is the grpc.ServiceDesc for PushExt service.
+var PushExt_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "openim.pushext.PushExt",
+	HandlerType: (*PushExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDeadLetters",
+			Handler:    _PushExt_GetDeadLetters_Handler,
+		},
+		{
+			MethodName: "ReplayDeadLetters",
+			Handler:    _PushExt_ReplayDeadLetters_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pushext.go",
+}
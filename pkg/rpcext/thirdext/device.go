@@ -0,0 +1,93 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package thirdext
+
+import (
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/tools/errs"
+	"github.com/openimsdk/tools/utils/datautil"
+)
+
+// DevicePlatformIDs are the platforms whose devices can be registered for offline push.
+var DevicePlatformIDs = []int{constant.IOSPlatformID, constant.AndroidPlatformID, constant.IPadPlatformID, constant.AndroidPadPlatformID}
+
+type RegisterDeviceReq struct {
+	UserID     string `json:"userID"`
+	PlatformID int32  `json:"platformID"`
+	// Provider is the offline push provider issuing Token, e.g. apns or fcm, empty for the default of the server.
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+	// ExpireTime is the number of seconds the device is kept, 0 for until it is unregistered.
+	ExpireTime int64 `json:"expireTime"`
+}
+
+func (x *RegisterDeviceReq) Check() error {
+	if err := checkDevice(x.UserID, x.PlatformID); err != nil {
+		return err
+	}
+	if x.Token == "" {
+		return errs.ErrArgs.WrapMsg("token is empty")
+	}
+	if x.ExpireTime < 0 {
+		return errs.ErrArgs.WrapMsg("expireTime is invalid")
+	}
+	return nil
+}
+
+type RegisterDeviceResp struct{}
+
+type UnregisterDeviceReq struct {
+	UserID     string `json:"userID"`
+	PlatformID int32  `json:"platformID"`
+}
+
+func (x *UnregisterDeviceReq) Check() error {
+	return checkDevice(x.UserID, x.PlatformID)
+}
+
+type UnregisterDeviceResp struct{}
+
+func checkDevice(userID string, platformID int32) error {
+	if userID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	if !datautil.Contain(int(platformID), DevicePlatformIDs...) {
+		return errs.ErrArgs.WrapMsg("platformID does not support offline push", "platformID", platformID)
+	}
+	return nil
+}
+
+type GetDevicesReq struct {
+	UserID string `json:"userID"`
+}
+
+func (x *GetDevicesReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	return nil
+}
+
+type Device struct {
+	PlatformID int32  `json:"platformID"`
+	Provider   string `json:"provider"`
+	Token      string `json:"token"`
+	ExpireTime int64  `json:"expireTime"`
+	UpdateTime int64  `json:"updateTime"`
+}
+
+type GetDevicesResp struct {
+	Devices []*Device `json:"devices"`
+}
@@ -0,0 +1,176 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package thirdext is the extension service of the third rpc, it is served by the third rpc
+// next to third.Third. It follows the layout of the generated grpc code.
+package thirdext
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ThirdExt_RegisterDevice_FullMethodName   = "/openim.thirdext.ThirdExt/RegisterDevice"
+	ThirdExt_UnregisterDevice_FullMethodName = "/openim.thirdext.ThirdExt/UnregisterDevice"
+	ThirdExt_GetDevices_FullMethodName       = "/openim.thirdext.ThirdExt/GetDevices"
+)
+
+// ThirdExtClient is the client API for ThirdExt service.
+type ThirdExtClient interface {
+	RegisterDevice(ctx context.Context, in *RegisterDeviceReq, opts ...grpc.CallOption) (*RegisterDeviceResp, error)
+	UnregisterDevice(ctx context.Context, in *UnregisterDeviceReq, opts ...grpc.CallOption) (*UnregisterDeviceResp, error)
+	GetDevices(ctx context.Context, in *GetDevicesReq, opts ...grpc.CallOption) (*GetDevicesResp, error)
+}
+
+type thirdExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThirdExtClient(cc grpc.ClientConnInterface) ThirdExtClient {
+	return &thirdExtClient{cc}
+}
+
+func (c *thirdExtClient) RegisterDevice(ctx context.Context, in *RegisterDeviceReq, opts ...grpc.CallOption) (*RegisterDeviceResp, error) {
+	out := new(RegisterDeviceResp)
+	err := c.cc.Invoke(ctx, ThirdExt_RegisterDevice_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thirdExtClient) UnregisterDevice(ctx context.Context, in *UnregisterDeviceReq, opts ...grpc.CallOption) (*UnregisterDeviceResp, error) {
+	out := new(UnregisterDeviceResp)
+	err := c.cc.Invoke(ctx, ThirdExt_UnregisterDevice_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thirdExtClient) GetDevices(ctx context.Context, in *GetDevicesReq, opts ...grpc.CallOption) (*GetDevicesResp, error) {
+	out := new(GetDevicesResp)
+	err := c.cc.Invoke(ctx, ThirdExt_GetDevices_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ThirdExtServer is the server API for ThirdExt service.
+type ThirdExtServer interface {
+	RegisterDevice(context.Context, *RegisterDeviceReq) (*RegisterDeviceResp, error)
+	UnregisterDevice(context.Context, *UnregisterDeviceReq) (*UnregisterDeviceResp, error)
+	GetDevices(context.Context, *GetDevicesReq) (*GetDevicesResp, error)
+}
+
+// UnimplementedThirdExtServer can be embedded to have forward compatible implementations.
+type UnimplementedThirdExtServer struct{}
+
+func (UnimplementedThirdExtServer) RegisterDevice(context.Context, *RegisterDeviceReq) (*RegisterDeviceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterDevice not implemented")
+}
+
+func (UnimplementedThirdExtServer) UnregisterDevice(context.Context, *UnregisterDeviceReq) (*UnregisterDeviceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnregisterDevice not implemented")
+}
+
+func (UnimplementedThirdExtServer) GetDevices(context.Context, *GetDevicesReq) (*GetDevicesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDevices not implemented")
+}
+
+func RegisterThirdExtServer(s grpc.ServiceRegistrar, srv ThirdExtServer) {
+	s.RegisterService(&ThirdExt_ServiceDesc, srv)
+}
+
+func _ThirdExt_RegisterDevice_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(RegisterDeviceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThirdExtServer).RegisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThirdExt_RegisterDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(ThirdExtServer).RegisterDevice(ctx, req.(*RegisterDeviceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThirdExt_UnregisterDevice_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(UnregisterDeviceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThirdExtServer).UnregisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThirdExt_UnregisterDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(ThirdExtServer).UnregisterDevice(ctx, req.(*UnregisterDeviceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThirdExt_GetDevices_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetDevicesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThirdExtServer).GetDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThirdExt_GetDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(ThirdExtServer).GetDevices(ctx, req.(*GetDevicesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ThirdExt_ServiceDesc is the grpc.ServiceDesc for ThirdExt service.
+var ThirdExt_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "openim.thirdext.ThirdExt",
+	HandlerType: (*ThirdExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterDevice",
+			Handler:    _ThirdExt_RegisterDevice_Handler,
+		},
+		{
+			MethodName: "UnregisterDevice",
+			Handler:    _ThirdExt_UnregisterDevice_Handler,
+		},
+		{
+			MethodName: "GetDevices",
+			Handler:    _ThirdExt_GetDevices_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "thirdext.go",
+}
@@ -0,0 +1,99 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userext
+
+import (
+	"github.com/openimsdk/tools/errs"
+)
+
+// MaxNotificationSettingUsers is the maximum number of users whose settings are got at once.
+const MaxNotificationSettingUsers = 1000
+
+// DNDWindow is a time of the day during which the user is not notified by offline pushes.
+type DNDWindow struct {
+	// Start and End are "15:04" times of the day, a window which does not end after it starts ends the next day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Weekdays are the days the window starts on, 0 for Sunday, every day when empty.
+	Weekdays []int32 `json:"weekdays"`
+}
+
+type NotificationSetting struct {
+	UserID string `json:"userID"`
+	// TimeZone is the IANA name of the time zone of DNDWindows, e.g. Asia/Shanghai, UTC when empty.
+	TimeZone   string       `json:"timeZone"`
+	DNDWindows []*DNDWindow `json:"dndWindows"`
+	// Keywords notify the messages containing one of them, ignoring case, even during DNDWindows
+	// and in the groups where only the mentions are notified.
+	Keywords []string `json:"keywords"`
+	// GroupMentionOnly only notifies the group messages which mention the user or everyone.
+	GroupMentionOnly bool `json:"groupMentionOnly"`
+	// HidePreviewPlatformIDs are the platforms whose notifications do not show the content of the messages.
+	HidePreviewPlatformIDs []int32 `json:"hidePreviewPlatformIDs"`
+	UpdateTime             int64   `json:"updateTime"`
+}
+
+type SetNotificationSettingReq struct {
+	Setting *NotificationSetting `json:"setting"`
+}
+
+func (x *SetNotificationSettingReq) Check() error {
+	if x.Setting == nil {
+		return errs.ErrArgs.WrapMsg("setting is empty")
+	}
+	if x.Setting.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	for _, window := range x.Setting.DNDWindows {
+		if window == nil {
+			return errs.ErrArgs.WrapMsg("dndWindow is empty")
+		}
+	}
+	return nil
+}
+
+type SetNotificationSettingResp struct{}
+
+type DeleteNotificationSettingReq struct {
+	UserID string `json:"userID"`
+}
+
+func (x *DeleteNotificationSettingReq) Check() error {
+	if x.UserID == "" {
+		return errs.ErrArgs.WrapMsg("userID is empty")
+	}
+	return nil
+}
+
+type DeleteNotificationSettingResp struct{}
+
+type GetNotificationSettingsReq struct {
+	UserIDs []string `json:"userIDs"`
+}
+
+func (x *GetNotificationSettingsReq) Check() error {
+	if len(x.UserIDs) == 0 {
+		return errs.ErrArgs.WrapMsg("userIDs is empty")
+	}
+	if len(x.UserIDs) > MaxNotificationSettingUsers {
+		return errs.ErrArgs.WrapMsg("too many userIDs", "max", MaxNotificationSettingUsers)
+	}
+	return nil
+}
+
+type GetNotificationSettingsResp struct {
+	// Settings are the ones of the users who changed their setting, the others have the default one.
+	Settings []*NotificationSetting `json:"settings"`
+}
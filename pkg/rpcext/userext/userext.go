@@ -0,0 +1,176 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package userext is the extension service of the user rpc, it is served by the user rpc
+// next to user.User. It follows the layout of the generated grpc code.
+package userext
+
+import (
+	"context"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/rpcext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	UserExt_SetNotificationSetting_FullMethodName    = "/openim.userext.UserExt/SetNotificationSetting"
+	UserExt_DeleteNotificationSetting_FullMethodName = "/openim.userext.UserExt/DeleteNotificationSetting"
+	UserExt_GetNotificationSettings_FullMethodName   = "/openim.userext.UserExt/GetNotificationSettings"
+)
+
+// UserExtClient is the client API for UserExt service.
+type UserExtClient interface {
+	SetNotificationSetting(ctx context.Context, in *SetNotificationSettingReq, opts ...grpc.CallOption) (*SetNotificationSettingResp, error)
+	DeleteNotificationSetting(ctx context.Context, in *DeleteNotificationSettingReq, opts ...grpc.CallOption) (*DeleteNotificationSettingResp, error)
+	GetNotificationSettings(ctx context.Context, in *GetNotificationSettingsReq, opts ...grpc.CallOption) (*GetNotificationSettingsResp, error)
+}
+
+type userExtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUserExtClient(cc grpc.ClientConnInterface) UserExtClient {
+	return &userExtClient{cc}
+}
+
+func (c *userExtClient) SetNotificationSetting(ctx context.Context, in *SetNotificationSettingReq, opts ...grpc.CallOption) (*SetNotificationSettingResp, error) {
+	out := new(SetNotificationSettingResp)
+	err := c.cc.Invoke(ctx, UserExt_SetNotificationSetting_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userExtClient) DeleteNotificationSetting(ctx context.Context, in *DeleteNotificationSettingReq, opts ...grpc.CallOption) (*DeleteNotificationSettingResp, error) {
+	out := new(DeleteNotificationSettingResp)
+	err := c.cc.Invoke(ctx, UserExt_DeleteNotificationSetting_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userExtClient) GetNotificationSettings(ctx context.Context, in *GetNotificationSettingsReq, opts ...grpc.CallOption) (*GetNotificationSettingsResp, error) {
+	out := new(GetNotificationSettingsResp)
+	err := c.cc.Invoke(ctx, UserExt_GetNotificationSettings_FullMethodName, in, out, append(opts, rpcext.CallOption)...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UserExtServer is the server API for UserExt service.
+type UserExtServer interface {
+	SetNotificationSetting(context.Context, *SetNotificationSettingReq) (*SetNotificationSettingResp, error)
+	DeleteNotificationSetting(context.Context, *DeleteNotificationSettingReq) (*DeleteNotificationSettingResp, error)
+	GetNotificationSettings(context.Context, *GetNotificationSettingsReq) (*GetNotificationSettingsResp, error)
+}
+
+// UnimplementedUserExtServer can be embedded to have forward compatible implementations.
+type UnimplementedUserExtServer struct{}
+
+func (UnimplementedUserExtServer) SetNotificationSetting(context.Context, *SetNotificationSettingReq) (*SetNotificationSettingResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNotificationSetting not implemented")
+}
+
+func (UnimplementedUserExtServer) DeleteNotificationSetting(context.Context, *DeleteNotificationSettingReq) (*DeleteNotificationSettingResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNotificationSetting not implemented")
+}
+
+func (UnimplementedUserExtServer) GetNotificationSettings(context.Context, *GetNotificationSettingsReq) (*GetNotificationSettingsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationSettings not implemented")
+}
+
+func RegisterUserExtServer(s grpc.ServiceRegistrar, srv UserExtServer) {
+	s.RegisterService(&UserExt_ServiceDesc, srv)
+}
+
+func _UserExt_SetNotificationSetting_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(SetNotificationSettingReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserExtServer).SetNotificationSetting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserExt_SetNotificationSetting_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(UserExtServer).SetNotificationSetting(ctx, req.(*SetNotificationSettingReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserExt_DeleteNotificationSetting_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DeleteNotificationSettingReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserExtServer).DeleteNotificationSetting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserExt_DeleteNotificationSetting_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(UserExtServer).DeleteNotificationSetting(ctx, req.(*DeleteNotificationSettingReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserExt_GetNotificationSettings_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetNotificationSettingsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserExtServer).GetNotificationSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserExt_GetNotificationSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(UserExtServer).GetNotificationSettings(ctx, req.(*GetNotificationSettingsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UserExt_ServiceDesc is the grpc.ServiceDesc for UserExt service.
+var UserExt_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "openim.userext.UserExt",
+	HandlerType: (*UserExtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetNotificationSetting",
+			Handler:    _UserExt_SetNotificationSetting_Handler,
+		},
+		{
+			MethodName: "DeleteNotificationSetting",
+			Handler:    _UserExt_DeleteNotificationSetting_Handler,
+		},
+		{
+			MethodName: "GetNotificationSettings",
+			Handler:    _UserExt_GetNotificationSettings_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "userext.go",
+}
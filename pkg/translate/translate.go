@@ -0,0 +1,151 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package translate translates text messages into the languages their readers ask for.
+// The language of a reader is kept in the request context as a rpc custom header, like the tenant,
+// the translations are stored next to the message and returned in its attached info.
+package translate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openimsdk/open-im-server/v3/pkg/msgprocessor"
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+)
+
+// LanguageKey is the context key, rpc metadata key, connection query and request header of the reader's language.
+const LanguageKey = "language"
+
+// maxLanguageLength is the length of the longest language tag accepted, as limited by BCP 47.
+const maxLanguageLength = 35
+
+// Msg is the part of a message a Translator needs.
+type Msg struct {
+	ConversationID string
+	Seq            int64
+	SendID         string
+	ContentType    int32
+	Text           string
+}
+
+// Translator translates the text of messages.
+type Translator interface {
+	// Translate returns the text of msg translated into each of languages, the languages it can not translate are left out.
+	Translate(ctx context.Context, msg *Msg, languages []string) (map[string]string, error)
+}
+
+// NormalizeLanguage returns the lower case form of a language tag such as "zh-CN" or "pt_BR", it reports false
+// for the values which are not a language tag. The result is used as a mongo field name.
+func NormalizeLanguage(language string) (string, bool) {
+	language = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+	if len(language) < 2 || len(language) > maxLanguageLength {
+		return "", false
+	}
+	for i := 0; i < len(language); i++ {
+		c := language[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return "", false
+		}
+	}
+	if language[0] == '-' || language[len(language)-1] == '-' {
+		return "", false
+	}
+	return language, true
+}
+
+// NormalizeLanguages returns the valid languages of languages normalized and without duplicates.
+func NormalizeLanguages(languages []string) []string {
+	res := make([]string, 0, len(languages))
+	seen := make(map[string]struct{}, len(languages))
+	for _, language := range languages {
+		language, ok := NormalizeLanguage(language)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[language]; ok {
+			continue
+		}
+		seen[language] = struct{}{}
+		res = append(res, language)
+	}
+	return res
+}
+
+// WithLanguage sets the reader's language of ctx and registers it as a rpc custom header,
+// ctx is returned as it is when language is not a language tag.
+func WithLanguage(ctx context.Context, language string) context.Context {
+	language, ok := NormalizeLanguage(language)
+	if !ok {
+		return ctx
+	}
+	keys, _ := ctx.Value(constant.RpcCustomHeader).([]string)
+	found := false
+	for _, key := range keys {
+		if key == LanguageKey {
+			found = true
+			break
+		}
+	}
+	if !found {
+		keys = append(keys[:len(keys):len(keys)], LanguageKey)
+		ctx = context.WithValue(ctx, constant.RpcCustomHeader, keys)
+	}
+	return context.WithValue(ctx, LanguageKey, []string{language})
+}
+
+// GetLanguage returns the reader's language of ctx, empty when the reader did not ask for one.
+func GetLanguage(ctx context.Context) string {
+	if val, ok := ctx.Value(LanguageKey).([]string); ok && len(val) > 0 {
+		language, _ := NormalizeLanguage(val[0])
+		return language
+	}
+	return ""
+}
+
+// NewMsg returns the part of msg of conversationID a Translator needs,
+// it reports false when msg has no text to translate or its text is longer than maxTextLength.
+func NewMsg(conversationID string, msg *sdkws.MsgData, maxTextLength int) (*Msg, bool) {
+	text := msgprocessor.GetText(msg)
+	if text == "" || (maxTextLength > 0 && len(text) > maxTextLength) {
+		return nil, false
+	}
+	return &Msg{
+		ConversationID: conversationID,
+		Seq:            msg.Seq,
+		SendID:         msg.SendID,
+		ContentType:    msg.ContentType,
+		Text:           text,
+	}, true
+}
+
+// filterTranslations returns the non empty translations into languages, keyed by the normalized language.
+func filterTranslations(translations map[string]string, languages []string) map[string]string {
+	wanted := make(map[string]struct{}, len(languages))
+	for _, language := range languages {
+		wanted[language] = struct{}{}
+	}
+	res := make(map[string]string, len(languages))
+	for language, text := range translations {
+		language, ok := NormalizeLanguage(language)
+		if !ok || text == "" {
+			continue
+		}
+		if _, ok := wanted[language]; ok {
+			res[language] = text
+		}
+	}
+	return res
+}
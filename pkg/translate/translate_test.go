@@ -0,0 +1,70 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openimsdk/protocol/constant"
+	"github.com/openimsdk/protocol/sdkws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	for in, want := range map[string]string{
+		"en":     "en",
+		" zh-CN": "zh-cn",
+		"pt_BR":  "pt-br",
+	} {
+		language, ok := NormalizeLanguage(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, language)
+	}
+	for _, in := range []string{"", "e", "en.us", "$en", "-en", "en-", "zh CN"} {
+		_, ok := NormalizeLanguage(in)
+		assert.False(t, ok, in)
+	}
+	assert.Equal(t, []string{"en", "zh-cn"}, NormalizeLanguages([]string{"en", "EN", "zh_CN", "a.b"}))
+}
+
+func TestLanguageContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", GetLanguage(ctx))
+	assert.Equal(t, ctx, WithLanguage(ctx, "not a language"))
+
+	ctx = context.WithValue(ctx, constant.RpcCustomHeader, []string{"tenantID"})
+	ctx = WithLanguage(ctx, "zh_CN")
+	assert.Equal(t, "zh-cn", GetLanguage(ctx))
+	ctx = WithLanguage(ctx, "en")
+	assert.Equal(t, "en", GetLanguage(ctx))
+	assert.Equal(t, []string{"tenantID", LanguageKey}, ctx.Value(constant.RpcCustomHeader))
+}
+
+func TestNewMsg(t *testing.T) {
+	msg, ok := NewMsg("sg_g1", &sdkws.MsgData{ContentType: constant.Text, Content: []byte(`{"content":"hello"}`), Seq: 3}, 5)
+	assert.True(t, ok)
+	assert.Equal(t, &Msg{ConversationID: "sg_g1", Seq: 3, ContentType: constant.Text, Text: "hello"}, msg)
+
+	_, ok = NewMsg("sg_g1", &sdkws.MsgData{ContentType: constant.Text, Content: []byte(`{"content":"hello"}`)}, 4)
+	assert.False(t, ok)
+	_, ok = NewMsg("sg_g1", &sdkws.MsgData{ContentType: constant.Text, Content: []byte(`{"content":""}`)}, 0)
+	assert.False(t, ok)
+}
+
+func TestFilterTranslations(t *testing.T) {
+	res := filterTranslations(map[string]string{"EN": "hello", "fr": "", "de": "hallo", "x.y": "?"}, []string{"en", "fr"})
+	assert.Equal(t, map[string]string{"en": "hello"}, res)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2024 OpenIM. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translate
+
+import (
+	"context"
+
+	cbapi "github.com/openimsdk/open-im-server/v3/pkg/callbackstruct"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/config"
+	"github.com/openimsdk/open-im-server/v3/pkg/common/webhook"
+	"github.com/openimsdk/tools/mcontext"
+)
+
+type webhookTranslator struct {
+	client *webhook.Client
+	config *config.BeforeConfig
+}
+
+// NewWebhookTranslator returns the default Translator, it posts the texts to the translateMsg webhook.
+// It returns nil when the webhook is not enabled.
+func NewWebhookTranslator(client *webhook.Client, before *config.BeforeConfig) Translator {
+	if !before.Enable {
+		return nil
+	}
+	return &webhookTranslator{client: client, config: before}
+}
+
+func (w *webhookTranslator) Translate(ctx context.Context, msg *Msg, languages []string) (map[string]string, error) {
+	cbReq := &cbapi.CallbackTranslateMsgReq{
+		CallbackCommand: cbapi.CallbackTranslateMsgCommand,
+		OperationID:     mcontext.GetOperationID(ctx),
+		ConversationID:  msg.ConversationID,
+		Seq:             msg.Seq,
+		SendID:          msg.SendID,
+		ContentType:     msg.ContentType,
+		Text:            msg.Text,
+		Languages:       languages,
+	}
+	resp := &cbapi.CallbackTranslateMsgResp{}
+	if err := w.client.SyncPost(ctx, cbReq.GetCallbackCommand(), cbReq, resp, w.config); err != nil {
+		return nil, err
+	}
+	return filterTranslations(resp.Translations, languages), nil
+}